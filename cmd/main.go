@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+
 	"github.com/ghulammuzz/misterblast/config"
 	"github.com/ghulammuzz/misterblast/internal/app"
 	metrics "github.com/ghulammuzz/misterblast/pkg/prom"
@@ -12,5 +14,14 @@ func init() {
 }
 
 func main() {
+	switch flag.Arg(0) {
+	case "migrate":
+		app.Migrate(flag.Args()[1:])
+		return
+	case "import-questions":
+		app.ImportQuestions(flag.Args()[1:])
+		return
+	}
+
 	app.Start()
 }
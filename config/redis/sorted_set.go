@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis drops a sorted set once its last member is removed, so a built board
+// is tracked by a marker key that expires together with the set.
+func builtKey(key string) string {
+	return key + ":built"
+}
+
+// ReplaceSortedSet swaps the content of a sorted set in one transaction and
+// expires it after ttl. An empty set leaves no key behind to expire, so callers
+// that add members later must set the TTL themselves.
+func ReplaceSortedSet(ctx context.Context, key string, members []redis.Z, ttl time.Duration, rdb *redis.Client) error {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(members) > 0 {
+		pipe.ZAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, ttl)
+	}
+	pipe.Set(ctx, builtKey(key), 1, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error("Failed to replace sorted set in Redis: %v", err)
+		return fmt.Errorf("failed to replace sorted set in redis: %w", err)
+	}
+	return nil
+}
+
+// SortedSetBuilt reports which of the keys were filled by ReplaceSortedSet
+// and have not expired yet.
+func SortedSetBuilt(ctx context.Context, keys []string, rdb *redis.Client) ([]bool, error) {
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Exists(ctx, builtKey(key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to check sorted sets in redis: %w", err)
+	}
+
+	built := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		built[i] = cmd.Val() > 0
+	}
+	return built, nil
+}
+
+// DeleteSortedSets drops every sorted set matching pattern together with its
+// marker, so the sets are rebuilt on the next read.
+func DeleteSortedSets(ctx context.Context, pattern string, rdb *redis.Client) error {
+	iter := rdb.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan sorted sets in redis: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := rdb.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete sorted sets in redis: %w", err)
+	}
+	return nil
+}
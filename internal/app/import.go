@@ -0,0 +1,84 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+
+	pg "github.com/ghulammuzz/misterblast/config/postgres"
+	"github.com/ghulammuzz/misterblast/internal/question/importer"
+	questionRepo "github.com/ghulammuzz/misterblast/internal/question/repo"
+	questionSvc "github.com/ghulammuzz/misterblast/internal/question/svc"
+)
+
+// ImportQuestions runs `import-questions -set <id> -lang <lang> [-format csv|json]
+// [-dry-run] <file>` and exits non-zero on failure or row errors.
+func ImportQuestions(args []string) {
+	if err := runImportQuestions(args); err != nil {
+		fmt.Fprintln(os.Stderr, "import-questions:", err)
+		os.Exit(1)
+	}
+}
+
+func runImportQuestions(args []string) error {
+	fs := flag.NewFlagSet("import-questions", flag.ContinueOnError)
+	setID := fs.Int("set", 0, "set to import the questions into")
+	lang := fs.String("lang", "", "language of the questions")
+	format := fs.String("format", "", "csv or json, guessed from the file extension when empty")
+	dryRun := fs.Bool("dry-run", false, "validate and roll back instead of committing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *setID <= 0 || *lang == "" || fs.NArg() != 1 {
+		return fmt.Errorf("usage: import-questions -set <id> -lang <lang> [-format csv|json] [-dry-run] <file>")
+	}
+
+	path := fs.Arg(0)
+	f, err := importer.DetectFormat(*format, path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	questions, rowErrs, err := importer.Read(validator.New(), f, file, int32(*setID))
+	if err != nil {
+		return err
+	}
+	if len(rowErrs) == 0 {
+		db, err := pg.InitPostgres()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		service := questionSvc.NewQuestionService(questionRepo.NewQuestionRepository(db, nil))
+		result, err := service.ImportQuestions(int32(*setID), *lang, questions, *dryRun)
+		if err != nil {
+			return err
+		}
+		rowErrs = result.Errors
+		if len(rowErrs) == 0 {
+			verb := "imported"
+			if *dryRun {
+				verb = "checked"
+			}
+			fmt.Printf("%s %d question(s) with %d answer(s) into set %d\n", verb, result.Questions, result.Answers, result.SetID)
+			return nil
+		}
+	}
+
+	for _, e := range rowErrs {
+		if e.Field != "" {
+			fmt.Fprintf(os.Stderr, "row %d: %s: %s\n", e.Row, e.Field, e.Message)
+		} else {
+			fmt.Fprintf(os.Stderr, "row %d: %s\n", e.Row, e.Message)
+		}
+	}
+	return fmt.Errorf("%d row error(s), nothing imported", len(rowErrs))
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"time"
+
+	questionRepo "github.com/ghulammuzz/misterblast/internal/question/repo"
+	questionSvc "github.com/ghulammuzz/misterblast/internal/question/svc"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+const defaultItemStatsInterval = 24 * time.Hour
+
+// StartItemStatsSnapshots stores a fresh item analysis snapshot of every
+// question each ITEM_STATS_INTERVAL (default 24h). Set it to 0 to disable.
+func StartItemStatsSnapshots(db *sql.DB) {
+	interval := defaultItemStatsInterval
+	if raw := os.Getenv("ITEM_STATS_INTERVAL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Warn("Invalid ITEM_STATS_INTERVAL, using default: ", err)
+		} else {
+			interval = parsed
+		}
+	}
+	if interval <= 0 || db == nil {
+		return
+	}
+
+	service := questionSvc.NewQuestionService(questionRepo.NewQuestionRepository(db, nil))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			count, err := service.SnapshotItemStats(context.Background())
+			if err != nil {
+				log.Error("Item statistics snapshot failed: ", err)
+				continue
+			}
+			log.Info("Item statistics snapshot stored", "questions", count)
+		}
+	}()
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"database/sql"
+	"os"
+	"strconv"
+
+	taskRepo "github.com/ghulammuzz/misterblast/internal/task/repo"
+	taskSvc "github.com/ghulammuzz/misterblast/internal/task/svc"
+	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
+	userSvc "github.com/ghulammuzz/misterblast/internal/user/svc"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultJobWorkers = 4
+
+// StartJobWorker runs background jobs with up to JOB_WORKERS (default 4) at
+// a time. Set it to 0 to run no jobs in this process.
+func StartJobWorker(db *sql.DB, redis *redis.Client, files storage.Storage, mail mailer.Mailer) *queue.Worker {
+	workers := defaultJobWorkers
+	if raw := os.Getenv("JOB_WORKERS"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Warn("Invalid JOB_WORKERS, using default: ", err)
+		} else {
+			workers = parsed
+		}
+	}
+	if workers <= 0 || db == nil {
+		return nil
+	}
+
+	worker := queue.NewWorker(queue.NewPostgresQueue(db, redis), workers)
+	worker.Handle(taskSvc.JobSubmissionAttachment, taskSvc.SubmissionAttachmentHandler(taskRepo.NewTaskSubmissionRepository(db), files))
+	worker.Handle(userSvc.JobProfileImage, userSvc.ProfileImageHandler(userRepo.NewUserRepository(db), imaging.NewPipeline(db, files)))
+	worker.Handle(mailer.JobSend, mailer.DeliveryHandler(db, mail))
+	worker.Start()
+	return worker
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	pg "github.com/ghulammuzz/misterblast/config/postgres"
+	"github.com/ghulammuzz/misterblast/migrations"
+	"github.com/ghulammuzz/misterblast/pkg/migrate"
+)
+
+// Migrate runs `migrate up`, `migrate down [steps]` or `migrate status` and
+// exits non-zero on failure.
+func Migrate(args []string) {
+	if err := runMigrate(args); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [steps] | status")
+	}
+
+	db, err := pg.InitPostgres()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up()
+		fmt.Printf("applied %d migration(s)\n", n)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+		n, err := m.Down(steps)
+		fmt.Printf("rolled back %d migration(s)\n", n)
+		return err
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
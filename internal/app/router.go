@@ -6,22 +6,28 @@ import (
 	"os"
 
 	m "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/redis/go-redis/v9"
 
 	class "github.com/ghulammuzz/misterblast/internal/class/di"
+	classroom "github.com/ghulammuzz/misterblast/internal/classroom/di"
 	content "github.com/ghulammuzz/misterblast/internal/content/di"
 	email "github.com/ghulammuzz/misterblast/internal/email/di"
+	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
+	gamification "github.com/ghulammuzz/misterblast/internal/gamification/di"
 	lesson "github.com/ghulammuzz/misterblast/internal/lesson/di"
 	question "github.com/ghulammuzz/misterblast/internal/question/di"
 	quiz "github.com/ghulammuzz/misterblast/internal/quiz/di"
 	set "github.com/ghulammuzz/misterblast/internal/set/di"
 	task "github.com/ghulammuzz/misterblast/internal/task/di"
+	upload "github.com/ghulammuzz/misterblast/internal/upload/di"
 	user "github.com/ghulammuzz/misterblast/internal/user/di"
+	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
 )
 
-func SetupRouter(db *sql.DB, redis *redis.Client) *fiber.App {
+func SetupRouter(db *sql.DB, redis *redis.Client, files storage.Storage) *fiber.App {
 	app := fiber.New(fiber.Config{
 		DisableStartupMessage: true,
 	})
@@ -30,6 +36,21 @@ func SetupRouter(db *sql.DB, redis *redis.Client) *fiber.App {
 	app.Use(m.Recover())
 	app.Use(m.Metrics())
 
+	m.SetSessionChecker(userRepo.NewSessionRepository(db, redis))
+
+	// Unverified students may still log in and browse, but submitting quizzes
+	// and tasks waits for a verified email once this is on.
+	if os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true" {
+		m.SetVerificationChecker(emailRepo.NewEmailRepository(db))
+	}
+
+	// Local storage serves its own files and takes presigned uploads; the
+	// other backends have URLs of their own.
+	if local, ok := files.(*storage.Local); ok {
+		app.Get(local.MountPath()+"/*", local.Handler())
+		app.Put(local.MountPath()+"/*", local.Handler())
+	}
+
 	api := app.Group("/v1")
 	// api := app.Group("/v2")
 
@@ -37,13 +58,16 @@ func SetupRouter(db *sql.DB, redis *redis.Client) *fiber.App {
 	lesson.InitializedLessonService(db, redis, m.Validate).Router(api)
 	set.InitializedSetService(db, redis, m.Validate).Router(api)
 	question.InitializedQuestionService(db, redis, m.Validate).Router(api)
-	user.InitializedUserService(db, m.Validate).Router(api)
-	email.InitializedEmailService(db, m.Validate).Router(api)
-	quiz.InitializedQuizService(db, m.Validate).Router(api)
+	user.InitializedUserService(db, redis, m.Validate).Router(api)
+	email.InitializedEmailService(db, redis, m.Validate).Router(api)
+	quiz.InitializedQuizService(db, redis, m.Validate).Router(api)
+	gamification.InitializedGamificationService(db, m.Validate).Router(api)
+	classroom.InitializedClassroomService(db, redis, m.Validate).Router(api)
 	task.InitializeTaskService(db, m.Validate).Router(api)
-	task.InitializeTaskSubmissionService(db, m.Validate).Router(api)
-	content.InitializedContentService(db, redis, m.Validate).Router(api)
+	task.InitializeTaskSubmissionService(db, redis, m.Validate).Router(api)
+	content.InitializedContentService(db, redis, files, m.Validate).Router(api)
 	content.InitializedAuthorService(db, redis, m.Validate).Router(api)
+	upload.InitializedUploadService(db, files, m.Validate).Router(api)
 
 	app.Get("/.well-known/assetlinks.json", func(c *fiber.Ctx) error {
 		jsonData, err := os.ReadFile("internal-link.json")
@@ -6,7 +6,9 @@ import (
 
 	pg "github.com/ghulammuzz/misterblast/config/postgres"
 	cache "github.com/ghulammuzz/misterblast/config/redis"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 )
 
 func Start() {
@@ -25,7 +27,19 @@ func Start() {
 		defer redis.Close()
 	}
 
-	app := SetupRouter(db, redis)
+	files, err := storage.FromEnv()
+	if err != nil {
+		log.Error("Storage misconfigured: ", err.Error())
+		os.Exit(1)
+	}
+
+	mail, err := mailer.FromEnv()
+	if err != nil {
+		log.Error("Mailer misconfigured: ", err.Error())
+		os.Exit(1)
+	}
+
+	app := SetupRouter(db, redis, files)
 
 	RegisterHealthRoutes(app, db)
 
@@ -36,6 +50,12 @@ func Start() {
 	}()
 
 	StartPrometheusExporter()
+	StartItemStatsSnapshots(db)
+	worker := StartJobWorker(db, redis, files, mail)
 
 	GracefulShutdown(app)
+
+	if worker != nil {
+		worker.Stop()
+	}
 }
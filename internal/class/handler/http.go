@@ -18,8 +18,8 @@ func NewClassHandler(classService classSvc.ClassService) *ClassHandler {
 }
 
 func (h *ClassHandler) Router(r fiber.Router) {
-	r.Post("/class", m.R100(), h.AddClassHandler)
-	r.Delete("/class/:id", m.R100(), h.DeleteClassHandler)
+	r.Post("/class", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageCurriculum), h.AddClassHandler)
+	r.Delete("/class/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageCurriculum), h.DeleteClassHandler)
 	r.Get("/class", m.R100(), h.ListClassesHandler)
 }
 
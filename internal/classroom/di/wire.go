@@ -0,0 +1,31 @@
+//go:build wireinject
+// +build wireinject
+
+package di
+
+import (
+	"database/sql"
+
+	classroomHandler "github.com/ghulammuzz/misterblast/internal/classroom/handler"
+	classroomRepo "github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	classroomSvc "github.com/ghulammuzz/misterblast/internal/classroom/svc"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitializedClassroomServiceFake(sb *sql.DB, redis *redis.Client, val *validator.Validate) *classroomHandler.ClassroomHandler {
+	wire.Build(
+		classroomHandler.NewClassroomHandler,
+		classroomSvc.NewClassroomService,
+		classroomRepo.NewClassroomRepository,
+		mailer.NewOutbox,
+		queue.NewPostgresQueue,
+		wire.Bind(new(queue.Enqueuer), new(*queue.PostgresQueue)),
+		wire.Bind(new(mailer.Sender), new(*mailer.Outbox)),
+	)
+
+	return &classroomHandler.ClassroomHandler{}
+}
@@ -0,0 +1,53 @@
+// Package enrollment holds the rules shared by the classroom module and the
+// listings it scopes: join codes and the enrolled-class SQL filter.
+package enrollment
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// CodeLength is the length of a classroom join code.
+const CodeLength = 8
+
+// codeAlphabet leaves out 0/O and 1/I/L so codes read back unambiguously.
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// NewCode returns a random join code.
+func NewCode() (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	code := make([]byte, CodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// NormalizeCode turns user input like "abcd-efgh " into the stored form.
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// ClassFilter returns an SQL condition that keeps rows whose column (a
+// classes.id) belongs to a classroom the user bound to placeholder $arg is
+// enrolled in. Users without any enrollment are not restricted, so the open
+// catalog keeps working for them.
+func ClassFilter(column string, arg int) string {
+	return fmt.Sprintf(`(NOT EXISTS (SELECT 1 FROM classroom_members cm WHERE cm.user_id = $%[2]d)
+		OR %[1]s IN (
+			SELECT cr.class_id FROM classroom_members cm
+			JOIN classrooms cr ON cr.id = cm.classroom_id
+			WHERE cm.user_id = $%[2]d))`, column, arg)
+}
@@ -0,0 +1,36 @@
+package enrollment_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		code, err := enrollment.NewCode()
+		require.NoError(t, err)
+		assert.Len(t, code, enrollment.CodeLength)
+		assert.Equal(t, code, enrollment.NormalizeCode(code))
+		assert.False(t, strings.ContainsAny(code, "0O1IL"), code)
+		seen[code] = true
+	}
+	assert.Greater(t, len(seen), 45)
+}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "ABCDEFGH", enrollment.NormalizeCode(" abcd-efgh "))
+	assert.Equal(t, "", enrollment.NormalizeCode(""))
+}
+
+func TestClassFilter(t *testing.T) {
+	filter := enrollment.ClassFilter("s.class_id", 3)
+
+	assert.Contains(t, filter, "s.class_id IN (")
+	assert.Equal(t, 2, strings.Count(filter, "cm.user_id = $3"))
+	assert.NotContains(t, filter, "%!")
+}
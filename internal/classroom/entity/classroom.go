@@ -0,0 +1,67 @@
+package entity
+
+// Classroom is a teacher's group of students studying one grade-level class.
+// JoinCode is only filled in for the teacher and admins.
+type Classroom struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	ClassID   int32  `json:"class_id"`
+	Class     string `json:"class"`
+	TeacherID int32  `json:"teacher_id"`
+	Teacher   string `json:"teacher"`
+	JoinCode  string `json:"join_code,omitempty"`
+	Members   int    `json:"members"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// MyClassroom is a classroom in the caller's own list, with the part they play
+// in it: "teacher" or "student".
+type MyClassroom struct {
+	Classroom
+	Role string `json:"role"`
+}
+
+type SetClassroom struct {
+	Name    string `json:"name" validate:"required,min=3,max=100"`
+	ClassID int32  `json:"class_id" validate:"required,gt=0"`
+}
+
+type JoinClassroom struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type InviteStudents struct {
+	Emails []string `json:"emails" validate:"required,min=1,max=50,dive,required,email"`
+}
+
+// InviteResult lists the addresses an invite went out to and those the mail
+// server rejected.
+type InviteResult struct {
+	Invited []string `json:"invited"`
+	Failed  []string `json:"failed"`
+}
+
+type Invite struct {
+	ID          int32
+	ClassroomID int32
+	Email       string
+	Token       string
+	InvitedBy   int32
+	ExpiresAt   int64
+	AcceptedAt  *int64
+}
+
+type Member struct {
+	UserID   int32   `json:"user_id"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	ImgURL   *string `json:"img_url"`
+	JoinedAt int64   `json:"joined_at"`
+}
+
+// Caller is who a classroom request is made on behalf of. Admins may manage
+// every classroom, teachers only their own.
+type Caller struct {
+	UserID int32
+	Admin  bool
+}
@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	m "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type ClassroomHandler struct {
+	classroomService svc.ClassroomService
+	val              *validator.Validate
+}
+
+func NewClassroomHandler(classroomService svc.ClassroomService, val *validator.Validate) *ClassroomHandler {
+	return &ClassroomHandler{classroomService, val}
+}
+
+func (h *ClassroomHandler) Router(r fiber.Router) {
+	r.Get("/classrooms", m.R100(), m.JWTProtected(), h.ListMineHandler)
+	r.Post("/classrooms", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageClassrooms), h.AddClassroomHandler)
+	r.Post("/classrooms/join", m.R100(), m.JWTProtected(), h.JoinHandler)
+	r.Post("/classrooms/invites/:token/accept", m.R100(), m.JWTProtected(), h.AcceptInviteHandler)
+	r.Get("/classrooms/:id", m.R100(), m.JWTProtected(), h.DetailClassroomHandler)
+	r.Put("/classrooms/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageClassrooms), h.EditClassroomHandler)
+	r.Delete("/classrooms/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageClassrooms), h.DeleteClassroomHandler)
+	r.Post("/classrooms/:id/join-code", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageClassrooms), h.RegenerateJoinCodeHandler)
+	r.Post("/classrooms/:id/invites", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageClassrooms), h.InviteHandler)
+	r.Get("/classrooms/:id/roster", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageClassrooms), h.RosterHandler)
+	// Teachers drop students here and students use it to leave.
+	r.Delete("/classrooms/:id/members/:user_id", m.R100(), m.JWTProtected(), h.RemoveMemberHandler)
+}
+
+func (h *ClassroomHandler) ListMineHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	classrooms, err := h.classroomService.ListMine(caller.UserID)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "classrooms retrieved successfully", classrooms)
+}
+
+func (h *ClassroomHandler) AddClassroomHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var classroom entity.SetClassroom
+	if err := c.BodyParser(&classroom); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(classroom); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	created, err := h.classroomService.Add(caller.UserID, classroom)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "classroom added successfully", created)
+}
+
+func (h *ClassroomHandler) DetailClassroomHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	classroom, err := h.classroomService.Detail(caller, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "classroom retrieved successfully", classroom)
+}
+
+func (h *ClassroomHandler) EditClassroomHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	var classroom entity.SetClassroom
+	if err := c.BodyParser(&classroom); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(classroom); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	if err := h.classroomService.Edit(caller, int32(id), classroom); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "classroom updated successfully", nil)
+}
+
+func (h *ClassroomHandler) DeleteClassroomHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	if err := h.classroomService.Delete(caller, int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "classroom deleted successfully", nil)
+}
+
+func (h *ClassroomHandler) RegenerateJoinCodeHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	code, err := h.classroomService.RegenerateJoinCode(caller, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "join code regenerated successfully", fiber.Map{"join_code": code})
+}
+
+func (h *ClassroomHandler) JoinHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var req entity.JoinClassroom
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	classroom, err := h.classroomService.Join(caller.UserID, req.Code)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "joined classroom successfully", classroom)
+}
+
+func (h *ClassroomHandler) InviteHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	var req entity.InviteStudents
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	result, err := h.classroomService.Invite(caller, int32(id), req.Emails)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "invites sent", result)
+}
+
+func (h *ClassroomHandler) AcceptInviteHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	classroom, err := h.classroomService.AcceptInvite(caller.UserID, c.Params("token"))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "joined classroom successfully", classroom)
+}
+
+func (h *ClassroomHandler) RosterHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	members, err := h.classroomService.Roster(caller, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "roster retrieved successfully", members)
+}
+
+func (h *ClassroomHandler) RemoveMemberHandler(c *fiber.Ctx) error {
+	caller, ok := callerFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+	userID, err := c.ParamsInt("user_id")
+	if err != nil || userID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid user id", nil)
+	}
+
+	if err := h.classroomService.RemoveMember(caller, int32(id), int32(userID)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "member removed successfully", nil)
+}
+
+// callerFromCtx reads the caller from the claims JWTProtected stored.
+func callerFromCtx(c *fiber.Ctx) (entity.Caller, bool) {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return entity.Caller{}, false
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return entity.Caller{}, false
+	}
+	return entity.Caller{UserID: int32(userID), Admin: m.RoleFromClaims(claims) == m.RoleAdmin}, true
+}
@@ -0,0 +1,158 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/handler"
+	appErr "github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClassroomService struct {
+	mock.Mock
+}
+
+func (m *MockClassroomService) Add(teacherID int32, classroom entity.SetClassroom) (entity.Classroom, error) {
+	args := m.Called(teacherID, classroom)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) Edit(caller entity.Caller, id int32, classroom entity.SetClassroom) error {
+	return m.Called(caller, id, classroom).Error(0)
+}
+
+func (m *MockClassroomService) Delete(caller entity.Caller, id int32) error {
+	return m.Called(caller, id).Error(0)
+}
+
+func (m *MockClassroomService) Detail(caller entity.Caller, id int32) (entity.Classroom, error) {
+	args := m.Called(caller, id)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) ListMine(userID int32) ([]entity.MyClassroom, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.MyClassroom), args.Error(1)
+}
+
+func (m *MockClassroomService) RegenerateJoinCode(caller entity.Caller, id int32) (string, error) {
+	args := m.Called(caller, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockClassroomService) Join(userID int32, code string) (entity.Classroom, error) {
+	args := m.Called(userID, code)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) Invite(caller entity.Caller, id int32, emails []string) (entity.InviteResult, error) {
+	args := m.Called(caller, id, emails)
+	return args.Get(0).(entity.InviteResult), args.Error(1)
+}
+
+func (m *MockClassroomService) AcceptInvite(userID int32, token string) (entity.Classroom, error) {
+	args := m.Called(userID, token)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) Roster(caller entity.Caller, id int32) ([]entity.Member, error) {
+	args := m.Called(caller, id)
+	return args.Get(0).([]entity.Member), args.Error(1)
+}
+
+func (m *MockClassroomService) RemoveMember(caller entity.Caller, id, userID int32) error {
+	return m.Called(caller, id, userID).Error(0)
+}
+
+// withClaims stands in for JWTProtected.
+func withClaims(claims jwt.MapClaims) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("claims", claims)
+		return c.Next()
+	}
+}
+
+func TestAddClassroomHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classrooms", withClaims(jwt.MapClaims{"user_id": float64(7), "role": "teacher"}), h.AddClassroomHandler)
+
+	req := entity.SetClassroom{Name: "5A Pagi", ClassID: 5}
+	mockService.On("Add", int32(7), req).Return(entity.Classroom{ID: 3, Name: "5A Pagi", JoinCode: "ABCDEFGH"}, nil)
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/classrooms", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(httpReq)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ = json.Marshal(entity.SetClassroom{Name: "5A"})
+	httpReq = httptest.NewRequest(http.MethodPost, "/classrooms", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(httpReq)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDetailClassroomHandler_Admin(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Get("/classrooms/:id", withClaims(jwt.MapClaims{"user_id": float64(1), "is_admin": true}), h.DetailClassroomHandler)
+
+	mockService.On("Detail", entity.Caller{UserID: 1, Admin: true}, int32(3)).Return(entity.Classroom{ID: 3}, nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/classrooms/3", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestJoinHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classrooms/join", withClaims(jwt.MapClaims{"user_id": float64(11), "role": "student"}), h.JoinHandler)
+
+	mockService.On("Join", int32(11), "NOPE").Return(entity.Classroom{}, appErr.NewAppError(http.StatusNotFound, "invalid join code"))
+
+	body, _ := json.Marshal(entity.JoinClassroom{Code: "NOPE"})
+	req := httptest.NewRequest(http.MethodPost, "/classrooms/join", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestInviteHandler_Validation(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classrooms/:id/invites", withClaims(jwt.MapClaims{"user_id": float64(7), "role": "teacher"}), h.InviteHandler)
+
+	body, _ := json.Marshal(entity.InviteStudents{Emails: []string{"not-an-email"}})
+	req := httptest.NewRequest(http.MethodPost, "/classrooms/3/invites", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Invite", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListMineHandler_NoClaims(t *testing.T) {
+	app := fiber.New()
+	h := handler.NewClassroomHandler(new(MockClassroomService), validator.New())
+	app.Get("/classrooms", h.ListMineHandler)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/classrooms", nil))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
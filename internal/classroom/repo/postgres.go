@@ -0,0 +1,243 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type ClassroomRepository interface {
+	// Classrooms
+	ClassExists(classID int32) (bool, error)
+	Add(teacherID int32, classroom entity.SetClassroom, joinCode string) (int32, error)
+	Edit(id int32, classroom entity.SetClassroom) error
+	Delete(id int32) error
+	Detail(id int32) (entity.Classroom, error)
+	ListMine(userID int32) ([]entity.MyClassroom, error)
+	JoinCodeExists(code string) (bool, error)
+	SetJoinCode(id int32, code string) error
+	FindByJoinCode(code string) (entity.Classroom, error)
+
+	// Members
+	IsMember(classroomID, userID int32) (bool, error)
+	AddMember(classroomID, userID int32) error
+	RemoveMember(classroomID, userID int32) error
+	Roster(classroomID int32) ([]entity.Member, error)
+
+	// Invites
+	UserEmail(userID int32) (string, error)
+	AddInvite(invite entity.Invite) error
+	Invite(token string) (entity.Invite, error)
+	AcceptInvite(invite entity.Invite, userID int32) error
+}
+
+type classroomRepository struct {
+	db *sql.DB
+}
+
+func NewClassroomRepository(db *sql.DB) ClassroomRepository {
+	return &classroomRepository{db: db}
+}
+
+const classroomColumns = `
+	SELECT cr.id, cr.name, cr.class_id, c.name, cr.teacher_id, u.name, cr.join_code,
+	       (SELECT COUNT(*) FROM classroom_members cm WHERE cm.classroom_id = cr.id), cr.created_at`
+
+const classroomJoins = `
+	FROM classrooms cr
+	JOIN classes c ON c.id = cr.class_id
+	JOIN users u ON u.id = cr.teacher_id`
+
+func (r *classroomRepository) ClassExists(classID int32) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM classes WHERE id = $1)`, classID).Scan(&exists)
+	if err != nil {
+		log.Error("[Repo][ClassExists] Error Query: ", err)
+		return false, app.NewAppError(500, "failed to check class")
+	}
+	return exists, nil
+}
+
+func (r *classroomRepository) Add(teacherID int32, classroom entity.SetClassroom, joinCode string) (int32, error) {
+	var id int32
+	err := r.db.QueryRow(`
+		INSERT INTO classrooms (name, class_id, teacher_id, join_code)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, classroom.Name, classroom.ClassID, teacherID, joinCode).Scan(&id)
+	if err != nil {
+		log.Error("[Repo][AddClassroom] Error Insert: ", err)
+		return 0, app.NewAppError(500, "failed to add classroom")
+	}
+	return id, nil
+}
+
+func (r *classroomRepository) Edit(id int32, classroom entity.SetClassroom) error {
+	res, err := r.db.Exec(`
+		UPDATE classrooms
+		SET name = $2, class_id = $3, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1
+	`, id, classroom.Name, classroom.ClassID)
+	if err != nil {
+		log.Error("[Repo][EditClassroom] Error Update: ", err)
+		return app.NewAppError(500, "failed to edit classroom")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "classroom not found")
+	}
+	return nil
+}
+
+// Delete removes the classroom together with its members and invites.
+func (r *classroomRepository) Delete(id int32) error {
+	res, err := r.db.Exec(`DELETE FROM classrooms WHERE id = $1`, id)
+	if err != nil {
+		log.Error("[Repo][DeleteClassroom] Error Delete: ", err)
+		return app.NewAppError(500, "failed to delete classroom")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "classroom not found")
+	}
+	return nil
+}
+
+func (r *classroomRepository) Detail(id int32) (entity.Classroom, error) {
+	return r.queryClassroom(classroomColumns+classroomJoins+` WHERE cr.id = $1`, id)
+}
+
+func (r *classroomRepository) FindByJoinCode(code string) (entity.Classroom, error) {
+	return r.queryClassroom(classroomColumns+classroomJoins+` WHERE cr.join_code = $1`, code)
+}
+
+func (r *classroomRepository) queryClassroom(query string, args ...interface{}) (entity.Classroom, error) {
+	var c entity.Classroom
+	err := r.db.QueryRow(query, args...).Scan(&c.ID, &c.Name, &c.ClassID, &c.Class, &c.TeacherID, &c.Teacher, &c.JoinCode, &c.Members, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c, app.NewAppError(404, "classroom not found")
+		}
+		log.Error("[Repo][queryClassroom] Error Query: ", err)
+		return c, app.NewAppError(500, "failed to get classroom")
+	}
+	return c, nil
+}
+
+// ListMine returns the classrooms the user teaches or is enrolled in.
+func (r *classroomRepository) ListMine(userID int32) ([]entity.MyClassroom, error) {
+	rows, err := r.db.Query(classroomColumns+`,
+	       CASE WHEN cr.teacher_id = $1 THEN 'teacher' ELSE 'student' END`+classroomJoins+`
+	WHERE cr.teacher_id = $1
+	   OR EXISTS (SELECT 1 FROM classroom_members cm WHERE cm.classroom_id = cr.id AND cm.user_id = $1)
+	ORDER BY cr.name, cr.id
+	`, userID)
+	if err != nil {
+		log.Error("[Repo][ListMine] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to get classrooms")
+	}
+	defer rows.Close()
+
+	classrooms := []entity.MyClassroom{}
+	for rows.Next() {
+		var c entity.MyClassroom
+		if err := rows.Scan(&c.ID, &c.Name, &c.ClassID, &c.Class, &c.TeacherID, &c.Teacher, &c.JoinCode, &c.Members, &c.CreatedAt, &c.Role); err != nil {
+			log.Error("[Repo][ListMine] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan classrooms")
+		}
+		classrooms = append(classrooms, c)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListMine] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read classrooms")
+	}
+	return classrooms, nil
+}
+
+func (r *classroomRepository) JoinCodeExists(code string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM classrooms WHERE join_code = $1)`, code).Scan(&exists)
+	if err != nil {
+		log.Error("[Repo][JoinCodeExists] Error Query: ", err)
+		return false, app.NewAppError(500, "failed to check join code")
+	}
+	return exists, nil
+}
+
+func (r *classroomRepository) SetJoinCode(id int32, code string) error {
+	res, err := r.db.Exec(`UPDATE classrooms SET join_code = $2, updated_at = EXTRACT(EPOCH FROM NOW()) WHERE id = $1`, id, code)
+	if err != nil {
+		log.Error("[Repo][SetJoinCode] Error Update: ", err)
+		return app.NewAppError(500, "failed to update join code")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "classroom not found")
+	}
+	return nil
+}
+
+func (r *classroomRepository) IsMember(classroomID, userID int32) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM classroom_members WHERE classroom_id = $1 AND user_id = $2)`, classroomID, userID).Scan(&exists)
+	if err != nil {
+		log.Error("[Repo][IsMember] Error Query: ", err)
+		return false, app.NewAppError(500, "failed to check membership")
+	}
+	return exists, nil
+}
+
+// AddMember enrolls the user; enrolling twice is a no-op.
+func (r *classroomRepository) AddMember(classroomID, userID int32) error {
+	_, err := r.db.Exec(`
+		INSERT INTO classroom_members (classroom_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (classroom_id, user_id) DO NOTHING
+	`, classroomID, userID)
+	if err != nil {
+		log.Error("[Repo][AddMember] Error Insert: ", err)
+		return app.NewAppError(500, "failed to join classroom")
+	}
+	return nil
+}
+
+func (r *classroomRepository) RemoveMember(classroomID, userID int32) error {
+	res, err := r.db.Exec(`DELETE FROM classroom_members WHERE classroom_id = $1 AND user_id = $2`, classroomID, userID)
+	if err != nil {
+		log.Error("[Repo][RemoveMember] Error Delete: ", err)
+		return app.NewAppError(500, "failed to remove member")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "member not found")
+	}
+	return nil
+}
+
+func (r *classroomRepository) Roster(classroomID int32) ([]entity.Member, error) {
+	rows, err := r.db.Query(`
+		SELECT u.id, u.name, u.email, u.img_url, cm.joined_at
+		FROM classroom_members cm
+		JOIN users u ON u.id = cm.user_id
+		WHERE cm.classroom_id = $1
+		ORDER BY u.name, u.id
+	`, classroomID)
+	if err != nil {
+		log.Error("[Repo][Roster] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to get roster")
+	}
+	defer rows.Close()
+
+	members := []entity.Member{}
+	for rows.Next() {
+		var m entity.Member
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.ImgURL, &m.JoinedAt); err != nil {
+			log.Error("[Repo][Roster] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan roster")
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][Roster] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read roster")
+	}
+	return members, nil
+}
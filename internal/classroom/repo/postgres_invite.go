@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+func (r *classroomRepository) UserEmail(userID int32) (string, error) {
+	var email string
+	err := r.db.QueryRow(`SELECT email FROM users WHERE id = $1`, userID).Scan(&email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", app.NewAppError(404, "user not found")
+		}
+		log.Error("[Repo][UserEmail] Error Query: ", err)
+		return "", app.NewAppError(500, "failed to get user")
+	}
+	return email, nil
+}
+
+func (r *classroomRepository) AddInvite(invite entity.Invite) error {
+	_, err := r.db.Exec(`
+		INSERT INTO classroom_invites (classroom_id, email, token, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, invite.ClassroomID, invite.Email, invite.Token, invite.InvitedBy, invite.ExpiresAt)
+	if err != nil {
+		log.Error("[Repo][AddInvite] Error Insert: ", err)
+		return app.NewAppError(500, "failed to add invite")
+	}
+	return nil
+}
+
+func (r *classroomRepository) Invite(token string) (entity.Invite, error) {
+	var invite entity.Invite
+	var invitedBy sql.NullInt32
+	err := r.db.QueryRow(`
+		SELECT id, classroom_id, email, token, invited_by, expires_at, accepted_at
+		FROM classroom_invites
+		WHERE token = $1
+	`, token).Scan(&invite.ID, &invite.ClassroomID, &invite.Email, &invite.Token, &invitedBy, &invite.ExpiresAt, &invite.AcceptedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invite, app.NewAppError(404, "invite not found")
+		}
+		log.Error("[Repo][Invite] Error Query: ", err)
+		return invite, app.NewAppError(500, "failed to get invite")
+	}
+	invite.InvitedBy = invitedBy.Int32
+	return invite, nil
+}
+
+// AcceptInvite marks the invite used and enrolls the user in one transaction,
+// so an invite can never be spent twice.
+func (r *classroomRepository) AcceptInvite(invite entity.Invite, userID int32) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][AcceptInvite] Error beginning transaction: ", err)
+		return app.NewAppError(500, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE classroom_invites SET accepted_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND accepted_at IS NULL
+	`, invite.ID)
+	if err != nil {
+		log.Error("[Repo][AcceptInvite] Error Update: ", err)
+		return app.NewAppError(500, "failed to accept invite")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(409, "invite already accepted")
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO classroom_members (classroom_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (classroom_id, user_id) DO NOTHING
+	`, invite.ClassroomID, userID); err != nil {
+		log.Error("[Repo][AcceptInvite] Error Insert member: ", err)
+		return app.NewAppError(500, "failed to join classroom")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][AcceptInvite] Error Commit: ", err)
+		return app.NewAppError(500, "failed to commit transaction")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+var classroomCols = []string{"id", "name", "class_id", "class", "teacher_id", "teacher", "join_code", "members", "created_at"}
+
+func TestAddClassroom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO classrooms`).
+		WithArgs("5A Pagi", int32(5), int32(7), "ABCDEFGH").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	id, err := repository.Add(7, entity.SetClassroom{Name: "5A Pagi", ClassID: 5}, "ABCDEFGH")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDetailClassroom_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`FROM classrooms cr`).WithArgs(int32(9)).WillReturnRows(sqlmock.NewRows(classroomCols))
+
+	_, err = repository.Detail(9)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`WHERE cr.teacher_id = \$1\s+OR EXISTS`).
+		WithArgs(int32(7)).
+		WillReturnRows(sqlmock.NewRows(append(classroomCols, "role")).
+			AddRow(3, "5A Pagi", 5, "5", 7, "Bu Sari", "ABCDEFGH", 20, 1760000000, "teacher").
+			AddRow(4, "Olimpiade", 6, "6", 2, "Pak Budi", "HGFEDCBA", 8, 1760000000, "student"))
+
+	classrooms, err := repository.ListMine(7)
+	assert.NoError(t, err)
+	assert.Len(t, classrooms, 2)
+	assert.Equal(t, "teacher", classrooms[0].Role)
+	assert.Equal(t, 20, classrooms[0].Members)
+	assert.Equal(t, "student", classrooms[1].Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectExec(`INSERT INTO classroom_members .* ON CONFLICT \(classroom_id, user_id\) DO NOTHING`).
+		WithArgs(int32(3), int32(11)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repository.AddMember(3, 11))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveMember_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectExec(`DELETE FROM classroom_members`).WithArgs(int32(3), int32(11)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.RemoveMember(3, 11)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoster(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`FROM classroom_members cm`).
+		WithArgs(int32(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "img_url", "joined_at"}).
+			AddRow(11, "Andi", "andi@example.com", nil, 1760000000))
+
+	members, err := repository.Roster(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Member{{UserID: 11, Name: "Andi", Email: "andi@example.com", JoinedAt: 1760000000}}, members)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptInvite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+	invite := entity.Invite{ID: 5, ClassroomID: 3}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE classroom_invites SET accepted_at`).WithArgs(int32(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO classroom_members`).WithArgs(int32(3), int32(11)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repository.AcceptInvite(invite, 11))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptInvite_AlreadyAccepted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE classroom_invites SET accepted_at`).WithArgs(int32(5)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repository.AcceptInvite(entity.Invite{ID: 5, ClassroomID: 3}, 11)
+	assert.Equal(t, 409, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
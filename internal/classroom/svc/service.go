@@ -0,0 +1,291 @@
+package svc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+// InviteTTL is how long an emailed invite can be accepted.
+const InviteTTL = 7 * 24 * time.Hour
+
+// joinCodeAttempts bounds the retries when a fresh join code collides with an
+// existing one.
+const joinCodeAttempts = 5
+
+type ClassroomService interface {
+	// Classrooms
+	Add(teacherID int32, classroom entity.SetClassroom) (entity.Classroom, error)
+	Edit(caller entity.Caller, id int32, classroom entity.SetClassroom) error
+	Delete(caller entity.Caller, id int32) error
+	Detail(caller entity.Caller, id int32) (entity.Classroom, error)
+	ListMine(userID int32) ([]entity.MyClassroom, error)
+	RegenerateJoinCode(caller entity.Caller, id int32) (string, error)
+
+	// Enrollment
+	Join(userID int32, code string) (entity.Classroom, error)
+	Invite(caller entity.Caller, id int32, emails []string) (entity.InviteResult, error)
+	AcceptInvite(userID int32, token string) (entity.Classroom, error)
+	Roster(caller entity.Caller, id int32) ([]entity.Member, error)
+	RemoveMember(caller entity.Caller, id, userID int32) error
+}
+
+type classroomService struct {
+	repo repo.ClassroomRepository
+	mail mailer.Sender
+}
+
+func NewClassroomService(repo repo.ClassroomRepository, mail mailer.Sender) ClassroomService {
+	return &classroomService{repo: repo, mail: mail}
+}
+
+func (s *classroomService) Add(teacherID int32, classroom entity.SetClassroom) (entity.Classroom, error) {
+	if err := s.checkClass(classroom.ClassID); err != nil {
+		return entity.Classroom{}, err
+	}
+	code, err := s.newJoinCode()
+	if err != nil {
+		return entity.Classroom{}, err
+	}
+	id, err := s.repo.Add(teacherID, classroom, code)
+	if err != nil {
+		return entity.Classroom{}, err
+	}
+	return s.repo.Detail(id)
+}
+
+func (s *classroomService) Edit(caller entity.Caller, id int32, classroom entity.SetClassroom) error {
+	if _, err := s.manage(caller, id); err != nil {
+		return err
+	}
+	if err := s.checkClass(classroom.ClassID); err != nil {
+		return err
+	}
+	return s.repo.Edit(id, classroom)
+}
+
+func (s *classroomService) Delete(caller entity.Caller, id int32) error {
+	if _, err := s.manage(caller, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Detail is open to the classroom's teacher, admins and its students; only
+// the first two get to see the join code.
+func (s *classroomService) Detail(caller entity.Caller, id int32) (entity.Classroom, error) {
+	classroom, err := s.repo.Detail(id)
+	if err != nil {
+		return classroom, err
+	}
+	if caller.Admin || classroom.TeacherID == caller.UserID {
+		return classroom, nil
+	}
+
+	member, err := s.repo.IsMember(id, caller.UserID)
+	if err != nil {
+		return entity.Classroom{}, err
+	}
+	if !member {
+		return entity.Classroom{}, app.NewAppError(403, "you are not a member of this classroom")
+	}
+	classroom.JoinCode = ""
+	return classroom, nil
+}
+
+func (s *classroomService) ListMine(userID int32) ([]entity.MyClassroom, error) {
+	classrooms, err := s.repo.ListMine(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range classrooms {
+		if classrooms[i].Role != "teacher" {
+			classrooms[i].JoinCode = ""
+		}
+	}
+	return classrooms, nil
+}
+
+// RegenerateJoinCode replaces the join code, e.g. after it leaked. Students
+// who already joined stay enrolled.
+func (s *classroomService) RegenerateJoinCode(caller entity.Caller, id int32) (string, error) {
+	if _, err := s.manage(caller, id); err != nil {
+		return "", err
+	}
+	code, err := s.newJoinCode()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.SetJoinCode(id, code); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func (s *classroomService) Join(userID int32, code string) (entity.Classroom, error) {
+	classroom, err := s.repo.FindByJoinCode(enrollment.NormalizeCode(code))
+	if err != nil {
+		if appErr, ok := err.(*app.AppError); ok && appErr.Code == 404 {
+			return classroom, app.NewAppError(404, "invalid join code")
+		}
+		return classroom, err
+	}
+	if classroom.TeacherID == userID {
+		return entity.Classroom{}, app.NewAppError(400, "you teach this classroom")
+	}
+	if err := s.repo.AddMember(classroom.ID, userID); err != nil {
+		return entity.Classroom{}, err
+	}
+	return s.repo.Detail(classroom.ID)
+}
+
+// Invite emails a one-time link to each address. Addresses whose email
+// could not be queued are reported back rather than failing the whole batch.
+func (s *classroomService) Invite(caller entity.Caller, id int32, emails []string) (entity.InviteResult, error) {
+	result := entity.InviteResult{Invited: []string{}, Failed: []string{}}
+	classroom, err := s.manage(caller, id)
+	if err != nil {
+		return result, err
+	}
+
+	seen := map[string]bool{}
+	expiresAt := time.Now().Add(InviteTTL).Unix()
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		token, err := newInviteToken()
+		if err != nil {
+			return result, err
+		}
+		invite := entity.Invite{ClassroomID: id, Email: email, Token: token, InvitedBy: caller.UserID, ExpiresAt: expiresAt}
+		if err := s.repo.AddInvite(invite); err != nil {
+			return result, err
+		}
+		err = s.mail.Send(context.Background(), mailer.Email{
+			To:       email,
+			Template: mailer.TemplateClassroomInvite,
+			Data: map[string]any{
+				"Classroom": classroom.Name,
+				"Link":      os.Getenv("BASE_URL") + "/classrooms/invite?token=" + token,
+				"Days":      int(InviteTTL.Hours() / 24),
+			},
+		})
+		if err != nil {
+			log.Warn("[Svc][Invite] failed to send invite to ", email, ": ", err)
+			result.Failed = append(result.Failed, email)
+			continue
+		}
+		result.Invited = append(result.Invited, email)
+	}
+	return result, nil
+}
+
+// AcceptInvite enrolls the caller, who must be signed in with the address the
+// invite was sent to.
+func (s *classroomService) AcceptInvite(userID int32, token string) (entity.Classroom, error) {
+	invite, err := s.repo.Invite(token)
+	if err != nil {
+		return entity.Classroom{}, err
+	}
+	if invite.AcceptedAt != nil {
+		return entity.Classroom{}, app.NewAppError(409, "invite already accepted")
+	}
+	if time.Now().Unix() > invite.ExpiresAt {
+		return entity.Classroom{}, app.NewAppError(410, "invite has expired")
+	}
+
+	email, err := s.repo.UserEmail(userID)
+	if err != nil {
+		return entity.Classroom{}, err
+	}
+	if !strings.EqualFold(email, invite.Email) {
+		return entity.Classroom{}, app.NewAppError(403, "invite was sent to another email")
+	}
+
+	if err := s.repo.AcceptInvite(invite, userID); err != nil {
+		return entity.Classroom{}, err
+	}
+	classroom, err := s.repo.Detail(invite.ClassroomID)
+	classroom.JoinCode = ""
+	return classroom, err
+}
+
+func (s *classroomService) Roster(caller entity.Caller, id int32) ([]entity.Member, error) {
+	if _, err := s.manage(caller, id); err != nil {
+		return nil, err
+	}
+	return s.repo.Roster(id)
+}
+
+// RemoveMember lets the teacher drop a student and a student leave on their
+// own.
+func (s *classroomService) RemoveMember(caller entity.Caller, id, userID int32) error {
+	if caller.UserID != userID {
+		if _, err := s.manage(caller, id); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(id, userID)
+}
+
+// manage loads the classroom and checks the caller may change it.
+func (s *classroomService) manage(caller entity.Caller, id int32) (entity.Classroom, error) {
+	classroom, err := s.repo.Detail(id)
+	if err != nil {
+		return classroom, err
+	}
+	if !caller.Admin && classroom.TeacherID != caller.UserID {
+		return entity.Classroom{}, app.NewAppError(403, "you do not teach this classroom")
+	}
+	return classroom, nil
+}
+
+func (s *classroomService) checkClass(classID int32) error {
+	exists, err := s.repo.ClassExists(classID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return app.NewAppError(404, "class not found")
+	}
+	return nil
+}
+
+func (s *classroomService) newJoinCode() (string, error) {
+	for i := 0; i < joinCodeAttempts; i++ {
+		code, err := enrollment.NewCode()
+		if err != nil {
+			return "", app.NewAppError(500, "failed to generate join code")
+		}
+		exists, err := s.repo.JoinCodeExists(code)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+	return "", app.NewAppError(500, "failed to generate a unique join code")
+}
+
+func newInviteToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", app.NewAppError(500, "failed to generate invite token")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,286 @@
+package svc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClassroomRepo struct {
+	mock.Mock
+}
+
+func (m *MockClassroomRepo) ClassExists(classID int32) (bool, error) {
+	args := m.Called(classID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockClassroomRepo) Add(teacherID int32, classroom entity.SetClassroom, joinCode string) (int32, error) {
+	args := m.Called(teacherID, classroom, joinCode)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockClassroomRepo) Edit(id int32, classroom entity.SetClassroom) error {
+	return m.Called(id, classroom).Error(0)
+}
+
+func (m *MockClassroomRepo) Delete(id int32) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockClassroomRepo) Detail(id int32) (entity.Classroom, error) {
+	args := m.Called(id)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) ListMine(userID int32) ([]entity.MyClassroom, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.MyClassroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) JoinCodeExists(code string) (bool, error) {
+	args := m.Called(code)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockClassroomRepo) SetJoinCode(id int32, code string) error {
+	return m.Called(id, code).Error(0)
+}
+
+func (m *MockClassroomRepo) FindByJoinCode(code string) (entity.Classroom, error) {
+	args := m.Called(code)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) IsMember(classroomID, userID int32) (bool, error) {
+	args := m.Called(classroomID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockClassroomRepo) AddMember(classroomID, userID int32) error {
+	return m.Called(classroomID, userID).Error(0)
+}
+
+func (m *MockClassroomRepo) RemoveMember(classroomID, userID int32) error {
+	return m.Called(classroomID, userID).Error(0)
+}
+
+func (m *MockClassroomRepo) Roster(classroomID int32) ([]entity.Member, error) {
+	args := m.Called(classroomID)
+	return args.Get(0).([]entity.Member), args.Error(1)
+}
+
+func (m *MockClassroomRepo) UserEmail(userID int32) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockClassroomRepo) AddInvite(invite entity.Invite) error {
+	return m.Called(invite).Error(0)
+}
+
+func (m *MockClassroomRepo) Invite(token string) (entity.Invite, error) {
+	args := m.Called(token)
+	return args.Get(0).(entity.Invite), args.Error(1)
+}
+
+func (m *MockClassroomRepo) AcceptInvite(invite entity.Invite, userID int32) error {
+	return m.Called(invite, userID).Error(0)
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, email mailer.Email) error {
+	return m.Called(email).Error(0)
+}
+
+var teacher = entity.Caller{UserID: 7}
+
+func TestAddClassroom(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+	req := entity.SetClassroom{Name: "5A Pagi", ClassID: 5}
+
+	mockRepo.On("ClassExists", int32(5)).Return(true, nil)
+	mockRepo.On("JoinCodeExists", mock.AnythingOfType("string")).Return(true, nil).Once()
+	mockRepo.On("JoinCodeExists", mock.AnythingOfType("string")).Return(false, nil).Once()
+	mockRepo.On("Add", int32(7), req, mock.AnythingOfType("string")).Return(int32(3), nil)
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, Name: "5A Pagi", TeacherID: 7, JoinCode: "ABCDEFGH"}, nil)
+
+	classroom, err := service.Add(7, req)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), classroom.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddClassroom_UnknownClass(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("ClassExists", int32(9)).Return(false, nil)
+
+	_, err := service.Add(7, entity.SetClassroom{Name: "5A Pagi", ClassID: 9})
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEditClassroom_NotOwner(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+	req := entity.SetClassroom{Name: "5B", ClassID: 5}
+
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 2}, nil)
+
+	err := service.Edit(teacher, 3, req)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+
+	mockRepo.On("ClassExists", int32(5)).Return(true, nil)
+	mockRepo.On("Edit", int32(3), req).Return(nil)
+	assert.NoError(t, service.Edit(entity.Caller{UserID: 1, Admin: true}, 3, req))
+}
+
+func TestDetailClassroom_HidesJoinCodeFromStudents(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 7, JoinCode: "ABCDEFGH"}, nil)
+	mockRepo.On("IsMember", int32(3), int32(11)).Return(true, nil)
+	mockRepo.On("IsMember", int32(3), int32(12)).Return(false, nil)
+
+	classroom, err := service.Detail(teacher, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEFGH", classroom.JoinCode)
+
+	classroom, err = service.Detail(entity.Caller{UserID: 11}, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, classroom.JoinCode)
+
+	_, err = service.Detail(entity.Caller{UserID: 12}, 3)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+}
+
+func TestListMine(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("ListMine", int32(7)).Return([]entity.MyClassroom{
+		{Classroom: entity.Classroom{ID: 3, JoinCode: "ABCDEFGH"}, Role: "teacher"},
+		{Classroom: entity.Classroom{ID: 4, JoinCode: "HGFEDCBA"}, Role: "student"},
+	}, nil)
+
+	classrooms, err := service.ListMine(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEFGH", classrooms[0].JoinCode)
+	assert.Empty(t, classrooms[1].JoinCode)
+}
+
+func TestJoin(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("FindByJoinCode", "ABCDEFGH").Return(entity.Classroom{ID: 3, TeacherID: 7}, nil)
+	mockRepo.On("AddMember", int32(3), int32(11)).Return(nil)
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 7, Members: 1}, nil)
+
+	classroom, err := service.Join(11, "abcd-efgh")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, classroom.Members)
+
+	_, err = service.Join(7, "ABCDEFGH")
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNumberOfCalls(t, "AddMember", 1)
+}
+
+func TestJoin_InvalidCode(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("FindByJoinCode", "NOPE").Return(entity.Classroom{}, app.NewAppError(404, "classroom not found"))
+
+	_, err := service.Join(11, "nope")
+	assert.Equal(t, "invalid join code", err.(*app.AppError).Message)
+}
+
+func TestInvite(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	mockMail := new(MockSender)
+	service := svc.NewClassroomService(mockRepo, mockMail)
+
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, Name: "5A Pagi", TeacherID: 7}, nil)
+	mockRepo.On("AddInvite", mock.MatchedBy(func(i entity.Invite) bool {
+		return i.ClassroomID == 3 && i.InvitedBy == 7 && len(i.Token) == 64 && i.ExpiresAt > time.Now().Unix()
+	})).Return(nil)
+	invite := func(to string) any {
+		return mock.MatchedBy(func(e mailer.Email) bool {
+			return e.To == to && e.Template == mailer.TemplateClassroomInvite && e.Data["Classroom"] == "5A Pagi" &&
+				strings.Contains(e.Data["Link"].(string), "/classrooms/invite?token=")
+		})
+	}
+	mockMail.On("Send", invite("andi@example.com")).Return(nil)
+	mockMail.On("Send", invite("budi@example.com")).Return(errors.New("queue down"))
+
+	result, err := service.Invite(teacher, 3, []string{"Andi@Example.com", "andi@example.com", "budi@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"andi@example.com"}, result.Invited)
+	assert.Equal(t, []string{"budi@example.com"}, result.Failed)
+	mockRepo.AssertNumberOfCalls(t, "AddInvite", 2)
+}
+
+func TestAcceptInvite(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+	invite := entity.Invite{ID: 5, ClassroomID: 3, Email: "andi@example.com", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	mockRepo.On("Invite", "tok").Return(invite, nil)
+	mockRepo.On("UserEmail", int32(11)).Return("Andi@example.com", nil)
+	mockRepo.On("UserEmail", int32(12)).Return("budi@example.com", nil)
+	mockRepo.On("AcceptInvite", invite, int32(11)).Return(nil)
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, JoinCode: "ABCDEFGH"}, nil)
+
+	_, err := service.AcceptInvite(12, "tok")
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+
+	classroom, err := service.AcceptInvite(11, "tok")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), classroom.ID)
+	assert.Empty(t, classroom.JoinCode)
+}
+
+func TestAcceptInvite_Expired(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("Invite", "tok").Return(entity.Invite{ID: 5, ExpiresAt: time.Now().Add(-time.Hour).Unix()}, nil)
+
+	_, err := service.AcceptInvite(11, "tok")
+	assert.Equal(t, 410, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "AcceptInvite", mock.Anything, mock.Anything)
+}
+
+func TestRemoveMember(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo, new(MockSender))
+
+	mockRepo.On("RemoveMember", int32(3), int32(11)).Return(nil)
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 7}, nil)
+
+	// students may leave on their own
+	assert.NoError(t, service.RemoveMember(entity.Caller{UserID: 11}, 3, 11))
+	mockRepo.AssertNotCalled(t, "Detail", int32(3))
+
+	assert.NoError(t, service.RemoveMember(teacher, 3, 11))
+
+	err := service.RemoveMember(entity.Caller{UserID: 12}, 3, 11)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+}
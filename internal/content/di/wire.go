@@ -6,17 +6,22 @@ import (
 	contentHandler "github.com/ghulammuzz/misterblast/internal/content/handler"
 	contentRepo "github.com/ghulammuzz/misterblast/internal/content/repo"
 	contentSvc "github.com/ghulammuzz/misterblast/internal/content/svc"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitializedContentServiceFake(db *sql.DB, redis *redis.Client, val *validator.Validate) *contentHandler.ContentHandler {
+func InitializedContentServiceFake(db *sql.DB, redis *redis.Client, files storage.Storage, val *validator.Validate) *contentHandler.ContentHandler {
 	wire.Build(
 		contentHandler.NewContentHandler,
 		contentSvc.NewContentService,
 		contentRepo.NewContentRepository,
+		imaging.NewPipeline,
+		wire.Bind(new(imaging.Putter), new(storage.Storage)),
+		wire.Bind(new(imaging.Saver), new(*imaging.Pipeline)),
 	)
 
 	return &contentHandler.ContentHandler{}
@@ -11,15 +11,18 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/content/handler"
 	"github.com/ghulammuzz/misterblast/internal/content/repo"
 	"github.com/ghulammuzz/misterblast/internal/content/svc"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
 )
 
 // Injectors from wire.go:
 
-func InitializedContentService(db *sql.DB, redis2 *redis.Client, val *validator.Validate) *handler.ContentHandler {
+func InitializedContentService(db *sql.DB, redis2 *redis.Client, files storage.Storage, val *validator.Validate) *handler.ContentHandler {
 	contentRepository := repo.NewContentRepository(db, redis2)
-	contentService := svc.NewContentService(contentRepository)
+	pipeline := imaging.NewPipeline(db, files)
+	contentService := svc.NewContentService(contentRepository, pipeline)
 	contentHandler := handler.NewContentHandler(contentService, val)
 	return contentHandler
 }
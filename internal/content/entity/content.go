@@ -1,12 +1,16 @@
 package entity
 
+import "github.com/ghulammuzz/misterblast/pkg/imaging"
+
 type Content struct {
-	ID      int    `json:"id"`
-	Title   string `json:"title"`
-	Desc    string `json:"desc"`
-	ImgURL  string `json:"img_url"`
-	SiteURL string `json:"site_url"`
-	Lang    string `json:"lang"`
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Desc   string `json:"desc"`
+	ImgURL string `json:"img_url"`
+	// ImgVariants is filled on reads; it is ignored when saving.
+	ImgVariants imaging.Variants `json:"img_variants,omitempty"`
+	SiteURL     string           `json:"site_url"`
+	Lang        string           `json:"lang"`
 }
 
 type Author struct {
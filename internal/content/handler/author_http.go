@@ -23,11 +23,11 @@ func NewAuthorHandler(authorService svc.AuthorService, val *validator.Validate)
 }
 
 func (h *AuthorHandler) Router(r fiber.Router) {
-	r.Post("/authors", m.R100(), h.AddAuthorHandler)
+	r.Post("/authors", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.AddAuthorHandler)
 	r.Get("/authors", m.R100(), h.ListAuthorHandler)
 	r.Get("/authors/:id", m.R100(), h.DetailAuthorHandler)
-	// r.Put("/authors/:id", m.R100(), h.EditAuthorHandler)
-	// r.Delete("/authors/:id", m.R100(), h.DeleteAuthorHandler)
+	// r.Put("/authors/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.EditAuthorHandler)
+	// r.Delete("/authors/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.DeleteAuthorHandler)
 }
 
 func (h *AuthorHandler) AddAuthorHandler(c *fiber.Ctx) error {
@@ -23,11 +23,30 @@ func NewContentHandler(contentService svc.ContentService, val *validator.Validat
 }
 
 func (h *ContentHandler) Router(r fiber.Router) {
-	r.Post("/content", m.R100(), h.AddContentHandler)
+	r.Post("/content", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.AddContentHandler)
+	r.Post("/content/images", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.UploadImageHandler)
 	r.Get("/content", m.R100(), h.ListContentHandler)
 	r.Get("/content/:id", m.R100(), h.DetailContentHandler)
-	r.Put("/content/:id", m.R100(), h.EditContentHandler)
-	r.Delete("/content/:id", m.R100(), h.DeleteContentHandler)
+	r.Put("/content/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.EditContentHandler)
+	r.Delete("/content/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageContent), h.DeleteContentHandler)
+}
+
+func (h *ContentHandler) UploadImageHandler(c *fiber.Ctx) error {
+	img, err := c.FormFile("img")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "img file is required", nil)
+	}
+
+	url, err := h.contentService.UploadImage(c.Context(), img)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "image uploaded successfully", fiber.Map{"url": url})
 }
 
 func (h *ContentHandler) AddContentHandler(c *fiber.Ctx) error {
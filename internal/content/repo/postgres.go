@@ -68,7 +68,7 @@ func (c *contentRepository) List(filter map[string]string, ctx context.Context)
 		}
 	}
 
-	query := `SELECT id, title, description, img_url, site_url, lang FROM content`
+	query := `SELECT id, title, description, img_url, site_url, lang, i.variants FROM content LEFT JOIN images i ON i.url = img_url`
 	countQuery := `SELECT COUNT(*) FROM content`
 	var args []interface{}
 	var conditions []string
@@ -110,7 +110,7 @@ func (c *contentRepository) List(filter map[string]string, ctx context.Context)
 
 	for rows.Next() {
 		var cont contentEntity.Content
-		if err := rows.Scan(&cont.ID, &cont.Title, &cont.Desc, &cont.ImgURL, &cont.SiteURL, &cont.Lang); err != nil {
+		if err := rows.Scan(&cont.ID, &cont.Title, &cont.Desc, &cont.ImgURL, &cont.SiteURL, &cont.Lang, &cont.ImgVariants); err != nil {
 			log.Error("[ContentRepository.List] Error scanning row: ", err)
 			return nil, app.NewAppError(500, "failed to scan content row")
 		}
@@ -163,9 +163,9 @@ func (c *contentRepository) Delete(id int32) error {
 }
 
 func (c *contentRepository) Detail(ctx context.Context, id int32) (contentEntity.Content, error) {
-	query := `SELECT id, title, description, img_url, site_url, lang FROM content WHERE id = $1`
+	query := `SELECT id, title, description, img_url, site_url, lang, i.variants FROM content LEFT JOIN images i ON i.url = img_url WHERE id = $1`
 	var cont contentEntity.Content
-	err := c.db.QueryRowContext(ctx, query, id).Scan(&cont.ID, &cont.Title, &cont.Desc, &cont.ImgURL, &cont.SiteURL, &cont.Lang)
+	err := c.db.QueryRowContext(ctx, query, id).Scan(&cont.ID, &cont.Title, &cont.Desc, &cont.ImgURL, &cont.SiteURL, &cont.Lang, &cont.ImgVariants)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return cont, app.NewAppError(404, "content not found")
@@ -2,10 +2,17 @@ package svc
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"mime/multipart"
 
 	contentEntity "github.com/ghulammuzz/misterblast/internal/content/entity"
 	contentRepo "github.com/ghulammuzz/misterblast/internal/content/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 )
 
 type ContentService interface {
@@ -14,14 +21,40 @@ type ContentService interface {
 	Delete(id int32) error
 	Detail(ctx context.Context, id int32) (contentEntity.Content, error)
 	Edit(id int32, content contentEntity.Content) error
+	UploadImage(ctx context.Context, img *multipart.FileHeader) (string, error)
 }
 
 type contentService struct {
-	repo contentRepo.ContentRepository
+	repo   contentRepo.ContentRepository
+	images imaging.Saver
 }
 
-func NewContentService(repo contentRepo.ContentRepository) ContentService {
-	return &contentService{repo: repo}
+func NewContentService(repo contentRepo.ContentRepository, images imaging.Saver) ContentService {
+	return &contentService{repo: repo, images: images}
+}
+
+// UploadImage stores an image for content, authors and answers and returns
+// its URL, to be sent as their img_url. The image is checked by its content,
+// stripped of metadata and given resized variants.
+func (s *contentService) UploadImage(ctx context.Context, img *multipart.FileHeader) (string, error) {
+	content, err := storage.ReadFile(img)
+	if err != nil {
+		return "", app.NewAppError(400, "failed to read image")
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return "", err
+	}
+	url, err := s.images.Save(ctx, "/prod/content/img/"+hex.EncodeToString(name), content)
+	if imaging.IsInvalid(err) {
+		return "", app.NewAppError(400, "file must be a JPEG, PNG, GIF or WebP image")
+	}
+	if err != nil {
+		log.Error("[ContentSvc] Failed to store image", "error", err)
+		return "", app.NewAppError(502, "failed to store image")
+	}
+	return url, nil
 }
 
 func (s *contentService) Add(content contentEntity.Content, lang string) error {
@@ -7,18 +7,25 @@ import (
 	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
 	emailSvc "github.com/ghulammuzz/misterblast/internal/email/svc"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitializedEmailServiceFake(sb *sql.DB, val *validator.Validate) *emailHandler.EmailHandler {
+func InitializedEmailServiceFake(sb *sql.DB, redis *redis.Client, val *validator.Validate) *emailHandler.EmailHandler {
 	wire.Build(
 		emailHandler.NewEmailHandler,
 		emailSvc.NewEmailService,
 		emailRepo.NewEmailRepository,
 		emailRepo.NewOTPService,
 		userRepo.NewUserRepository,
+		mailer.NewOutbox,
+		queue.NewPostgresQueue,
+		wire.Bind(new(queue.Enqueuer), new(*queue.PostgresQueue)),
+		wire.Bind(new(mailer.Sender), new(*mailer.Outbox)),
 	)
 
 	return &emailHandler.EmailHandler{}
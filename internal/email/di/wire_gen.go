@@ -12,16 +12,21 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/email/repo"
 	"github.com/ghulammuzz/misterblast/internal/email/svc"
 	repo2 "github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
 )
 
 // Injectors from wire.go:
 
-func InitializedEmailService(sb *sql.DB, val *validator.Validate) *handler.EmailHandler {
+func InitializedEmailService(sb *sql.DB, redis2 *redis.Client, val *validator.Validate) *handler.EmailHandler {
 	emailRepository := repo.NewEmailRepository(sb)
 	userRepository := repo2.NewUserRepository(sb)
 	otp := repo.NewOTPService()
-	emailService := svc.NewEmailService(emailRepository, userRepository, otp)
+	postgresQueue := queue.NewPostgresQueue(sb, redis2)
+	outbox := mailer.NewOutbox(sb, postgresQueue)
+	emailService := svc.NewEmailService(emailRepository, userRepository, otp, outbox)
 	emailHandler := handler.NewEmailHandler(emailService, val)
 	return emailHandler
 }
@@ -13,6 +13,18 @@ type CheckOTP struct {
 	ID  int32  `json:"id" validate:"required"`
 }
 
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// VerificationState is where a user is in verifying their email address.
+type VerificationState struct {
+	Email      string
+	IsVerified bool
+	// SentAt is when the last verification email went out, 0 if never.
+	SentAt int64
+}
+
 type DeeplinkResponse struct {
 	UserID    int32  `json:"user_id"`
 	Token     string `json:"token"`
@@ -9,6 +9,7 @@ import (
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type EmailHandler struct {
@@ -24,6 +25,8 @@ func (h *EmailHandler) Router(r fiber.Router) {
 	// r.Post("/activation/send-otp", m.R1(), h.SendOTPActivation)
 	// r.Post("/activation/check-otp", m.R100(), h.CheckOTPHandler)
 	r.Post("/forgot-password", m.R100(), h.SendDeeplinkForgotPasswordHandler)
+	r.Post("/verify-email", m.R100(), h.VerifyEmailHandler)
+	r.Post("/verify-email/resend", m.JWTProtected(), m.R100(), h.ResendVerificationHandler)
 }
 
 func (h *EmailHandler) SendOTPActivation(c *fiber.Ctx) error {
@@ -99,3 +102,43 @@ func (h *EmailHandler) SendDeeplinkForgotPasswordHandler(c *fiber.Ctx) error {
 	}
 	return response.SendSuccess(c, "Deeplink successfully sent to your email", token)
 }
+
+func (h *EmailHandler) VerifyEmailHandler(c *fiber.Ctx) error {
+
+	var verifyEmail entity.VerifyEmail
+
+	if err := c.BodyParser(&verifyEmail); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+
+	if err := h.val.Struct(verifyEmail); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.emailService.VerifyEmail(verifyEmail.Token); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Email verified", nil)
+}
+
+func (h *EmailHandler) ResendVerificationHandler(c *fiber.Ctx) error {
+	claims := c.Locals("claims").(jwt.MapClaims)
+	userID := int32(claims["user_id"].(float64))
+
+	if err := h.emailService.SendVerification(userID); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Verification email successfully sent", nil)
+}
@@ -4,12 +4,17 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 )
 
 type EmailRepository interface {
 	SetOTP(adminID int32, otp string, expiresAt int64) error
 	GetOTP(adminID int32) (string, int64, error)
+	VerificationState(userID int32) (entity.VerificationState, error)
+	ClaimVerificationSend(userID int32, now, notBefore int64) (bool, error)
+	MarkVerified(userID int32, email string) (bool, error)
+	IsVerified(userID int64) (bool, error)
 }
 
 type emailRepository struct {
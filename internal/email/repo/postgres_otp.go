@@ -4,18 +4,12 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
-	"net/smtp"
-	"os"
 
 	"github.com/ghulammuzz/misterblast/pkg/app"
-
-	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 )
 
 type OTP interface {
 	GenerateOTP() (string, error)
-	SendEmailSMTP(to string, otp string) error
-	SendDeeplinkEmailSMTP(to string, deeplink string) error
 }
 
 type otpService struct{}
@@ -32,46 +26,3 @@ func (o *otpService) GenerateOTP() (string, error) {
 	}
 	return fmt.Sprintf("%06d", n.Int64()), nil
 }
-
-func (o *otpService) SendEmailSMTP(to string, otp string) error {
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-	smtpUser := os.Getenv("EMAIL_HOST_USER")
-	smtpPassword := os.Getenv("EMAIL_HOST_PASSWORD")
-
-	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
-
-	msg := []byte("To: " + to + "\r\n" +
-		"Subject: OTP Verification\r\n" +
-		"\r\n" +
-		"Your OTP code is: " + otp + "\r\n")
-
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, smtpUser, []string{to}, msg)
-	if err != nil {
-		log.Error("[Repo][smtp.SendMail.OTP] Error Exec: ", err)
-		return app.NewAppError(500, err.Error())
-	}
-	return nil
-}
-
-func (o *otpService) SendDeeplinkEmailSMTP(to string, deeplink string) error {
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-	smtpUser := os.Getenv("EMAIL_HOST_USER")
-	smtpPassword := os.Getenv("EMAIL_HOST_PASSWORD")
-	baseURL := os.Getenv("BASE_URL")
-
-	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
-
-	msg := []byte("To: " + to + "\r\n" +
-		"Subject: OTP Verification\r\n" +
-		"\r\n" +
-		"Klik Tautan untuk mereset Password: " + baseURL + "/update-password?code=" + deeplink + "\r\n")
-
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, smtpUser, []string{to}, msg)
-	if err != nil {
-		log.Error("[Repo][smtp.SendMail.Deeplink] Error Exec: ", err)
-		return app.NewAppError(500, err.Error())
-	}
-	return nil
-}
@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+func (r *emailRepository) VerificationState(userID int32) (entity.VerificationState, error) {
+	query := `SELECT email, is_verified, COALESCE(verification_sent_at, 0) FROM users WHERE id = $1`
+	var state entity.VerificationState
+	err := r.DB.QueryRow(query, userID).Scan(&state.Email, &state.IsVerified, &state.SentAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return state, app.NewAppError(404, "user not found")
+		}
+		log.Error("[Repo][emailRepo.VerificationState] Error Query: ", err)
+		return state, app.NewAppError(500, "failed to get verification status")
+	}
+	return state, nil
+}
+
+// ClaimVerificationSend records that a verification email goes out at now,
+// unless the user is verified or one already went out after notBefore. It
+// reports whether the claim succeeded, so concurrent resends send one email.
+func (r *emailRepository) ClaimVerificationSend(userID int32, now, notBefore int64) (bool, error) {
+	query := `UPDATE users SET verification_sent_at = $2
+		WHERE id = $1 AND is_verified = false AND COALESCE(verification_sent_at, 0) <= $3`
+	res, err := r.DB.Exec(query, userID, now, notBefore)
+	if err != nil {
+		log.Error("[Repo][emailRepo.ClaimVerificationSend] Error Exec: ", err)
+		return false, app.NewAppError(500, "failed to update verification status")
+	}
+	rows, _ := res.RowsAffected()
+	return rows > 0, nil
+}
+
+// MarkVerified verifies the user if their address is still email. It reports
+// false when nothing changed: the user is already verified or the address
+// has changed since the link was sent.
+func (r *emailRepository) MarkVerified(userID int32, email string) (bool, error) {
+	query := `UPDATE users SET is_verified = true, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND email = $2 AND is_verified = false`
+	res, err := r.DB.Exec(query, userID, email)
+	if err != nil {
+		log.Error("[Repo][emailRepo.MarkVerified] Error Exec: ", err)
+		return false, app.NewAppError(500, "failed to update verification status")
+	}
+	rows, _ := res.RowsAffected()
+	return rows > 0, nil
+}
+
+// IsVerified lets middleware.RequireVerified check users. Unknown users are
+// not verified.
+func (r *emailRepository) IsVerified(userID int64) (bool, error) {
+	var verified bool
+	err := r.DB.QueryRow(`SELECT is_verified FROM users WHERE id = $1`, userID).Scan(&verified)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return verified, err
+}
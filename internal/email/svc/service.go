@@ -1,11 +1,16 @@
 package svc
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"time"
 
 	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/jwt"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 )
 
@@ -13,13 +18,22 @@ type EmailService interface {
 	SendOTP(email string) error
 	SendDeeplink(email string) (string, error)
 	ValidateOTP(adminID int32, otp string) error
+	SendVerification(userID int32) error
+	VerifyEmail(token string) error
 }
 
-func NewEmailService(emailRepo emailRepo.EmailRepository, userRepo userRepo.UserRepository, otp emailRepo.OTP) EmailService {
+// codeTTL is how long OTPs and password reset links stay valid.
+const codeTTL = 120 * time.Second
+
+// resendCooldown is how long a user waits between verification emails.
+const resendCooldown = 60 * time.Second
+
+func NewEmailService(emailRepo emailRepo.EmailRepository, userRepo userRepo.UserRepository, otp emailRepo.OTP, mail mailer.Sender) EmailService {
 	return &emailService{
 		emailRepo: emailRepo,
 		userRepo:  userRepo,
 		otp:       otp,
+		mail:      mail,
 	}
 }
 
@@ -27,6 +41,7 @@ type emailService struct {
 	emailRepo emailRepo.EmailRepository
 	userRepo  userRepo.UserRepository
 	otp       emailRepo.OTP
+	mail      mailer.Sender
 }
 
 func (s *emailService) SendOTP(email string) error {
@@ -40,14 +55,20 @@ func (s *emailService) SendOTP(email string) error {
 		return err
 	}
 
-	expAt := time.Now().Add(120 * time.Second).Unix()
+	expAt := time.Now().Add(codeTTL).Unix()
 
 	if err := s.emailRepo.SetOTP(adminID, otpString, expAt); err != nil {
 		return err
 	}
 
-	if err := s.otp.SendEmailSMTP(email, otpString); err != nil {
-		return err
+	err = s.mail.Send(context.Background(), mailer.Email{
+		To:       email,
+		Template: mailer.TemplateOTP,
+		Data:     map[string]any{"Code": otpString, "Minutes": int(codeTTL.Minutes())},
+	})
+	if err != nil {
+		log.Error("[Svc][s.mail.Send] Error Exec: ", err)
+		return app.NewAppError(500, "failed to send email")
 	}
 
 	return nil
@@ -101,17 +122,101 @@ func (s *emailService) SendDeeplink(email string) (string, error) {
 		return "", err
 	}
 
-	expAt := time.Now().Add(120 * time.Second).Unix()
+	expAt := time.Now().Add(codeTTL).Unix()
 
 	if err := s.userRepo.SetDeeplink(userID, tokenString, expAt); err != nil {
 		log.Error("[Svc][s.userRepo.SetDeeplink] Error Exec: ", err)
 		return "", err
 	}
 
-	if err := s.otp.SendDeeplinkEmailSMTP(email, tokenString); err != nil {
-		log.Error("[Svc][s.otp.SendDeeplinkEmailSMTP] Error Exec: ", err)
-		return "", err
+	err = s.mail.Send(context.Background(), mailer.Email{
+		To:       email,
+		Template: mailer.TemplatePasswordReset,
+		Data: map[string]any{
+			"Link":    os.Getenv("BASE_URL") + "/update-password?code=" + tokenString,
+			"Minutes": int(codeTTL.Minutes()),
+		},
+	})
+	if err != nil {
+		log.Error("[Svc][s.mail.Send] Error Exec: ", err)
+		return "", app.NewAppError(500, "failed to send email")
 	}
 
 	return tokenString, nil
 }
+
+// SendVerification emails the user a link to verify their address. It is
+// sent on registration and may be resent once every resendCooldown.
+func (s *emailService) SendVerification(userID int32) error {
+	state, err := s.emailRepo.VerificationState(userID)
+	if err != nil {
+		return err
+	}
+	if state.IsVerified {
+		return app.NewAppError(409, "email already verified")
+	}
+
+	now := time.Now()
+	claimed, err := s.emailRepo.ClaimVerificationSend(userID, now.Unix(), now.Add(-resendCooldown).Unix())
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		wait := max(state.SentAt+int64(resendCooldown.Seconds())-now.Unix(), 1)
+		return app.NewAppError(429, fmt.Sprintf("please wait %d seconds before requesting another verification email", wait))
+	}
+
+	token, err := jwt.GenerateEmailToken(userID, state.Email)
+	if err != nil {
+		log.Error("[Svc][jwt.GenerateEmailToken] Error Exec: ", err)
+		return app.NewAppError(500, "failed to create verification link")
+	}
+
+	err = s.mail.Send(context.Background(), mailer.Email{
+		UserID:   int64(userID),
+		Template: mailer.TemplateVerifyEmail,
+		Data: map[string]any{
+			"Link":  os.Getenv("BASE_URL") + "/verify-email?token=" + token,
+			"Hours": int(jwt.EmailTokenTTL.Hours()),
+		},
+	})
+	if err != nil {
+		log.Error("[Svc][s.mail.Send] Error Exec: ", err)
+		return app.NewAppError(500, "failed to send email")
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the user behind a verification link verified and welcomes
+// them. Following a link again after that succeeds without a second welcome.
+func (s *emailService) VerifyEmail(token string) error {
+	userID, email, err := jwt.VerifyEmailToken(token)
+	if err != nil {
+		if jwt.IsExpired(err) {
+			return app.NewAppError(410, "verification link has expired")
+		}
+		return app.NewAppError(400, "invalid verification link")
+	}
+
+	verified, err := s.emailRepo.MarkVerified(userID, email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		state, err := s.emailRepo.VerificationState(userID)
+		if err != nil {
+			return err
+		}
+		// Links to an address the user has since changed prove nothing.
+		if !state.IsVerified || state.Email != email {
+			return app.NewAppError(400, "invalid verification link")
+		}
+		return nil
+	}
+
+	if err := s.mail.Send(context.Background(), mailer.Email{UserID: int64(userID), Template: mailer.TemplateWelcome}); err != nil {
+		log.Warn("[Svc] Failed to queue welcome email", "error", err)
+	}
+	return nil
+}
@@ -1 +1,141 @@
-package svc
+package svc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	emailSvc "github.com/ghulammuzz/misterblast/internal/email/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/jwt"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+type MockEmailRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailRepository) SetOTP(adminID int32, otp string, expiresAt int64) error {
+	return m.Called(adminID, otp, expiresAt).Error(0)
+}
+
+func (m *MockEmailRepository) GetOTP(adminID int32) (string, int64, error) {
+	args := m.Called(adminID)
+	return args.String(0), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockEmailRepository) VerificationState(userID int32) (entity.VerificationState, error) {
+	args := m.Called(userID)
+	return args.Get(0).(entity.VerificationState), args.Error(1)
+}
+
+func (m *MockEmailRepository) ClaimVerificationSend(userID int32, now, notBefore int64) (bool, error) {
+	args := m.Called(userID, now, notBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailRepository) MarkVerified(userID int32, email string) (bool, error) {
+	args := m.Called(userID, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailRepository) IsVerified(userID int64) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, email mailer.Email) error {
+	return m.Called(email).Error(0)
+}
+
+func TestEmailService_SendVerification(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("BASE_URL", "https://misterblast.example")
+	mockRepo := new(MockEmailRepository)
+	mockMail := new(MockSender)
+	service := emailSvc.NewEmailService(mockRepo, nil, nil, mockMail)
+
+	mockRepo.On("VerificationState", int32(7)).Return(entity.VerificationState{Email: "andi@example.com"}, nil)
+	mockRepo.On("ClaimVerificationSend", int32(7), mock.Anything, mock.Anything).Return(true, nil)
+	var sent mailer.Email
+	mockMail.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(0).(mailer.Email)
+	}).Return(nil)
+
+	require.NoError(t, service.SendVerification(7))
+	assert.Equal(t, int64(7), sent.UserID)
+	assert.Equal(t, mailer.TemplateVerifyEmail, sent.Template)
+	assert.Equal(t, 24, sent.Data["Hours"])
+
+	link := sent.Data["Link"].(string)
+	prefix := "https://misterblast.example/verify-email?token="
+	require.True(t, strings.HasPrefix(link, prefix), link)
+	userID, email, err := jwt.VerifyEmailToken(strings.TrimPrefix(link, prefix))
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), userID)
+	assert.Equal(t, "andi@example.com", email)
+
+	// The link can never be used as an access token.
+	_, _, err = jwt.VerifyToken(strings.TrimPrefix(link, prefix))
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEmailService_SendVerification_Rejected(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := emailSvc.NewEmailService(mockRepo, nil, nil, new(MockSender))
+
+	mockRepo.On("VerificationState", int32(1)).Return(entity.VerificationState{Email: "a@example.com", IsVerified: true}, nil)
+	err := service.SendVerification(1)
+	assert.Equal(t, 409, err.(*app.AppError).Code)
+
+	// Another resend within the cooldown is throttled.
+	mockRepo.On("VerificationState", int32(2)).Return(entity.VerificationState{Email: "b@example.com", SentAt: time.Now().Unix() - 20}, nil)
+	mockRepo.On("ClaimVerificationSend", int32(2), mock.Anything, mock.Anything).Return(false, nil)
+	err = service.SendVerification(2)
+	require.Error(t, err)
+	assert.Equal(t, 429, err.(*app.AppError).Code)
+	assert.Regexp(t, `(39|40) seconds`, err.Error())
+}
+
+func TestEmailService_VerifyEmail(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	mockRepo := new(MockEmailRepository)
+	mockMail := new(MockSender)
+	service := emailSvc.NewEmailService(mockRepo, nil, nil, mockMail)
+
+	token, err := jwt.GenerateEmailToken(7, "andi@example.com")
+	require.NoError(t, err)
+
+	mockRepo.On("MarkVerified", int32(7), "andi@example.com").Return(true, nil).Once()
+	mockMail.On("Send", mailer.Email{UserID: 7, Template: mailer.TemplateWelcome}).Return(nil).Once()
+	require.NoError(t, service.VerifyEmail(token))
+
+	// Following the link again succeeds without a second welcome.
+	mockRepo.On("MarkVerified", int32(7), "andi@example.com").Return(false, nil)
+	mockRepo.On("VerificationState", int32(7)).Return(entity.VerificationState{Email: "andi@example.com", IsVerified: true}, nil).Once()
+	require.NoError(t, service.VerifyEmail(token))
+
+	// A link to an address the user has since changed is refused.
+	mockRepo.On("VerificationState", int32(7)).Return(entity.VerificationState{Email: "andi@new.example.com"}, nil).Once()
+	err = service.VerifyEmail(token)
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+
+	err = service.VerifyEmail(token + "x")
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+
+	mockRepo.AssertExpectations(t)
+	mockMail.AssertExpectations(t)
+}
@@ -0,0 +1,24 @@
+//go:build wireinject
+// +build wireinject
+
+package di
+
+import (
+	"database/sql"
+
+	gamificationHandler "github.com/ghulammuzz/misterblast/internal/gamification/handler"
+	gamificationRepo "github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	gamificationSvc "github.com/ghulammuzz/misterblast/internal/gamification/svc"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedGamificationServiceFake(sb *sql.DB, val *validator.Validate) *gamificationHandler.GamificationHandler {
+	wire.Build(
+		gamificationHandler.NewGamificationHandler,
+		gamificationSvc.NewGamificationService,
+		gamificationRepo.NewGamificationRepository,
+	)
+
+	return &gamificationHandler.GamificationHandler{}
+}
@@ -0,0 +1,53 @@
+package entity
+
+type Badge struct {
+	ID          int32   `json:"id"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ImgURL      *string `json:"img_url"`
+	Metric      string  `json:"metric"`
+	Threshold   int     `json:"threshold"`
+}
+
+// SetBadge creates or edits a badge. Metric must be one of rules.Metrics.
+type SetBadge struct {
+	Code        string  `json:"code" validate:"required,max=50"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description"`
+	ImgURL      *string `json:"img_url"`
+	Metric      string  `json:"metric" validate:"required"`
+	Threshold   int     `json:"threshold" validate:"required,min=1"`
+}
+
+type UserBadge struct {
+	Badge
+	AwardedAt int64 `json:"awarded_at"`
+}
+
+// Progress is a user's XP, streak and earned badges. CurrentStreak is 0 once
+// a whole day passed without activity.
+type Progress struct {
+	XP            int         `json:"xp"`
+	CurrentStreak int         `json:"current_streak"`
+	LongestStreak int         `json:"longest_streak"`
+	LastActiveOn  *string     `json:"last_active_on"`
+	Badges        []UserBadge `json:"badges"`
+}
+
+// Activity is one XP award. Source and SourceID identify what earned it.
+type Activity struct {
+	UserID   int
+	Source   string
+	SourceID int
+	Points   int
+}
+
+// QuizOutcome is what a quiz submission earns XP from. PreviousBest is the
+// user's best earlier grade on the same set, nil on the first attempt.
+type QuizOutcome struct {
+	UserID       int
+	Grade        int
+	Graded       bool
+	PreviousBest *int
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	m "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GamificationHandler struct {
+	gamificationService svc.GamificationService
+	val                 *validator.Validate
+}
+
+func NewGamificationHandler(gamificationService svc.GamificationService, val *validator.Validate) *GamificationHandler {
+	return &GamificationHandler{gamificationService, val}
+}
+
+func (h *GamificationHandler) Router(r fiber.Router) {
+	r.Get("/badges", m.R100(), h.ListBadgesHandler)
+	r.Post("/badges", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageBadges), h.AddBadgeHandler)
+	r.Put("/badges/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageBadges), h.EditBadgeHandler)
+	r.Delete("/badges/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageBadges), h.DeleteBadgeHandler)
+}
+
+func (h *GamificationHandler) ListBadgesHandler(c *fiber.Ctx) error {
+	badges, err := h.gamificationService.ListBadges()
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "badges retrieved successfully", badges)
+}
+
+func (h *GamificationHandler) AddBadgeHandler(c *fiber.Ctx) error {
+	var badge entity.SetBadge
+	if err := c.BodyParser(&badge); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(badge); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	if err := h.gamificationService.AddBadge(badge); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "badge added successfully", nil)
+}
+
+func (h *GamificationHandler) EditBadgeHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	var badge entity.SetBadge
+	if err := c.BodyParser(&badge); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(badge); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	if err := h.gamificationService.EditBadge(int32(id), badge); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "badge updated successfully", nil)
+}
+
+func (h *GamificationHandler) DeleteBadgeHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	if err := h.gamificationService.DeleteBadge(int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "badge deleted successfully", nil)
+}
@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/handler"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGamificationService struct {
+	mock.Mock
+}
+
+func (m *MockGamificationService) RecordQuizSubmission(submissionID int) error {
+	return m.Called(submissionID).Error(0)
+}
+
+func (m *MockGamificationService) RecordTaskSubmission(submissionID int) error {
+	return m.Called(submissionID).Error(0)
+}
+
+func (m *MockGamificationService) Progress(userID int) (entity.Progress, error) {
+	args := m.Called(userID)
+	return args.Get(0).(entity.Progress), args.Error(1)
+}
+
+func (m *MockGamificationService) ListBadges() ([]entity.Badge, error) {
+	args := m.Called()
+	return args.Get(0).([]entity.Badge), args.Error(1)
+}
+
+func (m *MockGamificationService) AddBadge(badge entity.SetBadge) error {
+	return m.Called(badge).Error(0)
+}
+
+func (m *MockGamificationService) EditBadge(id int32, badge entity.SetBadge) error {
+	return m.Called(id, badge).Error(0)
+}
+
+func (m *MockGamificationService) DeleteBadge(id int32) error {
+	return m.Called(id).Error(0)
+}
+
+func TestAddBadgeHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockGamificationService)
+	h := handler.NewGamificationHandler(mockService, validator.New())
+	app.Post("/badges", h.AddBadgeHandler)
+
+	badge := entity.SetBadge{Code: "quiz_10", Name: "Ten Quizzes", Metric: "quiz_submissions", Threshold: 10}
+	mockService.On("AddBadge", badge).Return(nil)
+
+	body, _ := json.Marshal(badge)
+	req := httptest.NewRequest(http.MethodPost, "/badges", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ = json.Marshal(entity.SetBadge{Code: "quiz_0", Name: "None", Metric: "quiz_submissions"})
+	req = httptest.NewRequest(http.MethodPost, "/badges", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListBadgesHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockGamificationService)
+	h := handler.NewGamificationHandler(mockService, validator.New())
+	app.Get("/badges", h.ListBadgesHandler)
+
+	mockService.On("ListBadges").Return([]entity.Badge{{ID: 1, Code: "streak_7"}}, nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/badges", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
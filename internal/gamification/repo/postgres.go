@@ -0,0 +1,184 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/rules"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type GamificationRepository interface {
+	// XP and streaks
+	AddXP(activity entity.Activity, now time.Time) (bool, error)
+	QuizOutcome(submissionID int) (entity.QuizOutcome, error)
+	TaskSubmitter(submissionID int) (int, error)
+	Progress(userID int, now time.Time) (entity.Progress, error)
+
+	// Badges
+	Stats(userID int) (rules.Stats, error)
+	UnearnedBadges(userID int) ([]entity.Badge, error)
+	AwardBadges(userID int, badgeIDs []int32) error
+	ListBadges() ([]entity.Badge, error)
+	BadgeCodeExists(code string, exceptID int32) (bool, error)
+	AddBadge(badge entity.SetBadge) error
+	EditBadge(id int32, badge entity.SetBadge) error
+	DeleteBadge(id int32) error
+}
+
+type gamificationRepository struct {
+	db *sql.DB
+}
+
+func NewGamificationRepository(db *sql.DB) GamificationRepository {
+	return &gamificationRepository{db: db}
+}
+
+// AddXP records the award and moves the user's streak forward. It returns
+// false without changing anything when the source was already awarded.
+func (r *gamificationRepository) AddXP(activity entity.Activity, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][AddXP] Error beginning transaction: ", err)
+		return false, app.NewAppError(500, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var eventID int
+	err = tx.QueryRow(`
+		INSERT INTO xp_events (user_id, source, source_id, points)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (source, source_id) DO NOTHING
+		RETURNING id
+	`, activity.UserID, activity.Source, activity.SourceID, activity.Points).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Error("[Repo][AddXP] Error Insert event: ", err)
+		return false, app.NewAppError(500, "failed to record xp")
+	}
+
+	if _, err := tx.Exec(`INSERT INTO user_progress (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`, activity.UserID); err != nil {
+		log.Error("[Repo][AddXP] Error Insert progress: ", err)
+		return false, app.NewAppError(500, "failed to record xp")
+	}
+
+	var current, longest int
+	var lastActive sql.NullTime
+	err = tx.QueryRow(`SELECT current_streak, longest_streak, last_active_on FROM user_progress WHERE user_id = $1 FOR UPDATE`, activity.UserID).
+		Scan(&current, &longest, &lastActive)
+	if err != nil {
+		log.Error("[Repo][AddXP] Error Query progress: ", err)
+		return false, app.NewAppError(500, "failed to record xp")
+	}
+
+	today := rules.Day(now)
+	var last *time.Time
+	if lastActive.Valid {
+		last = &lastActive.Time
+	}
+	streak := rules.NextStreak(last, today, current)
+	if streak > longest {
+		longest = streak
+	}
+
+	_, err = tx.Exec(`
+		UPDATE user_progress
+		SET xp = xp + $2, current_streak = $3, longest_streak = $4, last_active_on = $5, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE user_id = $1
+	`, activity.UserID, activity.Points, streak, longest, today)
+	if err != nil {
+		log.Error("[Repo][AddXP] Error Update progress: ", err)
+		return false, app.NewAppError(500, "failed to record xp")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][AddXP] Error committing: ", err)
+		return false, app.NewAppError(500, "failed to commit xp")
+	}
+	return true, nil
+}
+
+func (r *gamificationRepository) QuizOutcome(submissionID int) (entity.QuizOutcome, error) {
+	var outcome entity.QuizOutcome
+	var previousBest sql.NullInt64
+	err := r.db.QueryRow(`
+		SELECT s.user_id, s.grade, s.status = 'graded',
+		       (SELECT MAX(p.grade) FROM quiz_submissions p
+		        WHERE p.user_id = s.user_id AND p.set_id = s.set_id AND p.status = 'graded' AND p.id < s.id)
+		FROM quiz_submissions s
+		WHERE s.id = $1
+	`, submissionID).Scan(&outcome.UserID, &outcome.Grade, &outcome.Graded, &previousBest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return outcome, app.NewAppError(404, "quiz submission not found")
+		}
+		log.Error("[Repo][QuizOutcome] Error Query: ", err)
+		return outcome, app.NewAppError(500, "failed to get quiz submission")
+	}
+	if previousBest.Valid {
+		best := int(previousBest.Int64)
+		outcome.PreviousBest = &best
+	}
+	return outcome, nil
+}
+
+func (r *gamificationRepository) TaskSubmitter(submissionID int) (int, error) {
+	var userID int
+	err := r.db.QueryRow(`SELECT user_id FROM task_submissions WHERE id = $1`, submissionID).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, app.NewAppError(404, "task submission not found")
+		}
+		log.Error("[Repo][TaskSubmitter] Error Query: ", err)
+		return 0, app.NewAppError(500, "failed to get task submission")
+	}
+	return userID, nil
+}
+
+func (r *gamificationRepository) Progress(userID int, now time.Time) (entity.Progress, error) {
+	progress := entity.Progress{Badges: []entity.UserBadge{}}
+
+	var lastActive sql.NullTime
+	err := r.db.QueryRow(`SELECT xp, current_streak, longest_streak, last_active_on FROM user_progress WHERE user_id = $1`, userID).
+		Scan(&progress.XP, &progress.CurrentStreak, &progress.LongestStreak, &lastActive)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error("[Repo][Progress] Error Query: ", err)
+		return progress, app.NewAppError(500, "failed to get progress")
+	}
+	if lastActive.Valid {
+		day := lastActive.Time.Format("2006-01-02")
+		progress.LastActiveOn = &day
+		progress.CurrentStreak = rules.ActiveStreak(&lastActive.Time, now, progress.CurrentStreak)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT b.id, b.code, b.name, b.description, b.img_url, b.metric, b.threshold, ub.awarded_at
+		FROM user_badges ub
+		JOIN badges b ON b.id = ub.badge_id
+		WHERE ub.user_id = $1
+		ORDER BY ub.awarded_at, b.id
+	`, userID)
+	if err != nil {
+		log.Error("[Repo][Progress] Error Query badges: ", err)
+		return progress, app.NewAppError(500, "failed to get badges")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b entity.UserBadge
+		if err := rows.Scan(&b.ID, &b.Code, &b.Name, &b.Description, &b.ImgURL, &b.Metric, &b.Threshold, &b.AwardedAt); err != nil {
+			log.Error("[Repo][Progress] Error Scan badges: ", err)
+			return progress, app.NewAppError(500, "failed to scan badges")
+		}
+		progress.Badges = append(progress.Badges, b)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][Progress] Error Rows: ", err)
+		return progress, app.NewAppError(500, "failed to read badges")
+	}
+	return progress, nil
+}
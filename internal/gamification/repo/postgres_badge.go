@@ -0,0 +1,154 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/rules"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/lib/pq"
+)
+
+// Stats computes the value of every badge metric for the user. A lesson is
+// completed once each of its quiz sets has a graded submission.
+func (r *gamificationRepository) Stats(userID int) (rules.Stats, error) {
+	var xp, streak, perfect, quizzes, tasks, lessons int
+	err := r.db.QueryRow(`
+		SELECT COALESCE(p.xp, 0), COALESCE(p.longest_streak, 0),
+		       (SELECT COUNT(*) FROM quiz_submissions s WHERE s.user_id = u.id AND s.status = 'graded' AND s.grade = 100),
+		       (SELECT COUNT(*) FROM quiz_submissions s WHERE s.user_id = u.id AND s.status = 'graded'),
+		       (SELECT COUNT(DISTINCT t.task_id) FROM task_submissions t WHERE t.user_id = u.id),
+		       (SELECT COUNT(*) FROM lessons l
+		        WHERE EXISTS (SELECT 1 FROM sets a WHERE a.lesson_id = l.id AND a.is_quiz)
+		          AND NOT EXISTS (
+		              SELECT 1 FROM sets a
+		              WHERE a.lesson_id = l.id AND a.is_quiz
+		                AND NOT EXISTS (
+		                    SELECT 1 FROM quiz_submissions s
+		                    WHERE s.set_id = a.id AND s.user_id = u.id AND s.status = 'graded')))
+		FROM users u
+		LEFT JOIN user_progress p ON p.user_id = u.id
+		WHERE u.id = $1
+	`, userID).Scan(&xp, &streak, &perfect, &quizzes, &tasks, &lessons)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, app.NewAppError(404, "user not found")
+		}
+		log.Error("[Repo][Stats] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to compute badge stats")
+	}
+
+	return rules.Stats{
+		rules.MetricXP:               xp,
+		rules.MetricStreak:           streak,
+		rules.MetricPerfectScores:    perfect,
+		rules.MetricQuizSubmissions:  quizzes,
+		rules.MetricTaskSubmissions:  tasks,
+		rules.MetricLessonsCompleted: lessons,
+	}, nil
+}
+
+func (r *gamificationRepository) UnearnedBadges(userID int) ([]entity.Badge, error) {
+	return r.queryBadges(`
+		SELECT b.id, b.code, b.name, b.description, b.img_url, b.metric, b.threshold
+		FROM badges b
+		WHERE NOT EXISTS (SELECT 1 FROM user_badges ub WHERE ub.badge_id = b.id AND ub.user_id = $1)
+		ORDER BY b.id
+	`, userID)
+}
+
+func (r *gamificationRepository) AwardBadges(userID int, badgeIDs []int32) error {
+	if len(badgeIDs) == 0 {
+		return nil
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO user_badges (user_id, badge_id)
+		SELECT $1, unnest($2::int[])
+		ON CONFLICT (user_id, badge_id) DO NOTHING
+	`, userID, pq.Array(badgeIDs))
+	if err != nil {
+		log.Error("[Repo][AwardBadges] Error Insert: ", err)
+		return app.NewAppError(500, "failed to award badges")
+	}
+	return nil
+}
+
+func (r *gamificationRepository) ListBadges() ([]entity.Badge, error) {
+	return r.queryBadges(`SELECT id, code, name, description, img_url, metric, threshold FROM badges ORDER BY id`)
+}
+
+func (r *gamificationRepository) queryBadges(query string, args ...interface{}) ([]entity.Badge, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][queryBadges] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to get badges")
+	}
+	defer rows.Close()
+
+	badges := []entity.Badge{}
+	for rows.Next() {
+		var b entity.Badge
+		if err := rows.Scan(&b.ID, &b.Code, &b.Name, &b.Description, &b.ImgURL, &b.Metric, &b.Threshold); err != nil {
+			log.Error("[Repo][queryBadges] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan badges")
+		}
+		badges = append(badges, b)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][queryBadges] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read badges")
+	}
+	return badges, nil
+}
+
+func (r *gamificationRepository) BadgeCodeExists(code string, exceptID int32) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM badges WHERE code = $1 AND id <> $2)`, code, exceptID).Scan(&exists)
+	if err != nil {
+		log.Error("[Repo][BadgeCodeExists] Error Query: ", err)
+		return false, app.NewAppError(500, "failed to check badge code")
+	}
+	return exists, nil
+}
+
+func (r *gamificationRepository) AddBadge(badge entity.SetBadge) error {
+	_, err := r.db.Exec(`
+		INSERT INTO badges (code, name, description, img_url, metric, threshold)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, badge.Code, badge.Name, badge.Description, badge.ImgURL, badge.Metric, badge.Threshold)
+	if err != nil {
+		log.Error("[Repo][AddBadge] Error Insert: ", err)
+		return app.NewAppError(500, "failed to add badge")
+	}
+	return nil
+}
+
+func (r *gamificationRepository) EditBadge(id int32, badge entity.SetBadge) error {
+	res, err := r.db.Exec(`
+		UPDATE badges
+		SET code = $2, name = $3, description = $4, img_url = $5, metric = $6, threshold = $7, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1
+	`, id, badge.Code, badge.Name, badge.Description, badge.ImgURL, badge.Metric, badge.Threshold)
+	if err != nil {
+		log.Error("[Repo][EditBadge] Error Update: ", err)
+		return app.NewAppError(500, "failed to edit badge")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "badge not found")
+	}
+	return nil
+}
+
+// DeleteBadge removes the badge; users who earned it lose it as well.
+func (r *gamificationRepository) DeleteBadge(id int32) error {
+	res, err := r.db.Exec(`DELETE FROM badges WHERE id = $1`, id)
+	if err != nil {
+		log.Error("[Repo][DeleteBadge] Error Delete: ", err)
+		return app.NewAppError(500, "failed to delete badge")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "badge not found")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddXP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewGamificationRepository(db)
+	activity := entity.Activity{UserID: 2, Source: "quiz_submission", SourceID: 9, Points: 20}
+	now := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO xp_events`).
+		WithArgs(2, "quiz_submission", 9, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO user_progress`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT current_streak, longest_streak, last_active_on FROM user_progress`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"current_streak", "longest_streak", "last_active_on"}).AddRow(3, 3, yesterday))
+	mock.ExpectExec(`UPDATE user_progress`).
+		WithArgs(2, 20, 4, 4, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := repository.AddXP(activity, now)
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddXP_AlreadyAwarded(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewGamificationRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO xp_events`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	added, err := repository.AddXP(entity.Activity{UserID: 2, Source: "task_submission", SourceID: 4, Points: 15}, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+// Package rules holds the XP amounts, the daily streak arithmetic and the
+// metrics badges are defined on. Badges themselves live in the database; a
+// badge names a metric and the threshold at which it is earned.
+package rules
+
+import "time"
+
+// XP sources. Each source and source id pair is awarded at most once.
+const (
+	SourceQuiz        = "quiz_submission"
+	SourceImprovement = "quiz_improvement"
+	SourceTask        = "task_submission"
+)
+
+// TaskPoints is the XP for handing in a task.
+const TaskPoints = 15
+
+// QuizPoints is 10 XP for a graded quiz plus one for every 10 grade points.
+func QuizPoints(grade int) int {
+	if grade < 0 {
+		grade = 0
+	}
+	return 10 + grade/10
+}
+
+// ImprovementPoints rewards beating one's previous best on the same set with
+// half the gained grade points.
+func ImprovementPoints(previousBest, grade int) int {
+	if grade <= previousBest {
+		return 0
+	}
+	return (grade - previousBest) / 2
+}
+
+// Day truncates t to its UTC calendar day.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(Day(to).Sub(Day(from)).Hours() / 24)
+}
+
+// NextStreak returns the streak after activity on today. lastActive is nil
+// for a user's first activity.
+func NextStreak(lastActive *time.Time, today time.Time, current int) int {
+	if lastActive == nil {
+		return 1
+	}
+	switch daysBetween(*lastActive, today) {
+	case 0:
+		if current < 1 {
+			return 1
+		}
+		return current
+	case 1:
+		return current + 1
+	default:
+		return 1
+	}
+}
+
+// ActiveStreak is the streak as of today: it is broken once a whole day
+// passes without activity.
+func ActiveStreak(lastActive *time.Time, today time.Time, current int) int {
+	if lastActive == nil || daysBetween(*lastActive, today) > 1 {
+		return 0
+	}
+	return current
+}
+
+// Badge metrics.
+const (
+	MetricXP               = "xp"
+	MetricStreak           = "streak"
+	MetricPerfectScores    = "perfect_scores"
+	MetricQuizSubmissions  = "quiz_submissions"
+	MetricTaskSubmissions  = "task_submissions"
+	MetricLessonsCompleted = "lessons_completed"
+)
+
+// Metrics lists every metric a badge can be defined on.
+var Metrics = []string{
+	MetricXP, MetricStreak, MetricPerfectScores,
+	MetricQuizSubmissions, MetricTaskSubmissions, MetricLessonsCompleted,
+}
+
+func ValidMetric(metric string) bool {
+	for _, m := range Metrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats holds a user's value for every metric. The streak metric is the
+// longest streak reached, so a streak badge is kept after the streak breaks.
+type Stats map[string]int
+
+// Earned reports whether stats reach the badge threshold.
+func Earned(metric string, threshold int, stats Stats) bool {
+	return stats[metric] >= threshold
+}
@@ -0,0 +1,46 @@
+package rules_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/gamification/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoints(t *testing.T) {
+	assert.Equal(t, 10, rules.QuizPoints(0))
+	assert.Equal(t, 17, rules.QuizPoints(75))
+	assert.Equal(t, 20, rules.QuizPoints(100))
+
+	assert.Equal(t, 0, rules.ImprovementPoints(80, 80))
+	assert.Equal(t, 0, rules.ImprovementPoints(80, 60))
+	assert.Equal(t, 25, rules.ImprovementPoints(40, 90))
+}
+
+func TestNextStreak(t *testing.T) {
+	day := func(d int, hour int) time.Time { return time.Date(2026, 10, d, hour, 0, 0, 0, time.UTC) }
+	last := day(16, 23)
+
+	assert.Equal(t, 1, rules.NextStreak(nil, day(17, 8), 0))
+	assert.Equal(t, 3, rules.NextStreak(&last, day(16, 1), 3), "same day keeps the streak")
+	assert.Equal(t, 4, rules.NextStreak(&last, day(17, 0), 3), "next day extends it")
+	assert.Equal(t, 1, rules.NextStreak(&last, day(18, 12), 3), "a missed day restarts it")
+}
+
+func TestActiveStreak(t *testing.T) {
+	last := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5, rules.ActiveStreak(&last, time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC), 5))
+	assert.Equal(t, 0, rules.ActiveStreak(&last, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), 5))
+	assert.Equal(t, 0, rules.ActiveStreak(nil, last, 0))
+}
+
+func TestEarned(t *testing.T) {
+	stats := rules.Stats{rules.MetricStreak: 7, rules.MetricPerfectScores: 0}
+
+	assert.True(t, rules.Earned(rules.MetricStreak, 7, stats))
+	assert.False(t, rules.Earned(rules.MetricPerfectScores, 1, stats))
+	assert.True(t, rules.ValidMetric("lessons_completed"))
+	assert.False(t, rules.ValidMetric("logins"))
+}
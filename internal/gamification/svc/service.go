@@ -0,0 +1,139 @@
+package svc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	"github.com/ghulammuzz/misterblast/internal/gamification/rules"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+type GamificationService interface {
+	// Awards
+	RecordQuizSubmission(submissionID int) error
+	RecordTaskSubmission(submissionID int) error
+	Progress(userID int) (entity.Progress, error)
+
+	// Badges
+	ListBadges() ([]entity.Badge, error)
+	AddBadge(badge entity.SetBadge) error
+	EditBadge(id int32, badge entity.SetBadge) error
+	DeleteBadge(id int32) error
+}
+
+type gamificationService struct {
+	repo repo.GamificationRepository
+}
+
+func NewGamificationService(repo repo.GamificationRepository) GamificationService {
+	return &gamificationService{repo: repo}
+}
+
+// RecordQuizSubmission awards XP for the first graded attempt at a set and
+// only improvement XP for later attempts that beat the user's previous best,
+// so resubmitting cannot be farmed. It then checks for new badges.
+// Submissions still waiting for essay review are skipped until they are
+// graded; calling it twice for the same submission awards nothing new.
+func (s *gamificationService) RecordQuizSubmission(submissionID int) error {
+	outcome, err := s.repo.QuizOutcome(submissionID)
+	if err != nil {
+		return err
+	}
+	if !outcome.Graded {
+		return nil
+	}
+
+	now := time.Now()
+	if outcome.PreviousBest == nil {
+		activity := entity.Activity{UserID: outcome.UserID, Source: rules.SourceQuiz, SourceID: submissionID, Points: rules.QuizPoints(outcome.Grade)}
+		if _, err := s.repo.AddXP(activity, now); err != nil {
+			return err
+		}
+	} else {
+		if points := rules.ImprovementPoints(*outcome.PreviousBest, outcome.Grade); points > 0 {
+			activity := entity.Activity{UserID: outcome.UserID, Source: rules.SourceImprovement, SourceID: submissionID, Points: points}
+			if _, err := s.repo.AddXP(activity, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.awardBadges(outcome.UserID)
+}
+
+func (s *gamificationService) RecordTaskSubmission(submissionID int) error {
+	userID, err := s.repo.TaskSubmitter(submissionID)
+	if err != nil {
+		return err
+	}
+
+	activity := entity.Activity{UserID: userID, Source: rules.SourceTask, SourceID: submissionID, Points: rules.TaskPoints}
+	if _, err := s.repo.AddXP(activity, time.Now()); err != nil {
+		return err
+	}
+	return s.awardBadges(userID)
+}
+
+// awardBadges grants every badge whose threshold the user reached.
+func (s *gamificationService) awardBadges(userID int) error {
+	badges, err := s.repo.UnearnedBadges(userID)
+	if err != nil || len(badges) == 0 {
+		return err
+	}
+
+	stats, err := s.repo.Stats(userID)
+	if err != nil {
+		return err
+	}
+
+	var earned []int32
+	for _, b := range badges {
+		if rules.Earned(b.Metric, b.Threshold, stats) {
+			earned = append(earned, b.ID)
+		}
+	}
+	return s.repo.AwardBadges(userID, earned)
+}
+
+func (s *gamificationService) Progress(userID int) (entity.Progress, error) {
+	return s.repo.Progress(userID, time.Now())
+}
+
+func (s *gamificationService) ListBadges() ([]entity.Badge, error) {
+	return s.repo.ListBadges()
+}
+
+func (s *gamificationService) AddBadge(badge entity.SetBadge) error {
+	if err := s.checkBadge(0, badge); err != nil {
+		return err
+	}
+	return s.repo.AddBadge(badge)
+}
+
+func (s *gamificationService) EditBadge(id int32, badge entity.SetBadge) error {
+	if err := s.checkBadge(id, badge); err != nil {
+		return err
+	}
+	return s.repo.EditBadge(id, badge)
+}
+
+func (s *gamificationService) DeleteBadge(id int32) error {
+	return s.repo.DeleteBadge(id)
+}
+
+func (s *gamificationService) checkBadge(id int32, badge entity.SetBadge) error {
+	if !rules.ValidMetric(badge.Metric) {
+		return app.NewAppError(400, fmt.Sprintf("metric must be one of %s", strings.Join(rules.Metrics, ", ")))
+	}
+	exists, err := s.repo.BadgeCodeExists(badge.Code, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return app.NewAppError(409, "badge code already exists")
+	}
+	return nil
+}
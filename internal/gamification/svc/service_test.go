@@ -0,0 +1,142 @@
+package svc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/internal/gamification/rules"
+	"github.com/ghulammuzz/misterblast/internal/gamification/svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGamificationRepo struct {
+	mock.Mock
+}
+
+func (m *MockGamificationRepo) AddXP(activity entity.Activity, now time.Time) (bool, error) {
+	args := m.Called(activity)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGamificationRepo) QuizOutcome(submissionID int) (entity.QuizOutcome, error) {
+	args := m.Called(submissionID)
+	return args.Get(0).(entity.QuizOutcome), args.Error(1)
+}
+
+func (m *MockGamificationRepo) TaskSubmitter(submissionID int) (int, error) {
+	args := m.Called(submissionID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockGamificationRepo) Progress(userID int, now time.Time) (entity.Progress, error) {
+	args := m.Called(userID)
+	return args.Get(0).(entity.Progress), args.Error(1)
+}
+
+func (m *MockGamificationRepo) Stats(userID int) (rules.Stats, error) {
+	args := m.Called(userID)
+	return args.Get(0).(rules.Stats), args.Error(1)
+}
+
+func (m *MockGamificationRepo) UnearnedBadges(userID int) ([]entity.Badge, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.Badge), args.Error(1)
+}
+
+func (m *MockGamificationRepo) AwardBadges(userID int, badgeIDs []int32) error {
+	args := m.Called(userID, badgeIDs)
+	return args.Error(0)
+}
+
+func (m *MockGamificationRepo) ListBadges() ([]entity.Badge, error) {
+	args := m.Called()
+	return args.Get(0).([]entity.Badge), args.Error(1)
+}
+
+func (m *MockGamificationRepo) BadgeCodeExists(code string, exceptID int32) (bool, error) {
+	args := m.Called(code, exceptID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGamificationRepo) AddBadge(badge entity.SetBadge) error {
+	args := m.Called(badge)
+	return args.Error(0)
+}
+
+func (m *MockGamificationRepo) EditBadge(id int32, badge entity.SetBadge) error {
+	args := m.Called(id, badge)
+	return args.Error(0)
+}
+
+func (m *MockGamificationRepo) DeleteBadge(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestRecordQuizSubmission(t *testing.T) {
+	mockRepo := new(MockGamificationRepo)
+	service := svc.NewGamificationService(mockRepo)
+
+	mockRepo.On("QuizOutcome", 9).Return(entity.QuizOutcome{UserID: 2, Grade: 100, Graded: true}, nil)
+	mockRepo.On("AddXP", entity.Activity{UserID: 2, Source: rules.SourceQuiz, SourceID: 9, Points: 20}).Return(true, nil)
+	mockRepo.On("UnearnedBadges", 2).Return([]entity.Badge{
+		{ID: 1, Metric: rules.MetricPerfectScores, Threshold: 1},
+		{ID: 2, Metric: rules.MetricStreak, Threshold: 7},
+	}, nil)
+	mockRepo.On("Stats", 2).Return(rules.Stats{rules.MetricPerfectScores: 1, rules.MetricStreak: 2}, nil)
+	mockRepo.On("AwardBadges", 2, []int32{1}).Return(nil)
+
+	err := service.RecordQuizSubmission(9)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordQuizSubmission_Resubmitted(t *testing.T) {
+	mockRepo := new(MockGamificationRepo)
+	service := svc.NewGamificationService(mockRepo)
+
+	// A later attempt earns only the improvement over the previous best.
+	best := 40
+	mockRepo.On("QuizOutcome", 10).Return(entity.QuizOutcome{UserID: 2, Grade: 100, Graded: true, PreviousBest: &best}, nil)
+	mockRepo.On("AddXP", entity.Activity{UserID: 2, Source: rules.SourceImprovement, SourceID: 10, Points: 30}).Return(true, nil)
+	mockRepo.On("UnearnedBadges", 2).Return([]entity.Badge(nil), nil)
+
+	assert.NoError(t, service.RecordQuizSubmission(10))
+
+	// One that does not beat it earns nothing.
+	top := 100
+	mockRepo.On("QuizOutcome", 11).Return(entity.QuizOutcome{UserID: 2, Grade: 100, Graded: true, PreviousBest: &top}, nil)
+
+	assert.NoError(t, service.RecordQuizSubmission(11))
+	mockRepo.AssertNumberOfCalls(t, "AddXP", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordQuizSubmission_PendingReview(t *testing.T) {
+	mockRepo := new(MockGamificationRepo)
+	service := svc.NewGamificationService(mockRepo)
+
+	mockRepo.On("QuizOutcome", 9).Return(entity.QuizOutcome{UserID: 2, Grade: 50, Graded: false}, nil)
+
+	err := service.RecordQuizSubmission(9)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "AddXP", mock.Anything)
+}
+
+func TestAddBadge(t *testing.T) {
+	mockRepo := new(MockGamificationRepo)
+	service := svc.NewGamificationService(mockRepo)
+
+	badge := entity.SetBadge{Code: "xp_500", Name: "500 XP", Metric: rules.MetricXP, Threshold: 500}
+	mockRepo.On("BadgeCodeExists", "xp_500", int32(0)).Return(false, nil)
+	mockRepo.On("AddBadge", badge).Return(nil)
+
+	assert.NoError(t, service.AddBadge(badge))
+
+	err := service.AddBadge(entity.SetBadge{Code: "logins", Name: "Logins", Metric: "logins", Threshold: 1})
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
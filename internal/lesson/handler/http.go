@@ -21,8 +21,8 @@ func NewLessonHandler(lessonService svc.LessonService, val *validator.Validate)
 }
 
 func (h *LessonHandler) Router(r fiber.Router) {
-	r.Post("/lesson", m.R100(), h.AddLessonHandler)
-	r.Delete("/lesson/:id", m.R100(), h.DeleteLessonHandler)
+	r.Post("/lesson", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageCurriculum), h.AddLessonHandler)
+	r.Delete("/lesson/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageCurriculum), h.DeleteLessonHandler)
 	r.Get("/lesson", m.R100(), h.ListLessonsHandler)
 }
 
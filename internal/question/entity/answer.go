@@ -1,10 +1,14 @@
 package entity
 
+import "github.com/ghulammuzz/misterblast/pkg/imaging"
+
 type Answer struct {
 	ID         int32   `json:"id"`
 	QuestionID int32   `json:"question_id" validate:"required"`
-	Code       string  `json:"code" validate:"required,oneof=a b c d esay"`
+	Code       string  `json:"code" validate:"required,oneof=a b c d essay"`
 	Content    string  `json:"content" validate:"required"`
 	ImgURL     *string `json:"img_url,omitempty"`
-	IsAnswer   bool    `json:"is_answer"`
+	// ImgVariants holds the resized WebP URLs of ImgURL, when it has any.
+	ImgVariants imaging.Variants `json:"img_variants,omitempty"`
+	IsAnswer    bool             `json:"is_answer"`
 }
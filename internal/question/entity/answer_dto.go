@@ -1,5 +1,7 @@
 package entity
 
+import "github.com/ghulammuzz/misterblast/pkg/imaging"
+
 type SetAnswer struct {
 	ID         int32   `json:"id"`
 	QuestionID int32   `json:"question_id" validate:"required"`
@@ -16,17 +18,18 @@ type EditAnswer struct {
 }
 
 type ListAnswer struct {
-	ID       int32   `json:"id"`
-	Code     string  `json:"code"`
-	Content  string  `json:"content"`
-	ImgURL   *string `json:"img_url"`
+	ID          int32            `json:"id"`
+	Code        string           `json:"code"`
+	Content     string           `json:"content"`
+	ImgURL      *string          `json:"img_url"`
+	ImgVariants imaging.Variants `json:"img_variants,omitempty"`
 }
 
 type ListAnswerDetail struct {
-	ID       int32   `json:"id"`
-	Code     string  `json:"code"`
-	Content  string  `json:"content"`
-	ImgURL   *string `json:"img_url"`
-	IsAnswer bool    `json:"is_answer"`
+	ID          int32            `json:"id"`
+	Code        string           `json:"code"`
+	Content     string           `json:"content"`
+	ImgURL      *string          `json:"img_url"`
+	ImgVariants imaging.Variants `json:"img_variants,omitempty"`
+	IsAnswer    bool             `json:"is_answer"`
 }
-
@@ -0,0 +1,28 @@
+package entity
+
+// ExportQuestion is a question with its full answer key and the names of the
+// set, lesson and class it belongs to.
+type ExportQuestion struct {
+	ID          int32              `json:"id"`
+	Number      int                `json:"number"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Content     string             `json:"content"`
+	Explanation string             `json:"explanation"`
+	Reason      string             `json:"reason"`
+	Lang        string             `json:"lang"`
+	IsQuiz      bool               `json:"is_quiz"`
+	SetID       int32              `json:"set_id"`
+	SetName     string             `json:"set_name"`
+	LessonName  string             `json:"lesson_name"`
+	ClassName   string             `json:"class_name"`
+	Answers     []ListAnswerDetail `json:"answers"`
+}
+
+// ExportBank is the document written by the JSON export.
+type ExportBank struct {
+	Version    int               `json:"version"`
+	ExportedAt int64             `json:"exported_at"`
+	Filter     map[string]string `json:"filter"`
+	Questions  []ExportQuestion  `json:"questions"`
+}
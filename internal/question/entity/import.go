@@ -0,0 +1,37 @@
+package entity
+
+// ImportQuestion is one question of an imported bank together with its
+// answers. Row is the 1-based CSV line or JSON index it was read from.
+type ImportQuestion struct {
+	Row         int            `json:"-"`
+	Number      int            `json:"number"`
+	Type        string         `json:"type"`
+	Format      string         `json:"format"`
+	Content     string         `json:"content"`
+	Explanation string         `json:"explanation"`
+	Reason      string         `json:"reason"`
+	Answers     []ImportAnswer `json:"answers"`
+}
+
+type ImportAnswer struct {
+	Row      int     `json:"-"`
+	Code     string  `json:"code"`
+	Content  string  `json:"content"`
+	ImgURL   *string `json:"img_url,omitempty"`
+	IsAnswer bool    `json:"is_answer"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	SetID     int32            `json:"set_id"`
+	Lang      string           `json:"lang"`
+	DryRun    bool             `json:"dry_run"`
+	Questions int              `json:"questions"`
+	Answers   int              `json:"answers"`
+	Errors    []ImportRowError `json:"errors,omitempty"`
+}
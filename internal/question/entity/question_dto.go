@@ -56,18 +56,19 @@ type ListQuestionQuiz struct {
 }
 
 type ListQuestionAdmin struct {
-	ID          int32  `json:"id"`
-	Number      int    `json:"number"`
-	Type        string `json:"type"`
-	Format      string `json:"format"`
-	Content     string `json:"content"`
-	IsQuiz      bool   `json:"is_quiz"`
-	SetID       int32  `json:"set_id"`
-	SetName     string `json:"set_name"`
-	LessonName  string `json:"lesson_name"`
-	ClassName   string `json:"class_name"`
-	Explanation string `json:"explanation"`
-	Reason      string `json:"reason"`
+	ID          int32      `json:"id"`
+	Number      int        `json:"number"`
+	Type        string     `json:"type"`
+	Format      string     `json:"format"`
+	Content     string     `json:"content"`
+	IsQuiz      bool       `json:"is_quiz"`
+	SetID       int32      `json:"set_id"`
+	SetName     string     `json:"set_name"`
+	LessonName  string     `json:"lesson_name"`
+	ClassName   string     `json:"class_name"`
+	Explanation string     `json:"explanation"`
+	Reason      string     `json:"reason"`
+	Stats       *ItemStats `json:"stats"`
 }
 
 type QuestionType struct {
@@ -0,0 +1,13 @@
+package entity
+
+// ItemStats is the latest item analysis snapshot of a question. Difficulty
+// is the mean score (p-value) from 0 to 1, Discrimination is left out until
+// enough students answered, and Options counts the picks per option code of
+// choice questions.
+type ItemStats struct {
+	Responses      int            `json:"responses"`
+	Difficulty     float64        `json:"difficulty"`
+	Discrimination *float64       `json:"discrimination"`
+	Options        map[string]int `json:"options,omitempty"`
+	ComputedAt     int64          `json:"computed_at"`
+}
@@ -0,0 +1,74 @@
+// Package export writes question banks as IMS QTI 2.1 packages, Moodle XML
+// or the JSON format of this service.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+const (
+	FormatQTI    = "qti"
+	FormatMoodle = "moodle"
+	FormatJSON   = "json"
+)
+
+// File is an encoded export ready to be sent as a download.
+type File struct {
+	Name        string
+	ContentType string
+	Body        []byte
+}
+
+// Encode writes bank in the given format. name is used as the base of the
+// file name.
+func Encode(format, name string, bank questionEntity.ExportBank) (File, error) {
+	switch format {
+	case FormatQTI:
+		body, err := QTI(bank.Questions)
+		return File{Name: name + "-qti21.zip", ContentType: "application/zip", Body: body}, err
+	case FormatMoodle:
+		body, err := MoodleXML(bank.Questions)
+		return File{Name: name + "-moodle.xml", ContentType: "application/xml", Body: body}, err
+	case FormatJSON, "":
+		body, err := json.MarshalIndent(bank, "", "  ")
+		return File{Name: name + ".json", ContentType: "application/json", Body: body}, err
+	}
+	return File{}, fmt.Errorf("unsupported export format %q, use qti, moodle or json", format)
+}
+
+func correctAnswers(q questionEntity.ExportQuestion) []questionEntity.ListAnswerDetail {
+	var correct []questionEntity.ListAnswerDetail
+	for _, a := range q.Answers {
+		if a.IsAnswer {
+			correct = append(correct, a)
+		}
+	}
+	return correct
+}
+
+// htmlText renders plain text and an optional image as an HTML fragment.
+func htmlText(text string, imgURL *string) string {
+	var b strings.Builder
+	b.WriteString(html.EscapeString(text))
+	if imgURL != nil && *imgURL != "" {
+		fmt.Fprintf(&b, ` <img src="%s" alt=""/>`, html.EscapeString(*imgURL))
+	}
+	return b.String()
+}
+
+// feedback joins the explanation and reason of a question.
+func feedback(q questionEntity.ExportQuestion) []string {
+	var parts []string
+	if q.Explanation != "" {
+		parts = append(parts, q.Explanation)
+	}
+	if q.Reason != "" {
+		parts = append(parts, q.Reason)
+	}
+	return parts
+}
@@ -0,0 +1,192 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/question/export"
+	"github.com/ghulammuzz/misterblast/internal/question/importer"
+)
+
+func sampleQuestions() []questionEntity.ExportQuestion {
+	img := "https://cdn.example.com/jakarta.png"
+	return []questionEntity.ExportQuestion{
+		{
+			ID: 10, Number: 1, Format: "mc4", Content: "Ibu kota <Indonesia>?", Explanation: "Jakarta", Reason: "Fakta",
+			Lang: "id", SetID: 3, SetName: "Set A", LessonName: "IPS", ClassName: "Kelas 4",
+			Answers: []questionEntity.ListAnswerDetail{
+				{Code: "a", Content: "Jakarta", ImgURL: &img, IsAnswer: true},
+				{Code: "b", Content: "Bandung"},
+			},
+		},
+		{
+			ID: 11, Number: 2, Format: "mcx", Content: "Pilih bilangan genap", SetID: 3, SetName: "Set A",
+			Answers: []questionEntity.ListAnswerDetail{
+				{Code: "a", Content: "2", IsAnswer: true},
+				{Code: "b", Content: "3"},
+				{Code: "c", Content: "4", IsAnswer: true},
+			},
+		},
+		{
+			ID: 12, Number: 3, Format: "sa", Content: "2+2?", SetID: 3, SetName: "Set A",
+			Answers: []questionEntity.ListAnswerDetail{{Code: "a", Content: "4", IsAnswer: true}},
+		},
+		{
+			ID: 13, Number: 4, Format: "mm", Content: "Pasangkan", SetID: 3, SetName: "Set A",
+			Answers: []questionEntity.ListAnswerDetail{{Code: "a", Content: "Merah"}, {Code: "b", Content: "Biru"}},
+		},
+		{
+			ID: 14, Number: 5, Format: "essay", Content: "Jelaskan", SetID: 3, SetName: "Set A",
+			Answers: []questionEntity.ListAnswerDetail{{Code: "essay", Content: "Kunci", IsAnswer: true}},
+		},
+	}
+}
+
+func TestMoodleXML(t *testing.T) {
+	body, err := export.MoodleXML(sampleQuestions())
+	require.NoError(t, err)
+
+	var quiz struct {
+		Questions []struct {
+			Type     string `xml:"type,attr"`
+			Category string `xml:"category>text"`
+			Text     string `xml:"questiontext>text"`
+			Single   string `xml:"single"`
+			Answers  []struct {
+				Fraction string `xml:"fraction,attr"`
+				Text     string `xml:"text"`
+			} `xml:"answer"`
+			Subquestions []struct {
+				Answer string `xml:"answer>text"`
+			} `xml:"subquestion"`
+			GraderInfo string `xml:"graderinfo>text"`
+		} `xml:"question"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &quiz))
+	require.Len(t, quiz.Questions, 6)
+
+	assert.Equal(t, "category", quiz.Questions[0].Type)
+	assert.Equal(t, "$course$/Kelas 4/IPS/Set A", quiz.Questions[0].Category)
+
+	mc := quiz.Questions[1]
+	assert.Equal(t, "multichoice", mc.Type)
+	assert.Equal(t, "true", mc.Single)
+	assert.Equal(t, "Ibu kota &lt;Indonesia&gt;?", mc.Text)
+	assert.Equal(t, "100", mc.Answers[0].Fraction)
+	assert.Contains(t, mc.Answers[0].Text, `<img src="https://cdn.example.com/jakarta.png"`)
+	assert.Equal(t, "0", mc.Answers[1].Fraction)
+
+	mcx := quiz.Questions[2]
+	assert.Equal(t, "false", mcx.Single)
+	assert.Equal(t, []string{"50", "-100", "50"}, []string{mcx.Answers[0].Fraction, mcx.Answers[1].Fraction, mcx.Answers[2].Fraction})
+
+	assert.Equal(t, "shortanswer", quiz.Questions[3].Type)
+	assert.Equal(t, "4", quiz.Questions[3].Answers[0].Text)
+	assert.Equal(t, "matching", quiz.Questions[4].Type)
+	assert.Equal(t, "Biru", quiz.Questions[4].Subquestions[1].Answer)
+	assert.Equal(t, "essay", quiz.Questions[5].Type)
+	assert.Equal(t, "Kunci", quiz.Questions[5].GraderInfo)
+}
+
+func TestQTI(t *testing.T) {
+	body, err := export.QTI(sampleQuestions())
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	require.Len(t, files, 6)
+
+	var manifest struct {
+		Resources []struct {
+			Href string `xml:"href,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"resources>resource"`
+	}
+	require.NoError(t, xml.Unmarshal(files["imsmanifest.xml"], &manifest))
+	require.Len(t, manifest.Resources, 5)
+	assert.Equal(t, "items/Q10.xml", manifest.Resources[0].Href)
+	assert.Equal(t, "imsqti_item_xmlv2p1", manifest.Resources[0].Type)
+
+	type item struct {
+		Identifier string   `xml:"identifier,attr"`
+		Correct    []string `xml:"responseDeclaration>correctResponse>value"`
+		Choices    []string `xml:"itemBody>choiceInteraction>simpleChoice"`
+		Rubric     []string `xml:"itemBody>rubricBlock>p"`
+	}
+	var mc item
+	require.NoError(t, xml.Unmarshal(files["items/Q10.xml"], &mc))
+	assert.Equal(t, "Q10", mc.Identifier)
+	assert.Equal(t, []string{"A_a"}, mc.Correct)
+	assert.Len(t, mc.Choices, 2)
+	assert.Equal(t, []string{"Jakarta", "Fakta"}, mc.Rubric)
+
+	var mcx item
+	require.NoError(t, xml.Unmarshal(files["items/Q11.xml"], &mcx))
+	assert.Equal(t, []string{"A_a", "A_c"}, mcx.Correct)
+
+	var mm item
+	require.NoError(t, xml.Unmarshal(files["items/Q13.xml"], &mm))
+	assert.Equal(t, []string{"L_a R_1", "L_b R_2"}, mm.Correct)
+
+	assert.Contains(t, string(files["items/Q12.xml"]), `<textEntryInteraction responseIdentifier="RESPONSE"/>`)
+	assert.Contains(t, string(files["items/Q14.xml"]), "extendedTextInteraction")
+}
+
+func TestEncode(t *testing.T) {
+	bank := questionEntity.ExportBank{Version: 1, Questions: sampleQuestions()}
+
+	file, err := export.Encode(export.FormatJSON, "set-3", bank)
+	require.NoError(t, err)
+	assert.Equal(t, "set-3.json", file.Name)
+
+	var decoded questionEntity.ExportBank
+	require.NoError(t, json.Unmarshal(file.Body, &decoded))
+	assert.Equal(t, bank.Questions, decoded.Questions)
+
+	_, err = export.Encode("csv", "set-3", bank)
+	assert.Error(t, err)
+}
+
+func TestEncode_ImportRoundTrip(t *testing.T) {
+	bank := questionEntity.ExportBank{Version: 1, Filter: map[string]string{"set_id": "3"}, Questions: sampleQuestions()}
+
+	file, err := export.Encode(export.FormatJSON, "set-3", bank)
+	require.NoError(t, err)
+
+	questions, rowErrs, err := importer.Parse(importer.FormatJSON, bytes.NewReader(file.Body))
+	require.NoError(t, err)
+	assert.Empty(t, rowErrs)
+	require.Len(t, questions, len(bank.Questions))
+	for i, want := range bank.Questions {
+		got := questions[i]
+		assert.Equal(t, i+1, got.Row)
+		assert.Equal(t, want.Number, got.Number)
+		assert.Equal(t, want.Format, got.Format)
+		assert.Equal(t, want.Content, got.Content)
+		assert.Equal(t, want.Explanation, got.Explanation)
+		assert.Equal(t, want.Reason, got.Reason)
+		require.Len(t, got.Answers, len(want.Answers))
+		for j, a := range want.Answers {
+			assert.Equal(t, a.Code, got.Answers[j].Code)
+			assert.Equal(t, a.Content, got.Answers[j].Content)
+			assert.Equal(t, a.ImgURL, got.Answers[j].ImgURL)
+			assert.Equal(t, a.IsAnswer, got.Answers[j].IsAnswer)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleText struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+}
+
+type moodleAnswer struct {
+	Fraction string      `xml:"fraction,attr"`
+	Format   string      `xml:"format,attr,omitempty"`
+	Text     string      `xml:"text"`
+	Feedback *moodleText `xml:"feedback,omitempty"`
+}
+
+type moodleSubquestion struct {
+	Format string     `xml:"format,attr"`
+	Text   string     `xml:"text"`
+	Answer moodleText `xml:"answer"`
+}
+
+type moodleQuestion struct {
+	Type            string              `xml:"type,attr"`
+	Category        *moodleText         `xml:"category,omitempty"`
+	Name            *moodleText         `xml:"name,omitempty"`
+	QuestionText    *moodleText         `xml:"questiontext,omitempty"`
+	GeneralFeedback *moodleText         `xml:"generalfeedback,omitempty"`
+	DefaultGrade    string              `xml:"defaultgrade,omitempty"`
+	Single          string              `xml:"single,omitempty"`
+	ShuffleAnswers  string              `xml:"shuffleanswers,omitempty"`
+	AnswerNumbering string              `xml:"answernumbering,omitempty"`
+	UseCase         string              `xml:"usecase,omitempty"`
+	ResponseFormat  string              `xml:"responseformat,omitempty"`
+	GraderInfo      *moodleText         `xml:"graderinfo,omitempty"`
+	Answers         []moodleAnswer      `xml:"answer"`
+	Subquestions    []moodleSubquestion `xml:"subquestion"`
+}
+
+// MoodleXML writes the questions as a Moodle XML quiz. Each set becomes a
+// category named after its class, lesson and set.
+func MoodleXML(questions []questionEntity.ExportQuestion) ([]byte, error) {
+	var quiz moodleQuiz
+	lastSet := int32(-1)
+
+	for _, q := range questions {
+		if q.SetID != lastSet {
+			lastSet = q.SetID
+			quiz.Questions = append(quiz.Questions, moodleQuestion{
+				Type:     "category",
+				Category: &moodleText{Text: fmt.Sprintf("$course$/%s/%s/%s", q.ClassName, q.LessonName, q.SetName)},
+			})
+		}
+		quiz.Questions = append(quiz.Questions, moodleItem(q))
+	}
+
+	body, err := xml.MarshalIndent(quiz, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func moodleItem(q questionEntity.ExportQuestion) moodleQuestion {
+	item := moodleQuestion{
+		Name:         &moodleText{Text: fmt.Sprintf("%s %d", q.SetName, q.Number)},
+		QuestionText: &moodleText{Format: "html", Text: htmlText(q.Content, nil)},
+		DefaultGrade: "1",
+	}
+	if parts := feedback(q); len(parts) > 0 {
+		item.GeneralFeedback = &moodleText{Format: "html", Text: "<p>" + strings.Join(escapeAll(parts), "</p><p>") + "</p>"}
+	}
+
+	switch q.Format {
+	case "sa":
+		item.Type = "shortanswer"
+		item.UseCase = "0"
+		for _, a := range correctAnswers(q) {
+			item.Answers = append(item.Answers, moodleAnswer{Fraction: "100", Format: "moodle_auto_format", Text: a.Content})
+		}
+	case "essay":
+		item.Type = "essay"
+		item.ResponseFormat = "editor"
+		var keys []string
+		for _, a := range correctAnswers(q) {
+			keys = append(keys, htmlText(a.Content, a.ImgURL))
+		}
+		item.GraderInfo = &moodleText{Format: "html", Text: strings.Join(keys, "<br/>")}
+	case "mm":
+		item.Type = "matching"
+		item.ShuffleAnswers = "true"
+		for _, a := range q.Answers {
+			item.Subquestions = append(item.Subquestions, moodleSubquestion{
+				Format: "html",
+				Text:   htmlText(a.Code, a.ImgURL),
+				Answer: moodleText{Text: a.Content},
+			})
+		}
+	default:
+		// mc4, mcx and t/f are all choice questions in Moodle.
+		item.Type = "multichoice"
+		item.ShuffleAnswers = "true"
+		item.AnswerNumbering = "abc"
+
+		correct := len(correctAnswers(q))
+		wrong := len(q.Answers) - correct
+		item.Single = strconv.FormatBool(q.Format != "mcx")
+		for _, a := range q.Answers {
+			item.Answers = append(item.Answers, moodleAnswer{
+				Fraction: choiceFraction(a.IsAnswer, q.Format == "mcx", correct, wrong),
+				Format:   "html",
+				Text:     htmlText(a.Content, a.ImgURL),
+			})
+		}
+	}
+
+	return item
+}
+
+// choiceFraction is the Moodle grade percentage of one choice. Multiple
+// answer questions split the credit over the correct choices and take it
+// back evenly over the wrong ones.
+func choiceFraction(isAnswer, multiple bool, correct, wrong int) string {
+	switch {
+	case isAnswer && multiple && correct > 0:
+		return percent(100 / float64(correct))
+	case isAnswer:
+		return "100"
+	case multiple && wrong > 0:
+		return percent(-100 / float64(wrong))
+	}
+	return "0"
+}
+
+// percent formats v with the five decimals Moodle uses for fractions.
+func percent(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 5, 64)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+func escapeAll(parts []string) []string {
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = htmlText(p, nil)
+	}
+	return escaped
+}
@@ -0,0 +1,264 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+const (
+	qtiNamespace = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	cpNamespace  = "http://www.imsglobal.org/xsd/imscp_v1p1"
+
+	qtiMatchCorrect = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	qtiMapResponse  = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"
+)
+
+// QTI writes the questions as an IMS QTI 2.1 content package: a zip with one
+// assessmentItem file per question and an imsmanifest.xml listing them.
+func QTI(questions []questionEntity.ExportQuestion) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	manifest := qtiManifest{
+		Xmlns:      cpNamespace,
+		Identifier: "MANIFEST-misterblast",
+		Metadata:   qtiMetadata{Schema: "QTIv2.1 Package", SchemaVersion: "1.0.0"},
+	}
+
+	for _, q := range questions {
+		item := qtiItem(q)
+		href := "items/" + item.Identifier + ".xml"
+
+		body, err := xml.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeZipFile(zw, href, body); err != nil {
+			return nil, err
+		}
+		manifest.Resources = append(manifest.Resources, qtiResource{
+			Identifier: item.Identifier,
+			Type:       "imsqti_item_xmlv2p1",
+			Href:       href,
+			File:       qtiFile{Href: href},
+		})
+	}
+
+	body, err := xml.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(zw, "imsmanifest.xml", body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, body []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+type qtiManifest struct {
+	XMLName    xml.Name      `xml:"manifest"`
+	Xmlns      string        `xml:"xmlns,attr"`
+	Identifier string        `xml:"identifier,attr"`
+	Metadata   qtiMetadata   `xml:"metadata"`
+	Orgs       struct{}      `xml:"organizations"`
+	Resources  []qtiResource `xml:"resources>resource"`
+}
+
+type qtiMetadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+type qtiResource struct {
+	Identifier string  `xml:"identifier,attr"`
+	Type       string  `xml:"type,attr"`
+	Href       string  `xml:"href,attr"`
+	File       qtiFile `xml:"file"`
+}
+
+type qtiFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiAssessmentItem struct {
+	XMLName            xml.Name               `xml:"assessmentItem"`
+	Xmlns              string                 `xml:"xmlns,attr"`
+	Identifier         string                 `xml:"identifier,attr"`
+	Title              string                 `xml:"title,attr"`
+	Lang               string                 `xml:"xml:lang,attr,omitempty"`
+	Adaptive           bool                   `xml:"adaptive,attr"`
+	TimeDependent      bool                   `xml:"timeDependent,attr"`
+	Response           qtiResponseDecl        `xml:"responseDeclaration"`
+	Outcome            qtiOutcomeDecl         `xml:"outcomeDeclaration"`
+	Body               qtiItemBody            `xml:"itemBody"`
+	ResponseProcessing *qtiResponseProcessing `xml:"responseProcessing,omitempty"`
+}
+
+type qtiResponseDecl struct {
+	Identifier  string      `xml:"identifier,attr"`
+	Cardinality string      `xml:"cardinality,attr"`
+	BaseType    string      `xml:"baseType,attr"`
+	Correct     *qtiValues  `xml:"correctResponse,omitempty"`
+	Mapping     *qtiMapping `xml:"mapping,omitempty"`
+}
+
+type qtiValues struct {
+	Values []string `xml:"value"`
+}
+
+type qtiMapping struct {
+	DefaultValue string        `xml:"defaultValue,attr"`
+	Entries      []qtiMapEntry `xml:"mapEntry"`
+}
+
+type qtiMapEntry struct {
+	Key   string `xml:"mapKey,attr"`
+	Value string `xml:"mappedValue,attr"`
+}
+
+type qtiOutcomeDecl struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type qtiResponseProcessing struct {
+	Template string `xml:"template,attr"`
+}
+
+// qtiItemBody holds the question text, the interaction and the explanation.
+// Text is already escaped XHTML, so it is written as inner XML.
+type qtiItemBody struct {
+	Content     qtiInner   `xml:"div"`
+	Interaction string     `xml:",innerxml"`
+	Rubric      *qtiRubric `xml:"rubricBlock,omitempty"`
+}
+
+type qtiInner struct {
+	XML string `xml:",innerxml"`
+}
+
+type qtiRubric struct {
+	View string `xml:"view,attr"`
+	XML  string `xml:",innerxml"`
+}
+
+func qtiItem(q questionEntity.ExportQuestion) qtiAssessmentItem {
+	item := qtiAssessmentItem{
+		Xmlns:      qtiNamespace,
+		Identifier: fmt.Sprintf("Q%d", q.ID),
+		Title:      fmt.Sprintf("%s %d", q.SetName, q.Number),
+		Lang:       q.Lang,
+		Response:   qtiResponseDecl{Identifier: "RESPONSE"},
+		Outcome:    qtiOutcomeDecl{Identifier: "SCORE", Cardinality: "single", BaseType: "float"},
+		Body:       qtiItemBody{Content: qtiInner{XML: "<p>" + htmlText(q.Content, nil) + "</p>"}},
+	}
+	if parts := feedback(q); len(parts) > 0 {
+		item.Body.Rubric = &qtiRubric{View: "tutor", XML: "<p>" + strings.Join(escapeAll(parts), "</p><p>") + "</p>"}
+	}
+
+	var interaction strings.Builder
+	switch q.Format {
+	case "sa":
+		item.Response.Cardinality = "single"
+		item.Response.BaseType = "string"
+		mapping := &qtiMapping{DefaultValue: "0"}
+		correct := &qtiValues{}
+		for _, a := range correctAnswers(q) {
+			correct.Values = append(correct.Values, a.Content)
+			mapping.Entries = append(mapping.Entries, qtiMapEntry{Key: a.Content, Value: "1"})
+		}
+		item.Response.Correct, item.Response.Mapping = correct, mapping
+		item.ResponseProcessing = &qtiResponseProcessing{Template: qtiMapResponse}
+		interaction.WriteString(`<p><textEntryInteraction responseIdentifier="RESPONSE"/></p>`)
+
+	case "essay":
+		item.Response.Cardinality = "single"
+		item.Response.BaseType = "string"
+		interaction.WriteString(`<extendedTextInteraction responseIdentifier="RESPONSE"/>`)
+		var keys []string
+		for _, a := range correctAnswers(q) {
+			keys = append(keys, "<p>"+htmlText(a.Content, a.ImgURL)+"</p>")
+		}
+		if len(keys) > 0 {
+			scorer := &qtiRubric{View: "scorer", XML: strings.Join(keys, "")}
+			if item.Body.Rubric != nil {
+				scorer.XML = item.Body.Rubric.XML + scorer.XML
+			}
+			item.Body.Rubric = scorer
+		}
+
+	case "mm":
+		item.Response.Cardinality = "multiple"
+		item.Response.BaseType = "directedPair"
+		item.Response.Correct = &qtiValues{}
+		item.ResponseProcessing = &qtiResponseProcessing{Template: qtiMatchCorrect}
+
+		var left, right strings.Builder
+		for i, a := range q.Answers {
+			source, target := "L_"+qtiIdentifier(a.Code), fmt.Sprintf("R_%d", i+1)
+			item.Response.Correct.Values = append(item.Response.Correct.Values, source+" "+target)
+			fmt.Fprintf(&left, `<simpleAssociableChoice identifier="%s" matchMax="1">%s</simpleAssociableChoice>`, source, htmlText(a.Code, a.ImgURL))
+			fmt.Fprintf(&right, `<simpleAssociableChoice identifier="%s" matchMax="1">%s</simpleAssociableChoice>`, target, htmlText(a.Content, nil))
+		}
+		fmt.Fprintf(&interaction, `<matchInteraction responseIdentifier="RESPONSE" shuffle="true" maxAssociations="%d"><simpleMatchSet>%s</simpleMatchSet><simpleMatchSet>%s</simpleMatchSet></matchInteraction>`,
+			len(q.Answers), left.String(), right.String())
+
+	default:
+		// mc4, mcx and t/f are choice interactions; only mcx allows several.
+		maxChoices := 1
+		item.Response.Cardinality = "single"
+		if q.Format == "mcx" {
+			maxChoices = 0
+			item.Response.Cardinality = "multiple"
+		}
+		item.Response.BaseType = "identifier"
+		item.Response.Correct = &qtiValues{}
+		item.ResponseProcessing = &qtiResponseProcessing{Template: qtiMatchCorrect}
+
+		fmt.Fprintf(&interaction, `<choiceInteraction responseIdentifier="RESPONSE" shuffle="true" maxChoices="%d">`, maxChoices)
+		for _, a := range q.Answers {
+			id := "A_" + qtiIdentifier(a.Code)
+			if a.IsAnswer {
+				item.Response.Correct.Values = append(item.Response.Correct.Values, id)
+			}
+			fmt.Fprintf(&interaction, `<simpleChoice identifier="%s">%s</simpleChoice>`, id, htmlText(a.Content, a.ImgURL))
+		}
+		interaction.WriteString(`</choiceInteraction>`)
+	}
+
+	item.Body.Interaction = interaction.String()
+	if item.Response.Correct != nil && len(item.Response.Correct.Values) == 0 {
+		item.Response.Correct = nil
+	}
+	return item
+}
+
+// qtiIdentifier keeps only the characters allowed in a QTI identifier.
+func qtiIdentifier(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, code)
+}
@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/question/export"
+	"github.com/ghulammuzz/misterblast/internal/question/importer"
 	"github.com/ghulammuzz/misterblast/internal/question/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	m "github.com/ghulammuzz/misterblast/pkg/middleware"
@@ -24,25 +30,30 @@ func NewQuestionHandler(questionService svc.QuestionService, val *validator.Vali
 
 func (h *QuestionHandler) Router(r fiber.Router) {
 	// question
-	r.Post("/question", m.R100(), h.AddQuestionHandler)
-	r.Put("/question/:id", m.R100(), h.EditQuestionHandler)
+	r.Post("/question", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.AddQuestionHandler)
+	r.Put("/question/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.EditQuestionHandler)
 	r.Get("/question/:id", m.R100(), h.DetailQuestionsHandler)
 	r.Get("/question", m.R100(), h.ListQuestionsHandler)
-	r.Delete("/question/:id", m.R100(), h.DeleteQuestionHandler)
+	r.Delete("/question/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.DeleteQuestionHandler)
 
 	// answer
-	r.Delete("/answer/:id", m.R100(), h.DeleteAnswerHandler)
-	r.Put("/answer/:id", m.R100(), h.EditAnswerHandler)
-	r.Post("/quiz-answer", m.R100(), h.AddQuizAnswerHandler)
-	r.Post("/question-answer", m.R100(), h.AddQuizAnswerHandler)
-	r.Post("/quiz-answer-bulk/:id", m.R100(), h.AddQuizAnswerBulkHandler)
-	r.Post("/question-answer-bulk/:id", m.R100(), h.AddQuizAnswerBulkHandler)
+	r.Delete("/answer/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.DeleteAnswerHandler)
+	r.Put("/answer/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.EditAnswerHandler)
+	r.Post("/quiz-answer", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.AddQuizAnswerHandler)
+	r.Post("/question-answer", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.AddQuizAnswerHandler)
+	r.Post("/quiz-answer-bulk/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.AddQuizAnswerBulkHandler)
+	r.Post("/question-answer-bulk/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.AddQuizAnswerBulkHandler)
+
+	// import / export
+	r.Get("/question-export", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.ExportQuestionsHandler)
+	r.Post("/question-import/:set_id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.ImportQuestionsHandler)
 
 	// quiz
-	r.Get("/quiz", m.R100(), h.ListQuizHandler)
+	r.Get("/quiz", m.R100(), m.OptionalJWT(), h.ListQuizHandler)
 
 	// admin
-	r.Get("/admin-question", m.R100(), h.ListQuestionAdminHandler)
+	r.Get("/admin-question", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.ListQuestionAdminHandler)
+	r.Post("/admin-question/stats", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageQuestions), h.SnapshotItemStatsHandler)
 
 	// question type
 	r.Get("/question-type", m.R100(), h.ListQuestionTypes)
@@ -221,6 +232,13 @@ func (h *QuestionHandler) ListQuizHandler(c *fiber.Ctx) error {
 	if c.Query("lesson_id") != "" {
 		filter["lesson_id"] = c.Query("lesson_id")
 	}
+	// session_id returns the questions in the shuffled order of that attempt
+	if c.Query("session_id") != "" {
+		filter["session_id"] = c.Query("session_id")
+	}
+	if userID, ok := m.StudentID(c); ok {
+		filter["enrolled_user"] = strconv.Itoa(userID)
+	}
 	lang := c.Get("Lang")
 	if lang == "" {
 		lang = "id"
@@ -296,6 +314,21 @@ func (h *QuestionHandler) ListQuestionAdminHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "questions admin retrieved successfully", questions)
 }
 
+// SnapshotItemStatsHandler recomputes the item analysis right away instead of
+// waiting for the periodic snapshot.
+func (h *QuestionHandler) SnapshotItemStatsHandler(c *fiber.Ctx) error {
+	count, err := h.questionService.SnapshotItemStats(c.Context())
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "item statistics computed successfully", fiber.Map{"questions": count})
+}
+
 func (h *QuestionHandler) EditQuestionHandler(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil || id <= 0 {
@@ -361,3 +394,104 @@ func (h *QuestionHandler) ListQuestionTypes(c *fiber.Ctx) error {
 
 	return response.SendSuccess(c, "question types retrieved successfully", questionTypes)
 }
+
+// ImportQuestionsHandler imports a CSV or JSON question bank into a set. The
+// file is sent as multipart field "file" or as the raw request body.
+func (h *QuestionHandler) ImportQuestionsHandler(c *fiber.Ctx) error {
+	setID, err := c.ParamsInt("set_id")
+	if err != nil || setID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid set ID", nil)
+	}
+
+	lang := c.Query("lang")
+	if lang == "" {
+		return response.SendError(c, fiber.StatusBadRequest, "language (lang) is required", nil)
+	}
+	dryRun := c.QueryBool("dry_run", false)
+
+	var body io.Reader
+	var filename string
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return response.SendError(c, fiber.StatusBadRequest, "failed to open file", nil)
+		}
+		defer file.Close()
+		body, filename = file, fileHeader.Filename
+	} else {
+		body = bytes.NewReader(c.Body())
+		if strings.Contains(c.Get(fiber.HeaderContentType), "csv") {
+			filename = "import.csv"
+		} else if strings.Contains(c.Get(fiber.HeaderContentType), "json") {
+			filename = "import.json"
+		}
+	}
+
+	format, err := importer.DetectFormat(c.Query("format"), filename)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	questions, rowErrs, err := importer.Read(h.val, format, body, int32(setID))
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if len(rowErrs) > 0 {
+		result := entity.ImportResult{SetID: int32(setID), Lang: lang, DryRun: dryRun, Errors: rowErrs}
+		return response.SendError(c, fiber.StatusUnprocessableEntity, "import validation failed", result)
+	}
+
+	result, err := h.questionService.ImportQuestions(int32(setID), lang, questions, dryRun)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	if len(result.Errors) > 0 {
+		return response.SendError(c, fiber.StatusConflict, "import conflicts with existing questions", result)
+	}
+
+	if dryRun {
+		return response.SendSuccess(c, "import checked successfully", result)
+	}
+	return response.SendSuccess(c, "questions imported successfully", result)
+}
+
+// ExportQuestionsHandler downloads the questions of a set, lesson or class as
+// a QTI 2.1 package, Moodle XML or JSON.
+func (h *QuestionHandler) ExportQuestionsHandler(c *fiber.Ctx) error {
+	filter := map[string]string{}
+	name := "questions"
+	for _, key := range []string{"class_id", "lesson_id", "set_id"} {
+		if val := c.Query(key); val != "" {
+			if id, err := strconv.Atoi(val); err != nil || id <= 0 {
+				return response.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("invalid %s", key), nil)
+			}
+			filter[key] = val
+			name = strings.TrimSuffix(key, "_id") + "-" + val
+		}
+	}
+	if lang := c.Query("lang"); lang != "" {
+		filter["lang"] = lang
+	}
+
+	bank, err := h.questionService.ExportQuestions(c.Context(), filter)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	file, err := export.Encode(c.Query("format", export.FormatJSON), name, bank)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	c.Attachment(file.Name)
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	return c.Send(file.Body)
+}
@@ -82,6 +82,36 @@ func (m *MockQuestionService) ListQuestionTypes(ctx context.Context) ([]question
 	return args.Get(0).([]questionEntity.QuestionType), args.Error(1)
 }
 
+func (m *MockQuestionService) ImportQuestions(setID int32, lang string, questions []questionEntity.ImportQuestion, dryRun bool) (questionEntity.ImportResult, error) {
+	args := m.Called(setID, lang, questions, dryRun)
+	return args.Get(0).(questionEntity.ImportResult), args.Error(1)
+}
+
+func (m *MockQuestionService) ExportQuestions(ctx context.Context, filter map[string]string) (questionEntity.ExportBank, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(questionEntity.ExportBank), args.Error(1)
+}
+
+func (m *MockQuestionService) SnapshotItemStats(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestSnapshotItemStatsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	handler := handler.NewQuestionHandler(mockService, validator.New())
+	app.Post("/admin-question/stats", handler.SnapshotItemStatsHandler)
+
+	mockService.On("SnapshotItemStats", mock.Anything).Return(4, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin-question/stats", nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
 func TestAddQuestionHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
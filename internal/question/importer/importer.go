@@ -0,0 +1,254 @@
+// Package importer reads question banks from CSV or JSON files and checks
+// every row against the same rules as the single question endpoints.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// csvColumns are the columns a CSV import must have. Every line is one
+// answer; lines sharing a number belong to the same question, whose fields
+// are read from its first line.
+var csvColumns = []string{
+	"number", "type", "format", "content", "explanation", "reason",
+	"answer_code", "answer_content", "answer_img_url", "is_answer",
+}
+
+// DetectFormat returns the import format named by format, or guessed from the
+// file name extension when format is empty.
+func DetectFormat(format, filename string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+	switch format {
+	case FormatCSV, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported import format %q, use csv or json", format)
+}
+
+// Parse reads questions in the given format. Malformed rows are reported as
+// row errors; the returned error is only set when the file cannot be read.
+func Parse(format string, r io.Reader) ([]questionEntity.ImportQuestion, []questionEntity.ImportRowError, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatJSON:
+		return parseJSON(r)
+	}
+	return nil, nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+// parseJSON reads a bare array of questions, or the document written by the
+// JSON export, whose questions carry extra fields that are ignored.
+func parseJSON(r io.Reader) ([]questionEntity.ImportQuestion, []questionEntity.ImportRowError, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid json: %w", err)
+	}
+	var questions []questionEntity.ImportQuestion
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var bank struct {
+			Questions []questionEntity.ImportQuestion `json:"questions"`
+		}
+		if err := json.Unmarshal(raw, &bank); err != nil {
+			return nil, nil, fmt.Errorf("invalid json: %w", err)
+		}
+		questions = bank.Questions
+	} else if err := json.Unmarshal(raw, &questions); err != nil {
+		return nil, nil, fmt.Errorf("invalid json: %w", err)
+	}
+	for i := range questions {
+		questions[i].Row = i + 1
+		for j := range questions[i].Answers {
+			questions[i].Answers[j].Row = i + 1
+		}
+	}
+	return questions, nil, nil
+}
+
+func parseCSV(r io.Reader) ([]questionEntity.ImportQuestion, []questionEntity.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("csv file is empty")
+		}
+		return nil, nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := index[name]; !ok {
+			return nil, nil, fmt.Errorf("csv is missing column %q", name)
+		}
+	}
+
+	var questions []questionEntity.ImportQuestion
+	var rowErrs []questionEntity.ImportRowError
+	byNumber := map[int]int{}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, questionEntity.ImportRowError{Row: line, Message: err.Error()})
+			continue
+		}
+		field := func(name string) string {
+			if i := index[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		number, err := strconv.Atoi(field("number"))
+		if err != nil {
+			rowErrs = append(rowErrs, questionEntity.ImportRowError{Row: line, Field: "number", Message: "must be a number"})
+			continue
+		}
+
+		pos, ok := byNumber[number]
+		if !ok {
+			pos = len(questions)
+			byNumber[number] = pos
+			questions = append(questions, questionEntity.ImportQuestion{
+				Row:         line,
+				Number:      number,
+				Type:        field("type"),
+				Format:      field("format"),
+				Content:     field("content"),
+				Explanation: field("explanation"),
+				Reason:      field("reason"),
+			})
+		}
+
+		if field("answer_code") == "" && field("answer_content") == "" {
+			continue
+		}
+		answer := questionEntity.ImportAnswer{
+			Row:     line,
+			Code:    field("answer_code"),
+			Content: field("answer_content"),
+		}
+		if img := field("answer_img_url"); img != "" {
+			answer.ImgURL = &img
+		}
+		if v := field("is_answer"); v != "" {
+			isAnswer, err := strconv.ParseBool(v)
+			if err != nil {
+				rowErrs = append(rowErrs, questionEntity.ImportRowError{Row: line, Field: "is_answer", Message: "must be true or false"})
+				continue
+			}
+			answer.IsAnswer = isAnswer
+		}
+		questions[pos].Answers = append(questions[pos].Answers, answer)
+	}
+
+	return questions, rowErrs, nil
+}
+
+// Read parses the file and validates every question for the set. Questions
+// are only returned when there are no row errors.
+func Read(val *validator.Validate, format string, r io.Reader, setID int32) ([]questionEntity.ImportQuestion, []questionEntity.ImportRowError, error) {
+	questions, rowErrs, err := Parse(format, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	rowErrs = append(rowErrs, Validate(val, setID, questions)...)
+	if len(rowErrs) > 0 {
+		return nil, rowErrs, nil
+	}
+	return questions, nil, nil
+}
+
+// Validate checks every question against the SetQuestion rules and every
+// answer against the SetAnswer rules, and rejects numbers repeated in the
+// file.
+func Validate(val *validator.Validate, setID int32, questions []questionEntity.ImportQuestion) []questionEntity.ImportRowError {
+	var rowErrs []questionEntity.ImportRowError
+	seen := map[int]int{}
+
+	for _, q := range questions {
+		if first, ok := seen[q.Number]; ok {
+			rowErrs = append(rowErrs, questionEntity.ImportRowError{
+				Row:     q.Row,
+				Field:   "number",
+				Message: fmt.Sprintf("question number %d already used on row %d", q.Number, first),
+			})
+		} else {
+			seen[q.Number] = q.Row
+		}
+
+		question := questionEntity.SetQuestion{
+			Number:      q.Number,
+			Type:        q.Type,
+			Format:      q.Format,
+			Content:     q.Content,
+			Explanation: q.Explanation,
+			Reason:      q.Reason,
+			SetID:       setID,
+		}
+		rowErrs = append(rowErrs, fieldErrors(q.Row, val.Struct(question))...)
+
+		if len(q.Answers) == 0 {
+			rowErrs = append(rowErrs, questionEntity.ImportRowError{Row: q.Row, Field: "answers", Message: "question has no answers"})
+		}
+		for _, a := range q.Answers {
+			answer := questionEntity.SetAnswer{
+				Code:     a.Code,
+				Content:  a.Content,
+				ImgURL:   a.ImgURL,
+				IsAnswer: a.IsAnswer,
+			}
+			// The question does not exist yet, so its ID is not checked.
+			rowErrs = append(rowErrs, fieldErrors(a.Row, val.StructExcept(answer, "QuestionID"))...)
+		}
+	}
+
+	return rowErrs
+}
+
+func fieldErrors(row int, err error) []questionEntity.ImportRowError {
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []questionEntity.ImportRowError{{Row: row, Message: err.Error()}}
+	}
+	rowErrs := make([]questionEntity.ImportRowError, 0, len(verrs))
+	for _, fe := range verrs {
+		msg := "failed on " + fe.Tag()
+		if fe.Param() != "" {
+			msg += "=" + fe.Param()
+		}
+		rowErrs = append(rowErrs, questionEntity.ImportRowError{Row: row, Field: strings.ToLower(fe.Field()), Message: msg})
+	}
+	return rowErrs
+}
@@ -0,0 +1,76 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/question/importer"
+)
+
+const sampleCSV = `number,type,format,content,explanation,reason,answer_code,answer_content,answer_img_url,is_answer
+1,c1_faktual,mc4,Ibu kota Indonesia?,Jakarta adalah ibu kota,Fakta,a,Jakarta,,true
+1,,,,,,b,Bandung,,false
+2,c2_konseptual,essay,Jelaskan fotosintesis,Proses tumbuhan,Konsep,essay,Cahaya menjadi energi,,true
+`
+
+func TestParseCSV(t *testing.T) {
+	questions, rowErrs, err := importer.Parse(importer.FormatCSV, strings.NewReader(sampleCSV))
+	assert.NoError(t, err)
+	assert.Empty(t, rowErrs)
+	assert.Len(t, questions, 2)
+
+	assert.Equal(t, 2, questions[0].Row)
+	assert.Equal(t, "mc4", questions[0].Format)
+	assert.Len(t, questions[0].Answers, 2)
+	assert.Equal(t, 3, questions[0].Answers[1].Row)
+	assert.True(t, questions[0].Answers[0].IsAnswer)
+	assert.Equal(t, "essay", questions[1].Answers[0].Code)
+}
+
+func TestParseCSV_MissingColumn(t *testing.T) {
+	_, _, err := importer.Parse(importer.FormatCSV, strings.NewReader("number,type\n1,c1_faktual\n"))
+	assert.Error(t, err)
+}
+
+func TestParseJSON(t *testing.T) {
+	body := `[{"number":1,"type":"c1_faktual","format":"sa","content":"2+2?","explanation":"x","reason":"y","answers":[{"code":"a","content":"4","is_answer":true}]}]`
+
+	questions, rowErrs, err := importer.Parse(importer.FormatJSON, strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Empty(t, rowErrs)
+	assert.Len(t, questions, 1)
+	assert.Equal(t, 1, questions[0].Answers[0].Row)
+}
+
+func TestRead_RowErrors(t *testing.T) {
+	body := `number,type,format,content,explanation,reason,answer_code,answer_content,answer_img_url,is_answer
+1,c1_faktual,mc4,Soal,Penjelasan,Alasan,z,Jawaban,,true
+1,c1_faktual,mc4,Soal,Penjelasan,Alasan,a,Jawaban,,maybe
+x,c1_faktual,mc4,Soal,Penjelasan,Alasan,a,Jawaban,,true
+2,c9_faktual,mc4,Soal,Penjelasan,Alasan,a,Jawaban,,true
+`
+	questions, rowErrs, err := importer.Read(validator.New(), importer.FormatCSV, strings.NewReader(body), 1)
+	assert.NoError(t, err)
+	assert.Nil(t, questions)
+
+	byRow := map[int][]string{}
+	for _, e := range rowErrs {
+		byRow[e.Row] = append(byRow[e.Row], e.Field)
+	}
+	assert.Equal(t, []string{"is_answer"}, byRow[3])
+	assert.Equal(t, []string{"number"}, byRow[4])
+	assert.Equal(t, []string{"code"}, byRow[2])
+	assert.Equal(t, []string{"type"}, byRow[5])
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := importer.DetectFormat("", "bank.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, importer.FormatCSV, format)
+
+	_, err = importer.DetectFormat("xlsx", "bank.xlsx")
+	assert.Error(t, err)
+}
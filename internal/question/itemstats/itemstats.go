@@ -0,0 +1,128 @@
+// Package itemstats computes classical item analysis for quiz questions:
+// difficulty (p-value), discrimination index and how often each option was
+// chosen.
+package itemstats
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/grader"
+)
+
+const (
+	// GroupShare is the share of submissions in the upper and lower groups
+	// used for the discrimination index.
+	GroupShare = 0.27
+	// MinDiscriminationResponses is the number of responses a question needs
+	// before its discrimination index is reported.
+	MinDiscriminationResponses = 5
+)
+
+// Response is one graded answer to a question in a quiz submission.
+type Response struct {
+	QuestionID   int
+	SubmissionID int
+	Format       string
+	Answer       string
+	Score        float64
+}
+
+// Stat is the item analysis of one question. Options is only set for choice
+// formats and counts how many responses picked each option code.
+type Stat struct {
+	QuestionID     int
+	Responses      int
+	Difficulty     float64
+	Discrimination *float64
+	Options        map[string]int
+}
+
+// Compute analyses every question that has responses, ordered by question
+// ID. Submissions are ranked by their average score over all of their
+// responses, and the discrimination index is the difference in mean item
+// score between the top and bottom GroupShare of the submissions that
+// answered the question.
+func Compute(responses []Response) []Stat {
+	type total struct {
+		score float64
+		count int
+	}
+	totals := map[int]*total{}
+	byQuestion := map[int][]Response{}
+	for _, r := range responses {
+		t, ok := totals[r.SubmissionID]
+		if !ok {
+			t = &total{}
+			totals[r.SubmissionID] = t
+		}
+		t.score += r.Score
+		t.count++
+		byQuestion[r.QuestionID] = append(byQuestion[r.QuestionID], r)
+	}
+	rank := func(submissionID int) float64 {
+		t := totals[submissionID]
+		return t.score / float64(t.count)
+	}
+
+	ids := make([]int, 0, len(byQuestion))
+	for id := range byQuestion {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	stats := make([]Stat, 0, len(ids))
+	for _, id := range ids {
+		items := byQuestion[id]
+		stat := Stat{QuestionID: id, Responses: len(items)}
+
+		var sum float64
+		for _, r := range items {
+			sum += r.Score
+		}
+		stat.Difficulty = round(sum / float64(len(items)))
+
+		if len(items) >= MinDiscriminationResponses {
+			sorted := append([]Response(nil), items...)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return rank(sorted[i].SubmissionID) > rank(sorted[j].SubmissionID)
+			})
+			n := int(math.Ceil(float64(len(sorted)) * GroupShare))
+			d := round(mean(sorted[:n]) - mean(sorted[len(sorted)-n:]))
+			stat.Discrimination = &d
+		}
+
+		if choiceFormat(items[0].Format) {
+			stat.Options = map[string]int{}
+			for _, r := range items {
+				for _, code := range grader.SplitAnswer(r.Answer) {
+					stat.Options[strings.ToLower(code)]++
+				}
+			}
+		}
+
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+func choiceFormat(format string) bool {
+	switch format {
+	case "mc4", "mcx", "t/f":
+		return true
+	}
+	return false
+}
+
+func mean(responses []Response) float64 {
+	var sum float64
+	for _, r := range responses {
+		sum += r.Score
+	}
+	return sum / float64(len(responses))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,46 @@
+package itemstats_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/question/itemstats"
+)
+
+func TestCompute(t *testing.T) {
+	var responses []itemstats.Response
+	// Five submissions; question 1 is answered correctly by the two
+	// strongest students only, question 2 by everyone.
+	answers := []string{"a", "a", "b", "c", "b"}
+	for i, answer := range answers {
+		score := 0.0
+		if answer == "a" {
+			score = 1
+		}
+		responses = append(responses,
+			itemstats.Response{QuestionID: 1, SubmissionID: i + 1, Format: "mc4", Answer: answer, Score: score},
+			itemstats.Response{QuestionID: 2, SubmissionID: i + 1, Format: "sa", Answer: "4", Score: 1},
+		)
+	}
+	responses = append(responses, itemstats.Response{QuestionID: 3, SubmissionID: 1, Format: "mcx", Answer: "a, c", Score: 1})
+
+	stats := itemstats.Compute(responses)
+	assert.Len(t, stats, 3)
+
+	q1 := stats[0]
+	assert.Equal(t, 5, q1.Responses)
+	assert.Equal(t, 0.4, q1.Difficulty)
+	assert.NotNil(t, q1.Discrimination)
+	assert.Equal(t, 1.0, *q1.Discrimination)
+	assert.Equal(t, map[string]int{"a": 2, "b": 2, "c": 1}, q1.Options)
+
+	q2 := stats[1]
+	assert.Equal(t, 1.0, q2.Difficulty)
+	assert.Equal(t, 0.0, *q2.Discrimination)
+	assert.Nil(t, q2.Options)
+
+	q3 := stats[2]
+	assert.Nil(t, q3.Discrimination, "too few responses")
+	assert.Equal(t, map[string]int{"a": 1, "c": 1}, q3.Options)
+}
@@ -9,6 +9,7 @@ import (
 
 	cache "github.com/ghulammuzz/misterblast/config/redis"
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/question/itemstats"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
@@ -23,6 +24,8 @@ type QuestionRepository interface {
 	Detail(ctx context.Context, id int32) (questionEntity.DetailQuestionExample, error)
 	Exists(setID int32, number int) (bool, error)
 	Edit(id int32, question questionEntity.EditQuestion) error
+	Export(ctx context.Context, filter map[string]string) ([]questionEntity.ExportQuestion, error)
+	Import(setID int32, lang string, questions []questionEntity.ImportQuestion, dryRun bool) ([]questionEntity.ImportRowError, error)
 
 	// Answer
 	AddQuizAnswer(answer questionEntity.SetAnswer) error
@@ -35,6 +38,10 @@ type QuestionRepository interface {
 	// Admin
 	ListAdmin(ctx context.Context, filter map[string]string, page, limit int) (*response.PaginateResponse, error)
 
+	// Item statistics
+	ItemResponses(ctx context.Context) ([]itemstats.Response, error)
+	SaveItemStats(ctx context.Context, stats []itemstats.Stat, computedAt int64) error
+
 	// Q Type
 	ListQuestionTypes(ctx context.Context) ([]questionEntity.QuestionType, error)
 }
@@ -98,10 +105,12 @@ func (r *questionRepository) Detail(ctx context.Context, id int32) (questionEnti
 				'code', a.code,
 				'content', a.content,
 				'img_url', a.img_url,
+				'img_variants', ai.variants,
 				'is_answer', a.is_answer
 			)) FILTER (WHERE a.id IS NOT NULL), '[]') AS answers
 		FROM questions q
 		LEFT JOIN answers a ON q.id = a.question_id
+		LEFT JOIN images ai ON ai.url = a.img_url
 		WHERE q.id = $1
 		GROUP BY q.id
 	`
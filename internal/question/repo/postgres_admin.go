@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -101,8 +102,16 @@ func (r *questionRepository) ListAdmin(ctx context.Context, filter map[string]st
 
 	query := `
 		SELECT q.id, q.number, q.type, q.format, q.content, q.explanation, q.reasoning, q.is_quiz, q.set_id,
-		       s.name AS set_name, l.name AS lesson_name, c.name AS class_name
-	` + baseQuery + whereClause + " ORDER BY q.number"
+		       s.name AS set_name, l.name AS lesson_name, c.name AS class_name,
+		       st.responses, st.difficulty, st.discrimination, st.option_counts, st.computed_at
+	` + strings.Replace(baseQuery, "WHERE 1=1", `LEFT JOIN LATERAL (
+			SELECT responses, difficulty, discrimination, option_counts, computed_at
+			FROM question_stat_snapshots
+			WHERE question_id = q.id
+			ORDER BY computed_at DESC
+			LIMIT 1
+		) st ON TRUE
+		WHERE 1=1`, 1) + whereClause + " ORDER BY q.number"
 
 	// Pagination
 	if limit > 0 {
@@ -126,11 +135,30 @@ func (r *questionRepository) ListAdmin(ctx context.Context, filter map[string]st
 
 	for rows.Next() {
 		var q questionEntity.ListQuestionAdmin
-		err := rows.Scan(&q.ID, &q.Number, &q.Type, &q.Format, &q.Content, &q.Explanation, &q.Reason, &q.IsQuiz, &q.SetID, &q.SetName, &q.LessonName, &q.ClassName)
+		var responses, computedAt sql.NullInt64
+		var difficulty, discrimination sql.NullFloat64
+		var options []byte
+		err := rows.Scan(&q.ID, &q.Number, &q.Type, &q.Format, &q.Content, &q.Explanation, &q.Reason, &q.IsQuiz, &q.SetID, &q.SetName, &q.LessonName, &q.ClassName,
+			&responses, &difficulty, &discrimination, &options, &computedAt)
 		if err != nil {
 			log.Error("[Repo][ListAdmin] Error Scan:", err)
 			return nil, app.NewAppError(500, "failed to scan admin questions")
 		}
+		if computedAt.Valid {
+			q.Stats = &questionEntity.ItemStats{
+				Responses:  int(responses.Int64),
+				Difficulty: difficulty.Float64,
+				ComputedAt: computedAt.Int64,
+			}
+			if discrimination.Valid {
+				q.Stats.Discrimination = &discrimination.Float64
+			}
+			if len(options) > 0 {
+				if err := json.Unmarshal(options, &q.Stats.Options); err != nil {
+					log.Warn("[Repo][ListAdmin] Failed to unmarshal option counts:", err)
+				}
+			}
+		}
 		questions = append(questions, q)
 	}
 
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+// Export returns every live question of a set, lesson or class with its
+// answers, using the same joins as ListAdmin. The filter keys are set_id,
+// lesson_id, class_id and lang.
+func (r *questionRepository) Export(ctx context.Context, filter map[string]string) ([]questionEntity.ExportQuestion, error) {
+	query := `
+		SELECT q.id, q.number, q.type, q.format, q.content, q.explanation, q.reasoning, q.lang, q.is_quiz, q.set_id,
+		       s.name AS set_name, l.name AS lesson_name, c.name AS class_name,
+		       COALESCE(json_agg(json_build_object(
+				'id', a.id,
+				'code', a.code,
+				'content', a.content,
+				'img_url', a.img_url,
+				'is_answer', a.is_answer
+		       ) ORDER BY a.code) FILTER (WHERE a.id IS NOT NULL), '[]') AS answers
+		FROM questions q
+		JOIN sets s ON q.set_id = s.id
+		JOIN lessons l ON s.lesson_id = l.id
+		JOIN classes c ON s.class_id = c.id
+		LEFT JOIN answers a ON q.id = a.question_id
+		WHERE q.deleted_at IS NULL
+	`
+
+	args := []interface{}{}
+	for _, key := range []string{"set_id", "lesson_id", "class_id", "lang"} {
+		if val, exists := filter[key]; exists {
+			column := map[string]string{
+				"set_id":    "s.id",
+				"lesson_id": "l.id",
+				"class_id":  "c.id",
+				"lang":      "q.lang",
+			}[key]
+			args = append(args, val)
+			query += fmt.Sprintf(" AND %s = $%d", column, len(args))
+		}
+	}
+	query += " GROUP BY q.id, s.id, l.id, c.id ORDER BY c.id, l.id, s.id, q.number"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error("[Repo][Export] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch questions for export")
+	}
+	defer rows.Close()
+
+	var questions []questionEntity.ExportQuestion
+	for rows.Next() {
+		var q questionEntity.ExportQuestion
+		var answersJSON []byte
+		err := rows.Scan(&q.ID, &q.Number, &q.Type, &q.Format, &q.Content, &q.Explanation, &q.Reason, &q.Lang, &q.IsQuiz, &q.SetID,
+			&q.SetName, &q.LessonName, &q.ClassName, &answersJSON)
+		if err != nil {
+			log.Error("[Repo][Export] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan questions for export")
+		}
+		if err := json.Unmarshal(answersJSON, &q.Answers); err != nil {
+			log.Error("[Repo][Export] Failed to unmarshal answers: ", err)
+			return nil, app.NewAppError(500, "failed to parse answers")
+		}
+		questions = append(questions, q)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][Export] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read questions for export")
+	}
+
+	return questions, nil
+}
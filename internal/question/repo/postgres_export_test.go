@@ -0,0 +1,32 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db, nil)
+
+	answers := `[{"id":1,"code":"a","content":"Jakarta","img_url":null,"is_answer":true},{"id":2,"code":"b","content":"Bandung","img_url":null,"is_answer":false}]`
+	mock.ExpectQuery(`FROM questions q\s+JOIN sets s .* AND l.id = \$1 AND q.lang = \$2 GROUP BY`).
+		WithArgs("2", "id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "type", "format", "content", "explanation", "reasoning", "lang", "is_quiz", "set_id", "set_name", "lesson_name", "class_name", "answers"}).
+			AddRow(10, 1, "c1_faktual", "mc4", "Ibu kota?", "exp", "reason", "id", true, 3, "Set A", "IPS", "Kelas 4", []byte(answers)))
+
+	questions, err := repository.Export(context.Background(), map[string]string{"lesson_id": "2", "lang": "id"})
+	assert.NoError(t, err)
+	assert.Len(t, questions, 1)
+	assert.Equal(t, "IPS", questions[0].LessonName)
+	assert.Len(t, questions[0].Answers, 2)
+	assert.True(t, questions[0].Answers[0].IsAnswer)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
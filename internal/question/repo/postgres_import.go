@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/lib/pq"
+)
+
+// Import inserts the questions and their answers into the set in a single
+// transaction. Numbers already taken in the set are reported as row errors
+// and nothing is written. With dryRun the inserts run and are rolled back.
+func (r *questionRepository) Import(setID int32, lang string, questions []questionEntity.ImportQuestion, dryRun bool) ([]questionEntity.ImportRowError, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][Import] Error beginning transaction: ", err)
+		return nil, app.NewAppError(500, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var isQuiz bool
+	if err := tx.QueryRow(`SELECT is_quiz FROM sets WHERE id = $1 FOR UPDATE`, setID).Scan(&isQuiz); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, app.NewAppError(404, "set not found")
+		}
+		log.Error("[Repo][Import] Error QueryRow set: ", err)
+		return nil, app.NewAppError(500, "failed to get set")
+	}
+
+	numbers := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		numbers = append(numbers, int64(q.Number))
+	}
+	rows, err := tx.Query(`
+		SELECT number FROM questions
+		WHERE set_id = $1 AND deleted_at IS NULL AND number = ANY($2)
+	`, setID, pq.Array(numbers))
+	if err != nil {
+		log.Error("[Repo][Import] Error Query existing numbers: ", err)
+		return nil, app.NewAppError(500, "failed to check existing questions")
+	}
+	taken := map[int]bool{}
+	for rows.Next() {
+		var number int
+		if err := rows.Scan(&number); err != nil {
+			rows.Close()
+			log.Error("[Repo][Import] Error Scan existing numbers: ", err)
+			return nil, app.NewAppError(500, "failed to check existing questions")
+		}
+		taken[number] = true
+	}
+	rows.Close()
+
+	var rowErrs []questionEntity.ImportRowError
+	for _, q := range questions {
+		if taken[q.Number] {
+			rowErrs = append(rowErrs, questionEntity.ImportRowError{
+				Row:     q.Row,
+				Field:   "number",
+				Message: fmt.Sprintf("question number %d already exists in this set", q.Number),
+			})
+		}
+	}
+	if len(rowErrs) > 0 {
+		return rowErrs, nil
+	}
+
+	for _, q := range questions {
+		var questionID int32
+		err := tx.QueryRow(`
+			INSERT INTO questions (number, type, format, content, is_quiz, explanation, set_id, lang, reasoning)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id
+		`, q.Number, q.Type, q.Format, q.Content, isQuiz, q.Explanation, setID, lang, q.Reason).Scan(&questionID)
+		if err != nil {
+			log.Error("[Repo][Import] Error inserting question: ", err)
+			return nil, app.NewAppError(500, fmt.Sprintf("failed to insert question on row %d", q.Row))
+		}
+
+		for _, a := range q.Answers {
+			_, err := tx.Exec(`
+				INSERT INTO answers (question_id, code, content, img_url, is_answer)
+				VALUES ($1, $2, $3, $4, $5)
+			`, questionID, a.Code, a.Content, a.ImgURL, a.IsAnswer)
+			if err != nil {
+				log.Error("[Repo][Import] Error inserting answer: ", err)
+				return nil, app.NewAppError(500, fmt.Sprintf("failed to insert answer on row %d", a.Row))
+			}
+		}
+	}
+
+	if dryRun {
+		return nil, nil
+	}
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][Import] Error committing: ", err)
+		return nil, app.NewAppError(500, "failed to commit import")
+	}
+	return nil, nil
+}
@@ -0,0 +1,63 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func importQuestions() []questionEntity.ImportQuestion {
+	return []questionEntity.ImportQuestion{{
+		Row: 2, Number: 1, Type: "c1_faktual", Format: "mc4", Content: "Soal", Explanation: "exp", Reason: "reason",
+		Answers: []questionEntity.ImportAnswer{{Row: 2, Code: "a", Content: "Jawaban", IsAnswer: true}},
+	}}
+}
+
+func TestImport(t *testing.T) {
+	t.Run("Dry run rolls back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		repository := repo.NewQuestionRepository(db, nil)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT is_quiz FROM sets WHERE id = \$1 FOR UPDATE`).WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"is_quiz"}).AddRow(true))
+		mock.ExpectQuery(`SELECT number FROM questions`).WithArgs(3, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"number"}))
+		mock.ExpectQuery(`INSERT INTO questions`).
+			WithArgs(1, "c1_faktual", "mc4", "Soal", true, "exp", 3, "id", "reason").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(`INSERT INTO answers`).WithArgs(10, "a", "Jawaban", nil, true).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectRollback()
+
+		rowErrs, err := repository.Import(3, "id", importQuestions(), true)
+		assert.NoError(t, err)
+		assert.Empty(t, rowErrs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Existing number is a row error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		repository := repo.NewQuestionRepository(db, nil)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT is_quiz FROM sets`).WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"is_quiz"}).AddRow(false))
+		mock.ExpectQuery(`SELECT number FROM questions`).WithArgs(3, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"number"}).AddRow(1))
+		mock.ExpectRollback()
+
+		rowErrs, err := repository.Import(3, "id", importQuestions(), false)
+		assert.NoError(t, err)
+		assert.Len(t, rowErrs, 1)
+		assert.Equal(t, 2, rowErrs[0].Row)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
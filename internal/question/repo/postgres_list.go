@@ -47,6 +47,7 @@ func (r *questionRepository) List(ctx context.Context, filter map[string]string)
 		JOIN lessons l ON s.lesson_id = l.id
 		JOIN classes c ON s.class_id = c.id
 		LEFT JOIN answers a ON q.id = a.question_id
+		LEFT JOIN images ai ON ai.url = a.img_url
 		WHERE q.deleted_at IS NULL
 	`
 
@@ -99,7 +100,8 @@ func (r *questionRepository) List(ctx context.Context, filter map[string]string)
 						'id', a.id,
 						'code', a.code,
 						'content', a.content,
-						'img_url', a.img_url
+						'img_url', a.img_url,
+						'img_variants', ai.variants
 					)
 				) FILTER (WHERE a.id IS NOT NULL), '[]'
 			) AS answers
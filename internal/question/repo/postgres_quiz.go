@@ -2,16 +2,22 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 
 	cache "github.com/ghulammuzz/misterblast/config/redis"
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/shuffle"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -56,9 +62,10 @@ func (r *questionRepository) ListQuizQuestions(ctx context.Context, filter map[s
 	query := `
 		SELECT q.id, q.number, q.type, q.format, q.content, q.set_id,
 			   COALESCE(a.id, 0), COALESCE(a.code, ''), 
-			   COALESCE(a.content, ''), COALESCE(a.img_url, '')
+			   COALESCE(a.content, ''), COALESCE(a.img_url, ''), ai.variants
 		FROM questions q
 		LEFT JOIN answers a ON q.id = a.question_id
+		LEFT JOIN images ai ON ai.url = a.img_url
 		WHERE q.is_quiz = true
 	`
 	args := []interface{}{}
@@ -99,8 +106,9 @@ func (r *questionRepository) ListQuizQuestions(ctx context.Context, filter map[s
 		var setID int32
 		var aID int32
 		var code, aContent, imgURL string
+		var imgVariants imaging.Variants
 
-		err := rows.Scan(&qID, &number, &qType, &qFormat, &content, &setID, &aID, &code, &aContent, &imgURL)
+		err := rows.Scan(&qID, &number, &qType, &qFormat, &content, &setID, &aID, &code, &aContent, &imgURL, &imgVariants)
 		if err != nil {
 			log.Error("[Repo][ListQuizQuestions] Error Scan: ", err)
 			return nil, app.NewAppError(500, "failed to scan quiz questions")
@@ -121,9 +129,10 @@ func (r *questionRepository) ListQuizQuestions(ctx context.Context, filter map[s
 
 		if aID != 0 {
 			answer := questionEntity.ListAnswer{
-				ID:      aID,
-				Code:    code,
-				Content: aContent,
+				ID:          aID,
+				Code:        code,
+				Content:     aContent,
+				ImgVariants: imgVariants,
 			}
 			if imgURL != "" {
 				answer.ImgURL = &imgURL
@@ -179,10 +188,41 @@ func (r *questionRepository) EditAnswer(id int32, answer questionEntity.EditAnsw
 	return nil
 }
 
+// requireEnrolled hides a set outside the classrooms the user is enrolled in.
+func (r *questionRepository) requireEnrolled(ctx context.Context, setID, userID string) error {
+	var allowed bool
+	query := `SELECT EXISTS (SELECT 1 FROM sets s WHERE s.id = $1 AND ` + enrollment.ClassFilter("s.class_id", 2) + `)`
+	if err := r.db.QueryRowContext(ctx, query, setID, userID).Scan(&allowed); err != nil {
+		log.Error("[Repo][ListQuizQuestions] Failed to check enrollment: ", err)
+		return app.NewAppError(500, "failed to check enrollment")
+	}
+	if !allowed {
+		return app.NewAppError(404, "quiz set not found")
+	}
+	return nil
+}
+
 func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, filter map[string]string) ([]questionEntity.ListQuestionQuiz, int, error) {
 	var setID string
-
-	if val, ok := filter["set_id"]; ok && val != "" {
+	var seed int64
+	var order []int
+
+	if val, ok := filter["session_id"]; ok && val != "" {
+		var sessionOrder pq.Int64Array
+		query := `SELECT set_id, lang, seed, question_order FROM quiz_sessions WHERE id = $1`
+		var lang string
+		if err := r.db.QueryRowContext(ctx, query, val).Scan(&setID, &lang, &seed, &sessionOrder); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, 0, app.NewAppError(404, "quiz session not found")
+			}
+			log.Error("[Repo][ListQuizQuestions] Failed to get quiz session: ", err)
+			return nil, 0, app.NewAppError(500, "failed to get quiz session")
+		}
+		filter["lang"] = lang
+		for _, n := range sessionOrder {
+			order = append(order, int(n))
+		}
+	} else if val, ok := filter["set_id"]; ok && val != "" {
 		setID = val
 	} else {
 		lessonID, hasLesson := filter["lesson_id"]
@@ -192,17 +232,24 @@ func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, f
 		}
 
 		var classID string
+		classArgs := []interface{}{lessonID}
+		classFilter := ""
+		// Students enrolled in a classroom only get quizzes of its class.
+		if userID, ok := filter["enrolled_user"]; ok {
+			classFilter = " AND " + enrollment.ClassFilter("s.class_id", 2)
+			classArgs = append(classArgs, userID)
+		}
 		queryClass := `
 			SELECT class_id FROM (
-				SELECT class_id FROM sets 
-				WHERE is_quiz = true AND lesson_id = $1 
-				GROUP BY class_id
+				SELECT s.class_id FROM sets s
+				WHERE s.is_quiz = true AND s.lesson_id = $1` + classFilter + `
+				GROUP BY s.class_id
 				ORDER BY RANDOM()
 				LIMIT 1
 			) AS random_class
 		`
 
-		err := r.db.QueryRowContext(ctx, queryClass, lessonID).Scan(&classID)
+		err := r.db.QueryRowContext(ctx, queryClass, classArgs...).Scan(&classID)
 		if err != nil {
 			log.Error("[Repo][ListQuizQuestions] Failed to get random class_id: ", err)
 			return nil, 0, app.NewAppError(404, "no class found for specified lesson")
@@ -223,12 +270,25 @@ func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, f
 
 	log.Debug("[Repo][ListQuizQuestions] Using set_id: ", setID)
 
-	redisKey := fmt.Sprintf("quiz:list:%s:%s:%s", setID, filter["type"], filter["number"])
+	// A random set is already drawn from the student's classes.
+	if userID, ok := filter["enrolled_user"]; ok && (filter["session_id"] != "" || filter["set_id"] != "") {
+		if err := r.requireEnrolled(ctx, setID, userID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	setIDInt, err := strconv.Atoi(setID)
+	if err != nil {
+		log.Error("[Repo][ListQuizQuestions] Error converting setID to int: ", err)
+		return nil, 0, app.NewAppError(500, "failed to convert set_id to integer")
+	}
+
+	redisKey := fmt.Sprintf("quiz:list:%s:%s:%s:%s", setID, filter["type"], filter["number"], filter["lang"])
 	if r.redis != nil {
 		if cached, err := cache.Get(ctx, redisKey, r.redis); err == nil && cached != "" {
 			var cachedData []questionEntity.ListQuestionQuiz
 			if err := json.Unmarshal([]byte(cached), &cachedData); err == nil {
-				return cachedData, 0, nil
+				return orderQuiz(cachedData, seed, order), setIDInt, nil
 			}
 		}
 	}
@@ -236,9 +296,10 @@ func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, f
 	query := `
 		SELECT q.id, q.number, q.type, q.format, q.content, q.set_id,
 			   COALESCE(a.id, 0) AS answer_id, COALESCE(a.code, '') AS code, 
-			   COALESCE(a.content, '') AS answer_content, COALESCE(a.img_url, '') AS img_url
+			   COALESCE(a.content, '') AS answer_content, COALESCE(a.img_url, '') AS img_url, ai.variants
 		FROM questions q
 		LEFT JOIN answers a ON q.id = a.question_id
+		LEFT JOIN images ai ON ai.url = a.img_url
 		WHERE q.is_quiz = true AND q.set_id = $1
 	`
 	args := []interface{}{setID}
@@ -276,8 +337,9 @@ func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, f
 		var qID, aID, setIDInt int32
 		var number int
 		var qType, qFormat, content, code, aContent, imgURL string
+		var imgVariants imaging.Variants
 
-		err := rows.Scan(&qID, &number, &qType, &qFormat, &content, &setIDInt, &aID, &code, &aContent, &imgURL)
+		err := rows.Scan(&qID, &number, &qType, &qFormat, &content, &setIDInt, &aID, &code, &aContent, &imgURL, &imgVariants)
 		if err != nil {
 			log.Error("[Repo][ListQuizQuestions] Error Scan: ", err)
 			return nil, 0, app.NewAppError(500, "failed to scan quiz questions")
@@ -298,9 +360,10 @@ func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, f
 
 		if aID != 0 {
 			answer := questionEntity.ListAnswer{
-				ID:      aID,
-				Code:    code,
-				Content: aContent,
+				ID:          aID,
+				Code:        code,
+				Content:     aContent,
+				ImgVariants: imgVariants,
 			}
 			if imgURL != "" {
 				answer.ImgURL = &imgURL
@@ -314,22 +377,73 @@ func (r *questionRepository) ListQuizQuestionsLessonClass(ctx context.Context, f
 		finalQuestions[i] = *q
 	}
 
-	rand.Shuffle(len(finalQuestions), func(i, j int) {
-		finalQuestions[i], finalQuestions[j] = finalQuestions[j], finalQuestions[i]
-	})
-
 	if r.redis != nil {
 		if dataJSON, err := json.Marshal(finalQuestions); err == nil {
 			_ = cache.Set(ctx, redisKey, string(dataJSON), r.redis, cache.ExpBlazing)
 		}
 	}
 
-	// convert string to int setID
-	setIDInt, err := strconv.Atoi(setID)
-	if err != nil {
-		log.Error("[Repo][ListQuizQuestions] Error converting setID to int: ", err)
-		return nil, 0, app.NewAppError(500, "failed to convert set_id to integer")
+	return orderQuiz(finalQuestions, seed, order), setIDInt, nil
+}
+
+// orderQuiz puts the questions in the order of a quiz session. Without a
+// session the questions are shuffled at random and options stay canonical.
+func orderQuiz(questions []questionEntity.ListQuestionQuiz, seed int64, order []int) []questionEntity.ListQuestionQuiz {
+	if seed == 0 {
+		rand.Shuffle(len(questions), func(i, j int) {
+			questions[i], questions[j] = questions[j], questions[i]
+		})
+		return questions
+	}
+	return shuffleQuiz(questions, seed, order)
+}
+
+// shuffleQuiz applies the deterministic shuffle of seed: questions follow
+// order (or the seeded order of their numbers when order is nil) and the
+// options of choice questions are reordered and relabelled a, b, c, ...
+func shuffleQuiz(questions []questionEntity.ListQuestionQuiz, seed int64, order []int) []questionEntity.ListQuestionQuiz {
+	if seed == 0 {
+		return questions
+	}
+
+	byNumber := make(map[int]questionEntity.ListQuestionQuiz, len(questions))
+	numbers := make([]int, 0, len(questions))
+	for _, q := range questions {
+		byNumber[q.Number] = q
+		numbers = append(numbers, q.Number)
+	}
+	if order == nil {
+		sort.Ints(numbers)
+		order = shuffle.Questions(seed, numbers)
+	}
+
+	out := make([]questionEntity.ListQuestionQuiz, 0, len(questions))
+	for _, number := range order {
+		q, ok := byNumber[number]
+		if !ok {
+			continue
+		}
+		if shuffle.Shuffles(q.Format) {
+			q.Answers = shuffleOptions(q, seed)
+		}
+		out = append(out, q)
 	}
+	return out
+}
 
-	return finalQuestions, setIDInt, nil
+func shuffleOptions(q questionEntity.ListQuestionQuiz, seed int64) []questionEntity.ListAnswer {
+	byCode := make(map[string]questionEntity.ListAnswer, len(q.Answers))
+	codes := make([]string, 0, len(q.Answers))
+	for _, a := range q.Answers {
+		byCode[a.Code] = a
+		codes = append(codes, a.Code)
+	}
+
+	answers := make([]questionEntity.ListAnswer, 0, len(codes))
+	for i, code := range shuffle.Options(seed, int(q.ID), codes) {
+		a := byCode[code]
+		a.Code = shuffle.Label(i)
+		answers = append(answers, a)
+	}
+	return answers
 }
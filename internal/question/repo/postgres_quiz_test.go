@@ -0,0 +1,63 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/ghulammuzz/misterblast/internal/quiz/shuffle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListQuizQuestionsLessonClass_Session(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db, nil)
+	seed := int64(99)
+
+	mock.ExpectQuery(`SELECT set_id, lang, seed, question_order FROM quiz_sessions WHERE id = \$1`).
+		WithArgs("5").
+		WillReturnRows(sqlmock.NewRows([]string{"set_id", "lang", "seed", "question_order"}).
+			AddRow("3", "id", seed, "{2,1}"))
+
+	mock.ExpectQuery(`SELECT q.id, q.number`).
+		WithArgs("3", "id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "type", "format", "content", "set_id", "answer_id", "code", "answer_content", "img_url", "variants"}).
+			AddRow(10, 1, "c1_faktual", "mc4", "Q1", 3, 100, "a", "A", "", nil).
+			AddRow(10, 1, "c1_faktual", "mc4", "Q1", 3, 101, "b", "B", "", nil).
+			AddRow(10, 1, "c1_faktual", "mc4", "Q1", 3, 102, "c", "C", "", nil).
+			AddRow(11, 2, "c1_faktual", "sa", "Q2", 3, 103, "a", "Jakarta", "/files/jakarta.png", []byte(`{"thumb":"/files/jakarta.png_thumb.webp"}`)))
+
+	questions, setID, err := repository.ListQuizQuestionsLessonClass(context.Background(), map[string]string{"session_id": "5"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, setID)
+	assert.Equal(t, []int{2, 1}, []int{questions[0].Number, questions[1].Number})
+
+	mapping := shuffle.NewMapping(seed, 10, []string{"a", "b", "c"})
+	for i, a := range questions[1].Answers {
+		assert.Equal(t, shuffle.Label(i), a.Code)
+		assert.Equal(t, map[int32]string{100: "a", 101: "b", 102: "c"}[a.ID], mapping.Canonical(a.Code))
+	}
+	assert.Equal(t, "a", questions[0].Answers[0].Code, "short answer options keep their code")
+	assert.Equal(t, "/files/jakarta.png_thumb.webp", questions[0].Answers[0].ImgVariants["thumb"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListQuizQuestionsLessonClass_NotEnrolled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db, nil)
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM sets s WHERE s.id = \$1 AND`).
+		WithArgs("3", "7").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	_, _, err = repository.ListQuizQuestionsLessonClass(context.Background(), map[string]string{"set_id": "3", "enrolled_user": "7"})
+	assert.EqualError(t, err, "quiz set not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghulammuzz/misterblast/internal/question/itemstats"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+// ItemResponses returns every graded quiz answer to a live question.
+func (r *questionRepository) ItemResponses(ctx context.Context) ([]itemstats.Response, error) {
+	query := `
+		SELECT sa.question_id, sa.submission_id, q.format, sa.answer, sa.score
+		FROM quiz_submission_answers sa
+		JOIN questions q ON q.id = sa.question_id
+		WHERE NOT sa.pending AND q.deleted_at IS NULL
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("[Repo][ItemResponses] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to load quiz answers")
+	}
+	defer rows.Close()
+
+	var responses []itemstats.Response
+	for rows.Next() {
+		var resp itemstats.Response
+		if err := rows.Scan(&resp.QuestionID, &resp.SubmissionID, &resp.Format, &resp.Answer, &resp.Score); err != nil {
+			log.Error("[Repo][ItemResponses] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan quiz answers")
+		}
+		responses = append(responses, resp)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ItemResponses] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read quiz answers")
+	}
+	return responses, nil
+}
+
+// SaveItemStats stores one snapshot row per question, all stamped with the
+// same computedAt.
+func (r *questionRepository) SaveItemStats(ctx context.Context, stats []itemstats.Stat, computedAt int64) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error("[Repo][SaveItemStats] Error beginning transaction: ", err)
+		return app.NewAppError(500, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	const batch = 500
+	for start := 0; start < len(stats); start += batch {
+		end := start + batch
+		if end > len(stats) {
+			end = len(stats)
+		}
+
+		var values []string
+		var args []interface{}
+		for _, s := range stats[start:end] {
+			var options interface{}
+			if s.Options != nil {
+				encoded, err := json.Marshal(s.Options)
+				if err != nil {
+					return app.NewAppError(500, "failed to encode option counts")
+				}
+				options = string(encoded)
+			}
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+			args = append(args, s.QuestionID, s.Responses, s.Difficulty, s.Discrimination, options, computedAt)
+		}
+
+		query := `INSERT INTO question_stat_snapshots (question_id, responses, difficulty, discrimination, option_counts, computed_at) VALUES ` + strings.Join(values, ", ")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			log.Error("[Repo][SaveItemStats] Error Insert: ", err)
+			return app.NewAppError(500, "failed to store item statistics")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][SaveItemStats] Error committing: ", err)
+		return app.NewAppError(500, "failed to commit item statistics")
+	}
+	return nil
+}
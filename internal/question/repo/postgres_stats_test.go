@@ -0,0 +1,35 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/question/itemstats"
+	"github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveItemStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db, nil)
+
+	disc := 0.5
+	stats := []itemstats.Stat{
+		{QuestionID: 1, Responses: 10, Difficulty: 0.7, Discrimination: &disc, Options: map[string]int{"a": 7, "b": 3}},
+		{QuestionID: 2, Responses: 2, Difficulty: 0.5},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO question_stat_snapshots`).
+		WithArgs(1, 10, 0.7, &disc, `{"a":7,"b":3}`, int64(1700000000), 2, 2, 0.5, nil, nil, int64(1700000000)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err = repository.SaveItemStats(context.Background(), stats, 1700000000)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -109,8 +109,10 @@ func TestListAdmin(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
 	// Mock data query
-	mockRows := sqlmock.NewRows([]string{"id", "number", "type", "format", "content", "explanation", "reason", "is_quiz", "set_id", "set_name", "lesson_name", "class_name"}).
-		AddRow(1, 1, "c4_faktual", "mm", "Question 1", "exp-1", "r-1", true, 1, "Set 1", "Lesson 1", "Class 1")
+	mockRows := sqlmock.NewRows([]string{"id", "number", "type", "format", "content", "explanation", "reason", "is_quiz", "set_id", "set_name", "lesson_name", "class_name",
+		"responses", "difficulty", "discrimination", "option_counts", "computed_at"}).
+		AddRow(1, 1, "c4_faktual", "mm", "Question 1", "exp-1", "r-1", true, 1, "Set 1", "Lesson 1", "Class 1", 30, 0.6, 0.4, []byte(`{"a":18,"b":12}`), 1700000000).
+		AddRow(2, 2, "c4_faktual", "mm", "Question 2", "exp-2", "r-2", true, 1, "Set 1", "Lesson 1", "Class 1", nil, nil, nil, nil, nil)
 
 	mock.ExpectQuery(`SELECT q.id, q.number, q.type, q.format, q.content, q.explanation, q.reasoning, q.is_quiz, q.set_id`).
 		WillReturnRows(mockRows)
@@ -121,11 +123,17 @@ func TestListAdmin(t *testing.T) {
 	assert.Equal(t, int64(1), result.Total)
 	assert.Equal(t, 1, result.Page)
 	assert.Equal(t, 10, result.Limit)
-	assert.Len(t, result.Data, 1)
+	assert.Len(t, result.Data, 2)
 
 	questions, ok := result.Data.([]questionEntity.ListQuestionAdmin)
 	assert.True(t, ok)
 	assert.Equal(t, "Question 1", questions[0].Content)
+	if assert.NotNil(t, questions[0].Stats) {
+		assert.Equal(t, 30, questions[0].Stats.Responses)
+		assert.InDelta(t, 0.4, *questions[0].Stats.Discrimination, 1e-9)
+		assert.Equal(t, 18, questions[0].Stats.Options["a"])
+	}
+	assert.Nil(t, questions[1].Stats)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
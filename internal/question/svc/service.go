@@ -2,8 +2,10 @@ package svc
 
 import (
 	"context"
+	"time"
 
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/question/itemstats"
 	"github.com/ghulammuzz/misterblast/internal/question/repo"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/response"
@@ -17,6 +19,8 @@ type QuestionService interface {
 	DeleteQuestion(id int32) error
 	DetailQuestion(ctx context.Context, id int32) (questionEntity.DetailQuestionExample, error)
 	EditQuestion(id int32, question questionEntity.EditQuestion) error
+	ExportQuestions(ctx context.Context, filter map[string]string) (questionEntity.ExportBank, error)
+	ImportQuestions(setID int32, lang string, questions []questionEntity.ImportQuestion, dryRun bool) (questionEntity.ImportResult, error)
 
 	// Answer
 	AddQuizAnswer(answer questionEntity.SetAnswer) error
@@ -26,6 +30,7 @@ type QuestionService interface {
 
 	// Admin
 	ListAdmin(ctx context.Context, filter map[string]string, page, limit int) (*response.PaginateResponse, error)
+	SnapshotItemStats(ctx context.Context) (int, error)
 
 	// Q Type
 	ListQuestionTypes(ctx context.Context) ([]questionEntity.QuestionType, error)
@@ -89,6 +94,70 @@ func (s *questionService) EditQuestion(id int32, question questionEntity.EditQue
 	return s.repo.Edit(id, question)
 }
 
+// ExportQuestions collects the questions of a set, lesson or class for export.
+func (s *questionService) ExportQuestions(ctx context.Context, filter map[string]string) (questionEntity.ExportBank, error) {
+	_, bySet := filter["set_id"]
+	_, byLesson := filter["lesson_id"]
+	_, byClass := filter["class_id"]
+	if !bySet && !byLesson && !byClass {
+		return questionEntity.ExportBank{}, app.NewAppError(400, "set_id, lesson_id or class_id is required")
+	}
+
+	questions, err := s.repo.Export(ctx, filter)
+	if err != nil {
+		return questionEntity.ExportBank{}, err
+	}
+	if len(questions) == 0 {
+		return questionEntity.ExportBank{}, app.NewAppError(404, "no questions found to export")
+	}
+
+	return questionEntity.ExportBank{
+		Version:    1,
+		ExportedAt: time.Now().Unix(),
+		Filter:     filter,
+		Questions:  questions,
+	}, nil
+}
+
+// ImportQuestions writes already validated questions into the set. Conflicts
+// with existing question numbers come back as row errors on the result.
+func (s *questionService) ImportQuestions(setID int32, lang string, questions []questionEntity.ImportQuestion, dryRun bool) (questionEntity.ImportResult, error) {
+	result := questionEntity.ImportResult{SetID: setID, Lang: lang, DryRun: dryRun}
+	if len(questions) == 0 {
+		return result, app.NewAppError(400, "import file has no questions")
+	}
+
+	rowErrs, err := s.repo.Import(setID, lang, questions, dryRun)
+	if err != nil {
+		return result, err
+	}
+	if len(rowErrs) > 0 {
+		result.Errors = rowErrs
+		return result, nil
+	}
+
+	result.Questions = len(questions)
+	for _, q := range questions {
+		result.Answers += len(q.Answers)
+	}
+	return result, nil
+}
+
+// SnapshotItemStats recomputes the item analysis of every answered question
+// and stores it as a new snapshot. It returns the number of questions covered.
+func (s *questionService) SnapshotItemStats(ctx context.Context) (int, error) {
+	responses, err := s.repo.ItemResponses(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	stats := itemstats.Compute(responses)
+	if err := s.repo.SaveItemStats(ctx, stats, time.Now().Unix()); err != nil {
+		return 0, err
+	}
+	return len(stats), nil
+}
+
 func (s *questionService) DeleteAnswer(id int32) error {
 	return s.repo.DeleteAnswer(id)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/internal/question/itemstats"
 	"github.com/ghulammuzz/misterblast/internal/question/svc"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/stretchr/testify/assert"
@@ -86,6 +87,104 @@ func (m *MockQuestionRepo) UpsertAndSyncAnswers(questionID int32, answers []ques
 	return args.Error(0)
 }
 
+func (m *MockQuestionRepo) Import(setID int32, lang string, questions []questionEntity.ImportQuestion, dryRun bool) ([]questionEntity.ImportRowError, error) {
+	args := m.Called(setID, lang, questions, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]questionEntity.ImportRowError), args.Error(1)
+}
+
+func (m *MockQuestionRepo) Export(ctx context.Context, filter map[string]string) ([]questionEntity.ExportQuestion, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]questionEntity.ExportQuestion), args.Error(1)
+}
+
+func (m *MockQuestionRepo) ItemResponses(ctx context.Context) ([]itemstats.Response, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]itemstats.Response), args.Error(1)
+}
+
+func (m *MockQuestionRepo) SaveItemStats(ctx context.Context, stats []itemstats.Stat, computedAt int64) error {
+	args := m.Called(ctx, stats, computedAt)
+	return args.Error(0)
+}
+
+func TestSnapshotItemStatsService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo)
+
+	responses := []itemstats.Response{
+		{QuestionID: 1, SubmissionID: 1, Format: "mc4", Answer: "a", Score: 1},
+		{QuestionID: 1, SubmissionID: 2, Format: "mc4", Answer: "b", Score: 0},
+		{QuestionID: 2, SubmissionID: 1, Format: "essay", Answer: "text", Score: 0.5},
+	}
+	mockRepo.On("ItemResponses", mock.Anything).Return(responses, nil)
+	mockRepo.On("SaveItemStats", mock.Anything, itemstats.Compute(responses), mock.AnythingOfType("int64")).Return(nil)
+
+	n, err := service.SnapshotItemStats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportQuestionsService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo)
+
+	filter := map[string]string{"set_id": "3"}
+	questions := []questionEntity.ExportQuestion{{ID: 1, Number: 1, SetID: 3}}
+	mockRepo.On("Export", mock.Anything, filter).Return(questions, nil)
+
+	bank, err := service.ExportQuestions(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, bank.Version)
+	assert.Equal(t, questions, bank.Questions)
+
+	_, err = service.ExportQuestions(context.Background(), map[string]string{"lang": "id"})
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportQuestionsService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo)
+
+	questions := []questionEntity.ImportQuestion{
+		{Row: 2, Number: 1, Answers: []questionEntity.ImportAnswer{{Code: "a"}, {Code: "b"}}},
+		{Row: 4, Number: 2, Answers: []questionEntity.ImportAnswer{{Code: "a"}}},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.On("Import", int32(3), "id", questions, true).Return(nil, nil).Once()
+
+		result, err := service.ImportQuestions(3, "id", questions, true)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Questions)
+		assert.Equal(t, 3, result.Answers)
+		assert.True(t, result.DryRun)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		rowErrs := []questionEntity.ImportRowError{{Row: 4, Field: "number", Message: "taken"}}
+		mockRepo.On("Import", int32(3), "id", questions, false).Return(rowErrs, nil).Once()
+
+		result, err := service.ImportQuestions(3, "id", questions, false)
+		assert.NoError(t, err)
+		assert.Equal(t, rowErrs, result.Errors)
+		assert.Zero(t, result.Questions)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := service.ImportQuestions(3, "id", nil, false)
+		assert.Error(t, err)
+	})
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestListAdminService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
 	service := svc.NewQuestionService(mockRepo)
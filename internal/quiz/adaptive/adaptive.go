@@ -0,0 +1,209 @@
+// Package adaptive picks the next practice question for a student from their
+// accuracy per Bloom's taxonomy level (C1..C6) and knowledge dimension.
+package adaptive
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MasteryThreshold is the accuracy a student needs on a level before
+	// practice moves on to the next one.
+	MasteryThreshold = 0.8
+	// MinAttempts is the number of graded answers on a level needed before
+	// its accuracy counts as mastery.
+	MinAttempts = 3
+
+	MinLevel = 1
+	MaxLevel = 6
+)
+
+// Dimensions are the knowledge dimensions in the order they are taught.
+var Dimensions = []string{"faktual", "konseptual", "prosedural", "metakognitif"}
+
+// Item is one candidate question with the student's history on it. Score is
+// the sum of the 0..1 scores of every graded answer.
+type Item struct {
+	QuestionID int
+	Type       string
+	Attempts   int
+	Score      float64
+}
+
+// Mastery is the student's standing on one cognitive level.
+type Mastery struct {
+	Level     int     `json:"level"`
+	Questions int     `json:"questions"`
+	Attempts  int     `json:"attempts"`
+	Accuracy  float64 `json:"accuracy"`
+	Mastered  bool    `json:"mastered"`
+}
+
+// Pick is the chosen question with the level and dimension it practices.
+type Pick struct {
+	Item      Item
+	Level     int
+	Dimension string
+	Mastery   []Mastery
+}
+
+// ParseType splits a question type such as "c3_prosedural" into its level
+// and knowledge dimension.
+func ParseType(t string) (int, string, bool) {
+	prefix, dimension, ok := strings.Cut(strings.ToLower(t), "_")
+	if !ok || len(prefix) != 2 || prefix[0] != 'c' {
+		return 0, "", false
+	}
+	level, err := strconv.Atoi(prefix[1:])
+	if err != nil || level < MinLevel || level > MaxLevel {
+		return 0, "", false
+	}
+	for _, d := range Dimensions {
+		if d == dimension {
+			return level, dimension, true
+		}
+	}
+	return 0, "", false
+}
+
+type stat struct {
+	attempts int
+	score    float64
+}
+
+func (s stat) accuracy() float64 {
+	if s.attempts == 0 {
+		return 0
+	}
+	return s.score / float64(s.attempts)
+}
+
+// Levels reports the mastery of every level from C1 to C6.
+func Levels(items []Item) []Mastery {
+	levels := make([]Mastery, MaxLevel)
+	stats := make([]stat, MaxLevel)
+	for i := range levels {
+		levels[i].Level = i + MinLevel
+	}
+	for _, it := range items {
+		level, _, ok := ParseType(it.Type)
+		if !ok {
+			continue
+		}
+		levels[level-1].Questions++
+		stats[level-1].attempts += it.Attempts
+		stats[level-1].score += it.Score
+	}
+	for i := range levels {
+		levels[i].Attempts = stats[i].attempts
+		levels[i].Accuracy = stats[i].accuracy()
+		levels[i].Mastered = stats[i].attempts >= MinAttempts && levels[i].Accuracy >= MasteryThreshold
+	}
+	return levels
+}
+
+// Current is the level practice should focus on: the lowest level that has
+// questions and is not yet mastered, or the highest level with questions
+// once every level is mastered. It returns 0 when there are no questions.
+func Current(levels []Mastery) int {
+	highest := 0
+	for _, l := range levels {
+		if l.Questions == 0 {
+			continue
+		}
+		if !l.Mastered {
+			return l.Level
+		}
+		highest = l.Level
+	}
+	return highest
+}
+
+// Next picks the question to practice. It starts at the current level and
+// within it the weakest dimension, preferring the questions the student has
+// answered least. Excluded questions are skipped; when the current level has
+// none left, higher levels are tried first, then lower ones.
+func Next(items []Item, exclude map[int]bool) (Pick, bool) {
+	levels := Levels(items)
+	current := Current(levels)
+	if current == 0 {
+		return Pick{Mastery: levels}, false
+	}
+
+	byLevel := make(map[int][]Item)
+	for _, it := range items {
+		if exclude[it.QuestionID] {
+			continue
+		}
+		if level, _, ok := ParseType(it.Type); ok {
+			byLevel[level] = append(byLevel[level], it)
+		}
+	}
+
+	for _, level := range searchOrder(current) {
+		if candidates := byLevel[level]; len(candidates) > 0 {
+			item, dimension := pickInLevel(candidates)
+			return Pick{Item: item, Level: level, Dimension: dimension, Mastery: levels}, true
+		}
+	}
+	return Pick{Mastery: levels}, false
+}
+
+func searchOrder(current int) []int {
+	order := []int{current}
+	for l := current + 1; l <= MaxLevel; l++ {
+		order = append(order, l)
+	}
+	for l := current - 1; l >= MinLevel; l-- {
+		order = append(order, l)
+	}
+	return order
+}
+
+func pickInLevel(candidates []Item) (Item, string) {
+	dims := make(map[string]stat)
+	for _, it := range candidates {
+		_, d, _ := ParseType(it.Type)
+		s := dims[d]
+		s.attempts += it.Attempts
+		s.score += it.Score
+		dims[d] = s
+	}
+
+	weakest := ""
+	for _, d := range Dimensions {
+		s, ok := dims[d]
+		if !ok {
+			continue
+		}
+		if weakest == "" {
+			weakest = d
+			continue
+		}
+		w := dims[weakest]
+		if s.accuracy() < w.accuracy() || (s.accuracy() == w.accuracy() && s.attempts < w.attempts) {
+			weakest = d
+		}
+	}
+
+	var pool []Item
+	for _, it := range candidates {
+		if _, d, _ := ParseType(it.Type); d == weakest {
+			pool = append(pool, it)
+		}
+	}
+	sort.Slice(pool, func(i, j int) bool {
+		a, b := pool[i], pool[j]
+		if a.Attempts != b.Attempts {
+			return a.Attempts < b.Attempts
+		}
+		ra, rb := stat{a.Attempts, a.Score}.accuracy(), stat{b.Attempts, b.Score}.accuracy()
+		if ra != rb {
+			return ra < rb
+		}
+		return a.QuestionID < b.QuestionID
+	})
+	return pool[0], weakest
+}
@@ -0,0 +1,77 @@
+package adaptive_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/adaptive"
+)
+
+func TestParseType(t *testing.T) {
+	level, dim, ok := adaptive.ParseType("c3_prosedural")
+	assert.True(t, ok)
+	assert.Equal(t, 3, level)
+	assert.Equal(t, "prosedural", dim)
+
+	for _, bad := range []string{"c7_faktual", "c1_unknown", "x1_faktual", "c1"} {
+		_, _, ok := adaptive.ParseType(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestNext_StartsAtC1(t *testing.T) {
+	items := []adaptive.Item{
+		{QuestionID: 1, Type: "c2_faktual"},
+		{QuestionID: 2, Type: "c1_konseptual"},
+		{QuestionID: 3, Type: "c1_faktual"},
+	}
+
+	pick, ok := adaptive.Next(items, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 1, pick.Level)
+	assert.Equal(t, "faktual", pick.Dimension)
+	assert.Equal(t, 3, pick.Item.QuestionID)
+}
+
+func TestNext_MovesUpAfterMastery(t *testing.T) {
+	items := []adaptive.Item{
+		{QuestionID: 1, Type: "c1_faktual", Attempts: 2, Score: 2},
+		{QuestionID: 2, Type: "c1_konseptual", Attempts: 2, Score: 1.8},
+		{QuestionID: 3, Type: "c2_faktual"},
+	}
+
+	pick, ok := adaptive.Next(items, nil)
+	assert.True(t, ok)
+	assert.True(t, pick.Mastery[0].Mastered)
+	assert.Equal(t, 2, pick.Level)
+	assert.Equal(t, 3, pick.Item.QuestionID)
+}
+
+func TestNext_StaysBelowThreshold(t *testing.T) {
+	items := []adaptive.Item{
+		{QuestionID: 1, Type: "c1_faktual", Attempts: 3, Score: 3},
+		{QuestionID: 2, Type: "c1_konseptual", Attempts: 3, Score: 0},
+		{QuestionID: 3, Type: "c2_faktual"},
+	}
+
+	pick, ok := adaptive.Next(items, nil)
+	assert.True(t, ok)
+	assert.False(t, pick.Mastery[0].Mastered)
+	assert.Equal(t, 1, pick.Level)
+	assert.Equal(t, "konseptual", pick.Dimension, "the weakest dimension is practised first")
+}
+
+func TestNext_SkipsExcludedAndFallsBack(t *testing.T) {
+	items := []adaptive.Item{
+		{QuestionID: 1, Type: "c1_faktual"},
+		{QuestionID: 2, Type: "c3_faktual"},
+	}
+
+	pick, ok := adaptive.Next(items, map[int]bool{1: true})
+	assert.True(t, ok)
+	assert.Equal(t, 3, pick.Level)
+
+	_, ok = adaptive.Next(items, map[int]bool{1: true, 2: true})
+	assert.False(t, ok)
+}
@@ -3,18 +3,24 @@ package di
 import (
 	"database/sql"
 
+	gamificationRepo "github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	gamificationSvc "github.com/ghulammuzz/misterblast/internal/gamification/svc"
+
 	quizHandler "github.com/ghulammuzz/misterblast/internal/quiz/handler"
 	quizRepo "github.com/ghulammuzz/misterblast/internal/quiz/repo"
 	quizSvc "github.com/ghulammuzz/misterblast/internal/quiz/svc"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitializedQuizServiceFake(sb *sql.DB, val *validator.Validate) *quizHandler.QuizHandler {
+func InitializedQuizServiceFake(sb *sql.DB, redis *redis.Client, val *validator.Validate) *quizHandler.QuizHandler {
 	wire.Build(
 		quizHandler.NewQuizHandler,
 		quizSvc.NewQuizService,
 		quizRepo.NewQuizRepository,
+		gamificationSvc.NewGamificationService,
+		gamificationRepo.NewGamificationRepository,
 	)
 
 	return &quizHandler.QuizHandler{}
@@ -8,17 +8,22 @@ package di
 
 import (
 	"database/sql"
+	repo2 "github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	svc2 "github.com/ghulammuzz/misterblast/internal/gamification/svc"
 	"github.com/ghulammuzz/misterblast/internal/quiz/handler"
 	"github.com/ghulammuzz/misterblast/internal/quiz/repo"
 	"github.com/ghulammuzz/misterblast/internal/quiz/svc"
 	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
 )
 
 // Injectors from wire.go:
 
-func InitializedQuizService(sb *sql.DB, val *validator.Validate) *handler.QuizHandler {
-	quizRepository := repo.NewQuizRepository(sb)
-	quizService := svc.NewQuizService(quizRepository)
+func InitializedQuizService(sb *sql.DB, redis2 *redis.Client, val *validator.Validate) *handler.QuizHandler {
+	quizRepository := repo.NewQuizRepository(sb, redis2)
+	gamificationRepository := repo2.NewGamificationRepository(sb)
+	gamificationService := svc2.NewGamificationService(gamificationRepository)
+	quizService := svc.NewQuizService(quizRepository, gamificationService)
 	quizHandler := handler.NewQuizHandler(quizService, val)
 	return quizHandler
 }
@@ -0,0 +1,27 @@
+package entity
+
+type LeaderboardEntry struct {
+	Rank   int64   `json:"rank"`
+	UserID int     `json:"user_id"`
+	Name   string  `json:"name"`
+	ImgURL *string `json:"img_url"`
+	Score  int64   `json:"score"`
+}
+
+// Leaderboard is one page of a board. Me is the caller's own entry, nil when
+// they have no graded submission in the scope or opted out.
+type Leaderboard struct {
+	Scope   string             `json:"scope"`
+	ScopeID int                `json:"scope_id"`
+	Period  string             `json:"period"`
+	Total   int64              `json:"total"`
+	Page    int                `json:"page"`
+	Limit   int                `json:"limit"`
+	Entries []LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardEntry  `json:"me"`
+	OptOut  bool               `json:"opt_out"`
+}
+
+type LeaderboardOptOut struct {
+	OptOut *bool `json:"opt_out" validate:"required"`
+}
@@ -0,0 +1,44 @@
+package entity
+
+// MasteryResult is one graded quiz answer of a student with the curriculum
+// it belongs to.
+type MasteryResult struct {
+	ClassID     int
+	ClassName   string
+	LessonID    int
+	LessonName  string
+	SetID       int
+	SetName     string
+	Type        string
+	Score       float64
+	SubmittedAt int64
+}
+
+// MasteryStat is the accuracy of a student on one slice of their answers.
+// Accuracy is a percentage of the possible score.
+type MasteryStat struct {
+	ID       int     `json:"id,omitempty"`
+	Name     string  `json:"name"`
+	Parent   string  `json:"parent,omitempty"`
+	Answers  int     `json:"answers"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+type MasteryTrend struct {
+	WeekStart string  `json:"week_start"`
+	Answers   int     `json:"answers"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+type MasteryReport struct {
+	UserID     int            `json:"user_id"`
+	Overall    MasteryStat    `json:"overall"`
+	Classes    []MasteryStat  `json:"classes"`
+	Lessons    []MasteryStat  `json:"lessons"`
+	Sets       []MasteryStat  `json:"sets"`
+	Levels     []MasteryStat  `json:"levels"`
+	Dimensions []MasteryStat  `json:"dimensions"`
+	Trend      []MasteryTrend `json:"trend"`
+	Weakest    []MasteryStat  `json:"weakest"`
+}
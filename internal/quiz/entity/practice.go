@@ -0,0 +1,28 @@
+package entity
+
+import "github.com/ghulammuzz/misterblast/internal/quiz/adaptive"
+
+type PracticeOption struct {
+	Code    string  `json:"code"`
+	Content string  `json:"content"`
+	ImgURL  *string `json:"img_url"`
+}
+
+type PracticeQuestion struct {
+	ID      int              `json:"id"`
+	Number  int              `json:"number"`
+	Type    string           `json:"type"`
+	Format  string           `json:"format"`
+	Content string           `json:"content"`
+	SetID   int              `json:"set_id"`
+	Answers []PracticeOption `json:"answers"`
+}
+
+// PracticeNext is the next adaptive practice question with the student's
+// mastery per level. Question is nil once every question has been excluded.
+type PracticeNext struct {
+	Level     int                `json:"level"`
+	Dimension string             `json:"dimension"`
+	Question  *PracticeQuestion  `json:"question"`
+	Mastery   []adaptive.Mastery `json:"mastery"`
+}
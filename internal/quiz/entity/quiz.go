@@ -3,10 +3,26 @@ package entity
 type AnswersQuizSubmit struct {
 	Number int    `json:"number"`
 	Answer string `json:"answer"`
+	// TimeSpent is the client reported number of seconds spent on the question.
+	TimeSpent int `json:"time_spent" validate:"min=0"`
 }
 
 type QuizSubmit struct {
-	Answers []AnswersQuizSubmit `json:"answers"`
+	SessionID int                 `json:"session_id"`
+	Answers   []AnswersQuizSubmit `json:"answers" validate:"dive"`
+}
+
+type QuizSession struct {
+	ID            int    `json:"id"`
+	SetID         int    `json:"set_id"`
+	UserID        int    `json:"-"`
+	Lang          string `json:"lang"`
+	Status        string `json:"status"`
+	QuestionOrder []int  `json:"question_order"`
+	Seed          int64  `json:"seed"`
+	StartedAt     int64  `json:"started_at"`
+	Deadline      *int64 `json:"deadline"`
+	FinishedAt    *int64 `json:"finished_at,omitempty"`
 }
 
 type ListQuizSubmission struct {
@@ -14,6 +30,7 @@ type ListQuizSubmission struct {
 	SetID       int    `json:"set_id"`
 	Correct     int    `json:"correct"`
 	Grade       int    `json:"grade"`
+	Status      string `json:"status"`
 	Lesson      string `json:"lesson"`
 	Class       string `json:"class"`
 	SubmittedAt string `json:"submitted_at"`
@@ -25,6 +42,7 @@ type ListQuizSubmissionAdmin struct {
 	Name        string `json:"name"`
 	Correct     int    `json:"correct"`
 	Grade       int    `json:"grade"`
+	Status      string `json:"status"`
 	Lesson      string `json:"lesson"`
 	Class       string `json:"class"`
 	SubmittedAt string `json:"submitted_at"`
@@ -32,24 +50,42 @@ type ListQuizSubmissionAdmin struct {
 
 type QuizExp struct {
 	ID          int          `json:"id"`
+	UserID      int          `json:"-"`
 	Grade       string       `json:"grade"`
 	SubmittedAt int64        `json:"submitted_at"`
 	Correct     int          `json:"correct"`
 	Wrong       int          `json:"wrong"`
 	AttemptNo   int          `json:"attempt_no"`
+	Status      string       `json:"status"`
 	Lesson      string       `json:"lesson"`
+	StartedAt   *int64       `json:"started_at"`
+	TimeTaken   *int64       `json:"time_taken"`
 	Answers     []QuizExpObj `json:"answers"`
 }
 
 type QuizExpObj struct {
-	Number          int    `json:"number"`
-	UserCode        string `json:"user_code"`
-	ActualCode      string `json:"actual_code"`
-	UserContent     string `json:"user_content"`
-	ActualContent   string `json:"actual_content"`
-	QuestionContent string `json:"question_content"`
-	IsCorrect       bool   `json:"is_correct"`
-	Explanation     string `json:"explanation"`
-	Reason          string `json:"reason"`
-	Format          string `json:"format"`
+	Number          int     `json:"number"`
+	UserCode        string  `json:"user_code"`
+	ActualCode      string  `json:"actual_code"`
+	UserContent     string  `json:"user_content"`
+	ActualContent   string  `json:"actual_content"`
+	QuestionContent string  `json:"question_content"`
+	IsCorrect       bool    `json:"is_correct"`
+	Score           float64 `json:"score"`
+	Explanation     string  `json:"explanation"`
+	Reason          string  `json:"reason"`
+	Format          string  `json:"format"`
+	TimeSpent       *int    `json:"time_spent"`
+	Pending         bool    `json:"pending"`
+	Feedback        *string `json:"feedback,omitempty"`
+}
+
+type QuizSubmissionAnswer struct {
+	QuestionID int
+	Number     int
+	Answer     string
+	Score      float64
+	IsCorrect  bool
+	Pending    bool
+	TimeSpent  int
 }
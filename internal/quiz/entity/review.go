@@ -0,0 +1,22 @@
+package entity
+
+// EssayReview is one essay answer waiting in the teacher review queue.
+type EssayReview struct {
+	AnswerID        int    `json:"answer_id"`
+	SubmissionID    int    `json:"submission_id"`
+	UserID          int    `json:"user_id"`
+	UserName        string `json:"user_name"`
+	SetID           int    `json:"set_id"`
+	Lesson          string `json:"lesson"`
+	Class           string `json:"class"`
+	Number          int    `json:"number"`
+	QuestionContent string `json:"question_content"`
+	AnswerKey       string `json:"answer_key"`
+	Answer          string `json:"answer"`
+	SubmittedAt     int64  `json:"submitted_at"`
+}
+
+type ScoreEssayRequest struct {
+	Score    int    `json:"score" validate:"min=0,max=100"`
+	Feedback string `json:"feedback"`
+}
@@ -0,0 +1,48 @@
+package grader
+
+import "strings"
+
+// singleChoice grades mc4 questions: the answer is the code of one option.
+type singleChoice struct{}
+
+func (singleChoice) Grade(q Question, answer string) Result {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, o := range q.correctOptions() {
+		if strings.ToLower(o.Code) == answer {
+			return full()
+		}
+	}
+	return Result{}
+}
+
+// trueFalse grades t/f questions. The answer may be the option code or a
+// true/false word in either language, which is compared to the content of
+// the correct option.
+type trueFalse struct{}
+
+func (trueFalse) Grade(q Question, answer string) Result {
+	if r := (singleChoice{}).Grade(q, answer); r.Correct {
+		return r
+	}
+
+	given, ok := parseBool(answer)
+	if !ok {
+		return Result{}
+	}
+	for _, o := range q.correctOptions() {
+		if want, ok := parseBool(o.Content); ok && want == given {
+			return full()
+		}
+	}
+	return Result{}
+}
+
+func parseBool(s string) (bool, bool) {
+	switch Normalize(s) {
+	case "t", "true", "benar", "ya", "yes":
+		return true, true
+	case "f", "false", "salah", "tidak", "no":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,113 @@
+package grader
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Option is one row of the answers table as seen by a grader.
+type Option struct {
+	Code     string
+	Content  string
+	IsAnswer bool
+}
+
+// Question is the answer key of a single question.
+type Question struct {
+	ID      int
+	Number  int
+	Format  string
+	Options []Option
+}
+
+// Result is the outcome of grading one answer. Score is in the range 0..1,
+// Correct is only true for full credit.
+type Result struct {
+	Score   float64
+	Correct bool
+	Pending bool
+}
+
+type Grader interface {
+	Grade(q Question, answer string) Result
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Grader{
+		"mc4":   singleChoice{},
+		"t/f":   trueFalse{},
+		"mcx":   multipleChoice{},
+		"sa":    shortAnswer{},
+		"mm":    matching{},
+		"essay": essay{},
+	}
+)
+
+// Register replaces or adds the grader used for a question format.
+func Register(format string, g Grader) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[format] = g
+}
+
+func For(format string) (Grader, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	g, ok := registry[format]
+	if !ok {
+		return nil, fmt.Errorf("no grader registered for format %q", format)
+	}
+	return g, nil
+}
+
+// Grade looks up the grader for q.Format and grades answer with it.
+func Grade(q Question, answer string) (Result, error) {
+	g, err := For(q.Format)
+	if err != nil {
+		return Result{}, err
+	}
+	return g.Grade(q, answer), nil
+}
+
+// Normalize lowercases s, drops punctuation and collapses whitespace so that
+// "  Jakarta. " and "jakarta" compare equal.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// SplitAnswer splits a comma separated answer into trimmed, non empty parts.
+func SplitAnswer(answer string) []string {
+	var parts []string
+	for _, p := range strings.Split(answer, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+func (q Question) correctOptions() []Option {
+	var opts []Option
+	for _, o := range q.Options {
+		if o.IsAnswer {
+			opts = append(opts, o)
+		}
+	}
+	return opts
+}
+
+func full() Result {
+	return Result{Score: 1, Correct: true}
+}
@@ -0,0 +1,103 @@
+package grader_test
+
+import (
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/grader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		question grader.Question
+		answer   string
+		score    float64
+		correct  bool
+	}{
+		{
+			name: "mc4 correct",
+			question: grader.Question{Format: "mc4", Options: []grader.Option{
+				{Code: "a", Content: "1"}, {Code: "b", Content: "2", IsAnswer: true},
+			}},
+			answer: "B", score: 1, correct: true,
+		},
+		{
+			name: "mc4 wrong",
+			question: grader.Question{Format: "mc4", Options: []grader.Option{
+				{Code: "a", Content: "1"}, {Code: "b", Content: "2", IsAnswer: true},
+			}},
+			answer: "a", score: 0,
+		},
+		{
+			name: "mcx partial credit",
+			question: grader.Question{Format: "mcx", Options: []grader.Option{
+				{Code: "a", IsAnswer: true}, {Code: "b", IsAnswer: true}, {Code: "c"}, {Code: "d"},
+			}},
+			answer: "a", score: 0.5,
+		},
+		{
+			name: "mcx wrong pick cancels right pick",
+			question: grader.Question{Format: "mcx", Options: []grader.Option{
+				{Code: "a", IsAnswer: true}, {Code: "b", IsAnswer: true}, {Code: "c"},
+			}},
+			answer: "a,c", score: 0,
+		},
+		{
+			name: "mcx all correct",
+			question: grader.Question{Format: "mcx", Options: []grader.Option{
+				{Code: "a", IsAnswer: true}, {Code: "b", IsAnswer: true}, {Code: "c"},
+			}},
+			answer: "b, a", score: 1, correct: true,
+		},
+		{
+			name: "sa normalized",
+			question: grader.Question{Format: "sa", Options: []grader.Option{
+				{Code: "a", Content: "Jakarta", IsAnswer: true},
+			}},
+			answer: "  jakarta. ", score: 1, correct: true,
+		},
+		{
+			name: "t/f by word",
+			question: grader.Question{Format: "t/f", Options: []grader.Option{
+				{Code: "a", Content: "Benar", IsAnswer: true}, {Code: "b", Content: "Salah"},
+			}},
+			answer: "true", score: 1, correct: true,
+		},
+		{
+			name: "t/f wrong",
+			question: grader.Question{Format: "t/f", Options: []grader.Option{
+				{Code: "a", Content: "Benar"}, {Code: "b", Content: "Salah", IsAnswer: true},
+			}},
+			answer: "a", score: 0,
+		},
+		{
+			name: "mm half matched",
+			question: grader.Question{Format: "mm", Options: []grader.Option{
+				{Code: "a", Content: "Kucing"}, {Code: "b", Content: "Anjing"},
+			}},
+			answer: "a:kucing;b:ikan", score: 0.5,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := grader.Grade(tc.question, tc.answer)
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.score, result.Score, 0.0001)
+			assert.Equal(t, tc.correct, result.Correct)
+		})
+	}
+}
+
+func TestGrade_UnknownFormat(t *testing.T) {
+	_, err := grader.Grade(grader.Question{Format: "unknown"}, "a")
+	assert.Error(t, err)
+}
+
+func TestGrade_EssayPending(t *testing.T) {
+	result, err := grader.Grade(grader.Question{Format: "essay"}, "some text")
+	assert.NoError(t, err)
+	assert.True(t, result.Pending)
+	assert.Zero(t, result.Score)
+}
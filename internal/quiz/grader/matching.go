@@ -0,0 +1,40 @@
+package grader
+
+import "strings"
+
+// matching grades mm questions. Each option is one pair: its code is the left
+// item and its content the expected partner. The answer lists the pairs as
+// "a:partner;b:partner" and every matched pair earns an equal share.
+type matching struct{}
+
+func (matching) Grade(q Question, answer string) Result {
+	if len(q.Options) == 0 {
+		return Result{}
+	}
+
+	given := ParsePairs(answer)
+	hits := 0
+	for _, o := range q.Options {
+		if partner, ok := given[strings.ToLower(o.Code)]; ok && Normalize(partner) == Normalize(o.Content) {
+			hits++
+		}
+	}
+
+	if hits == len(q.Options) {
+		return full()
+	}
+	return Result{Score: float64(hits) / float64(len(q.Options))}
+}
+
+// ParsePairs parses a matching answer into a map of lowercased code to partner.
+func ParsePairs(answer string) map[string]string {
+	pairs := map[string]string{}
+	for _, p := range strings.Split(answer, ";") {
+		code, partner, ok := strings.Cut(p, ":")
+		if !ok {
+			continue
+		}
+		pairs[strings.ToLower(strings.TrimSpace(code))] = strings.TrimSpace(partner)
+	}
+	return pairs
+}
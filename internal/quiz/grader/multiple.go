@@ -0,0 +1,41 @@
+package grader
+
+import "strings"
+
+// multipleChoice grades mcx questions. The answer is a comma separated list
+// of option codes. Every correct pick earns 1/n of the credit and every wrong
+// pick takes 1/n away, never going below zero.
+type multipleChoice struct{}
+
+func (multipleChoice) Grade(q Question, answer string) Result {
+	correct := map[string]bool{}
+	for _, o := range q.correctOptions() {
+		correct[strings.ToLower(o.Code)] = true
+	}
+	if len(correct) == 0 {
+		return Result{}
+	}
+
+	picked := map[string]bool{}
+	for _, code := range SplitAnswer(answer) {
+		picked[strings.ToLower(code)] = true
+	}
+
+	hits, misses := 0, 0
+	for code := range picked {
+		if correct[code] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	score := float64(hits-misses) / float64(len(correct))
+	if score <= 0 {
+		return Result{}
+	}
+	if hits == len(correct) && misses == 0 {
+		return full()
+	}
+	return Result{Score: score}
+}
@@ -0,0 +1,26 @@
+package grader
+
+// shortAnswer grades sa questions by comparing the normalized answer against
+// the content of every option flagged as correct.
+type shortAnswer struct{}
+
+func (shortAnswer) Grade(q Question, answer string) Result {
+	given := Normalize(answer)
+	if given == "" {
+		return Result{}
+	}
+	for _, o := range q.correctOptions() {
+		if Normalize(o.Content) == given {
+			return full()
+		}
+	}
+	return Result{}
+}
+
+// essay answers cannot be graded automatically, they stay pending with no
+// score until a teacher reviews them.
+type essay struct{}
+
+func (essay) Grade(q Question, answer string) Result {
+	return Result{Pending: true}
+}
@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/leaderboard"
 	"github.com/ghulammuzz/misterblast/internal/quiz/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
@@ -22,13 +26,26 @@ func NewQuizHandler(quizService svc.QuizService, val *validator.Validate) *QuizH
 }
 
 func (h *QuizHandler) Router(r fiber.Router) {
-	r.Post("/submit-quiz/:set_id", m.JWTProtected(), m.R100(), h.SubmitQuizHandler)
+	r.Post("/start-quiz/:set_id", m.JWTProtected(), m.RequireVerified(), m.R100(), h.StartQuizHandler)
+	r.Get("/quiz-sessions/:session_id", m.JWTProtected(), m.R100(), h.GetSessionHandler)
+	r.Post("/submit-quiz/:set_id", m.JWTProtected(), m.RequireVerified(), m.R100(), h.SubmitQuizHandler)
 
-	r.Get("/quiz-submission-admin", m.R100(), h.AdminQuizSubmissionHandler)
+	r.Get("/quiz-submission-admin", m.JWTProtected(), m.Authorize(m.PermViewSubmissions), m.R100(), h.AdminQuizSubmissionHandler)
 	r.Get("/quiz-submission", m.JWTProtected(), m.R100(), h.QuizSubmissionHandler)
 	r.Get("/quiz-submission/:submission_id", m.JWTProtected(), m.R100(), h.GetSubmissionDetailHandler)
 	r.Get("/quiz-result", m.JWTProtected(), m.R100(), h.GetResultHandler)
 
+	// adaptive practice
+	r.Get("/practice/next", m.JWTProtected(), m.R100(), h.NextPracticeHandler)
+
+	// essay review
+	r.Get("/quiz-essay-review", m.JWTProtected(), m.Authorize(m.PermScoreSubmissions), m.R100(), h.ListEssayReviewHandler)
+	r.Put("/quiz-answer/:answer_id/score", m.JWTProtected(), m.Authorize(m.PermScoreSubmissions), m.R100(), h.ScoreEssayHandler)
+
+	// leaderboard
+	r.Get("/leaderboard/:scope", m.JWTProtected(), m.R100(), h.LeaderboardHandler)
+	r.Put("/leaderboard/opt-out", m.JWTProtected(), m.R100(), h.LeaderboardOptOutHandler)
+
 }
 
 func (h *QuizHandler) SubmitQuizHandler(c *fiber.Ctx) error {
@@ -48,14 +65,7 @@ func (h *QuizHandler) SubmitQuizHandler(c *fiber.Ctx) error {
 	}
 
 	userID := int(claims["user_id"].(float64))
-
-	lang := c.Get("Lang")
-	if lang == "" {
-		lang = c.Query("lang")
-	}
-	if lang == "" {
-		lang = "id"
-	}
+	lang := quizLang(c)
 
 	if err := c.BodyParser(&req); err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
@@ -76,6 +86,75 @@ func (h *QuizHandler) SubmitQuizHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "question added successfully", id)
 }
 
+func (h *QuizHandler) StartQuizHandler(c *fiber.Ctx) error {
+	setID, err := c.ParamsInt("set_id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid set ID", nil)
+	}
+
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	userID := int(claims["user_id"].(float64))
+
+	session, err := h.quizService.StartQuiz(setID, userID, quizLang(c))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "quiz session started successfully", session)
+}
+
+func (h *QuizHandler) GetSessionHandler(c *fiber.Ctx) error {
+	sessionID, err := c.ParamsInt("session_id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid session ID", nil)
+	}
+
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	userID := int(claims["user_id"].(float64))
+
+	session, err := h.quizService.GetSession(sessionID, userID)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "quiz session retrieved successfully", session)
+}
+
+// quizLang reads the question language from the Lang header or lang query,
+// defaulting to id.
+func quizLang(c *fiber.Ctx) string {
+	lang := c.Get("Lang")
+	if lang == "" {
+		lang = c.Query("lang")
+	}
+	if lang == "" {
+		lang = "id"
+	}
+	return lang
+}
+
 func (h *QuizHandler) AdminQuizSubmissionHandler(c *fiber.Ctx) error {
 	filter := map[string]string{}
 
@@ -178,7 +257,14 @@ func (h *QuizHandler) GetSubmissionDetailHandler(c *fiber.Ctx) error {
 	if err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "Invalid submission ID", nil)
 	}
-	submission, err := h.quizService.GetSubmissionResult(submissionId)
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	userID := int(claims["user_id"].(float64))
+	staff := m.RoleFromClaims(claims).Can(m.PermViewSubmissions)
+
+	submission, err := h.quizService.GetSubmissionResult(submissionId, userID, staff)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -189,3 +275,170 @@ func (h *QuizHandler) GetSubmissionDetailHandler(c *fiber.Ctx) error {
 
 	return response.SendSuccess(c, "quiz submission detail retrieved successfully", submission)
 }
+
+func (h *QuizHandler) ListEssayReviewHandler(c *fiber.Ctx) error {
+	filter := map[string]string{}
+	for _, key := range []string{"set_id", "lesson_id", "class_id"} {
+		if c.Query(key) != "" {
+			filter[key] = c.Query(key)
+		}
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	reviews, err := h.quizService.ListEssayReviews(filter, page, limit)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "essay reviews retrieved successfully", reviews)
+}
+
+func (h *QuizHandler) ScoreEssayHandler(c *fiber.Ctx) error {
+	answerID, err := c.ParamsInt("answer_id")
+	if err != nil || answerID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid answer ID", nil)
+	}
+
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	reviewerID := int(claims["user_id"].(float64))
+
+	var req entity.ScoreEssayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", err.Error())
+	}
+
+	if err := h.quizService.ScoreEssay(answerID, reviewerID, req); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "essay scored successfully", nil)
+}
+
+func (h *QuizHandler) NextPracticeHandler(c *fiber.Ctx) error {
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	userID := int(claims["user_id"].(float64))
+
+	filter := map[string]string{"lang": quizLang(c)}
+	for _, key := range []string{"set_id", "lesson_id", "class_id"} {
+		if c.Query(key) != "" {
+			filter[key] = c.Query(key)
+		}
+	}
+	if len(filter) == 1 {
+		return response.SendError(c, fiber.StatusBadRequest, "set_id, lesson_id or class_id is required", nil)
+	}
+
+	exclude := map[int]bool{}
+	if raw := c.Query("exclude"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return response.SendError(c, fiber.StatusBadRequest, "invalid exclude list", nil)
+			}
+			exclude[id] = true
+		}
+	}
+
+	next, err := h.quizService.NextPractice(userID, filter, exclude)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "practice question retrieved successfully", next)
+}
+
+// LeaderboardHandler returns one page of a set, lesson, class or global
+// leaderboard. period=week ranks the current week only.
+func (h *QuizHandler) LeaderboardHandler(c *fiber.Ctx) error {
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	userID := int(claims["user_id"].(float64))
+
+	scope, ok := leaderboard.ParseScope(c.Params("scope"))
+	if !ok {
+		return response.SendError(c, fiber.StatusBadRequest, "scope must be set, lesson, class or global", nil)
+	}
+	period, ok := leaderboard.ParsePeriod(c.Query("period"))
+	if !ok {
+		return response.SendError(c, fiber.StatusBadRequest, "period must be all or week", nil)
+	}
+
+	board := leaderboard.Board{Scope: scope, ID: c.QueryInt("id"), Period: period}
+	result, err := h.quizService.Leaderboard(board, userID, c.QueryInt("page", 1), c.QueryInt("limit", 10))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "leaderboard retrieved successfully", result)
+}
+
+func (h *QuizHandler) LeaderboardOptOutHandler(c *fiber.Ctx) error {
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	userID := int(claims["user_id"].(float64))
+
+	var req entity.LeaderboardOptOut
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(req); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", err.Error())
+	}
+
+	if err := h.quizService.SetLeaderboardOptOut(userID, *req.OptOut); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "leaderboard preference updated successfully", req)
+}
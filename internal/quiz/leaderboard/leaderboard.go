@@ -0,0 +1,84 @@
+// Package leaderboard names the Redis sorted sets that back the quiz
+// leaderboards. A board ranks users by the sum of their best graded score per
+// set within its scope, either all-time or for the current ISO week.
+package leaderboard
+
+import (
+	"fmt"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeSet    Scope = "set"
+	ScopeLesson Scope = "lesson"
+	ScopeClass  Scope = "class"
+	ScopeGlobal Scope = "global"
+)
+
+type Period string
+
+const (
+	PeriodAll  Period = "all"
+	PeriodWeek Period = "week"
+)
+
+// ParseScope accepts set, lesson, class and global.
+func ParseScope(raw string) (Scope, bool) {
+	switch s := Scope(raw); s {
+	case ScopeSet, ScopeLesson, ScopeClass, ScopeGlobal:
+		return s, true
+	}
+	return "", false
+}
+
+// ParsePeriod accepts all and week; an empty value means all.
+func ParsePeriod(raw string) (Period, bool) {
+	switch p := Period(raw); p {
+	case "":
+		return PeriodAll, true
+	case PeriodAll, PeriodWeek:
+		return p, true
+	}
+	return "", false
+}
+
+// WeekStart returns Monday 00:00 UTC of the week t falls in.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-day, 0, 0, 0, 0, time.UTC)
+}
+
+// Key returns the sorted set of one board. Weekly boards carry the ISO week
+// so a new week starts from an empty set.
+func Key(scope Scope, id int, period Period, now time.Time) string {
+	key := fmt.Sprintf("leaderboard:%s:%d:%s", scope, id, period)
+	if period == PeriodWeek {
+		year, week := now.UTC().ISOWeek()
+		key += fmt.Sprintf(":%d-W%02d", year, week)
+	}
+	return key
+}
+
+// Board identifies one leaderboard a submission counts towards.
+type Board struct {
+	Scope  Scope
+	ID     int
+	Period Period
+}
+
+// Boards lists every leaderboard a submission to the set counts towards.
+func Boards(setID, lessonID, classID int) []Board {
+	var boards []Board
+	for _, period := range []Period{PeriodAll, PeriodWeek} {
+		boards = append(boards,
+			Board{ScopeSet, setID, period},
+			Board{ScopeLesson, lessonID, period},
+			Board{ScopeClass, classID, period},
+			Board{ScopeGlobal, 0, period},
+		)
+	}
+	return boards
+}
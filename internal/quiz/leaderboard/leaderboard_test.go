@@ -0,0 +1,47 @@
+package leaderboard_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/leaderboard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	scope, ok := leaderboard.ParseScope("lesson")
+	assert.True(t, ok)
+	assert.Equal(t, leaderboard.ScopeLesson, scope)
+
+	_, ok = leaderboard.ParseScope("school")
+	assert.False(t, ok)
+
+	period, ok := leaderboard.ParsePeriod("")
+	assert.True(t, ok)
+	assert.Equal(t, leaderboard.PeriodAll, period)
+
+	_, ok = leaderboard.ParsePeriod("month")
+	assert.False(t, ok)
+}
+
+func TestWeekStart(t *testing.T) {
+	// Sunday belongs to the week that started the Monday before.
+	sunday := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), leaderboard.WeekStart(sunday))
+
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, leaderboard.WeekStart(monday))
+}
+
+func TestKey(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "leaderboard:set:3:all", leaderboard.Key(leaderboard.ScopeSet, 3, leaderboard.PeriodAll, now))
+	assert.Equal(t, "leaderboard:global:0:week:2026-W42", leaderboard.Key(leaderboard.ScopeGlobal, 0, leaderboard.PeriodWeek, now))
+}
+
+func TestBoards(t *testing.T) {
+	boards := leaderboard.Boards(3, 2, 1)
+	assert.Len(t, boards, 8)
+	assert.Contains(t, boards, leaderboard.Board{Scope: leaderboard.ScopeLesson, ID: 2, Period: leaderboard.PeriodWeek})
+	assert.Contains(t, boards, leaderboard.Board{Scope: leaderboard.ScopeGlobal, ID: 0, Period: leaderboard.PeriodAll})
+}
@@ -0,0 +1,167 @@
+// Package mastery builds per student accuracy reports from graded quiz
+// answers, broken down by curriculum, Bloom level and week.
+package mastery
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/adaptive"
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+)
+
+const (
+	// DefaultWeeks is the length of the trend when none is requested.
+	DefaultWeeks = 8
+	// MaxWeeks bounds the trend length.
+	MaxWeeks = 52
+	// WeakestMinAnswers is the number of answers a set needs before it can
+	// be listed as a weak topic.
+	WeakestMinAnswers = 3
+	// WeakestLimit is the number of weak topics reported.
+	WeakestLimit = 5
+)
+
+type acc struct {
+	stat  quizEntity.MasteryStat
+	score float64
+}
+
+func (a *acc) add(score float64) {
+	a.stat.Answers++
+	a.score += score
+	if score >= 1 {
+		a.stat.Correct++
+	}
+}
+
+func (a *acc) result() quizEntity.MasteryStat {
+	s := a.stat
+	if s.Answers > 0 {
+		s.Accuracy = math.Round(a.score/float64(s.Answers)*10000) / 100
+	}
+	return s
+}
+
+type group struct {
+	order []int
+	byKey map[int]*acc
+}
+
+func newGroup() *group { return &group{byKey: map[int]*acc{}} }
+
+// get returns the accumulator for key. Slices that are not stored rows,
+// such as dimensions, pass an id of zero.
+func (g *group) get(key, id int, name, parent string) *acc {
+	a, ok := g.byKey[key]
+	if !ok {
+		a = &acc{stat: quizEntity.MasteryStat{ID: id, Name: name, Parent: parent}}
+		g.byKey[key] = a
+		g.order = append(g.order, key)
+	}
+	return a
+}
+
+func (g *group) results() []quizEntity.MasteryStat {
+	sort.Ints(g.order)
+	out := make([]quizEntity.MasteryStat, 0, len(g.order))
+	for _, k := range g.order {
+		out = append(out, g.byKey[k].result())
+	}
+	return out
+}
+
+// Build aggregates the results into a report. The trend covers the given
+// number of weeks up to now, starting on Mondays in UTC.
+func Build(userID int, results []quizEntity.MasteryResult, now time.Time, weeks int) quizEntity.MasteryReport {
+	if weeks <= 0 {
+		weeks = DefaultWeeks
+	}
+	if weeks > MaxWeeks {
+		weeks = MaxWeeks
+	}
+
+	overall := &acc{stat: quizEntity.MasteryStat{Name: "overall"}}
+	classes, lessons, sets := newGroup(), newGroup(), newGroup()
+	levels, dimensions := newGroup(), newGroup()
+
+	firstWeek := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	trend := make([]acc, weeks)
+
+	for _, r := range results {
+		overall.add(r.Score)
+		classes.get(r.ClassID, r.ClassID, r.ClassName, "").add(r.Score)
+		lessons.get(r.LessonID, r.LessonID, r.LessonName, r.ClassName).add(r.Score)
+		sets.get(r.SetID, r.SetID, r.SetName, r.LessonName).add(r.Score)
+
+		if level, dimension, ok := adaptive.ParseType(r.Type); ok {
+			levels.get(level, level, fmt.Sprintf("C%d", level), "").add(r.Score)
+			dimensions.get(dimensionIndex(dimension), 0, dimension, "").add(r.Score)
+		}
+
+		submitted := time.Unix(r.SubmittedAt, 0).UTC()
+		if week := int(submitted.Sub(firstWeek).Hours() / (24 * 7)); !submitted.Before(firstWeek) && week < weeks {
+			trend[week].add(r.Score)
+		}
+	}
+
+	report := quizEntity.MasteryReport{
+		UserID:     userID,
+		Overall:    overall.result(),
+		Classes:    classes.results(),
+		Lessons:    lessons.results(),
+		Sets:       sets.results(),
+		Levels:     levels.results(),
+		Dimensions: dimensions.results(),
+		Trend:      make([]quizEntity.MasteryTrend, weeks),
+	}
+	for i := range trend {
+		s := trend[i].result()
+		report.Trend[i] = quizEntity.MasteryTrend{
+			WeekStart: firstWeek.AddDate(0, 0, 7*i).Format("2006-01-02"),
+			Answers:   s.Answers,
+			Accuracy:  s.Accuracy,
+		}
+	}
+	report.Weakest = weakest(report.Sets)
+	return report
+}
+
+// weakest lists the sets with enough answers and the lowest accuracy.
+func weakest(sets []quizEntity.MasteryStat) []quizEntity.MasteryStat {
+	var candidates []quizEntity.MasteryStat
+	for _, s := range sets {
+		if s.Answers >= WeakestMinAnswers && s.Accuracy < 100 {
+			candidates = append(candidates, s)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Accuracy != candidates[j].Accuracy {
+			return candidates[i].Accuracy < candidates[j].Accuracy
+		}
+		return candidates[i].Answers > candidates[j].Answers
+	})
+	if len(candidates) > WeakestLimit {
+		candidates = candidates[:WeakestLimit]
+	}
+	return candidates
+}
+
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func dimensionIndex(dimension string) int {
+	for i, d := range adaptive.Dimensions {
+		if strings.EqualFold(d, dimension) {
+			return i + 1
+		}
+	}
+	return len(adaptive.Dimensions) + 1
+}
@@ -0,0 +1,65 @@
+package mastery_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/mastery"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) // Thursday
+	thisWeek := time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC).Unix()
+	lastWeek := time.Date(2026, 10, 6, 9, 0, 0, 0, time.UTC).Unix()
+	longAgo := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC).Unix()
+
+	result := func(setID int, set, typ string, score float64, at int64) quizEntity.MasteryResult {
+		return quizEntity.MasteryResult{
+			ClassID: 1, ClassName: "Kelas 4", LessonID: 2, LessonName: "IPA",
+			SetID: setID, SetName: set, Type: typ, Score: score, SubmittedAt: at,
+		}
+	}
+	results := []quizEntity.MasteryResult{
+		result(10, "Tumbuhan", "c1_faktual", 1, thisWeek),
+		result(10, "Tumbuhan", "c1_faktual", 1, thisWeek),
+		result(10, "Tumbuhan", "c2_konseptual", 1, lastWeek),
+		result(11, "Hewan", "c1_faktual", 0, lastWeek),
+		result(11, "Hewan", "c3_prosedural", 0.5, lastWeek),
+		result(11, "Hewan", "c3_prosedural", 0, longAgo),
+	}
+
+	report := mastery.Build(7, results, now, 4)
+
+	assert.Equal(t, 7, report.UserID)
+	assert.Equal(t, 6, report.Overall.Answers)
+	assert.Equal(t, 3, report.Overall.Correct)
+	assert.Equal(t, 58.33, report.Overall.Accuracy)
+
+	assert.Len(t, report.Classes, 1)
+	assert.Len(t, report.Sets, 2)
+	assert.Equal(t, "IPA", report.Sets[0].Parent)
+
+	assert.Equal(t, []string{"C1", "C2", "C3"}, []string{report.Levels[0].Name, report.Levels[1].Name, report.Levels[2].Name})
+	assert.Equal(t, 66.67, report.Levels[0].Accuracy)
+	assert.Equal(t, "faktual", report.Dimensions[0].Name)
+	assert.Zero(t, report.Dimensions[0].ID)
+
+	assert.Len(t, report.Trend, 4)
+	assert.Equal(t, "2026-10-12", report.Trend[3].WeekStart)
+	assert.Equal(t, 2, report.Trend[3].Answers)
+	assert.Equal(t, 3, report.Trend[2].Answers)
+	assert.Equal(t, 0, report.Trend[0].Answers)
+
+	assert.Len(t, report.Weakest, 1)
+	assert.Equal(t, "Hewan", report.Weakest[0].Name)
+}
+
+func TestBuild_Empty(t *testing.T) {
+	report := mastery.Build(1, nil, time.Now(), 0)
+	assert.Len(t, report.Trend, mastery.DefaultWeeks)
+	assert.Empty(t, report.Weakest)
+	assert.Zero(t, report.Overall.Accuracy)
+}
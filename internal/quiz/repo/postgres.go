@@ -3,15 +3,21 @@ package repo
 import (
 	"database/sql"
 	"fmt"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghulammuzz/misterblast/helper"
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
+	"github.com/ghulammuzz/misterblast/internal/quiz/adaptive"
 	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/grader"
+	"github.com/ghulammuzz/misterblast/internal/quiz/leaderboard"
+	"github.com/ghulammuzz/misterblast/internal/quiz/shuffle"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/redis/go-redis/v9"
 )
 
 type QuizRepository interface {
@@ -21,6 +27,16 @@ type QuizRepository interface {
 	GetLast(userID int) (quizEntity.QuizExp, error)
 	GetSubmissionDetail(submissionId int) (quizEntity.QuizExp, error)
 	GetAvgTotal(userID int, filter map[string]string) (int, float64, error)
+	MasteryResults(userID int, filter map[string]string) ([]quizEntity.MasteryResult, error)
+	StartSession(setID, userID int, lang string) (quizEntity.QuizSession, error)
+	GetSession(sessionID, userID int) (quizEntity.QuizSession, error)
+	ListEssayReviews(filter map[string]string, page, limit int) (*response.PaginateResponse, error)
+	ScoreEssay(answerID, reviewerID int, req quizEntity.ScoreEssayRequest) (int, error)
+	PracticePool(userID int, filter map[string]string) ([]adaptive.Item, error)
+	PracticeQuestion(questionID int) (quizEntity.PracticeQuestion, error)
+	Leaderboard(board leaderboard.Board, userID, page, limit int) (quizEntity.Leaderboard, error)
+	SyncLeaderboards(submissionID int) error
+	SetLeaderboardOptOut(userID int, optOut bool) error
 }
 
 func (r *quizRepository) List(filter map[string]string, userID int) (*response.PaginateResponse, error) {
@@ -76,7 +92,7 @@ func (r *quizRepository) List(filter map[string]string, userID int) (*response.P
 	}
 
 	mainQuery := `
-		SELECT s.id, s.set_id, s.correct, s.grade, s.submitted_at,
+		SELECT s.id, s.set_id, s.correct, s.grade, s.status, s.submitted_at,
 			   l.name AS lesson_name, c.name AS class_name
 	` + baseQuery + " ORDER BY s.submitted_at DESC"
 
@@ -105,7 +121,7 @@ func (r *quizRepository) List(filter map[string]string, userID int) (*response.P
 		var submission quizEntity.ListQuizSubmission
 		err := rows.Scan(
 			&submission.ID, &submission.SetID, &submission.Correct,
-			&submission.Grade, &submission.SubmittedAt,
+			&submission.Grade, &submission.Status, &submission.SubmittedAt,
 			&submission.Lesson, &submission.Class,
 		)
 		if err != nil {
@@ -131,63 +147,50 @@ func (r *quizRepository) List(filter map[string]string, userID int) (*response.P
 	return paginateResp, nil
 }
 
-func (r *quizRepository) checkTotalQuestion(setID int, lang string) (int, error) {
-	var total int
-
-	query := `SELECT COUNT(*) FROM questions WHERE set_id = $1 and lang = $2`
-
-	err := r.db.QueryRow(query, setID, lang).Scan(&total)
-	if err != nil {
-		log.Error("[Repo][checkTotalQuestion] Error Exec: ", err)
-		return 0, app.NewAppError(500, err.Error())
-	}
-
-	return total, nil
-}
-
-func (r *quizRepository) checkCorrectAnswer(setID int) (string, error) {
-	var correctAnswers string
-
+func (r *quizRepository) loadAnswerKey(setID int, lang string) ([]grader.Question, error) {
 	query := `
-	SELECT STRING_AGG(a.code, '' ORDER BY q.number) AS correct_answers
+		SELECT q.id, q.number, q.format,
+			   COALESCE(a.code, ''), COALESCE(a.content, ''), COALESCE(a.is_answer, false)
 		FROM questions q
-		JOIN answers a ON q.id = a.question_id
-		WHERE q.set_id = $1 AND a.is_answer = true
-		`
+		LEFT JOIN answers a ON q.id = a.question_id
+		WHERE q.set_id = $1 AND q.lang = $2
+		ORDER BY q.number, a.code
+	`
 
-	err := r.db.QueryRow(query, setID).Scan(&correctAnswers)
+	rows, err := r.db.Query(query, setID, lang)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil
-		}
-		log.Error("[Repo][checkCorrectAnswer] Error Exec: ", err)
-		return "", app.NewAppError(500, err.Error())
-	}
-
-	return correctAnswers, nil
-}
-
-func (r *quizRepository) checkQuizScore(userAnswer, correctAnswer string, setID int, lang string) (int, int, error) {
-	totalQuestions, err := r.checkTotalQuestion(setID, lang)
-	if err != nil {
-		return 0, 0, err
+		log.Error("[Repo][loadAnswerKey] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to load answer key")
 	}
+	defer rows.Close()
 
-	if totalQuestions == 0 {
-		log.Error("[Repo][checkQuizScore] No questions found in this set")
-		return 0, 0, app.NewAppError(400, "no questions found in this set")
-	}
+	var key []grader.Question
+	index := make(map[int]int)
+	for rows.Next() {
+		var q grader.Question
+		var opt grader.Option
+		if err := rows.Scan(&q.ID, &q.Number, &q.Format, &opt.Code, &opt.Content, &opt.IsAnswer); err != nil {
+			log.Error("[Repo][loadAnswerKey] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan answer key")
+		}
 
-	correctCount := 0
-	for i := 0; i < len(userAnswer) && i < len(correctAnswer); i++ {
-		if userAnswer[i] == correctAnswer[i] {
-			correctCount++
+		i, ok := index[q.ID]
+		if !ok {
+			key = append(key, q)
+			i = len(key) - 1
+			index[q.ID] = i
+		}
+		if opt.Code != "" {
+			key[i].Options = append(key[i].Options, opt)
 		}
 	}
 
-	score := (correctCount * 100) / totalQuestions
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][loadAnswerKey] Error Rows: ", err)
+		return nil, app.NewAppError(500, "error while iterating answer key")
+	}
 
-	return score, correctCount, nil
+	return key, nil
 }
 
 func (r *quizRepository) getNextAttemptNo(setID int, userID int) (int, error) {
@@ -201,54 +204,202 @@ func (r *quizRepository) getNextAttemptNo(setID int, userID int) (int, error) {
 }
 
 func (r *quizRepository) Submit(req quizEntity.QuizSubmit, setID int, userID int, lang string) (int, error) {
-	correctAnswer, err := r.checkCorrectAnswer(setID)
-	if err != nil {
+	if err := r.requireEnrolled(setID, userID); err != nil {
 		return 0, err
 	}
 
-	totalQuestions, err := r.checkTotalQuestion(setID, lang)
+	key, err := r.loadAnswerKey(setID, lang)
 	if err != nil {
 		return 0, err
 	}
-	if len(req.Answers) != totalQuestions {
+
+	if len(key) == 0 {
+		log.Error("[Repo][Submit] No questions found in this set")
+		return 0, app.NewAppError(400, "no questions found in this set")
+	}
+	if len(req.Answers) != len(key) {
 		log.Error("[Repo][Submit] Invalid number of answers provided")
 		return 0, app.NewAppError(400, "invalid number of answers provided")
 	}
 
-	sort.Slice(req.Answers, func(i, j int) bool {
-		return req.Answers[i].Number < req.Answers[j].Number
-	})
-
-	var answers []string
-	for _, ans := range req.Answers {
-		answers = append(answers, ans.Answer)
+	if req.SessionID == 0 {
+		if err := r.requireUntimed(setID); err != nil {
+			return 0, err
+		}
 	}
-	answerStr := strings.Join(answers, "")
 
 	attemptNo, err := r.getNextAttemptNo(setID, userID)
 	if err != nil {
 		return 0, err
 	}
 
-	score, correctCount, err := r.checkQuizScore(answerStr, correctAnswer, setID, lang)
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][Submit] Error beginning transaction: ", err)
+		return 0, app.NewAppError(500, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	submittedAt := time.Now().Unix()
+	var seed int64
+	var sessionID, startedAt, timeTaken interface{}
+	if req.SessionID != 0 {
+		var started int64
+		started, seed, err = claimSession(tx, req.SessionID, setID, userID)
+		if err != nil {
+			if err == errSessionExpired {
+				tx.Rollback()
+				_ = r.expireSession(req.SessionID)
+			}
+			return 0, err
+		}
+		sessionID, startedAt, timeTaken = req.SessionID, started, submittedAt-started
+	}
+
+	answers, total, correctCount, err := gradeAnswers(key, req.Answers, seed)
 	if err != nil {
 		return 0, err
 	}
+	score := int(total * 100 / float64(len(key)))
+
+	status := submissionGraded
+	for _, ans := range answers {
+		if ans.Pending {
+			status = submissionPendingReview
+			break
+		}
+	}
 
 	var id int
-	query := "INSERT INTO quiz_submissions (answer, correct, grade, attempt_no, set_id, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
-	err = r.db.QueryRow(query, answerStr, correctCount, score, attemptNo, setID, userID).Scan(&id)
+	query := `
+		INSERT INTO quiz_submissions (correct, grade, attempt_no, set_id, user_id, session_id, started_at, time_taken, submitted_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	err = tx.QueryRow(query, correctCount, score, attemptNo, setID, userID, sessionID, startedAt, timeTaken, submittedAt, status).Scan(&id)
 	if err != nil {
 		log.Error("[Repo][Submit] Error Exec: ", err)
 		return 0, app.NewAppError(500, err.Error())
 	}
+
+	var values []string
+	var args []interface{}
+	argIdx := 1
+	for _, ans := range answers {
+		var timeSpent interface{}
+		if ans.TimeSpent > 0 {
+			timeSpent = ans.TimeSpent
+		}
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", argIdx, argIdx+1, argIdx+2, argIdx+3, argIdx+4, argIdx+5, argIdx+6, argIdx+7))
+		args = append(args, id, ans.QuestionID, ans.Number, ans.Answer, ans.Score, ans.IsCorrect, timeSpent, ans.Pending)
+		argIdx += 8
+	}
+
+	answerQuery := "INSERT INTO quiz_submission_answers (submission_id, question_id, number, answer, score, is_correct, time_spent, pending) VALUES " + strings.Join(values, ", ")
+	if _, err := tx.Exec(answerQuery, args...); err != nil {
+		log.Error("[Repo][Submit] Error inserting answers: ", err)
+		return 0, app.NewAppError(500, "failed to store quiz answers")
+	}
+
+	if req.SessionID != 0 {
+		if err := finishSession(tx, req.SessionID, submittedAt); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][Submit] Error committing transaction: ", err)
+		return 0, app.NewAppError(500, "failed to commit quiz submission")
+	}
 	return id, nil
 }
 
+// gradeAnswers grades every question of the answer key. Option codes of
+// shuffled attempts are mapped back to the canonical codes first, and the
+// canonical answer is what gets stored.
+func gradeAnswers(key []grader.Question, given []quizEntity.AnswersQuizSubmit, seed int64) ([]quizEntity.QuizSubmissionAnswer, float64, int, error) {
+	submitted := make(map[int]quizEntity.AnswersQuizSubmit, len(given))
+	for _, ans := range given {
+		submitted[ans.Number] = ans
+	}
+
+	var answers []quizEntity.QuizSubmissionAnswer
+	var total float64
+	correctCount := 0
+	for _, q := range key {
+		answer := submitted[q.Number]
+		if seed != 0 && shuffle.Shuffles(q.Format) {
+			answer.Answer = shuffle.NewMapping(seed, q.ID, optionCodes(q.Options)).CanonicalAnswer(answer.Answer)
+		}
+
+		result, err := grader.Grade(q, answer.Answer)
+		if err != nil {
+			log.Error("[Repo][Submit] Error Grade: ", err)
+			return nil, 0, 0, app.NewAppError(400, err.Error())
+		}
+
+		total += result.Score
+		if result.Correct {
+			correctCount++
+		}
+		answers = append(answers, quizEntity.QuizSubmissionAnswer{
+			QuestionID: q.ID,
+			Number:     q.Number,
+			Answer:     answer.Answer,
+			Score:      result.Score,
+			IsCorrect:  result.Correct,
+			Pending:    result.Pending,
+			TimeSpent:  answer.TimeSpent,
+		})
+	}
+	return answers, total, correctCount, nil
+}
+
+func optionCodes(options []grader.Option) []string {
+	codes := make([]string, len(options))
+	for i, o := range options {
+		codes[i] = o.Code
+	}
+	return codes
+}
+
+// requireUntimed rejects submissions without a session on sets that have a
+// time limit, since the server could not tell when the student started.
+// requireEnrolled hides a set outside the classrooms the user is enrolled in,
+// as the set listing does.
+func (r *quizRepository) requireEnrolled(setID, userID int) error {
+	var allowed bool
+	query := `SELECT EXISTS (SELECT 1 FROM sets s WHERE s.id = $1 AND ` + enrollment.ClassFilter("s.class_id", 2) + `)`
+	if err := r.db.QueryRow(query, setID, userID).Scan(&allowed); err != nil {
+		log.Error("[Repo][requireEnrolled] Error QueryRow: ", err)
+		return app.NewAppError(500, "failed to check enrollment")
+	}
+	if !allowed {
+		return app.NewAppError(404, "set not found")
+	}
+	return nil
+}
+
+func (r *quizRepository) requireUntimed(setID int) error {
+	var duration int
+	if err := r.db.QueryRow(`SELECT duration_seconds FROM sets WHERE id = $1`, setID).Scan(&duration); err != nil {
+		if err == sql.ErrNoRows {
+			return app.NewAppError(404, "set not found")
+		}
+		log.Error("[Repo][requireUntimed] Error QueryRow: ", err)
+		return app.NewAppError(500, "failed to get set")
+	}
+	if duration > 0 {
+		return app.NewAppError(400, "this quiz is timed, start a quiz session first")
+	}
+	return nil
+}
+
 type quizRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	redis *redis.Client
 }
 
-func NewQuizRepository(db *sql.DB) QuizRepository {
-	return &quizRepository{db: db}
+func NewQuizRepository(db *sql.DB, redis *redis.Client) QuizRepository {
+	return &quizRepository{db: db, redis: redis}
 }
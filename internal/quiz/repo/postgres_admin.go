@@ -13,7 +13,7 @@ import (
 
 func (r *quizRepository) ListAdmin(filter map[string]string, page, limit int) (*response.PaginateResponse, error) {
 	query := `
-		SELECT s.id, s.set_id, s.correct, s.grade, s.submitted_at,
+		SELECT s.id, s.set_id, s.correct, s.grade, s.status, s.submitted_at,
 			   u.name AS user_name,
 			   l.name AS lesson_name, c.name AS class_name
 		FROM quiz_submissions s
@@ -108,7 +108,7 @@ func (r *quizRepository) ListAdmin(filter map[string]string, page, limit int) (*
 		var submission quizEntity.ListQuizSubmissionAdmin
 		err := rows.Scan(
 			&submission.ID, &submission.SetID, &submission.Correct,
-			&submission.Grade, &submission.SubmittedAt,
+			&submission.Grade, &submission.Status, &submission.SubmittedAt,
 			&submission.Name, &submission.Lesson, &submission.Class,
 		)
 		if err != nil {
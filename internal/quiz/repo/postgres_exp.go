@@ -3,8 +3,11 @@ package repo
 import (
 	"database/sql"
 	"errors"
+	"strings"
 
 	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/grader"
+	"github.com/ghulammuzz/misterblast/internal/quiz/shuffle"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
@@ -12,18 +15,16 @@ import (
 )
 
 func (r *quizRepository) GetLast(userID int) (quizEntity.QuizExp, error) {
-	var quiz quizEntity.QuizExp
+	var submissionID int
 
 	query := `
-		SELECT id, answer, correct, grade, attempt_no, submitted_at, set_id
+		SELECT id
 		FROM quiz_submissions
 		WHERE user_id = $1
 		ORDER BY submitted_at DESC
 		LIMIT 1
 	`
-	var correct, attemptNo, setID int
-	var answer string
-	if err := r.db.QueryRow(query, userID).Scan(&quiz.ID, &answer, &correct, &quiz.Grade, &attemptNo, &quiz.SubmittedAt, &setID); err != nil {
+	if err := r.db.QueryRow(query, userID).Scan(&submissionID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return quizEntity.QuizExp{}, nil
 		}
@@ -31,208 +32,183 @@ func (r *quizRepository) GetLast(userID int) (quizEntity.QuizExp, error) {
 		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get last quiz submission")
 	}
 
-	var totalQuestions int
-	if err := r.db.QueryRow("SELECT COUNT(*) FROM questions WHERE set_id = $1", setID).Scan(&totalQuestions); err != nil {
-		log.Error("[quizRepo.GetLast] failed to get total questions", err.Error())
-		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get total questions")
-	}
-
-	// log.Debug("total questions: ", totalQuestions)
-	// log.Debug("correct: ", correct)
-	quiz.Correct = correct
-	quiz.Wrong = totalQuestions - correct
-	// log.Debug("wrong: ", quiz.Wrong)
-	quiz.AttemptNo = attemptNo
-
-	questionsQuery := `
-		SELECT id, number, content, format, explanation, reasoning
-		FROM questions
-		WHERE set_id = $1
-		ORDER BY number ASC
-	`
-	rows, err := r.db.Query(questionsQuery, setID)
-	if err != nil {
-		log.Error("[quizRepo.GetLast] failed to get questions", err.Error())
-		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get questions")
-	}
-	defer rows.Close()
-
-	var questions []quizEntity.QuizExpObj
-	questionMap := make(map[int]*quizEntity.QuizExpObj)
-	questionIDs := []int{}
-
-	for rows.Next() {
-		var q quizEntity.QuizExpObj
-		var questionID int
-		if err := rows.Scan(&questionID, &q.Number, &q.QuestionContent, &q.Format, &q.Explanation, &q.Reason); err != nil {
-			log.Error("[quizRepo.GetLast] failed to scan questions", err.Error())
-			return quizEntity.QuizExp{}, app.NewAppError(500, "failed to scan questions")
-		}
-		questionMap[questionID] = &q
-		questionIDs = append(questionIDs, questionID)
-	}
-
-	answersQuery := `
-		SELECT question_id, code, content
-		FROM answers
-		WHERE question_id = ANY($1)
-		AND is_answer = TRUE
-	`
-	ansRows, err := r.db.Query(answersQuery, pq.Array(questionIDs))
-	if err != nil {
-		log.Error("[quizRepo.GetLast] failed to get answers", err.Error())
-		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get answers")
-	}
-	defer ansRows.Close()
-
-	actualAnswers := make(map[int]quizEntity.QuizExpObj)
-	for ansRows.Next() {
-		var questionID int
-		var code, content string
-		if err := ansRows.Scan(&questionID, &code, &content); err != nil {
-			log.Error("[quizRepo.GetLast] failed to scan answers", err.Error())
-			return quizEntity.QuizExp{}, app.NewAppError(500, "failed to scan answers")
-		}
-		actualAnswers[questionID] = quizEntity.QuizExpObj{
-			ActualCode:    code,
-			ActualContent: content,
-		}
-	}
-
-	userAnswers := []rune(answer)
-	for i, questionID := range questionIDs {
-		if i >= len(userAnswers) {
-			break
-		}
-		q := questionMap[questionID]
-		actualAns := actualAnswers[questionID]
-		q.UserCode = string(userAnswers[i])
-		q.ActualCode = actualAns.ActualCode
-
-		var userContent string
-		userAnswerQuery := `
-			SELECT content FROM answers 
-			WHERE question_id = $1 AND code = $2
-		`
-		if err := r.db.QueryRow(userAnswerQuery, questionID, q.UserCode).Scan(&userContent); err != nil {
-			userContent = ""
-		}
-		q.UserContent = userContent
-		q.ActualContent = actualAns.ActualContent
-		q.IsCorrect = q.UserCode == q.ActualCode
-		questions = append(questions, *q)
-	}
-
-	quiz.Answers = questions
-	return quiz, nil
+	return r.GetSubmissionDetail(submissionID)
 }
 
 func (r *quizRepository) GetSubmissionDetail(submissionId int) (quizEntity.QuizExp, error) {
 	var qr quizEntity.QuizExp
 
 	query := `
-		SELECT qs.id, qs.answer, qs.correct, qs.grade, qs.attempt_no, qs.submitted_at, qs.set_id, l.code
+		SELECT qs.id, qs.user_id, qs.correct, qs.grade, qs.attempt_no, qs.status, qs.submitted_at, qs.started_at, qs.time_taken, l.code,
+			   COALESCE(ss.seed, 0)
 		from quiz_submissions qs
 		inner join sets s on qs.set_id = s.id
 		inner join lessons l on s.lesson_id = l.id
+		left join quiz_sessions ss on qs.session_id = ss.id
 		WHERE qs.id = $1;
 	`
 
-	var correct, attempNo, setID int
-	var answer string
-	if err := r.db.QueryRow(query, submissionId).Scan(&qr.ID, &answer, &correct, &qr.Grade, &attempNo, &qr.SubmittedAt, &setID, &qr.Lesson); err != nil {
+	var startedAt, timeTaken sql.NullInt64
+	var seed int64
+	if err := r.db.QueryRow(query, submissionId).Scan(&qr.ID, &qr.UserID, &qr.Correct, &qr.Grade, &qr.AttemptNo, &qr.Status, &qr.SubmittedAt, &startedAt, &timeTaken, &qr.Lesson, &seed); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return quizEntity.QuizExp{}, app.NewAppError(404, "quiz submission not found")
 		}
+		log.Error("[quizRepo.GetSubmissionDetail] failed to get quiz submission", err.Error())
+		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get quiz submission")
+	}
+
+	answers, err := r.explainAnswers(submissionId, seed)
+	if err != nil {
+		return quizEntity.QuizExp{}, err
+	}
+
+	if startedAt.Valid {
+		qr.StartedAt = &startedAt.Int64
 	}
-	var totalQuestions int
-	if err := r.db.QueryRow("SELECT COUNT(*) from questions q where q.set_id = $1", setID).Scan(&totalQuestions); err != nil {
-		log.Error("[quizRepo.GetSubmissionDetail] failed to get total questions", err.Error())
-		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get total questions")
+	if timeTaken.Valid {
+		qr.TimeTaken = &timeTaken.Int64
 	}
-	qr.Correct = correct
-	qr.Wrong = totalQuestions - correct
-	qr.AttemptNo = attempNo
-
-	questionsQuery := `
-		SELECT id, number, content, format, explanation, reasoning
-		FROM questions
-		WHERE set_id = $1
-		ORDER BY number ASC
+	qr.Answers = answers
+	qr.Wrong = len(answers) - qr.Correct
+	return qr, nil
+}
+
+// explainAnswers rebuilds the per question explanation of a submission from
+// its stored answers and the current answer key. Codes are shown as the
+// student saw them in a shuffled attempt.
+func (r *quizRepository) explainAnswers(submissionID int, seed int64) ([]quizEntity.QuizExpObj, error) {
+	query := `
+		SELECT q.id, sa.number, q.content, q.format, q.explanation, q.reasoning,
+			   sa.answer, sa.score, sa.is_correct, sa.time_spent, sa.pending, sa.feedback
+		FROM quiz_submission_answers sa
+		JOIN questions q ON q.id = sa.question_id
+		WHERE sa.submission_id = $1
+		ORDER BY sa.number ASC
 	`
-	rows, err := r.db.Query(questionsQuery, setID)
+	rows, err := r.db.Query(query, submissionID)
 	if err != nil {
-		log.Error("[quizRepo.GetLast] failed to get questions", err.Error())
-		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get questions")
+		log.Error("[quizRepo.explainAnswers] failed to get answers", err.Error())
+		return nil, app.NewAppError(500, "failed to get submission answers")
 	}
 	defer rows.Close()
 
-	var questions []quizEntity.QuizExpObj
-	questionMap := make(map[int]*quizEntity.QuizExpObj)
-	questionIDs := []int{}
-
+	var answers []quizEntity.QuizExpObj
+	var questionIDs []int
 	for rows.Next() {
-		var q quizEntity.QuizExpObj
+		var a quizEntity.QuizExpObj
 		var questionID int
-		if err := rows.Scan(&questionID, &q.Number, &q.QuestionContent, &q.Format, &q.Explanation, &q.Reason); err != nil {
-			log.Error("[quizRepo.GetLast] failed to scan questions", err.Error())
-			return quizEntity.QuizExp{}, app.NewAppError(500, "failed to scan questions")
+		var timeSpent sql.NullInt32
+		var feedback sql.NullString
+		if err := rows.Scan(&questionID, &a.Number, &a.QuestionContent, &a.Format, &a.Explanation, &a.Reason, &a.UserCode, &a.Score, &a.IsCorrect, &timeSpent, &a.Pending, &feedback); err != nil {
+			log.Error("[quizRepo.explainAnswers] failed to scan answers", err.Error())
+			return nil, app.NewAppError(500, "failed to scan submission answers")
 		}
-		questionMap[questionID] = &q
+		if timeSpent.Valid {
+			spent := int(timeSpent.Int32)
+			a.TimeSpent = &spent
+		}
+		if feedback.Valid {
+			a.Feedback = &feedback.String
+		}
+		answers = append(answers, a)
 		questionIDs = append(questionIDs, questionID)
 	}
+	if err := rows.Err(); err != nil {
+		log.Error("[quizRepo.explainAnswers] failed to iterate answers", err.Error())
+		return nil, app.NewAppError(500, "failed to read submission answers")
+	}
+
+	options, err := r.questionOptions(questionIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	answersQuery := `
-		SELECT question_id, code, content
+	for i := range answers {
+		describeAnswer(&answers[i], options[questionIDs[i]])
+		if seed != 0 && shuffle.Shuffles(answers[i].Format) {
+			mapping := shuffle.NewMapping(seed, questionIDs[i], optionCodes(options[questionIDs[i]]))
+			answers[i].UserCode = mapping.DisplayAnswer(answers[i].UserCode)
+			answers[i].ActualCode = mapping.DisplayAnswer(answers[i].ActualCode)
+		}
+	}
+	return answers, nil
+}
+
+func (r *quizRepository) questionOptions(questionIDs []int) (map[int][]grader.Option, error) {
+	options := make(map[int][]grader.Option)
+	if len(questionIDs) == 0 {
+		return options, nil
+	}
+
+	query := `
+		SELECT question_id, code, content, is_answer
 		FROM answers
 		WHERE question_id = ANY($1)
-		AND is_answer = TRUE
+		ORDER BY question_id, code
 	`
-	ansRows, err := r.db.Query(answersQuery, pq.Array(questionIDs))
+	rows, err := r.db.Query(query, pq.Array(questionIDs))
 	if err != nil {
-		log.Error("[quizRepo.GetLast] failed to get answers", err.Error())
-		return quizEntity.QuizExp{}, app.NewAppError(500, "failed to get answers")
+		log.Error("[quizRepo.questionOptions] failed to get options", err.Error())
+		return nil, app.NewAppError(500, "failed to get answers")
 	}
-	defer ansRows.Close()
+	defer rows.Close()
 
-	actualAnswers := make(map[int]quizEntity.QuizExpObj)
-	for ansRows.Next() {
+	for rows.Next() {
 		var questionID int
-		var code, content string
-		if err := ansRows.Scan(&questionID, &code, &content); err != nil {
-			log.Error("[quizRepo.GetLast] failed to scan answers", err.Error())
-			return quizEntity.QuizExp{}, app.NewAppError(500, "failed to scan answers")
-		}
-		actualAnswers[questionID] = quizEntity.QuizExpObj{
-			ActualCode:    code,
-			ActualContent: content,
+		var o grader.Option
+		if err := rows.Scan(&questionID, &o.Code, &o.Content, &o.IsAnswer); err != nil {
+			log.Error("[quizRepo.questionOptions] failed to scan options", err.Error())
+			return nil, app.NewAppError(500, "failed to scan answers")
 		}
+		options[questionID] = append(options[questionID], o)
+	}
+	return options, rows.Err()
+}
+
+// describeAnswer fills the user and actual code/content of a in the shape of
+// its question format. UserCode must already hold the raw stored answer.
+func describeAnswer(a *quizEntity.QuizExpObj, options []grader.Option) {
+	contentByCode := make(map[string]string, len(options))
+	for _, o := range options {
+		contentByCode[strings.ToLower(o.Code)] = o.Content
 	}
 
-	userAnswers := []rune(answer)
-	for i, questionID := range questionIDs {
-		if i >= len(userAnswers) {
-			break
+	switch a.Format {
+	case "mm":
+		var pairs []string
+		for _, o := range options {
+			pairs = append(pairs, o.Code+":"+o.Content)
+		}
+		a.ActualCode = strings.Join(pairs, ";")
+		a.ActualContent = a.ActualCode
+		a.UserContent = a.UserCode
+	case "sa", "essay":
+		var accepted []string
+		for _, o := range options {
+			if o.IsAnswer {
+				accepted = append(accepted, o.Content)
+			}
 		}
-		q := questionMap[questionID]
-		actualAns := actualAnswers[questionID]
-		q.UserCode = string(userAnswers[i])
-		q.ActualCode = actualAns.ActualCode
-
-		var userContent string
-		userAnswerQuery := `
-			SELECT content FROM answers 
-			WHERE question_id = $1 AND code = $2
-		`
-		if err := r.db.QueryRow(userAnswerQuery, questionID, q.UserCode).Scan(&userContent); err != nil {
-			userContent = ""
+		a.ActualContent = strings.Join(accepted, ", ")
+		a.UserContent = a.UserCode
+	default:
+		var codes, contents []string
+		for _, o := range options {
+			if o.IsAnswer {
+				codes = append(codes, o.Code)
+				contents = append(contents, o.Content)
+			}
 		}
-		q.UserContent = userContent
-		q.ActualContent = actualAns.ActualContent
-		q.IsCorrect = q.UserCode == q.ActualCode
-		questions = append(questions, *q)
+		a.ActualCode = strings.Join(codes, ",")
+		a.ActualContent = strings.Join(contents, ", ")
+
+		var picked []string
+		for _, code := range grader.SplitAnswer(a.UserCode) {
+			if content, ok := contentByCode[strings.ToLower(code)]; ok {
+				picked = append(picked, content)
+			}
+		}
+		a.UserContent = strings.Join(picked, ", ")
 	}
-
-	qr.Answers = questions
-	return qr, nil
 }
@@ -0,0 +1,382 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	cache "github.com/ghulammuzz/misterblast/config/redis"
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/leaderboard"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+// boardQuery sums the best graded score per set of every user on the board.
+// Users who opted out are left off.
+func boardQuery(board leaderboard.Board, now time.Time) (string, []interface{}) {
+	where := " WHERE s.status = $1 AND NOT u.leaderboard_opt_out"
+	args := []interface{}{submissionGraded}
+
+	column := map[leaderboard.Scope]string{
+		leaderboard.ScopeSet:    "s.set_id",
+		leaderboard.ScopeLesson: "a.lesson_id",
+		leaderboard.ScopeClass:  "a.class_id",
+	}[board.Scope]
+	if column != "" {
+		args = append(args, board.ID)
+		where += fmt.Sprintf(" AND %s = $%d", column, len(args))
+	}
+	if board.Period == leaderboard.PeriodWeek {
+		args = append(args, leaderboard.WeekStart(now).Unix())
+		where += fmt.Sprintf(" AND s.submitted_at >= $%d", len(args))
+	}
+
+	query := `
+		SELECT b.user_id, SUM(b.best) AS score
+		FROM (
+			SELECT s.user_id, s.set_id, MAX(s.grade) AS best
+			FROM quiz_submissions s
+			JOIN sets a ON a.id = s.set_id
+			JOIN users u ON u.id = s.user_id` + where + `
+			GROUP BY s.user_id, s.set_id
+		) b
+		GROUP BY b.user_id`
+	return query, args
+}
+
+// Leaderboard returns one page of the board and the caller's own entry. The
+// Redis sorted set is rebuilt from Postgres when it is missing, and Postgres
+// answers directly when Redis is not available.
+func (r *quizRepository) Leaderboard(board leaderboard.Board, userID, page, limit int) (quizEntity.Leaderboard, error) {
+	result := quizEntity.Leaderboard{
+		Scope:   string(board.Scope),
+		ScopeID: board.ID,
+		Period:  string(board.Period),
+		Page:    page,
+		Limit:   limit,
+		Entries: []quizEntity.LeaderboardEntry{},
+	}
+
+	if err := r.db.QueryRow(`SELECT leaderboard_opt_out FROM users WHERE id = $1`, userID).Scan(&result.OptOut); err != nil {
+		if err == sql.ErrNoRows {
+			return result, app.NewAppError(404, "user not found")
+		}
+		log.Error("[Repo][Leaderboard] Error Query opt-out: ", err)
+		return result, app.NewAppError(500, "failed to get leaderboard")
+	}
+
+	if r.redis != nil {
+		err := r.leaderboardFromRedis(board, userID, &result)
+		if err == nil {
+			return result, nil
+		}
+		log.Warn("[Repo][Leaderboard] Redis error, falling back to Postgres: ", err)
+		result.Entries = []quizEntity.LeaderboardEntry{}
+		result.Me = nil
+	}
+
+	if err := r.leaderboardFromPostgres(board, userID, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *quizRepository) leaderboardFromRedis(board leaderboard.Board, userID int, result *quizEntity.Leaderboard) error {
+	ctx := context.Background()
+	key := leaderboard.Key(board.Scope, board.ID, board.Period, time.Now())
+
+	built, err := cache.SortedSetBuilt(ctx, []string{key}, r.redis)
+	if err != nil {
+		return err
+	}
+	if !built[0] {
+		if err := r.rebuildLeaderboard(ctx, board, key); err != nil {
+			return err
+		}
+	}
+
+	total, err := r.redis.ZCard(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	result.Total = total
+
+	start := int64((result.Page - 1) * result.Limit)
+	members, err := r.redis.ZRevRangeWithScores(ctx, key, start, start+int64(result.Limit)-1).Result()
+	if err != nil {
+		return err
+	}
+
+	ids := []int64{int64(userID)}
+	for i, z := range members {
+		id, err := strconv.Atoi(z.Member.(string))
+		if err != nil {
+			return fmt.Errorf("invalid leaderboard member %v", z.Member)
+		}
+		ids = append(ids, int64(id))
+		result.Entries = append(result.Entries, quizEntity.LeaderboardEntry{
+			Rank:   start + int64(i) + 1,
+			UserID: id,
+			Score:  int64(z.Score),
+		})
+	}
+
+	if !result.OptOut {
+		member := strconv.Itoa(userID)
+		rank, err := r.redis.ZRevRank(ctx, key, member).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			score, err := r.redis.ZScore(ctx, key, member).Result()
+			if err != nil {
+				return err
+			}
+			result.Me = &quizEntity.LeaderboardEntry{Rank: rank + 1, UserID: userID, Score: int64(score)}
+		}
+	}
+
+	return r.fillLeaderboardUsers(ids, result)
+}
+
+func (r *quizRepository) rebuildLeaderboard(ctx context.Context, board leaderboard.Board, key string) error {
+	query, args := boardQuery(board, time.Now())
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][rebuildLeaderboard] Error Query: ", err)
+		return app.NewAppError(500, "failed to build leaderboard")
+	}
+	defer rows.Close()
+
+	var members []redis.Z
+	for rows.Next() {
+		var id, score int64
+		if err := rows.Scan(&id, &score); err != nil {
+			log.Error("[Repo][rebuildLeaderboard] Error Scan: ", err)
+			return app.NewAppError(500, "failed to build leaderboard")
+		}
+		members = append(members, redis.Z{Score: float64(score), Member: strconv.FormatInt(id, 10)})
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][rebuildLeaderboard] Error Rows: ", err)
+		return app.NewAppError(500, "failed to build leaderboard")
+	}
+
+	return cache.ReplaceSortedSet(ctx, key, members, cache.LongEXP, r.redis)
+}
+
+// fillLeaderboardUsers adds the names and pictures of the ranked users.
+func (r *quizRepository) fillLeaderboardUsers(ids []int64, result *quizEntity.Leaderboard) error {
+	rows, err := r.db.Query(`SELECT id, name, img_url FROM users WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		log.Error("[Repo][fillLeaderboardUsers] Error Query: ", err)
+		return app.NewAppError(500, "failed to get leaderboard users")
+	}
+	defer rows.Close()
+
+	type user struct {
+		name   string
+		imgURL *string
+	}
+	users := map[int]user{}
+	for rows.Next() {
+		var id int
+		var u user
+		if err := rows.Scan(&id, &u.name, &u.imgURL); err != nil {
+			log.Error("[Repo][fillLeaderboardUsers] Error Scan: ", err)
+			return app.NewAppError(500, "failed to get leaderboard users")
+		}
+		users[id] = u
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][fillLeaderboardUsers] Error Rows: ", err)
+		return app.NewAppError(500, "failed to get leaderboard users")
+	}
+
+	for i := range result.Entries {
+		u := users[result.Entries[i].UserID]
+		result.Entries[i].Name, result.Entries[i].ImgURL = u.name, u.imgURL
+	}
+	if result.Me != nil {
+		u := users[result.Me.UserID]
+		result.Me.Name, result.Me.ImgURL = u.name, u.imgURL
+	}
+	return nil
+}
+
+func (r *quizRepository) leaderboardFromPostgres(board leaderboard.Board, userID int, result *quizEntity.Leaderboard) error {
+	query, args := boardQuery(board, time.Now())
+
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM (`+query+`) board`, args...).Scan(&result.Total); err != nil {
+		log.Error("[Repo][leaderboardFromPostgres] Error Count Query: ", err)
+		return app.NewAppError(500, "failed to count leaderboard")
+	}
+
+	// Ties are broken like a Redis sorted set in reverse order, by the user id
+	// as text, so both sources rank the same way.
+	ranked := `
+		WITH board AS (` + query + `),
+		ranked AS (
+			SELECT user_id, score, ROW_NUMBER() OVER (ORDER BY score DESC, user_id::text DESC) AS rank
+			FROM board
+		)
+		SELECT r.rank, r.user_id, u.name, u.img_url, r.score
+		FROM ranked r
+		JOIN users u ON u.id = r.user_id`
+
+	pageArgs := append(append([]interface{}{}, args...), result.Limit, (result.Page-1)*result.Limit)
+	rows, err := r.db.Query(ranked+fmt.Sprintf(" ORDER BY r.rank LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2), pageArgs...)
+	if err != nil {
+		log.Error("[Repo][leaderboardFromPostgres] Error Query: ", err)
+		return app.NewAppError(500, "failed to get leaderboard")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e quizEntity.LeaderboardEntry
+		if err := rows.Scan(&e.Rank, &e.UserID, &e.Name, &e.ImgURL, &e.Score); err != nil {
+			log.Error("[Repo][leaderboardFromPostgres] Error Scan: ", err)
+			return app.NewAppError(500, "failed to scan leaderboard")
+		}
+		result.Entries = append(result.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][leaderboardFromPostgres] Error Rows: ", err)
+		return app.NewAppError(500, "failed to read leaderboard")
+	}
+
+	if result.OptOut {
+		return nil
+	}
+
+	var me quizEntity.LeaderboardEntry
+	meArgs := append(append([]interface{}{}, args...), userID)
+	err = r.db.QueryRow(ranked+fmt.Sprintf(" WHERE r.user_id = $%d", len(args)+1), meArgs...).
+		Scan(&me.Rank, &me.UserID, &me.Name, &me.ImgURL, &me.Score)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error("[Repo][leaderboardFromPostgres] Error Query rank: ", err)
+		return app.NewAppError(500, "failed to get leaderboard rank")
+	}
+	if err == nil {
+		result.Me = &me
+	}
+	return nil
+}
+
+// SyncLeaderboards writes the submitting user's current score to every built
+// board the submission counts towards. Scores are recomputed from Postgres
+// rather than incremented, so resubmits and essay rescoring never count twice.
+// Boards that are not in Redis are skipped; they are built on the next read.
+func (r *quizRepository) SyncLeaderboards(submissionID int) error {
+	if r.redis == nil {
+		return nil
+	}
+	ctx := context.Background()
+
+	var userID, setID, lessonID, classID int
+	var optOut bool
+	err := r.db.QueryRow(`
+		SELECT s.user_id, s.set_id, a.lesson_id, a.class_id, u.leaderboard_opt_out
+		FROM quiz_submissions s
+		JOIN sets a ON a.id = s.set_id
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1
+	`, submissionID).Scan(&userID, &setID, &lessonID, &classID, &optOut)
+	if err != nil {
+		log.Error("[Repo][SyncLeaderboards] Error Query submission: ", err)
+		return app.NewAppError(500, "failed to get quiz submission")
+	}
+	if optOut {
+		return nil
+	}
+
+	now := time.Now()
+	boards := leaderboard.Boards(setID, lessonID, classID)
+	keys := make([]string, len(boards))
+	for i, b := range boards {
+		keys[i] = leaderboard.Key(b.Scope, b.ID, b.Period, now)
+	}
+	built, err := cache.SortedSetBuilt(ctx, keys, r.redis)
+	if err != nil {
+		return err
+	}
+	anyBuilt := false
+	for _, b := range built {
+		anyBuilt = anyBuilt || b
+	}
+	if !anyBuilt {
+		return nil
+	}
+
+	// One score per board, in the order of leaderboard.Boards.
+	scores := make([]sql.NullInt64, len(boards))
+	err = r.db.QueryRow(`
+		WITH best AS (
+			SELECT s.set_id, a.lesson_id, a.class_id,
+			       MAX(s.grade) AS best_all,
+			       MAX(s.grade) FILTER (WHERE s.submitted_at >= $3) AS best_week
+			FROM quiz_submissions s
+			JOIN sets a ON a.id = s.set_id
+			WHERE s.user_id = $1 AND s.status = $2
+			GROUP BY s.set_id, a.lesson_id, a.class_id
+		)
+		SELECT SUM(best_all) FILTER (WHERE set_id = $4),
+		       SUM(best_all) FILTER (WHERE lesson_id = $5),
+		       SUM(best_all) FILTER (WHERE class_id = $6),
+		       SUM(best_all),
+		       SUM(best_week) FILTER (WHERE set_id = $4),
+		       SUM(best_week) FILTER (WHERE lesson_id = $5),
+		       SUM(best_week) FILTER (WHERE class_id = $6),
+		       SUM(best_week)
+		FROM best
+	`, userID, submissionGraded, leaderboard.WeekStart(now).Unix(), setID, lessonID, classID).
+		Scan(&scores[0], &scores[1], &scores[2], &scores[3], &scores[4], &scores[5], &scores[6], &scores[7])
+	if err != nil {
+		log.Error("[Repo][SyncLeaderboards] Error Query scores: ", err)
+		return app.NewAppError(500, "failed to compute leaderboard scores")
+	}
+
+	member := strconv.Itoa(userID)
+	pipe := r.redis.Pipeline()
+	for i, key := range keys {
+		if !built[i] {
+			continue
+		}
+		if scores[i].Valid {
+			pipe.ZAdd(ctx, key, redis.Z{Score: float64(scores[i].Int64), Member: member})
+			// A board built empty had no key to expire; this add created it.
+			pipe.Expire(ctx, key, cache.LongEXP)
+		} else {
+			pipe.ZRem(ctx, key, member)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update leaderboards: %w", err)
+	}
+	return nil
+}
+
+// SetLeaderboardOptOut stores the user's choice and drops every cached board,
+// so they are rebuilt with or without the user.
+func (r *quizRepository) SetLeaderboardOptOut(userID int, optOut bool) error {
+	res, err := r.db.Exec(`UPDATE users SET leaderboard_opt_out = $2, updated_at = EXTRACT(EPOCH FROM NOW()) WHERE id = $1`, userID, optOut)
+	if err != nil {
+		log.Error("[Repo][SetLeaderboardOptOut] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update leaderboard preference")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "user not found")
+	}
+
+	if r.redis != nil {
+		if err := cache.DeleteSortedSets(context.Background(), "leaderboard:*", r.redis); err != nil {
+			log.Warn("[Repo][SetLeaderboardOptOut] Failed to drop cached leaderboards: ", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/adaptive"
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+// PracticePool returns the questions in scope of the filter (set_id,
+// lesson_id or class_id, and lang) with the user's graded quiz history on
+// each of them.
+func (r *quizRepository) PracticePool(userID int, filter map[string]string) ([]adaptive.Item, error) {
+	query := `
+		SELECT q.id, q.type, COUNT(sa.id), COALESCE(SUM(sa.score), 0)
+		FROM questions q
+		JOIN sets s ON s.id = q.set_id
+		LEFT JOIN quiz_submission_answers sa ON sa.question_id = q.id AND NOT sa.pending
+			AND sa.submission_id IN (SELECT id FROM quiz_submissions WHERE user_id = $1)
+		WHERE q.deleted_at IS NULL AND q.lang = $2
+	`
+	args := []interface{}{userID, filter["lang"]}
+	for _, key := range []string{"set_id", "lesson_id", "class_id"} {
+		if val, exists := filter[key]; exists {
+			column := map[string]string{
+				"set_id":    "s.id",
+				"lesson_id": "s.lesson_id",
+				"class_id":  "s.class_id",
+			}[key]
+			args = append(args, val)
+			query += fmt.Sprintf(" AND %s = $%d", column, len(args))
+		}
+	}
+	query += " GROUP BY q.id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][PracticePool] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to load practice questions")
+	}
+	defer rows.Close()
+
+	var items []adaptive.Item
+	for rows.Next() {
+		var it adaptive.Item
+		if err := rows.Scan(&it.QuestionID, &it.Type, &it.Attempts, &it.Score); err != nil {
+			log.Error("[Repo][PracticePool] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan practice questions")
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][PracticePool] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read practice questions")
+	}
+	return items, nil
+}
+
+// PracticeQuestion loads a question and its options without the answer key.
+func (r *quizRepository) PracticeQuestion(questionID int) (quizEntity.PracticeQuestion, error) {
+	var q quizEntity.PracticeQuestion
+	err := r.db.QueryRow(`
+		SELECT id, number, type, format, content, set_id
+		FROM questions
+		WHERE id = $1 AND deleted_at IS NULL
+	`, questionID).Scan(&q.ID, &q.Number, &q.Type, &q.Format, &q.Content, &q.SetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return q, app.NewAppError(404, "question not found")
+		}
+		log.Error("[Repo][PracticeQuestion] Error QueryRow: ", err)
+		return q, app.NewAppError(500, "failed to get practice question")
+	}
+
+	rows, err := r.db.Query(`SELECT code, content, img_url FROM answers WHERE question_id = $1 ORDER BY code`, questionID)
+	if err != nil {
+		log.Error("[Repo][PracticeQuestion] Error Query answers: ", err)
+		return q, app.NewAppError(500, "failed to get practice answers")
+	}
+	defer rows.Close()
+
+	q.Answers = []quizEntity.PracticeOption{}
+	for rows.Next() {
+		var o quizEntity.PracticeOption
+		if err := rows.Scan(&o.Code, &o.Content, &o.ImgURL); err != nil {
+			log.Error("[Repo][PracticeQuestion] Error Scan answers: ", err)
+			return q, app.NewAppError(500, "failed to scan practice answers")
+		}
+		q.Answers = append(q.Answers, o)
+	}
+	return q, rows.Err()
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+)
+
+const (
+	submissionGraded        = "graded"
+	submissionPendingReview = "pending_review"
+)
+
+// ListEssayReviews returns the essay answers still waiting for a score,
+// oldest submission first.
+func (r *quizRepository) ListEssayReviews(filter map[string]string, page, limit int) (*response.PaginateResponse, error) {
+	baseQuery := `
+		FROM quiz_submission_answers sa
+		JOIN quiz_submissions qs ON qs.id = sa.submission_id
+		JOIN users u ON u.id = qs.user_id
+		JOIN questions q ON q.id = sa.question_id
+		JOIN sets a ON a.id = qs.set_id
+		JOIN lessons l ON a.lesson_id = l.id
+		JOIN classes c ON a.class_id = c.id
+		WHERE sa.pending
+	`
+
+	args := []interface{}{}
+	for _, key := range []string{"set_id", "lesson_id", "class_id"} {
+		if val, exists := filter[key]; exists {
+			column := map[string]string{
+				"set_id":    "a.id",
+				"lesson_id": "l.id",
+				"class_id":  "c.id",
+			}[key]
+			args = append(args, val)
+			baseQuery += fmt.Sprintf(" AND %s = $%d", column, len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.QueryRow("SELECT COUNT(*) "+baseQuery, args...).Scan(&total); err != nil {
+		log.Error("[Repo][ListEssayReviews] Error Count Query: ", err)
+		return nil, app.NewAppError(500, "failed to count essay reviews")
+	}
+
+	query := `
+		SELECT sa.id, qs.id, u.id, u.name, a.id, l.name, c.name, sa.number, q.content,
+			   COALESCE((SELECT string_agg(an.content, ', ') FROM answers an WHERE an.question_id = q.id AND an.is_answer), ''),
+			   sa.answer, qs.submitted_at
+	` + baseQuery + " ORDER BY qs.submitted_at ASC, sa.number ASC"
+
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if page > 0 {
+			args = append(args, (page-1)*limit)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ListEssayReviews] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch essay reviews")
+	}
+	defer rows.Close()
+
+	reviews := []quizEntity.EssayReview{}
+	for rows.Next() {
+		var e quizEntity.EssayReview
+		if err := rows.Scan(&e.AnswerID, &e.SubmissionID, &e.UserID, &e.UserName, &e.SetID, &e.Lesson, &e.Class, &e.Number,
+			&e.QuestionContent, &e.AnswerKey, &e.Answer, &e.SubmittedAt); err != nil {
+			log.Error("[Repo][ListEssayReviews] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan essay reviews")
+		}
+		reviews = append(reviews, e)
+	}
+
+	return &response.PaginateResponse{
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Data:  reviews,
+	}, nil
+}
+
+// ScoreEssay stores a teacher's score and feedback on one essay answer. Once
+// no answer of the submission is pending, its correct count and grade are
+// recomputed and it is marked graded. It returns the submission id.
+func (r *quizRepository) ScoreEssay(answerID, reviewerID int, req quizEntity.ScoreEssayRequest) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][ScoreEssay] Error beginning transaction: ", err)
+		return 0, app.NewAppError(500, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var submissionID int
+	var format string
+	err = tx.QueryRow(`
+		SELECT sa.submission_id, q.format
+		FROM quiz_submission_answers sa
+		JOIN questions q ON q.id = sa.question_id
+		WHERE sa.id = $1
+	`, answerID).Scan(&submissionID, &format)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, app.NewAppError(404, "quiz answer not found")
+		}
+		log.Error("[Repo][ScoreEssay] Error QueryRow answer: ", err)
+		return 0, app.NewAppError(500, "failed to get quiz answer")
+	}
+	if format != "essay" {
+		return 0, app.NewAppError(400, "only essay answers can be scored manually")
+	}
+
+	// Lock the submission so concurrent reviews of its essays recompute the
+	// grade one after another.
+	if _, err := tx.Exec(`SELECT id FROM quiz_submissions WHERE id = $1 FOR UPDATE`, submissionID); err != nil {
+		log.Error("[Repo][ScoreEssay] Error locking submission: ", err)
+		return 0, app.NewAppError(500, "failed to lock quiz submission")
+	}
+
+	_, err = tx.Exec(`
+		UPDATE quiz_submission_answers
+		SET score = $2, is_correct = $3, pending = FALSE,
+			feedback = $4, reviewed_by = $5, reviewed_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1
+	`, answerID, float64(req.Score)/100, req.Score == 100, req.Feedback, reviewerID)
+	if err != nil {
+		log.Error("[Repo][ScoreEssay] Error updating answer: ", err)
+		return 0, app.NewAppError(500, "failed to score essay")
+	}
+
+	var count, correct, pending int
+	var total float64
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(score), 0),
+			   COUNT(*) FILTER (WHERE is_correct), COUNT(*) FILTER (WHERE pending)
+		FROM quiz_submission_answers
+		WHERE submission_id = $1
+	`, submissionID).Scan(&count, &total, &correct, &pending)
+	if err != nil {
+		log.Error("[Repo][ScoreEssay] Error summing answers: ", err)
+		return 0, app.NewAppError(500, "failed to recompute grade")
+	}
+
+	if pending == 0 && count > 0 {
+		grade := int(total * 100 / float64(count))
+		_, err := tx.Exec(`UPDATE quiz_submissions SET correct = $2, grade = $3, status = $4 WHERE id = $1`,
+			submissionID, correct, grade, submissionGraded)
+		if err != nil {
+			log.Error("[Repo][ScoreEssay] Error updating submission: ", err)
+			return 0, app.NewAppError(500, "failed to update quiz submission")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][ScoreEssay] Error committing: ", err)
+		return 0, app.NewAppError(500, "failed to commit essay score")
+	}
+	return submissionID, nil
+}
@@ -0,0 +1,222 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/shuffle"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/lib/pq"
+)
+
+const (
+	sessionActive    = "active"
+	sessionSubmitted = "submitted"
+	sessionExpired   = "expired"
+)
+
+// sessionGrace is added to a session deadline before a submission is
+// rejected, so answers sent right at the deadline still arrive in time.
+const sessionGrace = 30
+
+var errSessionExpired = app.NewAppError(410, "quiz session has expired")
+
+// StartSession returns the user's active session on the set, or starts a new
+// one. Sessions whose deadline has passed are closed as expired first.
+func (r *quizRepository) StartSession(setID, userID int, lang string) (quizEntity.QuizSession, error) {
+	// Students enrolled in a classroom can only take the quizzes of its class.
+	var duration int
+	setQuery := `SELECT s.duration_seconds FROM sets s WHERE s.id = $1 AND ` + enrollment.ClassFilter("s.class_id", 2)
+	if err := r.db.QueryRow(setQuery, setID, userID).Scan(&duration); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quizEntity.QuizSession{}, app.NewAppError(404, "set not found")
+		}
+		log.Error("[Repo][StartSession] Error QueryRow set: ", err)
+		return quizEntity.QuizSession{}, app.NewAppError(500, "failed to get set")
+	}
+
+	if err := r.expireSessions(userID, setID); err != nil {
+		return quizEntity.QuizSession{}, err
+	}
+
+	active, err := r.findSession(`WHERE user_id = $1 AND set_id = $2 AND status = 'active' ORDER BY id DESC LIMIT 1`, userID, setID)
+	if err == nil {
+		return active, nil
+	}
+	if appErr, ok := err.(*app.AppError); !ok || appErr.Code != 404 {
+		return quizEntity.QuizSession{}, err
+	}
+
+	order, err := r.questionOrder(setID, lang)
+	if err != nil {
+		return quizEntity.QuizSession{}, err
+	}
+	if len(order) == 0 {
+		return quizEntity.QuizSession{}, app.NewAppError(400, "no questions found in this set")
+	}
+
+	seed := shuffle.NewSeed()
+	session := quizEntity.QuizSession{
+		SetID:         setID,
+		UserID:        userID,
+		Lang:          lang,
+		Status:        sessionActive,
+		QuestionOrder: shuffle.Questions(seed, order),
+		Seed:          seed,
+		StartedAt:     time.Now().Unix(),
+	}
+	if duration > 0 {
+		deadline := session.StartedAt + int64(duration)
+		session.Deadline = &deadline
+	}
+
+	query := `
+		INSERT INTO quiz_sessions (user_id, set_id, lang, status, question_order, seed, started_at, deadline_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err = r.db.QueryRow(query, userID, setID, lang, session.Status, pq.Array(session.QuestionOrder), seed, session.StartedAt, session.Deadline).Scan(&session.ID)
+	if err != nil {
+		log.Error("[Repo][StartSession] Error Insert: ", err)
+		return quizEntity.QuizSession{}, app.NewAppError(500, "failed to start quiz session")
+	}
+
+	return session, nil
+}
+
+func (r *quizRepository) GetSession(sessionID, userID int) (quizEntity.QuizSession, error) {
+	if err := r.expireSession(sessionID); err != nil {
+		return quizEntity.QuizSession{}, err
+	}
+	return r.findSession(`WHERE id = $1 AND user_id = $2`, sessionID, userID)
+}
+
+func (r *quizRepository) findSession(where string, args ...interface{}) (quizEntity.QuizSession, error) {
+	var s quizEntity.QuizSession
+	var order pq.Int64Array
+	var deadline, finished sql.NullInt64
+
+	query := `
+		SELECT id, user_id, set_id, lang, status, question_order, seed, started_at, deadline_at, finished_at
+		FROM quiz_sessions
+	` + where
+
+	err := r.db.QueryRow(query, args...).Scan(&s.ID, &s.UserID, &s.SetID, &s.Lang, &s.Status, &order, &s.Seed, &s.StartedAt, &deadline, &finished)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quizEntity.QuizSession{}, app.NewAppError(404, "quiz session not found")
+		}
+		log.Error("[Repo][findSession] Error QueryRow: ", err)
+		return quizEntity.QuizSession{}, app.NewAppError(500, "failed to get quiz session")
+	}
+
+	for _, n := range order {
+		s.QuestionOrder = append(s.QuestionOrder, int(n))
+	}
+	if deadline.Valid {
+		s.Deadline = &deadline.Int64
+	}
+	if finished.Valid {
+		s.FinishedAt = &finished.Int64
+	}
+	return s, nil
+}
+
+func (r *quizRepository) questionOrder(setID int, lang string) ([]int, error) {
+	rows, err := r.db.Query(`SELECT number FROM questions WHERE set_id = $1 AND lang = $2 ORDER BY number`, setID, lang)
+	if err != nil {
+		log.Error("[Repo][questionOrder] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to get questions")
+	}
+	defer rows.Close()
+
+	var order []int
+	for rows.Next() {
+		var number int
+		if err := rows.Scan(&number); err != nil {
+			log.Error("[Repo][questionOrder] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan questions")
+		}
+		order = append(order, number)
+	}
+	return order, rows.Err()
+}
+
+func (r *quizRepository) expireSessions(userID, setID int) error {
+	query := `
+		UPDATE quiz_sessions SET status = 'expired', finished_at = deadline_at
+		WHERE user_id = $1 AND set_id = $2 AND status = 'active'
+		  AND deadline_at IS NOT NULL AND deadline_at + $3 < EXTRACT(EPOCH FROM NOW())
+	`
+	if _, err := r.db.Exec(query, userID, setID, sessionGrace); err != nil {
+		log.Error("[Repo][expireSessions] Error Exec: ", err)
+		return app.NewAppError(500, "failed to close expired quiz sessions")
+	}
+	return nil
+}
+
+func (r *quizRepository) expireSession(sessionID int) error {
+	query := `
+		UPDATE quiz_sessions SET status = 'expired', finished_at = deadline_at
+		WHERE id = $1 AND status = 'active'
+		  AND deadline_at IS NOT NULL AND deadline_at + $2 < EXTRACT(EPOCH FROM NOW())
+	`
+	if _, err := r.db.Exec(query, sessionID, sessionGrace); err != nil {
+		log.Error("[Repo][expireSession] Error Exec: ", err)
+		return app.NewAppError(500, "failed to close expired quiz session")
+	}
+	return nil
+}
+
+// claimSession locks the session a submission belongs to and checks it is
+// still open for that user and set. It returns the session start time and
+// shuffle seed.
+func claimSession(tx *sql.Tx, sessionID, setID, userID int) (int64, int64, error) {
+	var ownerID, sessionSetID int
+	var status string
+	var startedAt, seed int64
+	var deadline sql.NullInt64
+
+	query := `
+		SELECT user_id, set_id, status, started_at, seed, deadline_at
+		FROM quiz_sessions
+		WHERE id = $1
+		FOR UPDATE
+	`
+	err := tx.QueryRow(query, sessionID).Scan(&ownerID, &sessionSetID, &status, &startedAt, &seed, &deadline)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, app.NewAppError(404, "quiz session not found")
+		}
+		log.Error("[Repo][claimSession] Error QueryRow: ", err)
+		return 0, 0, app.NewAppError(500, "failed to get quiz session")
+	}
+
+	if ownerID != userID || sessionSetID != setID {
+		return 0, 0, app.NewAppError(404, "quiz session not found")
+	}
+	switch status {
+	case sessionSubmitted:
+		return 0, 0, app.NewAppError(409, "quiz session already submitted")
+	case sessionExpired:
+		return 0, 0, errSessionExpired
+	}
+	if deadline.Valid && time.Now().Unix() > deadline.Int64+sessionGrace {
+		return 0, 0, errSessionExpired
+	}
+
+	return startedAt, seed, nil
+}
+
+func finishSession(tx *sql.Tx, sessionID int, finishedAt int64) error {
+	query := `UPDATE quiz_sessions SET status = 'submitted', finished_at = $2 WHERE id = $1`
+	if _, err := tx.Exec(query, sessionID, finishedAt); err != nil {
+		log.Error("[Repo][finishSession] Error Exec: ", err)
+		return app.NewAppError(500, "failed to close quiz session")
+	}
+	return nil
+}
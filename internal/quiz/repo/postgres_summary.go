@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 )
 
@@ -41,3 +43,57 @@ func (r *quizRepository) GetAvgTotal(userID int, filter map[string]string) (int,
 
 	return count, avg, nil
 }
+
+// MasteryResults returns every graded per question answer of the user with
+// the class, lesson and set it belongs to. Essays awaiting review are left
+// out. The filter accepts class_id, lesson_id and set_id.
+func (r *quizRepository) MasteryResults(userID int, filter map[string]string) ([]quizEntity.MasteryResult, error) {
+	query := `
+		SELECT c.id, c.name, l.id, l.name, s.id, s.name, q.type, sa.score, qs.submitted_at
+		FROM quiz_submission_answers sa
+		JOIN quiz_submissions qs ON qs.id = sa.submission_id
+		JOIN questions q ON q.id = sa.question_id
+		JOIN sets s ON s.id = qs.set_id
+		JOIN lessons l ON l.id = s.lesson_id
+		JOIN classes c ON c.id = s.class_id
+		WHERE qs.user_id = $1 AND NOT sa.pending
+	`
+	args := []interface{}{userID}
+	for _, key := range []string{"class_id", "lesson_id", "set_id"} {
+		if val, ok := filter[key]; ok && val != "" {
+			if _, err := strconv.Atoi(val); err != nil {
+				return nil, app.NewAppError(400, "invalid "+key)
+			}
+			column := map[string]string{
+				"class_id":  "c.id",
+				"lesson_id": "l.id",
+				"set_id":    "s.id",
+			}[key]
+			args = append(args, val)
+			query += fmt.Sprintf(" AND %s = $%d", column, len(args))
+		}
+	}
+	query += " ORDER BY qs.submitted_at"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[QuizRepo][MasteryResults] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to load quiz results")
+	}
+	defer rows.Close()
+
+	var results []quizEntity.MasteryResult
+	for rows.Next() {
+		var m quizEntity.MasteryResult
+		if err := rows.Scan(&m.ClassID, &m.ClassName, &m.LessonID, &m.LessonName, &m.SetID, &m.SetName, &m.Type, &m.Score, &m.SubmittedAt); err != nil {
+			log.Error("[QuizRepo][MasteryResults] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan quiz results")
+		}
+		results = append(results, m)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[QuizRepo][MasteryResults] Error Rows: ", err)
+		return nil, app.NewAppError(500, "failed to read quiz results")
+	}
+	return results, nil
+}
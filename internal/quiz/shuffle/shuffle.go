@@ -0,0 +1,111 @@
+// Package shuffle derives the per attempt order of questions and answer
+// options from the seed stored on a quiz session. The same seed always gives
+// the same order, so grading and the explanation view can map what the
+// student saw back to the canonical answer codes.
+package shuffle
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/grader"
+)
+
+// NewSeed returns a random non-zero seed. Zero means "not shuffled".
+func NewSeed() int64 {
+	for {
+		if seed := rand.Int63(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// Questions returns numbers in the order they are shown for seed.
+func Questions(seed int64, numbers []int) []int {
+	out := append([]int(nil), numbers...)
+	if seed == 0 {
+		return out
+	}
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+// Shuffles reports whether options of a question format are reordered. Only
+// choice formats are; true/false, matching and free text keep their layout.
+func Shuffles(format string) bool {
+	return format == "mc4" || format == "mcx"
+}
+
+// Label is the code shown for the option at position i: a, b, c, ...
+func Label(i int) string {
+	return string(rune('a' + i))
+}
+
+// Mapping translates between the option codes a student saw and the
+// canonical codes stored in the answers table for one question.
+type Mapping struct {
+	toCanonical map[string]string
+	toDisplay   map[string]string
+}
+
+// NewMapping builds the mapping for questionID. codes are the canonical option
+// codes of the question in any order. A zero seed yields the identity mapping.
+func NewMapping(seed int64, questionID int, codes []string) Mapping {
+	m := Mapping{toCanonical: map[string]string{}, toDisplay: map[string]string{}}
+	for i, code := range Options(seed, questionID, codes) {
+		canonical := strings.ToLower(code)
+		display := canonical
+		if seed != 0 {
+			display = Label(i)
+		}
+		m.toCanonical[display] = canonical
+		m.toDisplay[canonical] = display
+	}
+	return m
+}
+
+// Options returns the canonical codes in the order they are shown.
+func Options(seed int64, questionID int, codes []string) []string {
+	out := append([]string(nil), codes...)
+	sort.Strings(out)
+	if seed == 0 {
+		return out
+	}
+	r := rand.New(rand.NewSource(seed ^ int64(questionID)*2654435761))
+	r.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+func (m Mapping) Canonical(display string) string {
+	if c, ok := m.toCanonical[strings.ToLower(display)]; ok {
+		return c
+	}
+	return display
+}
+
+func (m Mapping) Display(canonical string) string {
+	if d, ok := m.toDisplay[strings.ToLower(canonical)]; ok {
+		return d
+	}
+	return canonical
+}
+
+// CanonicalAnswer rewrites a comma separated list of displayed codes.
+func (m Mapping) CanonicalAnswer(answer string) string {
+	return m.translate(answer, m.Canonical)
+}
+
+// DisplayAnswer rewrites a comma separated list of canonical codes.
+func (m Mapping) DisplayAnswer(answer string) string {
+	return m.translate(answer, m.Display)
+}
+
+func (m Mapping) translate(answer string, fn func(string) string) string {
+	codes := grader.SplitAnswer(answer)
+	for i, code := range codes {
+		codes[i] = fn(code)
+	}
+	return strings.Join(codes, ",")
+}
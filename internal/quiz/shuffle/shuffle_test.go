@@ -0,0 +1,41 @@
+package shuffle_test
+
+import (
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/quiz/shuffle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuestions_Deterministic(t *testing.T) {
+	numbers := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	first := shuffle.Questions(42, numbers)
+	assert.Equal(t, first, shuffle.Questions(42, numbers))
+	assert.ElementsMatch(t, numbers, first)
+	assert.Equal(t, numbers, shuffle.Questions(0, numbers), "zero seed keeps the canonical order")
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, numbers, "input must not be modified")
+}
+
+func TestMapping_RoundTrip(t *testing.T) {
+	codes := []string{"d", "b", "a", "c"}
+	m := shuffle.NewMapping(7, 11, codes)
+
+	for _, canonical := range []string{"a", "b", "c", "d"} {
+		display := m.Display(canonical)
+		assert.Equal(t, canonical, m.Canonical(display))
+	}
+
+	shown := shuffle.Options(7, 11, codes)
+	for i, canonical := range shown {
+		assert.Equal(t, shuffle.Label(i), m.Display(canonical))
+	}
+
+	assert.Equal(t, "a,c", shuffle.NewMapping(7, 11, codes).CanonicalAnswer(m.DisplayAnswer("a, c")))
+}
+
+func TestMapping_ZeroSeedIsIdentity(t *testing.T) {
+	m := shuffle.NewMapping(0, 3, []string{"a", "b", "c", "d"})
+	assert.Equal(t, "c", m.Canonical("c"))
+	assert.Equal(t, "b,d", m.DisplayAnswer("b, d"))
+}
@@ -1,8 +1,13 @@
 package svc
 
 import (
+	gamificationSvc "github.com/ghulammuzz/misterblast/internal/gamification/svc"
+	"github.com/ghulammuzz/misterblast/internal/quiz/adaptive"
 	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/leaderboard"
 	quizRepo "github.com/ghulammuzz/misterblast/internal/quiz/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 )
 
@@ -11,23 +16,76 @@ type QuizService interface {
 	ListAdmin(filter map[string]string, page int, limit int) (*response.PaginateResponse, error)
 	List(filter map[string]string, userID int) (*response.PaginateResponse, error)
 	GetResult(userID int) (quizEntity.QuizExp, error)
-	GetSubmissionResult(submissionId int) (quizEntity.QuizExp, error)
+	GetSubmissionResult(submissionId int, userID int, staff bool) (quizEntity.QuizExp, error)
+	StartQuiz(setID int, userID int, lang string) (quizEntity.QuizSession, error)
+	GetSession(sessionID int, userID int) (quizEntity.QuizSession, error)
+	ListEssayReviews(filter map[string]string, page int, limit int) (*response.PaginateResponse, error)
+	ScoreEssay(answerID int, reviewerID int, req quizEntity.ScoreEssayRequest) error
+	NextPractice(userID int, filter map[string]string, exclude map[int]bool) (quizEntity.PracticeNext, error)
+	Leaderboard(board leaderboard.Board, userID int, page int, limit int) (quizEntity.Leaderboard, error)
+	SetLeaderboardOptOut(userID int, optOut bool) error
 }
 
 type quizService struct {
-	repo quizRepo.QuizRepository
+	repo         quizRepo.QuizRepository
+	gamification gamificationSvc.GamificationService
 }
 
 func (s *quizService) GetResult(userID int) (quizEntity.QuizExp, error) {
 	return s.repo.GetLast(userID)
 }
 
-func NewQuizService(repo quizRepo.QuizRepository) QuizService {
-	return &quizService{repo: repo}
+func NewQuizService(repo quizRepo.QuizRepository, gamification gamificationSvc.GamificationService) QuizService {
+	return &quizService{repo: repo, gamification: gamification}
 }
 
 func (s *quizService) SubmitQuiz(req quizEntity.QuizSubmit, setID int, userID int, lang string) (int, error) {
-	return s.repo.Submit(req, setID, userID, lang)
+	id, err := s.repo.Submit(req, setID, userID, lang)
+	if err != nil {
+		return 0, err
+	}
+	s.syncLeaderboards(id)
+	s.recordProgress(id)
+	return id, nil
+}
+
+// syncLeaderboards updates the leaderboards after a submission was graded. A
+// failure only leaves the cached boards stale until they expire, so it does
+// not fail the request.
+func (s *quizService) syncLeaderboards(submissionID int) {
+	if err := s.repo.SyncLeaderboards(submissionID); err != nil {
+		log.Warn("[QuizSvc][syncLeaderboards] Failed to update leaderboards: ", err)
+	}
+}
+
+// recordProgress awards XP, streak and badges for a graded submission. Like
+// the leaderboards it never fails the submission itself.
+func (s *quizService) recordProgress(submissionID int) {
+	if err := s.gamification.RecordQuizSubmission(submissionID); err != nil {
+		log.Warn("[QuizSvc][recordProgress] Failed to record progress: ", err)
+	}
+}
+
+// Leaderboard returns one page of the board in the scope, with the caller's
+// own rank.
+func (s *quizService) Leaderboard(board leaderboard.Board, userID int, page int, limit int) (quizEntity.Leaderboard, error) {
+	if board.Scope != leaderboard.ScopeGlobal && board.ID <= 0 {
+		return quizEntity.Leaderboard{}, app.NewAppError(400, "id is required for this leaderboard")
+	}
+	if board.Scope == leaderboard.ScopeGlobal {
+		board.ID = 0
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	return s.repo.Leaderboard(board, userID, page, limit)
+}
+
+func (s *quizService) SetLeaderboardOptOut(userID int, optOut bool) error {
+	return s.repo.SetLeaderboardOptOut(userID, optOut)
 }
 
 func (s *quizService) ListAdmin(filter map[string]string, page int, limit int) (*response.PaginateResponse, error) {
@@ -37,6 +95,67 @@ func (s *quizService) ListAdmin(filter map[string]string, page int, limit int) (
 func (s *quizService) List(filter map[string]string, userID int) (*response.PaginateResponse, error) {
 	return s.repo.List(filter, userID)
 }
-func (s *quizService) GetSubmissionResult(submissionId int) (quizEntity.QuizExp, error) {
-	return s.repo.GetSubmissionDetail(submissionId)
+
+// GetSubmissionResult shows a submission, with its reviewers' feedback, only
+// to the student who made it or to staff who may view every submission.
+func (s *quizService) GetSubmissionResult(submissionId int, userID int, staff bool) (quizEntity.QuizExp, error) {
+	result, err := s.repo.GetSubmissionDetail(submissionId)
+	if err != nil {
+		return quizEntity.QuizExp{}, err
+	}
+	if !staff && result.UserID != userID {
+		return quizEntity.QuizExp{}, app.NewAppError(403, "not your submission")
+	}
+	return result, nil
+}
+
+func (s *quizService) StartQuiz(setID int, userID int, lang string) (quizEntity.QuizSession, error) {
+	return s.repo.StartSession(setID, userID, lang)
+}
+
+func (s *quizService) GetSession(sessionID int, userID int) (quizEntity.QuizSession, error) {
+	return s.repo.GetSession(sessionID, userID)
+}
+
+func (s *quizService) ListEssayReviews(filter map[string]string, page int, limit int) (*response.PaginateResponse, error) {
+	return s.repo.ListEssayReviews(filter, page, limit)
+}
+
+func (s *quizService) ScoreEssay(answerID int, reviewerID int, req quizEntity.ScoreEssayRequest) error {
+	if req.Score < 0 || req.Score > 100 {
+		return app.NewAppError(400, "score must be between 0 and 100")
+	}
+	submissionID, err := s.repo.ScoreEssay(answerID, reviewerID, req)
+	if err != nil {
+		return err
+	}
+	s.syncLeaderboards(submissionID)
+	s.recordProgress(submissionID)
+	return nil
+}
+
+// NextPractice picks the next adaptive practice question in the scope of the
+// filter. Questions in exclude, usually those already shown in the current
+// practice run, are skipped.
+func (s *quizService) NextPractice(userID int, filter map[string]string, exclude map[int]bool) (quizEntity.PracticeNext, error) {
+	items, err := s.repo.PracticePool(userID, filter)
+	if err != nil {
+		return quizEntity.PracticeNext{}, err
+	}
+	if len(items) == 0 {
+		return quizEntity.PracticeNext{}, app.NewAppError(404, "no questions found to practice")
+	}
+
+	pick, ok := adaptive.Next(items, exclude)
+	next := quizEntity.PracticeNext{Mastery: pick.Mastery}
+	if !ok {
+		return next, nil
+	}
+
+	question, err := s.repo.PracticeQuestion(pick.Item.QuestionID)
+	if err != nil {
+		return quizEntity.PracticeNext{}, err
+	}
+	next.Level, next.Dimension, next.Question = pick.Level, pick.Dimension, &question
+	return next, nil
 }
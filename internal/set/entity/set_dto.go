@@ -5,12 +5,16 @@ type SetSet struct {
 	IsQuiz   bool   `json:"is_quiz"`
 	LessonID int32  `json:"lesson_id" validate:"required"`
 	ClassID  int32  `json:"class_id" validate:"required"`
+	// DurationSeconds limits how long a quiz session on this set may run.
+	// Zero means untimed.
+	DurationSeconds int32 `json:"duration_seconds" validate:"min=0,max=86400"`
 }
 
 type ListSet struct {
-	ID     int32  `json:"id"`
-	Name   string `json:"name"`
-	Lesson string `json:"lesson"`
-	Class  string `json:"class"`
-	IsQuiz bool   `json:"is_quiz"`
+	ID              int32  `json:"id"`
+	Name            string `json:"name"`
+	Lesson          string `json:"lesson"`
+	Class           string `json:"class"`
+	IsQuiz          bool   `json:"is_quiz"`
+	DurationSeconds int32  `json:"duration_seconds"`
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strconv"
+
 	"github.com/ghulammuzz/misterblast/internal/set/entity"
 	"github.com/ghulammuzz/misterblast/internal/set/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
@@ -21,9 +23,9 @@ func NewSetHandler(setService svc.SetService, val *validator.Validate) *SetHandl
 }
 
 func (h *SetHandler) Router(r fiber.Router) {
-	r.Post("/set", m.R100(), h.AddSetHandler)
-	r.Delete("/set/:id", m.R100(), h.DeleteSetHandler)
-	r.Get("/set", m.R100(), h.ListSetsHandler)
+	r.Post("/set", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageCurriculum), h.AddSetHandler)
+	r.Delete("/set/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageCurriculum), h.DeleteSetHandler)
+	r.Get("/set", m.R100(), m.OptionalJWT(), h.ListSetsHandler)
 }
 
 func (h *SetHandler) AddSetHandler(c *fiber.Ctx) error {
@@ -78,6 +80,9 @@ func (h *SetHandler) ListSetsHandler(c *fiber.Ctx) error {
 	if isQuiz := c.Query("is_quiz"); isQuiz != "" {
 		filter["is_quiz"] = isQuiz
 	}
+	if userID, ok := m.StudentID(c); ok {
+		filter["enrolled_user"] = strconv.Itoa(userID)
+	}
 
 	sets, err := h.setService.ListSets(c.Context(), filter)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	cache "github.com/ghulammuzz/misterblast/config/redis"
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
 	setEntity "github.com/ghulammuzz/misterblast/internal/set/entity"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
@@ -31,8 +32,8 @@ func NewSetRepository(db *sql.DB, redis *redis.Client) SetRepository {
 
 func (c *setRepository) Add(class setEntity.SetSet) error {
 
-	query := `INSERT INTO sets (name, lesson_id, class_id, is_quiz) VALUES ($1, $2, $3, $4)`
-	_, err := c.db.Exec(query, class.Name, class.LessonID, class.ClassID, class.IsQuiz)
+	query := `INSERT INTO sets (name, lesson_id, class_id, is_quiz, duration_seconds) VALUES ($1, $2, $3, $4, $5)`
+	_, err := c.db.Exec(query, class.Name, class.LessonID, class.ClassID, class.IsQuiz, class.DurationSeconds)
 	if err != nil {
 		log.Error("[Repo][AddSet] Error Exec: ", err)
 		return app.NewAppError(500, "failed to insert class")
@@ -83,7 +84,7 @@ func (r *setRepository) List(ctx context.Context, filter map[string]string) ([]s
 	}
 
 	query := `
-		SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz
+		SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz, s.duration_seconds
 		FROM sets s
 		JOIN lessons l ON s.lesson_id = l.id
 		JOIN classes c ON s.class_id = c.id
@@ -116,6 +117,13 @@ func (r *setRepository) List(ctx context.Context, filter map[string]string) ([]s
 		argCounter++
 	}
 
+	// Students enrolled in a classroom only see the sets of its class.
+	if userID, ok := filter["enrolled_user"]; ok {
+		query += " AND " + enrollment.ClassFilter("s.class_id", argCounter)
+		args = append(args, userID)
+		argCounter++
+	}
+
 	query += " ORDER BY s.name"
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -128,7 +136,7 @@ func (r *setRepository) List(ctx context.Context, filter map[string]string) ([]s
 	var sets []setEntity.ListSet
 	for rows.Next() {
 		var set setEntity.ListSet
-		if err := rows.Scan(&set.ID, &set.Name, &set.Lesson, &set.Class, &set.IsQuiz); err != nil {
+		if err := rows.Scan(&set.ID, &set.Name, &set.Lesson, &set.Class, &set.IsQuiz, &set.DurationSeconds); err != nil {
 			log.Error("[Repo][ListSets] Error scanning row: ", err)
 			return nil, app.NewAppError(500, "failed to scan set")
 		}
@@ -18,7 +18,7 @@ func TestAddSet(t *testing.T) {
 	repository := repo.NewSetRepository(db, nil)
 
 	mock.ExpectExec("INSERT INTO sets").
-		WithArgs("Set A", 1, 1, false).
+		WithArgs("Set A", 1, 1, false, 0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	set := entity.SetSet{Name: "Set A", LessonID: 1, ClassID: 1, IsQuiz: false}
@@ -50,11 +50,11 @@ func TestListSets(t *testing.T) {
 
 	repository := repo.NewSetRepository(db, nil)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz"}).
-		AddRow(1, "Set A", "Math", "Class 1", false).
-		AddRow(2, "Set B", "Science", "Class 2", true)
+	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz", "duration_seconds"}).
+		AddRow(1, "Set A", "Math", "Class 1", false, 0).
+		AddRow(2, "Set B", "Science", "Class 2", true, 600)
 
-	mock.ExpectQuery("SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz FROM sets").
+	mock.ExpectQuery("SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz, s.duration_seconds FROM sets").
 		WillReturnRows(rows)
 
 	filter := map[string]string{}
@@ -70,10 +70,10 @@ func TestListWithFilters(t *testing.T) {
 
 	repository := repo.NewSetRepository(db, nil)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz"}).
-		AddRow(1, "Set A", "Math", "Class 1", false)
+	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz", "duration_seconds"}).
+		AddRow(1, "Set A", "Math", "Class 1", false, 0)
 
-	mock.ExpectQuery(`SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz FROM sets s`+
+	mock.ExpectQuery(`SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz, s.duration_seconds FROM sets s`+
 		` JOIN lessons l ON s.lesson_id = l.id`+
 		` JOIN classes c ON s.class_id = c.id WHERE 1=1 AND l.name = \$1 AND c.name = \$2`).
 		WithArgs("Math", "Class 1").
@@ -85,3 +85,23 @@ func TestListWithFilters(t *testing.T) {
 	assert.Len(t, sets, 1)
 	assert.Equal(t, "Set A", sets[0].Name)
 }
+
+func TestListEnrolledStudent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewSetRepository(db, nil)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz", "duration_seconds"}).
+		AddRow(1, "Set A", "Math", "5", true, 0)
+
+	mock.ExpectQuery(`AND s.is_quiz = \$1 AND \(NOT EXISTS \(SELECT 1 FROM classroom_members cm WHERE cm.user_id = \$2\)\s+OR s.class_id IN`).
+		WithArgs(true, "11").
+		WillReturnRows(rows)
+
+	sets, err := repository.List(context.TODO(), map[string]string{"is_quiz": "true", "enrolled_user": "11"})
+	assert.NoError(t, err)
+	assert.Len(t, sets, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,60 @@
+// Package annotation anchors feedback comments into a task submission: a
+// character range of the answer text, a page of the attached file, or both.
+package annotation
+
+import (
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+// Anchor positions are counted in characters, not bytes. Start is inclusive
+// and End exclusive; Page is 1-based.
+type Anchor struct {
+	Start *int32 `json:"start" validate:"omitempty,gte=0"`
+	End   *int32 `json:"end" validate:"omitempty,gt=0"`
+	Page  *int32 `json:"page" validate:"omitempty,gt=0"`
+}
+
+// HasRange reports whether the anchor points into the answer text.
+func (a Anchor) HasRange() bool {
+	return a.Start != nil && a.End != nil
+}
+
+// Validate checks the anchor against the submission it annotates.
+func (a Anchor) Validate(answer string, hasAttachment bool) error {
+	if (a.Start == nil) != (a.End == nil) {
+		return app.NewAppError(400, "anchor needs both start and end")
+	}
+	if !a.HasRange() && a.Page == nil {
+		return app.NewAppError(400, "anchor needs a character range or a page")
+	}
+	if a.HasRange() {
+		if *a.Start < 0 || *a.End <= *a.Start {
+			return app.NewAppError(400, "anchor end must be after start")
+		}
+		if int(*a.End) > len([]rune(answer)) {
+			return app.NewAppError(400, "anchor is outside the answer")
+		}
+	}
+	if a.Page != nil {
+		if *a.Page < 1 {
+			return app.NewAppError(400, "anchor page must be at least 1")
+		}
+		if !hasAttachment {
+			return app.NewAppError(400, "submission has no attachment to anchor to")
+		}
+	}
+	return nil
+}
+
+// Quote returns the answer text the anchor covers, or "" when it has no
+// range or the range no longer fits.
+func (a Anchor) Quote(answer string) string {
+	if !a.HasRange() {
+		return ""
+	}
+	runes := []rune(answer)
+	if *a.Start < 0 || *a.End <= *a.Start || int(*a.End) > len(runes) {
+		return ""
+	}
+	return string(runes[*a.Start:*a.End])
+}
@@ -0,0 +1,41 @@
+package annotation_test
+
+import (
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/task/annotation"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(n int32) *int32 { return &n }
+
+func TestValidate(t *testing.T) {
+	answer := "Café au lait"
+
+	assert.NoError(t, annotation.Anchor{Start: at(0), End: at(4)}.Validate(answer, false))
+	assert.NoError(t, annotation.Anchor{Start: at(5), End: at(12)}.Validate(answer, false))
+	assert.NoError(t, annotation.Anchor{Page: at(2)}.Validate(answer, true))
+
+	for _, a := range []annotation.Anchor{
+		{},
+		{Start: at(1)},
+		{Start: at(4), End: at(4)},
+		{Start: at(5), End: at(13)},
+		{Page: at(0)},
+		{Page: at(1)},
+	} {
+		err := a.Validate(answer, false)
+		if assert.Error(t, err) {
+			assert.Equal(t, 400, err.(*app.AppError).Code)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	answer := "Café au lait"
+
+	assert.Equal(t, "Café", annotation.Anchor{Start: at(0), End: at(4)}.Quote(answer))
+	assert.Equal(t, "", annotation.Anchor{Page: at(1)}.Quote(answer))
+	assert.Equal(t, "", annotation.Anchor{Start: at(5), End: at(40)}.Quote(answer))
+}
@@ -6,12 +6,18 @@ package di
 import (
 	"database/sql"
 
+	gamificationRepo "github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	gamificationSvc "github.com/ghulammuzz/misterblast/internal/gamification/svc"
 	"github.com/ghulammuzz/misterblast/internal/task/handler"
 	"github.com/ghulammuzz/misterblast/internal/task/repo"
 	service "github.com/ghulammuzz/misterblast/internal/task/svc"
+	uploadRepo "github.com/ghulammuzz/misterblast/internal/upload/repo"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
 func InitializeTaskServiceFake(sb *sql.DB, val *validator.Validate) *handler.TaskHandler {
@@ -24,11 +30,19 @@ func InitializeTaskServiceFake(sb *sql.DB, val *validator.Validate) *handler.Tas
 	return &handler.TaskHandler{}
 }
 
-func InitializeTaskSubmissionServiceFake(sb *sql.DB, val *validator.Validate) *handler.TaskSubmissionHandler {
+func InitializeTaskSubmissionServiceFake(sb *sql.DB, redis *redis.Client, val *validator.Validate) *handler.TaskSubmissionHandler {
 	wire.Build(
 		handler.NewTaskSubmissionHandler,
 		service.NewTaskSubmissionService,
 		repo.NewTaskSubmissionRepository,
+		repo.NewTaskRepository,
+		gamificationSvc.NewGamificationService,
+		gamificationRepo.NewGamificationRepository,
+		uploadRepo.NewUploadRepository,
+		queue.NewPostgresQueue,
+		wire.Bind(new(queue.Enqueuer), new(*queue.PostgresQueue)),
+		mailer.NewOutbox,
+		wire.Bind(new(mailer.Sender), new(*mailer.Outbox)),
 	)
 
 	return &handler.TaskSubmissionHandler{}
@@ -8,10 +8,16 @@ package di
 
 import (
 	"database/sql"
+	repo2 "github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	svc2 "github.com/ghulammuzz/misterblast/internal/gamification/svc"
 	"github.com/ghulammuzz/misterblast/internal/task/handler"
 	"github.com/ghulammuzz/misterblast/internal/task/repo"
 	"github.com/ghulammuzz/misterblast/internal/task/svc"
+	repo3 "github.com/ghulammuzz/misterblast/internal/upload/repo"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
 )
 
 // Injectors from wire.go:
@@ -23,9 +29,15 @@ func InitializeTaskService(sb *sql.DB, val *validator.Validate) *handler.TaskHan
 	return taskHandler
 }
 
-func InitializeTaskSubmissionService(sb *sql.DB, val *validator.Validate) *handler.TaskSubmissionHandler {
+func InitializeTaskSubmissionService(sb *sql.DB, redis2 *redis.Client, val *validator.Validate) *handler.TaskSubmissionHandler {
 	taskSubmissionRepository := repo.NewTaskSubmissionRepository(sb)
-	taskSubmissionService := svc.NewTaskSubmissionService(taskSubmissionRepository)
+	taskRepository := repo.NewTaskRepository(sb)
+	gamificationRepository := repo2.NewGamificationRepository(sb)
+	gamificationService := svc2.NewGamificationService(gamificationRepository)
+	uploadRepository := repo3.NewUploadRepository(sb)
+	postgresQueue := queue.NewPostgresQueue(sb, redis2)
+	outbox := mailer.NewOutbox(sb, postgresQueue)
+	taskSubmissionService := svc.NewTaskSubmissionService(taskSubmissionRepository, taskRepository, gamificationService, uploadRepository, postgresQueue, outbox)
 	taskSubmissionHandler := handler.NewTaskSubmissionHandler(taskSubmissionService, val)
 	return taskSubmissionHandler
 }
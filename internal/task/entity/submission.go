@@ -5,6 +5,8 @@ type Submission struct {
 	TaskId    int64  `db:"task_id"`
 	UserId    int64  `db:"user_id"`
 	Answer    string `db:"answer"`
+	Attempt   int32  `db:"attempt"`
+	IsFinal   bool   `db:"is_final"`
 	Score     int32  `db:"score"`
 	Feedback  string `db:"feedback"`
 	ScoredBy  int64  `db:"scored_by"`
@@ -1,5 +1,10 @@
 package entity
 
+import (
+	"github.com/ghulammuzz/misterblast/internal/task/annotation"
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+)
+
 type SubmissionResponseDto struct {
 	Id   int64           `json:"id"`
 	Task TaskResponseDto `json:"task"`
@@ -10,7 +15,76 @@ type ListSubmissionsRequestDto struct {
 	StartDate string
 	EndDate   string
 }
+
+// ScoreSubmissionRequestDto scores a submission. On a task with a rubric,
+// Rubric picks a level per criterion and Score is computed from it.
 type ScoreSubmissionRequestDto struct {
-	Score    int32  `json:"score"`
-	Feedback string `json:"feedback"`
+	Score    int32         `json:"score"`
+	Feedback string        `json:"feedback"`
+	Rubric   []rubric.Pick `json:"rubric" validate:"omitempty,dive"`
+}
+
+// RubricScoreDto is the level a submission earned on one criterion.
+type RubricScoreDto struct {
+	CriterionID int32  `json:"criterion_id"`
+	Criterion   string `json:"criterion"`
+	Weight      int32  `json:"weight"`
+	LevelID     int32  `json:"level_id"`
+	Level       string `json:"level"`
+	Points      int32  `json:"points"`
+	MaxPoints   int32  `json:"max_points"`
+	Comment     string `json:"comment"`
+}
+
+// CreateCommentRequestDto starts a thread on a submission, or replies to one
+// when ParentID is set. Only thread starters may be anchored.
+type CreateCommentRequestDto struct {
+	Body     string             `json:"body" validate:"required,max=5000"`
+	ParentID *int64             `json:"parent_id" validate:"omitempty,gt=0"`
+	Anchor   *annotation.Anchor `json:"anchor"`
+}
+
+// GradedSubmission is what the "task graded" email says.
+type GradedSubmission struct {
+	UserID    int64
+	TaskTitle string
+	Score     int64
+	Feedback  string
+}
+
+// CommentTarget is what a comment needs to know about its submission.
+type CommentTarget struct {
+	SubmissionID  int64
+	OwnerID       int64
+	Answer        string
+	HasAttachment bool
+}
+
+type SubmissionCommentDto struct {
+	ID        int64                  `json:"id"`
+	ParentID  *int64                 `json:"parent_id"`
+	AuthorID  int64                  `json:"author_id"`
+	Author    string                 `json:"author"`
+	Body      string                 `json:"body"`
+	Anchor    *annotation.Anchor     `json:"anchor"`
+	Quote     string                 `json:"quote,omitempty"`
+	IsRead    bool                   `json:"is_read"`
+	CreatedAt int64                  `json:"created_at"`
+	Replies   []SubmissionCommentDto `json:"replies,omitempty"`
+}
+
+// SubmissionThreadsDto is the feedback on a submission as seen by one user;
+// Unread counts the comments of others they have not read.
+type SubmissionThreadsDto struct {
+	SubmissionID int64                  `json:"submission_id"`
+	Unread       int                    `json:"unread"`
+	Threads      []SubmissionCommentDto `json:"threads"`
+}
+
+type UnreadFeedbackDto struct {
+	SubmissionID  int64  `json:"submission_id"`
+	TaskID        int64  `json:"task_id"`
+	Title         string `json:"title"`
+	Unread        int    `json:"unread"`
+	LastCommentAt int64  `json:"last_comment_at"`
 }
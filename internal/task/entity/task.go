@@ -6,6 +6,13 @@ type Task struct {
 	Description string
 	Content     string
 	AttachedURL string
+	ClassID     *int32
+	LessonID    *int32
+	OpenAt      *int64
+	DueAt       *int64
+	CloseAt     *int64
+	LatePolicy  string
+	MaxAttempts *int32
 	UpdatedAt   int64
 	CreatedAt   int64
 	DeletedAt   int64
@@ -1,6 +1,10 @@
 package entity
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+)
 
 type ListTaskRequestDto struct {
 	Search string
@@ -13,7 +17,37 @@ type CreateTaskRequestDto struct {
 	Description string `json:"description" validate:"required,min=1"`
 	Content     string `json:"content" validate:"required,min=1"`
 	AttachedURL string `json:"attached_url" validate:"omitempty,url"`
+	// ClassID limits the task to students enrolled in that class; leave it
+	// empty for a task every student sees.
+	ClassID  *int32 `json:"class_id" validate:"omitempty,gt=0"`
+	LessonID *int32 `json:"lesson_id" validate:"omitempty,gt=0"`
+	// OpenAt, DueAt and CloseAt are epoch seconds; LatePolicy defaults to
+	// "flag".
+	OpenAt     *int64 `json:"open_at" validate:"omitempty,gt=0"`
+	DueAt      *int64 `json:"due_at" validate:"omitempty,gt=0"`
+	CloseAt    *int64 `json:"close_at" validate:"omitempty,gt=0"`
+	LatePolicy string `json:"late_policy" validate:"omitempty,oneof=flag reject"`
+	// MaxAttempts caps the submissions per student; empty is unlimited.
+	MaxAttempts *int32 `json:"max_attempts" validate:"omitempty,gt=0"`
+}
+
+type TaskScheduleRequestDto struct {
+	OpenAt      *int64 `json:"open_at" validate:"omitempty,gt=0"`
+	DueAt       *int64 `json:"due_at" validate:"omitempty,gt=0"`
+	CloseAt     *int64 `json:"close_at" validate:"omitempty,gt=0"`
+	LatePolicy  string `json:"late_policy" validate:"required,oneof=flag reject"`
+	MaxAttempts *int32 `json:"max_attempts" validate:"omitempty,gt=0"`
+}
+
+// AssignTaskRequestDto targets whole classrooms, single students or both.
+type AssignTaskRequestDto struct {
+	ClassroomIDs []int32 `json:"classroom_ids" validate:"dive,gt=0"`
+	UserIDs      []int32 `json:"user_ids" validate:"dive,gt=0"`
 }
+type TaskRubricRequestDto struct {
+	Criteria []rubric.Criterion `json:"criteria" validate:"required,min=1,dive"`
+}
+
 type UpdateTaskRequestDto struct {
 	Title       string `form:"title"`
 	Description string `form:"description"`
@@ -24,6 +58,8 @@ type UpdateTaskRequestDto struct {
 type SubmitTaskRequestDto struct {
 	Answer      string                `form:"answer"`
 	AttachedURL *multipart.FileHeader `form:"attached_url" validate:"omitempty"`
+	// UploadID names a confirmed direct upload to attach instead of a file.
+	UploadID *int64 `form:"upload_id" validate:"omitempty"`
 }
 
 type TaskResponseDto struct {
@@ -31,6 +67,13 @@ type TaskResponseDto struct {
 	Title         string           `json:"title"`
 	Description   string           `json:"description"`
 	Content       string           `json:"content"`
+	ClassID       *int32           `json:"class_id"`
+	LessonID      *int32           `json:"lesson_id"`
+	OpenAt        *int64           `json:"open_at"`
+	DueAt         *int64           `json:"due_at"`
+	CloseAt       *int64           `json:"close_at"`
+	LatePolicy    string           `json:"late_policy"`
+	MaxAttempts   *int32           `json:"max_attempts"`
 	LastUpdatedAt string           `json:"last_updated_at"`
 	Statistic     TaskStatisticDto `json:"statistic"`
 }
@@ -41,10 +84,61 @@ type TaskDetailResponseDto struct {
 	Description   string           `json:"description"`
 	Content       string           `json:"content"`
 	AttachedURL   string           `json:"attached_url"`
+	ClassID       *int32           `json:"class_id"`
+	LessonID      *int32           `json:"lesson_id"`
+	OpenAt        *int64           `json:"open_at"`
+	DueAt         *int64           `json:"due_at"`
+	CloseAt       *int64           `json:"close_at"`
+	LatePolicy    string           `json:"late_policy"`
+	MaxAttempts   *int32           `json:"max_attempts"`
 	Statistic     TaskStatisticDto `json:"statistic"`
 	LastUpdatedAt string           `json:"last_updated_at"`
 }
 
+type TaskAssignmentDto struct {
+	ID          int32   `json:"id"`
+	ClassroomID *int32  `json:"classroom_id"`
+	Classroom   *string `json:"classroom"`
+	UserID      *int32  `json:"user_id"`
+	User        *string `json:"user"`
+	AssignedAt  int64   `json:"assigned_at"`
+}
+
+// MyTaskResponseDto is a task in a student's to-do list. Status is pending,
+// overdue or submitted.
+type MyTaskResponseDto struct {
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	DueAt       *int64 `json:"due_at"`
+	CloseAt     *int64 `json:"close_at"`
+	LatePolicy  string `json:"late_policy"`
+	Status      string `json:"status"`
+	SubmittedAt *int64 `json:"submitted_at"`
+	IsLate      bool   `json:"is_late"`
+}
+
+// TaskAttemptDto is one submission in a student's attempt history.
+type TaskAttemptDto struct {
+	ID          int64   `json:"id"`
+	Attempt     int32   `json:"attempt"`
+	Answer      string  `json:"answer"`
+	AttachedURL *string `json:"attached_url"`
+	IsFinal     bool    `json:"is_final"`
+	IsLate      bool    `json:"is_late"`
+	Score       *int32  `json:"score"`
+	Feedback    *string `json:"feedback"`
+	ScoredAt    *int64  `json:"scored_at"`
+	SubmittedAt int64   `json:"submitted_at"`
+}
+
+type TaskAttemptHistoryDto struct {
+	TaskID      int32            `json:"task_id"`
+	UserID      int64            `json:"user_id"`
+	MaxAttempts *int32           `json:"max_attempts"`
+	Attempts    []TaskAttemptDto `json:"attempts"`
+}
+
 type TaskStatisticDto struct {
 	TotalAssignment        int32   `json:"total_assignment"`
 	AverageAssignmentScore float32 `json:"average_assignment_score"`
@@ -60,6 +154,9 @@ type TaskListSubmissionResponseDto struct {
 	Score       int32  `json:"score"`
 	ScoredAt    int64  `json:"scored_at"`
 	SubmittedAt string `json:"submitted_at"`
+	Attempt     int32  `json:"attempt"`
+	IsFinal     bool   `json:"is_final"`
+	IsLate      bool   `json:"is_late"`
 	Feedback    string `json:"feedback"`
 }
 
@@ -74,5 +171,24 @@ type TaskSubmissionDetailResponseDto struct {
 	Score               int64  `json:"score"`
 	ScoredAt            int64  `json:"scored_at"`
 	SubmittedAt         int64  `json:"submitted_at"`
+	Attempt             int32  `json:"attempt"`
+	IsFinal             bool   `json:"is_final"`
+	IsLate              bool   `json:"is_late"`
 	Feedback            string `json:"feedback"`
+	// Rubric is the per-criterion breakdown when the task has a rubric and
+	// the submission was scored with it.
+	Rubric []RubricScoreDto `json:"rubric"`
+	// Upload is the background upload of the attachment, if one was sent.
+	Upload *UploadStatusDto `json:"upload"`
+}
+
+// UploadStatusDto reports a queued upload. Status is queued, running, done
+// or dead; a dead upload will not be retried.
+type UploadStatusDto struct {
+	JobID       int64   `json:"job_id"`
+	Status      string  `json:"status"`
+	Attempts    int32   `json:"attempts"`
+	MaxAttempts int32   `json:"max_attempts"`
+	LastError   *string `json:"last_error"`
+	UpdatedAt   int64   `json:"updated_at"`
 }
@@ -25,11 +25,17 @@ func NewTaskSubmissionHandler(s svc.TaskSubmissionService, val *validator.Valida
 }
 
 func (h *TaskSubmissionHandler) Router(r fiber.Router) {
-	r.Post("/submit-task/:id", m.R100(), m.JWTProtected(), h.SubmitTask)
-	r.Put("/submission/:submissionId/score", m.R100(), h.ScoreSubmission)
+	r.Post("/submit-task/:id", m.R100(), m.JWTProtected(), m.RequireVerified(), h.SubmitTask)
+	r.Put("/submission/:submissionId/score", m.R100(), m.JWTProtected(), m.Authorize(m.PermScoreSubmissions), h.ScoreSubmission)
 	r.Get("/my-submissions", m.R100(), m.JWTProtected(), h.ListMySubmissions)
-	r.Get("/task-submissions/:taskId", m.R100(), m.JWTProtected(), h.ListTaskSubmissions)
-	r.Get("/submission/:submissionId", m.R100(), h.GetSubmissionDetail)
+	r.Get("/my-submissions/unread-comments", m.R100(), m.JWTProtected(), h.UnreadFeedback)
+	r.Get("/task-submissions/:taskId", m.R100(), m.JWTProtected(), m.Authorize(m.PermViewSubmissions), h.ListTaskSubmissions)
+	r.Get("/submission/:submissionId", m.R100(), m.JWTProtected(), h.GetSubmissionDetail)
+	r.Put("/submission/:submissionId/final", m.R100(), m.JWTProtected(), m.RequireVerified(), h.MarkFinal)
+	r.Get("/submission/:submissionId/comments", m.R100(), m.JWTProtected(), h.ListComments)
+	r.Post("/submission/:submissionId/comments", m.R100(), m.JWTProtected(), h.AddComment)
+	r.Put("/submission/:submissionId/comments/read", m.R100(), m.JWTProtected(), h.MarkCommentsRead)
+	r.Get("/tasks/:taskId/attempts/:userId", m.R100(), m.JWTProtected(), m.AuthorizeSelfOr(m.PermViewSubmissions, "userId"), h.AttemptHistory)
 }
 
 func (h *TaskSubmissionHandler) SubmitTask(c *fiber.Ctx) error {
@@ -71,6 +77,19 @@ func (h *TaskSubmissionHandler) SubmitTask(c *fiber.Ctx) error {
 		}
 	}
 
+	// Larger files are uploaded straight to storage through /uploads and
+	// referenced here by their upload id.
+	if uploadID := c.FormValue("upload_id"); uploadID != "" {
+		if submitDTO.AttachedURL != nil {
+			return response.SendError(c, fiber.StatusBadRequest, "Send either attached_url or upload_id, not both", nil)
+		}
+		id, err := strconv.ParseInt(uploadID, 10, 64)
+		if err != nil {
+			return response.SendError(c, fiber.StatusBadRequest, "Invalid upload ID", nil)
+		}
+		submitDTO.UploadID = &id
+	}
+
 	if err := h.val.Struct(submitDTO); err != nil {
 		validationErrors := app.ValidationErrorResponse(err)
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
@@ -164,6 +183,7 @@ func (h *TaskSubmissionHandler) ListTaskSubmissions(c *fiber.Ctx) error {
 		"page":  c.Query("page", "1"),
 		"limit": c.Query("limit", "10"),
 		"type":  c.Query("type", ""),
+		"final": c.Query("final", ""),
 	}
 
 	result, err := h.svc.GetSubmissionsByTask(filter, taskId)
@@ -183,8 +203,12 @@ func (h *TaskSubmissionHandler) GetSubmissionDetail(c *fiber.Ctx) error {
 	if err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "Invalid Submission ID", nil)
 	}
+	userId, staff, ok := commentCaller(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
 
-	result, err := h.svc.GetSubmissionDetailById(submissionId)
+	result, err := h.svc.GetSubmissionDetailById(submissionId, userId, staff)
 	if err != nil {
 		var appErr *app.AppError
 		if !errors.As(err, &appErr) {
@@ -195,3 +219,160 @@ func (h *TaskSubmissionHandler) GetSubmissionDetail(c *fiber.Ctx) error {
 
 	return response.SendSuccess(c, "Submission detail retrieved", result)
 }
+
+// AttemptHistory lists every attempt of a student at a task with its score
+// and feedback. Students may only read their own history.
+func (h *TaskSubmissionHandler) AttemptHistory(c *fiber.Ctx) error {
+	taskId, err := strconv.ParseInt(c.Params("taskId"), 10, 64)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Task ID", nil)
+	}
+	userId, err := strconv.ParseInt(c.Params("userId"), 10, 64)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid User ID", nil)
+	}
+
+	result, err := h.svc.History(taskId, userId)
+	if err != nil {
+		var appErr *app.AppError
+		if !errors.As(err, &appErr) {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Attempt history retrieved", result)
+}
+
+func (h *TaskSubmissionHandler) MarkFinal(c *fiber.Ctx) error {
+	submissionId, err := strconv.ParseInt(c.Params("submissionId"), 10, 64)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Submission ID", nil)
+	}
+
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	userId := int(claims["user_id"].(float64))
+
+	if err := h.svc.MarkFinal(submissionId, int64(userId)); err != nil {
+		var appErr *app.AppError
+		if !errors.As(err, &appErr) {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Final attempt updated", nil)
+}
+
+// commentCaller returns the caller's user id and whether their role may
+// view every submission. Callers that are neither may only see their own.
+func commentCaller(c *fiber.Ctx) (int64, bool, bool) {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return 0, false, false
+	}
+	userId, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, false, false
+	}
+	return int64(userId), m.RoleFromClaims(claims).Can(m.PermViewSubmissions), true
+}
+
+func (h *TaskSubmissionHandler) ListComments(c *fiber.Ctx) error {
+	submissionId, err := strconv.ParseInt(c.Params("submissionId"), 10, 64)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Submission ID", nil)
+	}
+	userId, staff, ok := commentCaller(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	result, err := h.svc.Comments(submissionId, userId, staff)
+	if err != nil {
+		var appErr *app.AppError
+		if !errors.As(err, &appErr) {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Comments retrieved", result)
+}
+
+func (h *TaskSubmissionHandler) AddComment(c *fiber.Ctx) error {
+	submissionId, err := strconv.ParseInt(c.Params("submissionId"), 10, 64)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Submission ID", nil)
+	}
+	userId, staff, ok := commentCaller(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var dto entity.CreateCommentRequestDto
+	if err := c.BodyParser(&dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid body", err.Error())
+	}
+	if err := h.val.Struct(dto); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	id, err := h.svc.AddComment(submissionId, userId, staff, dto)
+	if err != nil {
+		var appErr *app.AppError
+		if !errors.As(err, &appErr) {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Comment added", fiber.Map{"id": id})
+}
+
+func (h *TaskSubmissionHandler) MarkCommentsRead(c *fiber.Ctx) error {
+	submissionId, err := strconv.ParseInt(c.Params("submissionId"), 10, 64)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Submission ID", nil)
+	}
+	userId, staff, ok := commentCaller(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	if err := h.svc.MarkCommentsRead(submissionId, userId, staff); err != nil {
+		var appErr *app.AppError
+		if !errors.As(err, &appErr) {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Comments marked as read", nil)
+}
+
+func (h *TaskSubmissionHandler) UnreadFeedback(c *fiber.Ctx) error {
+	userId, _, ok := commentCaller(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	result, err := h.svc.UnreadFeedback(userId)
+	if err != nil {
+		var appErr *app.AppError
+		if !errors.As(err, &appErr) {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Unread comments retrieved", result)
+}
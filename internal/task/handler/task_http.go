@@ -2,7 +2,9 @@ package handler
 
 import (
 	"errors"
+	"strconv"
 
+	classroomEntity "github.com/ghulammuzz/misterblast/internal/classroom/entity"
 	"github.com/ghulammuzz/misterblast/internal/task/entity"
 	service "github.com/ghulammuzz/misterblast/internal/task/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
@@ -10,6 +12,7 @@ import (
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type TaskHandler struct {
@@ -22,10 +25,17 @@ func NewTaskHandler(s service.TaskService, val *validator.Validate) *TaskHandler
 }
 
 func (h *TaskHandler) Router(r fiber.Router) {
-	r.Get("/tasks", m.R100(), h.List)
+	r.Get("/tasks", m.R100(), m.OptionalJWT(), h.List)
 	r.Get("/tasks/:id", m.R100(), h.Index)
-	r.Post("/tasks", m.R100(), h.CreateTask)
-	r.Delete("/tasks/:id", m.R100(), h.Delete)
+	r.Post("/tasks", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.CreateTask)
+	r.Delete("/tasks/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.Delete)
+	r.Put("/tasks/:id/schedule", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.UpdateSchedule)
+	r.Get("/tasks/:id/rubric", m.R100(), h.Rubric)
+	r.Put("/tasks/:id/rubric", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.SetRubric)
+	r.Post("/tasks/:id/assignments", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.Assign)
+	r.Get("/tasks/:id/assignments", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.Assignments)
+	r.Delete("/tasks/:id/assignments/:assignmentId", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageTasks), h.Unassign)
+	r.Get("/my-tasks", m.R100(), m.JWTProtected(), h.ListMine)
 }
 
 func (h *TaskHandler) List(c *fiber.Ctx) error {
@@ -36,6 +46,9 @@ func (h *TaskHandler) List(c *fiber.Ctx) error {
 	if c.Query("search") != "" {
 		filter["search"] = c.Query("search")
 	}
+	if userID, ok := m.StudentID(c); ok {
+		filter["enrolled_user"] = strconv.Itoa(userID)
+	}
 
 	tasks, err := h.s.List(filter, page, limit)
 	if err != nil {
@@ -105,3 +118,165 @@ func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
 	}
 	return response.SendSuccess(c, "Task added successfully", nil)
 }
+
+func (h *TaskHandler) UpdateSchedule(c *fiber.Ctx) error {
+	taskId, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+
+	var dto entity.TaskScheduleRequestDto
+	if err := c.BodyParser(&dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	if err := h.s.UpdateSchedule(int32(taskId), dto); err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "Task schedule updated", nil)
+}
+
+func (h *TaskHandler) Rubric(c *fiber.Ctx) error {
+	taskId, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+
+	criteria, err := h.s.Rubric(int32(taskId))
+	if err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "Task rubric retrieved", criteria)
+}
+
+func (h *TaskHandler) SetRubric(c *fiber.Ctx) error {
+	taskId, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+
+	var dto entity.TaskRubricRequestDto
+	if err := c.BodyParser(&dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	if err := h.s.SetRubric(int32(taskId), dto); err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "Task rubric updated", nil)
+}
+
+func (h *TaskHandler) Assign(c *fiber.Ctx) error {
+	taskId, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	caller := classroomEntity.Caller{
+		UserID: int32(claims["user_id"].(float64)),
+		Admin:  m.RoleFromClaims(claims) == m.RoleAdmin,
+	}
+
+	var dto entity.AssignTaskRequestDto
+	if err := c.BodyParser(&dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+
+	if err := h.s.Assign(caller, int32(taskId), dto); err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "Task assigned successfully", nil)
+}
+
+func (h *TaskHandler) Assignments(c *fiber.Ctx) error {
+	taskId, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+
+	assignments, err := h.s.Assignments(int32(taskId))
+	if err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "assignments retrieved successfully", assignments)
+}
+
+func (h *TaskHandler) Unassign(c *fiber.Ctx) error {
+	taskId, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+	assignmentId, err := c.ParamsInt("assignmentId")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid Params", nil)
+	}
+
+	if err := h.s.Unassign(int32(taskId), int32(assignmentId)); err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "Assignment removed", nil)
+}
+
+// ListMine is the student's to-do list; ?status= narrows it to pending,
+// overdue or submitted tasks.
+func (h *TaskHandler) ListMine(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	userId := int32(claims["user_id"].(float64))
+
+	tasks, err := h.s.ListForStudent(userId, c.Query("status"), c.QueryInt("page", 1), c.QueryInt("limit", 10))
+	if err != nil {
+		var appErr *app.AppError
+		ok := errors.As(err, &appErr)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "tasks retrieved successfully", tasks)
+}
@@ -0,0 +1,241 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/enrollment"
+	"github.com/ghulammuzz/misterblast/internal/task/entity"
+	"github.com/ghulammuzz/misterblast/internal/task/schedule"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/lib/pq"
+)
+
+// openNow hides tasks whose open time has not come yet.
+const openNow = `(t.open_at IS NULL OR t.open_at <= EXTRACT(EPOCH FROM NOW()))`
+
+// visibleTo limits tasks t to those the student bound to $arg may see: tasks
+// assigned to them or to one of their classrooms, and unassigned tasks of
+// their enrolled classes.
+func visibleTo(arg int) string {
+	return fmt.Sprintf(`(EXISTS (
+			SELECT 1 FROM task_assignments ta
+			WHERE ta.task_id = t.id
+			  AND (ta.user_id = $%[1]d OR ta.classroom_id IN (
+			      SELECT cm.classroom_id FROM classroom_members cm WHERE cm.user_id = $%[1]d)))
+		OR (NOT EXISTS (SELECT 1 FROM task_assignments ta WHERE ta.task_id = t.id)
+		    AND (t.class_id IS NULL OR %[2]s)))`, arg, enrollment.ClassFilter("t.class_id", arg))
+}
+
+func (r *TaskRepositoryImpl) Window(taskId int32) (schedule.Window, error) {
+	var w schedule.Window
+	err := r.db.QueryRow(`
+		SELECT open_at, due_at, close_at, late_policy
+		FROM tasks
+		WHERE id = $1 AND deleted_at IS NULL
+	`, taskId).Scan(&w.OpenAt, &w.DueAt, &w.CloseAt, &w.Policy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return w, app.NewAppError(http.StatusNotFound, "task not found")
+		}
+		log.Error("[Repo][Tasks] failed to get task window, cause : %s", err.Error())
+		return w, app.NewAppError(http.StatusInternalServerError, "failed to get task")
+	}
+	return w, nil
+}
+
+func (r *TaskRepositoryImpl) UpdateSchedule(taskId int32, window schedule.Window, maxAttempts *int32) error {
+	res, err := r.db.Exec(`
+		UPDATE tasks
+		SET open_at = $2, due_at = $3, close_at = $4, late_policy = $5, max_attempts = $6, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND deleted_at IS NULL
+	`, taskId, window.OpenAt, window.DueAt, window.CloseAt, window.Policy, maxAttempts)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to update schedule, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to update task schedule")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(http.StatusNotFound, "task not found")
+	}
+	return nil
+}
+
+func (r *TaskRepositoryImpl) IsAssigned(taskId int32, userId int64) (bool, error) {
+	var assigned bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM tasks t WHERE t.id = $1 AND `+visibleTo(2)+`)`, taskId, userId).Scan(&assigned)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to check assignment, cause : %s", err.Error())
+		return false, app.NewAppError(http.StatusInternalServerError, "failed to check task assignment")
+	}
+	return assigned, nil
+}
+
+// UntaughtClassrooms returns the ids among classroomIds that do not exist or
+// are taught by someone else.
+func (r *TaskRepositoryImpl) UntaughtClassrooms(teacherId int32, classroomIds []int32) ([]int32, error) {
+	return r.missingIDs(`
+		SELECT id FROM unnest($1::int[]) AS ids(id)
+		WHERE NOT EXISTS (SELECT 1 FROM classrooms cr WHERE cr.id = ids.id AND ($2 = 0 OR cr.teacher_id = $2))
+	`, pq.Array(classroomIds), teacherId)
+}
+
+func (r *TaskRepositoryImpl) MissingUsers(userIds []int32) ([]int32, error) {
+	return r.missingIDs(`
+		SELECT id FROM unnest($1::int[]) AS ids(id)
+		WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = ids.id)
+	`, pq.Array(userIds))
+}
+
+func (r *TaskRepositoryImpl) missingIDs(query string, args ...any) ([]int32, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to check ids, cause : %s", err.Error())
+		return nil, app.NewAppError(http.StatusInternalServerError, "failed to check assignees")
+	}
+	defer rows.Close()
+
+	ids := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			log.Error("[Repo][Tasks] failed to scan id, cause : %s", err.Error())
+			return nil, app.NewAppError(http.StatusInternalServerError, "failed to check assignees")
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Assign adds the classrooms and students to the task. Assigning the same
+// target twice is a no-op.
+func (r *TaskRepositoryImpl) Assign(taskId int32, assignedBy int32, classroomIds, userIds []int32) error {
+	_, err := r.db.Exec(`
+		INSERT INTO task_assignments (task_id, classroom_id, user_id, assigned_by)
+		SELECT $1::int, unnest($2::int[]), NULL::int, $4::int
+		UNION ALL
+		SELECT $1::int, NULL::int, unnest($3::int[]), $4::int
+		ON CONFLICT DO NOTHING
+	`, taskId, pq.Array(classroomIds), pq.Array(userIds), assignedBy)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to assign task, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to assign task")
+	}
+	return nil
+}
+
+func (r *TaskRepositoryImpl) Assignments(taskId int32) ([]entity.TaskAssignmentDto, error) {
+	rows, err := r.db.Query(`
+		SELECT ta.id, ta.classroom_id, cr.name, ta.user_id, u.name, ta.created_at
+		FROM task_assignments ta
+		LEFT JOIN classrooms cr ON cr.id = ta.classroom_id
+		LEFT JOIN users u ON u.id = ta.user_id
+		WHERE ta.task_id = $1
+		ORDER BY ta.id
+	`, taskId)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to query assignments, cause : %s", err.Error())
+		return nil, app.NewAppError(http.StatusInternalServerError, "failed to get assignments")
+	}
+	defer rows.Close()
+
+	assignments := []entity.TaskAssignmentDto{}
+	for rows.Next() {
+		var a entity.TaskAssignmentDto
+		if err := rows.Scan(&a.ID, &a.ClassroomID, &a.Classroom, &a.UserID, &a.User, &a.AssignedAt); err != nil {
+			log.Error("[Repo][Tasks] failed to scan assignment, cause : %s", err.Error())
+			return nil, app.NewAppError(http.StatusInternalServerError, "failed to scan assignment")
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+func (r *TaskRepositoryImpl) Unassign(taskId int32, assignmentId int32) error {
+	res, err := r.db.Exec(`DELETE FROM task_assignments WHERE id = $1 AND task_id = $2`, assignmentId, taskId)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to delete assignment, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to delete assignment")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(http.StatusNotFound, "assignment not found")
+	}
+	return nil
+}
+
+// ListForStudent lists the open tasks the student can see with their
+// status, soonest due first. status filters on pending, overdue or
+// submitted; empty lists all.
+func (r *TaskRepositoryImpl) ListForStudent(userId int32, status string, page, limit int) (*response.PaginateResponse, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	inner := `
+		SELECT t.id, t.title, t.description, t.due_at, t.close_at, t.late_policy,
+		       s.created_at AS submitted_at, COALESCE(s.is_late, FALSE) AS is_late,
+		       CASE WHEN s.id IS NOT NULL THEN 'submitted'
+		            WHEN t.due_at IS NOT NULL AND t.due_at < EXTRACT(EPOCH FROM NOW()) THEN 'overdue'
+		            ELSE 'pending' END AS status
+		FROM tasks t
+		LEFT JOIN LATERAL (
+			SELECT ts.id, ts.created_at, ts.is_late
+			FROM task_submissions ts
+			WHERE ts.task_id = t.id AND ts.user_id = $1
+			ORDER BY ts.created_at DESC
+			LIMIT 1
+		) s ON TRUE
+		WHERE t.deleted_at IS NULL AND ` + openNow + ` AND ` + visibleTo(1)
+
+	args := []any{userId}
+	where := ""
+	if status != "" {
+		where = " WHERE my.status = $2"
+		args = append(args, status)
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM (`+inner+`) my`+where, args...).Scan(&total); err != nil {
+		log.Error("[Repo][Tasks] failed to count student tasks, cause : %s", err.Error())
+		return nil, app.NewAppError(http.StatusInternalServerError, "failed to get count")
+	}
+
+	query := fmt.Sprintf(`SELECT my.id, my.title, my.description, my.due_at, my.close_at, my.late_policy, my.submitted_at, my.is_late, my.status
+		FROM (%s) my%s
+		ORDER BY my.due_at ASC NULLS LAST, my.id
+		LIMIT $%d OFFSET $%d`, inner, where, len(args)+1, len(args)+2)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to query student tasks, cause : %s", err.Error())
+		return nil, app.NewAppError(http.StatusInternalServerError, "failed to get tasks")
+	}
+	defer rows.Close()
+
+	tasks := []entity.MyTaskResponseDto{}
+	for rows.Next() {
+		var t entity.MyTaskResponseDto
+		if err := rows.Scan(&t.ID, &t.Title, &t.Description, &t.DueAt, &t.CloseAt, &t.LatePolicy, &t.SubmittedAt, &t.IsLate, &t.Status); err != nil {
+			log.Error("[Repo][Tasks] failed to scan student task, cause : %s", err.Error())
+			return nil, app.NewAppError(http.StatusInternalServerError, "failed to scan task")
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][Tasks] failed to read student tasks, cause : %s", err.Error())
+		return nil, app.NewAppError(http.StatusInternalServerError, "failed to read tasks")
+	}
+
+	return &response.PaginateResponse{
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Data:  tasks,
+	}, nil
+}
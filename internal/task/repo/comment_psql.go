@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/ghulammuzz/misterblast/internal/task/annotation"
+	"github.com/ghulammuzz/misterblast/internal/task/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+func (t *TaskSubmissionRepositoryImpl) CommentTarget(submissionId int64) (entity.CommentTarget, error) {
+	target := entity.CommentTarget{SubmissionID: submissionId}
+	err := t.db.QueryRow(`
+		SELECT user_id, answer, COALESCE(attachment_url, '') <> ''
+		FROM task_submissions
+		WHERE id = $1
+	`, submissionId).Scan(&target.OwnerID, &target.Answer, &target.HasAttachment)
+	if err == sql.ErrNoRows {
+		return target, app.NewAppError(404, "submission not found")
+	}
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to get comment target, cause: %s", err.Error())
+		return target, err
+	}
+	return target, nil
+}
+
+// CommentThread returns the submission of a comment and whether it starts a
+// thread.
+func (t *TaskSubmissionRepositoryImpl) CommentThread(commentId int64) (int64, bool, error) {
+	var submissionId int64
+	var parentId sql.NullInt64
+	err := t.db.QueryRow(`SELECT submission_id, parent_id FROM task_submission_comments WHERE id = $1`, commentId).Scan(&submissionId, &parentId)
+	if err == sql.ErrNoRows {
+		return 0, false, app.NewAppError(404, "comment not found")
+	}
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to get comment, cause: %s", err.Error())
+		return 0, false, err
+	}
+	return submissionId, !parentId.Valid, nil
+}
+
+func (t *TaskSubmissionRepositoryImpl) AddComment(submissionId int64, authorId int64, dto entity.CreateCommentRequestDto) (int64, error) {
+	var anchor annotation.Anchor
+	if dto.Anchor != nil {
+		anchor = *dto.Anchor
+	}
+	var id int64
+	err := t.db.QueryRow(`
+		INSERT INTO task_submission_comments (submission_id, parent_id, author_id, body, anchor_start, anchor_end, anchor_page)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, submissionId, dto.ParentID, authorId, dto.Body, anchor.Start, anchor.End, anchor.Page).Scan(&id)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to insert comment, cause: %s", err.Error())
+		return 0, err
+	}
+	return id, nil
+}
+
+// Comments lists every comment on the submission oldest first. A comment
+// counts as read for userId when they wrote it or have marked it read.
+func (t *TaskSubmissionRepositoryImpl) Comments(submissionId int64, userId int64) ([]entity.SubmissionCommentDto, error) {
+	rows, err := t.db.Query(`
+		SELECT c.id, c.parent_id, c.author_id, u.name, c.body, c.anchor_start, c.anchor_end, c.anchor_page,
+		       c.author_id = $2 OR EXISTS (
+		           SELECT 1 FROM task_submission_comment_reads r WHERE r.comment_id = c.id AND r.user_id = $2),
+		       c.created_at
+		FROM task_submission_comments c
+		JOIN users u ON u.id = c.author_id
+		WHERE c.submission_id = $1
+		ORDER BY c.created_at, c.id
+	`, submissionId, userId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to query comments, cause: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []entity.SubmissionCommentDto{}
+	for rows.Next() {
+		var c entity.SubmissionCommentDto
+		var a annotation.Anchor
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.AuthorID, &c.Author, &c.Body, &a.Start, &a.End, &a.Page, &c.IsRead, &c.CreatedAt); err != nil {
+			log.Error("[TaskSubmissionRepo] failed to scan comment, cause: %s", err.Error())
+			return nil, err
+		}
+		if a.HasRange() || a.Page != nil {
+			c.Anchor = &a
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// MarkCommentsRead marks every comment others wrote on the submission as read
+// by userId.
+func (t *TaskSubmissionRepositoryImpl) MarkCommentsRead(submissionId int64, userId int64) error {
+	_, err := t.db.Exec(`
+		INSERT INTO task_submission_comment_reads (comment_id, user_id)
+		SELECT c.id, $2 FROM task_submission_comments c
+		WHERE c.submission_id = $1 AND c.author_id <> $2
+		ON CONFLICT DO NOTHING
+	`, submissionId, userId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to mark comments read, cause: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// UnreadFeedback lists the submissions with comments userId has not read,
+// among those they own or have commented on, most recent first.
+func (t *TaskSubmissionRepositoryImpl) UnreadFeedback(userId int64) ([]entity.UnreadFeedbackDto, error) {
+	rows, err := t.db.Query(`
+		SELECT ts.id, ts.task_id, COALESCE(tk.title, ''), COUNT(*), MAX(c.created_at)
+		FROM task_submission_comments c
+		JOIN task_submissions ts ON ts.id = c.submission_id
+		LEFT JOIN tasks tk ON tk.id = ts.task_id
+		WHERE c.author_id <> $1
+		  AND NOT EXISTS (SELECT 1 FROM task_submission_comment_reads r WHERE r.comment_id = c.id AND r.user_id = $1)
+		  AND (ts.user_id = $1 OR EXISTS (
+		      SELECT 1 FROM task_submission_comments mine WHERE mine.submission_id = ts.id AND mine.author_id = $1))
+		GROUP BY ts.id, ts.task_id, tk.title
+		ORDER BY MAX(c.created_at) DESC
+	`, userId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to query unread feedback, cause: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	unread := []entity.UnreadFeedbackDto{}
+	for rows.Next() {
+		var u entity.UnreadFeedbackDto
+		if err := rows.Scan(&u.SubmissionID, &u.TaskID, &u.Title, &u.Unread, &u.LastCommentAt); err != nil {
+			log.Error("[TaskSubmissionRepo] failed to scan unread feedback, cause: %s", err.Error())
+			return nil, err
+		}
+		unread = append(unread, u)
+	}
+	return unread, rows.Err()
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+// Rubric returns the task's criteria with their levels in the order they
+// were defined. A task without a rubric has no criteria.
+func (r *TaskRepositoryImpl) Rubric(taskId int32) ([]rubric.Criterion, error) {
+	rows, err := r.db.Query(`
+		SELECT rc.id, rc.name, rc.description, rc.weight, rl.id, rl.name, rl.description, rl.points
+		FROM task_rubric_criteria rc
+		JOIN task_rubric_levels rl ON rl.criterion_id = rc.id
+		WHERE rc.task_id = $1
+		ORDER BY rc.position, rc.id, rl.position, rl.id
+	`, taskId)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to query rubric, cause : %s", err.Error())
+		return nil, app.NewAppError(http.StatusInternalServerError, "failed to get rubric")
+	}
+	defer rows.Close()
+
+	criteria := []rubric.Criterion{}
+	for rows.Next() {
+		var c rubric.Criterion
+		var l rubric.Level
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Weight, &l.ID, &l.Name, &l.Description, &l.Points); err != nil {
+			log.Error("[Repo][Tasks] failed to scan rubric, cause : %s", err.Error())
+			return nil, app.NewAppError(http.StatusInternalServerError, "failed to scan rubric")
+		}
+		if n := len(criteria); n == 0 || criteria[n-1].ID != c.ID {
+			criteria = append(criteria, c)
+		}
+		last := &criteria[len(criteria)-1]
+		last.Levels = append(last.Levels, l)
+	}
+	return criteria, rows.Err()
+}
+
+// SetRubric replaces the task's rubric. It fails with 409 once the rubric
+// has been used to score a submission, since the stored breakdowns point at
+// its levels.
+func (r *TaskRepositoryImpl) SetRubric(taskId int32, criteria []rubric.Criterion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to begin transaction, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+	}
+	defer tx.Rollback()
+
+	var id int32
+	err = tx.QueryRow(`SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, taskId).Scan(&id)
+	if err == sql.ErrNoRows {
+		return app.NewAppError(http.StatusNotFound, "task not found")
+	}
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to lock task, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+	}
+
+	var used bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM task_submission_rubric_scores rs
+			JOIN task_rubric_criteria rc ON rc.id = rs.criterion_id
+			WHERE rc.task_id = $1)
+	`, taskId).Scan(&used)
+	if err != nil {
+		log.Error("[Repo][Tasks] failed to check rubric usage, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+	}
+	if used {
+		return app.NewAppError(http.StatusConflict, "rubric is already used to score submissions")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM task_rubric_criteria WHERE task_id = $1`, taskId); err != nil {
+		log.Error("[Repo][Tasks] failed to clear rubric, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+	}
+
+	for i, c := range criteria {
+		var criterionId int32
+		err := tx.QueryRow(`
+			INSERT INTO task_rubric_criteria (task_id, name, description, weight, position)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, taskId, c.Name, c.Description, c.Weight, i).Scan(&criterionId)
+		if err != nil {
+			log.Error("[Repo][Tasks] failed to insert rubric criterion, cause : %s", err.Error())
+			return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+		}
+		for j, l := range c.Levels {
+			_, err := tx.Exec(`
+				INSERT INTO task_rubric_levels (criterion_id, name, description, points, position)
+				VALUES ($1, $2, $3, $4, $5)
+			`, criterionId, l.Name, l.Description, l.Points, j)
+			if err != nil {
+				log.Error("[Repo][Tasks] failed to insert rubric level, cause : %s", err.Error())
+				return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][Tasks] failed to commit rubric, cause : %s", err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to save rubric")
+	}
+	return nil
+}
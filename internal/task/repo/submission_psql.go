@@ -6,15 +6,31 @@ import (
 	"strconv"
 
 	"github.com/ghulammuzz/misterblast/internal/task/entity"
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/ghulammuzz/misterblast/pkg/sqlutils"
 )
 
 type TaskSubmissionRepository interface {
-	Create(taskId int64, userId int64, answer string, attachedURL string) error
+	Create(taskId int64, userId int64, answer string, attachedURL string, isLate bool) (int64, int32, error)
 	ScoreSubmission(submissionId int64, submissionDto entity.ScoreSubmissionRequestDto) error
-	UpdateAttachmentURL(taskId int64, userId int64, url string) error
+	TaskID(submissionId int64) (int32, error)
+	Graded(submissionId int64) (entity.GradedSubmission, error)
+	ScoreWithRubric(submissionId int64, score int32, feedback string, picks []rubric.Pick) error
+	UpdateAttachmentURL(submissionId int64, url string) error
+	SetUploadJob(submissionId int64, jobId int64) error
+	History(taskId int64, userId int64) ([]entity.TaskAttemptDto, error)
+	MarkFinal(submissionId int64, userId int64) error
+
+	// Feedback threads
+	CommentTarget(submissionId int64) (entity.CommentTarget, error)
+	CommentThread(commentId int64) (int64, bool, error)
+	AddComment(submissionId int64, authorId int64, dto entity.CreateCommentRequestDto) (int64, error)
+	Comments(submissionId int64, userId int64) ([]entity.SubmissionCommentDto, error)
+	MarkCommentsRead(submissionId int64, userId int64) error
+	UnreadFeedback(userId int64) ([]entity.UnreadFeedbackDto, error)
 
 	// ListByUserId(filter map[string]string, userId int64) ([]entity.TaskListSubmissionResponseDto, error)
 	ListByUserId(filter map[string]string, userId int64) (*response.PaginateResponse, error)
@@ -29,13 +45,60 @@ type TaskSubmissionRepositoryImpl struct {
 	db *sql.DB
 }
 
-func (t *TaskSubmissionRepositoryImpl) Create(taskId int64, userId int64, answer string, attachedURL string) error {
+// Create stores the user's next attempt at the task, makes it the final one
+// and returns its id and attempt number. It fails with 409 once the task's
+// max_attempts is used up.
+func (t *TaskSubmissionRepositoryImpl) Create(taskId int64, userId int64, answer string, attachedURL string, isLate bool) (int64, int32, error) {
+	tx, err := t.db.Begin()
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to begin transaction, cause: %s", err.Error())
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	// Locking the task row serializes attempts, so two concurrent submissions
+	// cannot claim the same attempt number.
+	var maxAttempts sql.NullInt32
+	err = tx.QueryRow(`SELECT max_attempts FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, taskId).Scan(&maxAttempts)
+	if err == sql.ErrNoRows {
+		return 0, 0, app.NewAppError(404, "task not found")
+	}
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to lock task, cause: %s", err.Error())
+		return 0, 0, err
+	}
+
+	var lastAttempt int32
+	err = tx.QueryRow(`SELECT COALESCE(MAX(attempt), 0) FROM task_submissions WHERE task_id = $1 AND user_id = $2`, taskId, userId).Scan(&lastAttempt)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to count attempts, cause: %s", err.Error())
+		return 0, 0, err
+	}
+	if maxAttempts.Valid && lastAttempt >= maxAttempts.Int32 {
+		return 0, 0, app.NewAppError(409, fmt.Sprintf("maximum of %d attempts reached", maxAttempts.Int32))
+	}
+
+	if _, err := tx.Exec(`UPDATE task_submissions SET is_final = FALSE WHERE task_id = $1 AND user_id = $2 AND is_final`, taskId, userId); err != nil {
+		log.Error("[TaskSubmissionRepo] failed to clear final attempt, cause: %s", err.Error())
+		return 0, 0, err
+	}
+
 	query := `
-		INSERT INTO public.task_submissions (task_id, user_id, answer, attachment_url)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO public.task_submissions (task_id, user_id, answer, attachment_url, is_late, attempt, is_final)
+		VALUES ($1, $2, $3, $4, $5, $6, TRUE)
+		RETURNING id
 	`
-	_, err := t.db.Exec(query, taskId, userId, answer, attachedURL)
-	return err
+	var id int64
+	if err := tx.QueryRow(query, taskId, userId, answer, attachedURL, isLate, lastAttempt+1).Scan(&id); err != nil {
+		log.Error("[TaskSubmissionRepo] failed to insert submission, cause: %s", err.Error())
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[TaskSubmissionRepo] failed to commit submission, cause: %s", err.Error())
+		return 0, 0, err
+	}
+	return id, lastAttempt + 1, nil
 }
 
 func (t *TaskSubmissionRepositoryImpl) LIstByTaskId(filter map[string]string, taskId int64) (*response.PaginateResponse, error) {
@@ -46,6 +109,10 @@ func (t *TaskSubmissionRepositoryImpl) LIstByTaskId(filter map[string]string, ta
 	where := "WHERE ts.task_id = $1"
 	order := ""
 
+	if filter["final"] == "true" {
+		where += " AND ts.is_final"
+	}
+
 	if filter["type"] == "this_week" {
 		where += " AND to_timestamp(ts.created_at) >= now() - interval '7 days'"
 		order = "ORDER BY ts.created_at DESC"
@@ -57,8 +124,9 @@ func (t *TaskSubmissionRepositoryImpl) LIstByTaskId(filter map[string]string, ta
 	}
 
 	query := fmt.Sprintf(`
-		SELECT ts.id, t.title,t.description, t.content, ts.created_at, ts.scored_at, ts.feedback, ts.score 
+		SELECT ts.id, t.title,t.description, t.content, ts.created_at, ts.attempt, ts.is_final, ts.is_late, ts.scored_at, ts.feedback, ts.score 
 		FROM task_submissions ts
+		JOIN tasks t ON t.id = ts.task_id
 		%s
 		%s
 		LIMIT $2 OFFSET $3
@@ -74,11 +142,17 @@ func (t *TaskSubmissionRepositoryImpl) LIstByTaskId(filter map[string]string, ta
 	var submissions []entity.TaskListSubmissionResponseDto
 	for rows.Next() {
 		var s entity.TaskListSubmissionResponseDto
-		err = rows.Scan(&s.ID, &s.Title, &s.Description, &s.Content, &s.SubmittedAt, &s.ScoredAt, &s.Feedback, &s.Score)
+		var scoredAt sql.NullInt64
+		var feedback sql.NullString
+		var score sql.NullInt32
+		err = rows.Scan(&s.ID, &s.Title, &s.Description, &s.Content, &s.SubmittedAt, &s.Attempt, &s.IsFinal, &s.IsLate, &scoredAt, &feedback, &score)
 		if err != nil {
 			log.Error("[TaskSubmissionRepo] failed to scan submission, cause: %s", err.Error())
 			return nil, err
 		}
+		s.Feedback = sqlutils.ToString(feedback)
+		s.Score = sqlutils.ToInt32(score)
+		s.ScoredAt = sqlutils.ToInt64(scoredAt)
 		submissions = append(submissions, s)
 	}
 
@@ -125,6 +199,9 @@ func (t *TaskSubmissionRepositoryImpl) ListByUserId(filter map[string]string, us
 			t.description, 
 			t.content, 
 			ts.created_at, 
+			ts.attempt, 
+			ts.is_final, 
+			ts.is_late, 
 			ts.scored_at, 
 			ts.feedback, 
 			ts.score 
@@ -159,6 +236,9 @@ func (t *TaskSubmissionRepositoryImpl) ListByUserId(filter map[string]string, us
 			&s.Description,
 			&s.Content,
 			&s.SubmittedAt,
+			&s.Attempt,
+			&s.IsFinal,
+			&s.IsLate,
 			&scoredAt,
 			&feedback,
 			&score,
@@ -217,6 +297,14 @@ func (t *TaskSubmissionRepositoryImpl) SubmissionDetailById(submissionId int64)
 	var score sql.NullInt64
 	var scoredAt sql.NullInt64
 	var submittedAt sql.NullInt64
+	var jobID sql.NullInt64
+	var upload struct {
+		Status      sql.NullString
+		Attempts    sql.NullInt32
+		MaxAttempts sql.NullInt32
+		LastError   *string
+		UpdatedAt   sql.NullInt64
+	}
 
 	query := `
 		SELECT
@@ -230,9 +318,19 @@ func (t *TaskSubmissionRepositoryImpl) SubmissionDetailById(submissionId int64)
 			ts.score,
 			ts.scored_at,
 			ts.created_at,
-			ts.feedback
+			ts.attempt,
+			ts.is_final,
+			ts.is_late,
+			ts.feedback,
+			j.id,
+			j.status,
+			j.attempts,
+			j.max_attempts,
+			j.last_error,
+			j.updated_at
 		FROM task_submissions ts 
 		LEFT JOIN tasks t ON t.id = ts.task_id
+		LEFT JOIN jobs j ON j.id = ts.upload_job_id
 		WHERE ts.id = $1
 	`
 
@@ -247,7 +345,16 @@ func (t *TaskSubmissionRepositoryImpl) SubmissionDetailById(submissionId int64)
 		&score,
 		&scoredAt,
 		&submittedAt,
+		&response.Attempt,
+		&response.IsFinal,
+		&response.IsLate,
 		&feedback,
+		&jobID,
+		&upload.Status,
+		&upload.Attempts,
+		&upload.MaxAttempts,
+		&upload.LastError,
+		&upload.UpdatedAt,
 	)
 	if err != nil {
 		log.Error("[TaskSubmissionRepo] failed to scan submission detail, cause : %s", err.Error())
@@ -260,23 +367,198 @@ func (t *TaskSubmissionRepositoryImpl) SubmissionDetailById(submissionId int64)
 	response.ScoredAt = sqlutils.ToInt64(scoredAt)
 	response.SubmittedAt = sqlutils.ToInt64(submittedAt)
 	response.Feedback = sqlutils.ToString(feedback)
+	if jobID.Valid {
+		response.Upload = &entity.UploadStatusDto{
+			JobID:       jobID.Int64,
+			Status:      upload.Status.String,
+			Attempts:    upload.Attempts.Int32,
+			MaxAttempts: upload.MaxAttempts.Int32,
+			LastError:   upload.LastError,
+			UpdatedAt:   upload.UpdatedAt.Int64,
+		}
+	}
+
+	response.Rubric, err = t.rubricScores(submissionId)
+	if err != nil {
+		return nil, err
+	}
 
 	return &response, nil
 }
 
-func (t *TaskSubmissionRepositoryImpl) UpdateAttachmentURL(taskId int64, userId int64, url string) error {
+func (t *TaskSubmissionRepositoryImpl) rubricScores(submissionId int64) ([]entity.RubricScoreDto, error) {
+	rows, err := t.db.Query(`
+		SELECT rc.id, rc.name, rc.weight, rl.id, rl.name, rl.points,
+		       (SELECT MAX(l.points) FROM task_rubric_levels l WHERE l.criterion_id = rc.id),
+		       rs.comment
+		FROM task_submission_rubric_scores rs
+		JOIN task_rubric_criteria rc ON rc.id = rs.criterion_id
+		JOIN task_rubric_levels rl ON rl.id = rs.level_id
+		WHERE rs.submission_id = $1
+		ORDER BY rc.position, rc.id
+	`, submissionId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to query rubric scores, cause : %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := []entity.RubricScoreDto{}
+	for rows.Next() {
+		var s entity.RubricScoreDto
+		if err := rows.Scan(&s.CriterionID, &s.Criterion, &s.Weight, &s.LevelID, &s.Level, &s.Points, &s.MaxPoints, &s.Comment); err != nil {
+			log.Error("[TaskSubmissionRepo] failed to scan rubric score, cause : %s", err.Error())
+			return nil, err
+		}
+		scores = append(scores, s)
+	}
+	return scores, rows.Err()
+}
+
+func (t *TaskSubmissionRepositoryImpl) TaskID(submissionId int64) (int32, error) {
+	var taskId int32
+	err := t.db.QueryRow(`SELECT task_id FROM task_submissions WHERE id = $1`, submissionId).Scan(&taskId)
+	if err == sql.ErrNoRows {
+		return 0, app.NewAppError(404, "submission not found")
+	}
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to get submission task, cause : %s", err.Error())
+		return 0, err
+	}
+	return taskId, nil
+}
+
+// Graded returns the student, task and result of a scored submission.
+func (t *TaskSubmissionRepositoryImpl) Graded(submissionId int64) (entity.GradedSubmission, error) {
+	var graded entity.GradedSubmission
+	err := t.db.QueryRow(`
+		SELECT ts.user_id, COALESCE(t.title, ''), COALESCE(ts.score, 0), COALESCE(ts.feedback, '')
+		FROM task_submissions ts
+		JOIN tasks t ON t.id = ts.task_id
+		WHERE ts.id = $1
+	`, submissionId).Scan(&graded.UserID, &graded.TaskTitle, &graded.Score, &graded.Feedback)
+	if err == sql.ErrNoRows {
+		return graded, app.NewAppError(404, "submission not found")
+	}
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to get graded submission, cause : %s", err.Error())
+		return graded, err
+	}
+	return graded, nil
+}
+
+// ScoreWithRubric stores the computed score together with the level picked
+// for each criterion, replacing any earlier breakdown.
+func (t *TaskSubmissionRepositoryImpl) ScoreWithRubric(submissionId int64, score int32, feedback string, picks []rubric.Pick) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to begin transaction, cause: %s", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE public.task_submissions
+		SET score = $1, feedback = $2, scored_at = EXTRACT(EPOCH FROM now())
+		WHERE id = $3
+	`, score, feedback, submissionId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to update submission score, cause: %s", err.Error())
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "submission not found")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM task_submission_rubric_scores WHERE submission_id = $1`, submissionId); err != nil {
+		log.Error("[TaskSubmissionRepo] failed to clear rubric scores, cause: %s", err.Error())
+		return err
+	}
+	for _, p := range picks {
+		_, err := tx.Exec(`
+			INSERT INTO task_submission_rubric_scores (submission_id, criterion_id, level_id, comment)
+			VALUES ($1, $2, $3, $4)
+		`, submissionId, p.CriterionID, p.LevelID, p.Comment)
+		if err != nil {
+			log.Error("[TaskSubmissionRepo] failed to insert rubric score, cause: %s", err.Error())
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[TaskSubmissionRepo] failed to commit rubric score, cause: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (t *TaskSubmissionRepositoryImpl) SetUploadJob(submissionId int64, jobId int64) error {
+	if _, err := t.db.Exec(`UPDATE task_submissions SET upload_job_id = $1 WHERE id = $2`, jobId, submissionId); err != nil {
+		log.Error("[TaskSubmissionRepo] failed to set upload job, cause: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// UpdateAttachmentURL sets the attachment of one submission; earlier
+// attempts keep their own files.
+func (t *TaskSubmissionRepositoryImpl) UpdateAttachmentURL(submissionId int64, url string) error {
 	query := `
 		UPDATE task_submissions
 		SET attachment_url = $1
-		WHERE task_id = $2 AND user_id = $3
+		WHERE id = $2
 		`
-	_, err := t.db.Exec(query, url, taskId, userId)
+	_, err := t.db.Exec(query, url, submissionId)
 	if err != nil {
-		log.Error("[TaskSubmissionRepo] Failed to update attachment URL", "error", err, "taskId", taskId, "userId", userId)
+		log.Error("[TaskSubmissionRepo] Failed to update attachment URL", "error", err, "submissionId", submissionId)
 		return err
 	}
 
-	log.Info("[TaskSubmissionRepo] Successfully updated attachment URL", "taskId", taskId, "userId", userId, "url", url)
+	log.Info("[TaskSubmissionRepo] Successfully updated attachment URL", "submissionId", submissionId, "url", url)
+	return nil
+}
+
+// History lists every attempt of the user at the task, oldest first.
+func (t *TaskSubmissionRepositoryImpl) History(taskId int64, userId int64) ([]entity.TaskAttemptDto, error) {
+	rows, err := t.db.Query(`
+		SELECT id, attempt, answer, attachment_url, is_final, is_late, score, feedback, scored_at, created_at
+		FROM task_submissions
+		WHERE task_id = $1 AND user_id = $2
+		ORDER BY attempt
+	`, taskId, userId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to query attempt history, cause: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []entity.TaskAttemptDto{}
+	for rows.Next() {
+		var a entity.TaskAttemptDto
+		if err := rows.Scan(&a.ID, &a.Attempt, &a.Answer, &a.AttachedURL, &a.IsFinal, &a.IsLate, &a.Score, &a.Feedback, &a.ScoredAt, &a.SubmittedAt); err != nil {
+			log.Error("[TaskSubmissionRepo] failed to scan attempt, cause: %s", err.Error())
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// MarkFinal makes one of the user's own attempts the final one in place of
+// the latest.
+func (t *TaskSubmissionRepositoryImpl) MarkFinal(submissionId int64, userId int64) error {
+	res, err := t.db.Exec(`
+		UPDATE task_submissions
+		SET is_final = (id = $1)
+		WHERE (task_id, user_id) = (SELECT task_id, user_id FROM task_submissions WHERE id = $1 AND user_id = $2)
+	`, submissionId, userId)
+	if err != nil {
+		log.Error("[TaskSubmissionRepo] failed to mark final attempt, cause: %s", err.Error())
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(404, "submission not found")
+	}
 	return nil
 }
 
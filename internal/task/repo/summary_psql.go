@@ -1,7 +1,9 @@
 package repo
 
+// GetAvgTotal counts each task once, by its final attempt, so resubmissions
+// do not inflate the total or drag the average.
 func (r *TaskRepositoryImpl) GetAvgTotal(userID int32) (int, float64, error) {
-	taskQuery := `SELECT COUNT(*), COALESCE(AVG(score), 0) FROM task_submissions WHERE user_id = $1`
+	taskQuery := `SELECT COUNT(*), COALESCE(AVG(score), 0) FROM task_submissions WHERE user_id = $1 AND is_final`
 	var taskCount int
 	var avgTask float64
 	err := r.db.QueryRow(taskQuery, userID).Scan(&taskCount, &avgTask)
@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/ghulammuzz/misterblast/internal/task/entity"
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+	"github.com/ghulammuzz/misterblast/internal/task/schedule"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
@@ -19,6 +21,21 @@ type TaskRepository interface {
 	Update(task entity.Task) error
 	Delete(taskId int32) error
 	GetAvgTotal(userID int32) (int, float64, error)
+
+	// Schedule and assignments
+	Window(taskId int32) (schedule.Window, error)
+	UpdateSchedule(taskId int32, window schedule.Window, maxAttempts *int32) error
+	IsAssigned(taskId int32, userId int64) (bool, error)
+	UntaughtClassrooms(teacherId int32, classroomIds []int32) ([]int32, error)
+	MissingUsers(userIds []int32) ([]int32, error)
+	Assign(taskId int32, assignedBy int32, classroomIds, userIds []int32) error
+	Assignments(taskId int32) ([]entity.TaskAssignmentDto, error)
+	Unassign(taskId int32, assignmentId int32) error
+	ListForStudent(userId int32, status string, page, limit int) (*response.PaginateResponse, error)
+
+	// Rubric
+	Rubric(taskId int32) ([]rubric.Criterion, error)
+	SetRubric(taskId int32, criteria []rubric.Criterion) error
 }
 
 type TaskRepositoryImpl struct {
@@ -31,18 +48,25 @@ func NewTaskRepository(db *sql.DB) TaskRepository {
 
 func (r *TaskRepositoryImpl) List(filter map[string]string, page, limit int) (*response.PaginateResponse, error) {
 	var total int64
-	countArgs := []any{}
 	queryArgs := []any{}
 	argIndex := 1
 
-	// --- COUNT QUERY ---
-	queryCount := "SELECT COUNT(*) FROM tasks WHERE deleted_at IS NULL"
+	where := ""
 	if search, ok := filter["search"]; ok && search != "" {
-		queryCount += fmt.Sprintf(" AND title ILIKE $%d", argIndex)
-		countArgs = append(countArgs, "%"+search+"%")
+		where += fmt.Sprintf(" AND t.title ILIKE $%d", argIndex)
+		queryArgs = append(queryArgs, "%"+search+"%")
+		argIndex++
+	}
+	if userID, ok := filter["enrolled_user"]; ok {
+		where += " AND " + openNow + " AND " + visibleTo(argIndex)
+		queryArgs = append(queryArgs, userID)
+		argIndex++
 	}
 
-	err := r.db.QueryRow(queryCount, countArgs...).Scan(&total)
+	// --- COUNT QUERY ---
+	queryCount := "SELECT COUNT(*) FROM tasks t WHERE t.deleted_at IS NULL" + where
+
+	err := r.db.QueryRow(queryCount, queryArgs...).Scan(&total)
 	if err != nil {
 		log.Error("[Repo][Tasks] failed to query count, cause : %s", err.Error())
 		return nil, app.NewAppError(http.StatusInternalServerError, "failed to get count")
@@ -50,14 +74,10 @@ func (r *TaskRepositoryImpl) List(filter map[string]string, page, limit int) (*r
 
 	// --- DATA QUERY ---
 	query := `
-		SELECT t.id, t.title, t.description, t.content, t.updated_at
+		SELECT t.id, t.title, t.description, t.content, t.class_id, t.lesson_id,
+		       t.open_at, t.due_at, t.close_at, t.late_policy, t.max_attempts, t.updated_at
 		FROM tasks t
-		WHERE t.deleted_at IS NULL`
-	if search, ok := filter["search"]; ok && search != "" {
-		query += fmt.Sprintf(" AND title ILIKE $%d", argIndex)
-		queryArgs = append(queryArgs, "%"+search+"%")
-		argIndex++
-	}
+		WHERE t.deleted_at IS NULL` + where
 
 	if limit <= 0 {
 		limit = 10
@@ -78,7 +98,8 @@ func (r *TaskRepositoryImpl) List(filter map[string]string, page, limit int) (*r
 	for rows.Next() {
 		var task entity.TaskResponseDto
 		if err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Content, &task.LastUpdatedAt,
+			&task.ID, &task.Title, &task.Description, &task.Content, &task.ClassID, &task.LessonID,
+			&task.OpenAt, &task.DueAt, &task.CloseAt, &task.LatePolicy, &task.MaxAttempts, &task.LastUpdatedAt,
 		); err != nil {
 			log.Error("[Repo][Tasks] failed to scan tasks, cause : %s", err.Error())
 			return nil, app.NewAppError(http.StatusInternalServerError, "failed to scan task")
@@ -97,13 +118,15 @@ func (r *TaskRepositoryImpl) List(filter map[string]string, page, limit int) (*r
 func (r *TaskRepositoryImpl) Index(taskId int32) (entity.TaskDetailResponseDto, error) {
 	var task entity.TaskDetailResponseDto
 	tasksQuery := `SELECT 
-    t.id, t.title, t.description, t.content, t.updated_at, t.attachment_url
+    t.id, t.title, t.description, t.content, t.updated_at, t.attachment_url, t.class_id,
+    t.lesson_id, t.open_at, t.due_at, t.close_at, t.late_policy, t.max_attempts
 	FROM tasks t  
 	WHERE t.id = $1 AND t.deleted_at IS NULL`
 
 	row := r.db.QueryRow(tasksQuery, taskId)
 	if err := row.Scan(
-		&task.ID, &task.Title, &task.Description, &task.Content, &task.LastUpdatedAt, &task.AttachedURL,
+		&task.ID, &task.Title, &task.Description, &task.Content, &task.LastUpdatedAt, &task.AttachedURL, &task.ClassID,
+		&task.LessonID, &task.OpenAt, &task.DueAt, &task.CloseAt, &task.LatePolicy, &task.MaxAttempts,
 	); err != nil {
 		log.Error("[Repo][Tasks] failed to scan tasks, cause : %s", err.Error())
 		if err.Error() == "sql: no rows in result set" {
@@ -117,8 +140,17 @@ func (r *TaskRepositoryImpl) Index(taskId int32) (entity.TaskDetailResponseDto,
 
 // done
 func (r *TaskRepositoryImpl) Create(task entity.Task) error {
-	query := "INSERT INTO tasks (title, description, content, attachment_url) VALUES ($1, $2, $3, $4)"
-	_, err := r.db.Exec(query, task.Title, task.Description, task.Content, task.AttachedURL)
+	if err := r.checkReference("SELECT EXISTS(SELECT 1 FROM classes WHERE id = $1)", task.ClassID, "class"); err != nil {
+		return err
+	}
+	if err := r.checkReference("SELECT EXISTS(SELECT 1 FROM lessons WHERE id = $1)", task.LessonID, "lesson"); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO tasks (title, description, content, attachment_url, class_id, lesson_id, open_at, due_at, close_at, late_policy, max_attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Exec(query, task.Title, task.Description, task.Content, task.AttachedURL, task.ClassID,
+		task.LessonID, task.OpenAt, task.DueAt, task.CloseAt, task.LatePolicy, task.MaxAttempts)
 	if err != nil {
 		log.Error("[Repo.Task.Create] failed to insert task, cause : %s", err.Error())
 		return err
@@ -126,6 +158,22 @@ func (r *TaskRepositoryImpl) Create(task entity.Task) error {
 	return nil
 }
 
+// checkReference returns 404 when the row a task points at does not exist.
+func (r *TaskRepositoryImpl) checkReference(query string, id *int32, name string) error {
+	if id == nil {
+		return nil
+	}
+	var exists bool
+	if err := r.db.QueryRow(query, *id).Scan(&exists); err != nil {
+		log.Error("[Repo.Task.Create] failed to check %s, cause : %s", name, err.Error())
+		return app.NewAppError(http.StatusInternalServerError, "failed to check "+name)
+	}
+	if !exists {
+		return app.NewAppError(http.StatusNotFound, name+" not found")
+	}
+	return nil
+}
+
 func (r *TaskRepositoryImpl) Update(task entity.Task) error {
 	query := `UPDATE tasks SET title = $1, description = $2, content = $3, attachment_url = $4 updated_at = EXTRACT(EPOCH FROM NOW()) WHERE id = $5`
 	res, err := r.db.Exec(query, task.Title, task.Description, task.Content, task.AttachedURL, task.ID)
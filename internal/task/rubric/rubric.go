@@ -0,0 +1,133 @@
+// Package rubric scores task submissions against a task's rubric. A rubric
+// is a list of weighted criteria, each with performance levels worth some
+// points; a grader picks one level per criterion and the total is the
+// weighted share of the best possible points, out of 100.
+package rubric
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+type Level struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+	Points      int32  `json:"points" validate:"gte=0"`
+}
+
+type Criterion struct {
+	ID          int32   `json:"id"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description"`
+	Weight      int32   `json:"weight" validate:"gt=0"`
+	Levels      []Level `json:"levels" validate:"required,min=1,dive"`
+}
+
+// Pick is the level a grader chose for one criterion.
+type Pick struct {
+	CriterionID int32  `json:"criterion_id" validate:"required,gt=0"`
+	LevelID     int32  `json:"level_id" validate:"required,gt=0"`
+	Comment     string `json:"comment"`
+}
+
+// Validate checks a rubric before it is stored: names are unique, weights
+// positive and every criterion has a level worth points.
+func Validate(criteria []Criterion) error {
+	if len(criteria) == 0 {
+		return app.NewAppError(400, "rubric needs at least one criterion")
+	}
+	names := map[string]bool{}
+	for _, c := range criteria {
+		name := strings.ToLower(strings.TrimSpace(c.Name))
+		if name == "" {
+			return app.NewAppError(400, "criterion name is required")
+		}
+		if names[name] {
+			return app.NewAppError(400, fmt.Sprintf("duplicate criterion %q", c.Name))
+		}
+		names[name] = true
+		if c.Weight <= 0 {
+			return app.NewAppError(400, fmt.Sprintf("criterion %q needs a positive weight", c.Name))
+		}
+		if len(c.Levels) == 0 {
+			return app.NewAppError(400, fmt.Sprintf("criterion %q needs at least one level", c.Name))
+		}
+		levels := map[string]bool{}
+		for _, l := range c.Levels {
+			level := strings.ToLower(strings.TrimSpace(l.Name))
+			if level == "" {
+				return app.NewAppError(400, fmt.Sprintf("criterion %q has a level without a name", c.Name))
+			}
+			if levels[level] {
+				return app.NewAppError(400, fmt.Sprintf("criterion %q has duplicate level %q", c.Name, l.Name))
+			}
+			levels[level] = true
+			if l.Points < 0 {
+				return app.NewAppError(400, fmt.Sprintf("level %q of criterion %q has negative points", l.Name, c.Name))
+			}
+		}
+		if MaxPoints(c) == 0 {
+			return app.NewAppError(400, fmt.Sprintf("criterion %q needs a level worth points", c.Name))
+		}
+	}
+	return nil
+}
+
+// MaxPoints is the most a criterion can earn.
+func MaxPoints(c Criterion) int32 {
+	var max int32
+	for _, l := range c.Levels {
+		if l.Points > max {
+			max = l.Points
+		}
+	}
+	return max
+}
+
+// Score checks that picks choose exactly one known level for every criterion
+// and returns the total out of 100, rounded to the nearest point.
+func Score(criteria []Criterion, picks []Pick) (int32, error) {
+	chosen := make(map[int32]int32, len(picks))
+	for _, p := range picks {
+		if _, dup := chosen[p.CriterionID]; dup {
+			return 0, app.NewAppError(400, fmt.Sprintf("criterion %d is scored twice", p.CriterionID))
+		}
+		chosen[p.CriterionID] = p.LevelID
+	}
+	if len(chosen) != len(criteria) {
+		for _, c := range criteria {
+			if _, ok := chosen[c.ID]; !ok {
+				return 0, app.NewAppError(400, fmt.Sprintf("criterion %q is not scored", c.Name))
+			}
+		}
+		return 0, app.NewAppError(400, "picks name criteria outside the rubric")
+	}
+
+	var earned, weights float64
+	for _, c := range criteria {
+		levelID, ok := chosen[c.ID]
+		if !ok {
+			return 0, app.NewAppError(400, fmt.Sprintf("criterion %q is not scored", c.Name))
+		}
+		level, ok := findLevel(c, levelID)
+		if !ok {
+			return 0, app.NewAppError(400, fmt.Sprintf("level %d does not belong to criterion %q", levelID, c.Name))
+		}
+		weights += float64(c.Weight)
+		earned += float64(c.Weight) * float64(level.Points) / float64(MaxPoints(c))
+	}
+	return int32(math.Round(100 * earned / weights)), nil
+}
+
+func findLevel(c Criterion, id int32) (Level, bool) {
+	for _, l := range c.Levels {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return Level{}, false
+}
@@ -0,0 +1,66 @@
+package rubric_test
+
+import (
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func sample() []rubric.Criterion {
+	return []rubric.Criterion{
+		{ID: 1, Name: "Content", Weight: 3, Levels: []rubric.Level{
+			{ID: 10, Name: "Weak", Points: 0},
+			{ID: 11, Name: "Good", Points: 2},
+			{ID: 12, Name: "Excellent", Points: 4},
+		}},
+		{ID: 2, Name: "Grammar", Weight: 1, Levels: []rubric.Level{
+			{ID: 20, Name: "Poor", Points: 1},
+			{ID: 21, Name: "Clean", Points: 2},
+		}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, rubric.Validate(sample()))
+
+	dupCriterion := sample()
+	dupCriterion[1].Name = " content "
+	dupLevel := sample()
+	dupLevel[0].Levels[1].Name = "weak"
+	noWeight := sample()
+	noWeight[0].Weight = 0
+	noPoints := sample()
+	noPoints[1].Levels = []rubric.Level{{Name: "Any", Points: 0}}
+
+	for _, criteria := range [][]rubric.Criterion{nil, dupCriterion, dupLevel, noWeight, noPoints} {
+		err := rubric.Validate(criteria)
+		if assert.Error(t, err) {
+			assert.Equal(t, 400, err.(*app.AppError).Code)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	total, err := rubric.Score(sample(), []rubric.Pick{{CriterionID: 1, LevelID: 12}, {CriterionID: 2, LevelID: 21}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(100), total)
+
+	// 3/4 * 2/4 + 1/4 * 1/2 = 0.5
+	total, err = rubric.Score(sample(), []rubric.Pick{{CriterionID: 1, LevelID: 11}, {CriterionID: 2, LevelID: 20}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(50), total)
+
+	_, err = rubric.Score(sample(), []rubric.Pick{{CriterionID: 1, LevelID: 11}})
+	assert.EqualError(t, err, `criterion "Grammar" is not scored`)
+
+	_, err = rubric.Score(sample(), []rubric.Pick{{CriterionID: 1, LevelID: 11}, {CriterionID: 1, LevelID: 12}})
+	assert.EqualError(t, err, "criterion 1 is scored twice")
+
+	_, err = rubric.Score(sample(), []rubric.Pick{{CriterionID: 1, LevelID: 20}, {CriterionID: 2, LevelID: 20}})
+	assert.EqualError(t, err, `level 20 does not belong to criterion "Content"`)
+
+	_, err = rubric.Score(sample(), []rubric.Pick{{CriterionID: 1, LevelID: 11}, {CriterionID: 2, LevelID: 20}, {CriterionID: 3, LevelID: 30}})
+	assert.EqualError(t, err, "picks name criteria outside the rubric")
+}
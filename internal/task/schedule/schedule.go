@@ -0,0 +1,74 @@
+// Package schedule decides when a task accepts submissions. A task may have
+// an open time, a due time after which submissions are late, and a close time
+// after which nothing is accepted; what happens to late work is the task's
+// late policy.
+package schedule
+
+import (
+	"fmt"
+
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+type LatePolicy string
+
+const (
+	// PolicyFlag accepts late submissions and marks them late.
+	PolicyFlag LatePolicy = "flag"
+	// PolicyReject refuses submissions once the task is due.
+	PolicyReject LatePolicy = "reject"
+)
+
+func (p LatePolicy) Valid() bool {
+	return p == PolicyFlag || p == PolicyReject
+}
+
+// Status of an assigned task from a student's point of view.
+const (
+	StatusPending   = "pending"
+	StatusOverdue   = "overdue"
+	StatusSubmitted = "submitted"
+)
+
+// Window holds a task's timestamps in epoch seconds; nil means unbounded.
+type Window struct {
+	OpenAt  *int64
+	DueAt   *int64
+	CloseAt *int64
+	Policy  LatePolicy
+}
+
+// Validate checks the policy and that open, due and close are in order.
+func (w Window) Validate() error {
+	if !w.Policy.Valid() {
+		return app.NewAppError(400, fmt.Sprintf("late_policy must be one of %s, %s", PolicyFlag, PolicyReject))
+	}
+	if w.OpenAt != nil && w.DueAt != nil && *w.DueAt < *w.OpenAt {
+		return app.NewAppError(400, "due_at must not be before open_at")
+	}
+	if w.OpenAt != nil && w.CloseAt != nil && *w.CloseAt < *w.OpenAt {
+		return app.NewAppError(400, "close_at must not be before open_at")
+	}
+	if w.DueAt != nil && w.CloseAt != nil && *w.CloseAt < *w.DueAt {
+		return app.NewAppError(400, "close_at must not be before due_at")
+	}
+	return nil
+}
+
+// Check decides a submission made at now. It returns whether the submission
+// is late, or an error when the window does not accept it.
+func (w Window) Check(now int64) (bool, error) {
+	if w.OpenAt != nil && now < *w.OpenAt {
+		return false, app.NewAppError(403, "task is not open yet")
+	}
+	if w.CloseAt != nil && now > *w.CloseAt {
+		return false, app.NewAppError(403, "task is closed")
+	}
+	if w.DueAt == nil || now <= *w.DueAt {
+		return false, nil
+	}
+	if w.Policy == PolicyReject {
+		return false, app.NewAppError(403, "task is past its due date")
+	}
+	return true, nil
+}
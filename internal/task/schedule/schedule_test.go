@@ -0,0 +1,56 @@
+package schedule_test
+
+import (
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/internal/task/schedule"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(t int64) *int64 { return &t }
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, schedule.Window{Policy: schedule.PolicyFlag}.Validate())
+	assert.NoError(t, schedule.Window{OpenAt: at(10), DueAt: at(20), CloseAt: at(20), Policy: schedule.PolicyReject}.Validate())
+
+	for _, w := range []schedule.Window{
+		{Policy: "lenient"},
+		{OpenAt: at(20), DueAt: at(10), Policy: schedule.PolicyFlag},
+		{OpenAt: at(20), CloseAt: at(10), Policy: schedule.PolicyFlag},
+		{DueAt: at(20), CloseAt: at(10), Policy: schedule.PolicyFlag},
+	} {
+		err := w.Validate()
+		if assert.Error(t, err) {
+			assert.Equal(t, 400, err.(*app.AppError).Code)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	w := schedule.Window{OpenAt: at(10), DueAt: at(20), CloseAt: at(30), Policy: schedule.PolicyFlag}
+
+	_, err := w.Check(5)
+	assert.EqualError(t, err, "task is not open yet")
+
+	late, err := w.Check(20)
+	assert.NoError(t, err)
+	assert.False(t, late)
+
+	late, err = w.Check(25)
+	assert.NoError(t, err)
+	assert.True(t, late)
+
+	_, err = w.Check(31)
+	assert.EqualError(t, err, "task is closed")
+
+	w.Policy = schedule.PolicyReject
+	_, err = w.Check(25)
+	assert.EqualError(t, err, "task is past its due date")
+}
+
+func TestCheck_Unbounded(t *testing.T) {
+	late, err := schedule.Window{Policy: schedule.PolicyReject}.Check(1 << 40)
+	assert.NoError(t, err)
+	assert.False(t, late)
+}
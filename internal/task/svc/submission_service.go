@@ -1,15 +1,22 @@
 package svc
 
 import (
+	"context"
 	"fmt"
-	"mime/multipart"
+	"time"
 
+	gamificationSvc "github.com/ghulammuzz/misterblast/internal/gamification/svc"
 	"github.com/ghulammuzz/misterblast/internal/task/entity"
 	"github.com/ghulammuzz/misterblast/internal/task/repo"
-	"github.com/ghulammuzz/misterblast/pkg/agent"
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+	uploadEntity "github.com/ghulammuzz/misterblast/internal/upload/entity"
+	uploadRepo "github.com/ghulammuzz/misterblast/internal/upload/repo"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 )
 
 type TaskSubmissionService interface {
@@ -17,48 +24,178 @@ type TaskSubmissionService interface {
 	GiveScore(submissionId int64, dto entity.ScoreSubmissionRequestDto) error
 	GetSubmissionsByUser(filter map[string]string, userId int64) (*response.PaginateResponse, error)
 	GetSubmissionsByTask(filter map[string]string, taskId int64) (*response.PaginateResponse, error)
-	GetSubmissionDetailById(submissionId int64) (*entity.TaskSubmissionDetailResponseDto, error)
+	GetSubmissionDetailById(submissionId int64, userId int64, staff bool) (*entity.TaskSubmissionDetailResponseDto, error)
+	History(taskId int64, userId int64) (*entity.TaskAttemptHistoryDto, error)
+	MarkFinal(submissionId int64, userId int64) error
+
+	// staff is true for callers allowed to view every submission; others may
+	// only read and comment on their own.
+	Comments(submissionId int64, userId int64, staff bool) (*entity.SubmissionThreadsDto, error)
+	AddComment(submissionId int64, userId int64, staff bool, dto entity.CreateCommentRequestDto) (int64, error)
+	MarkCommentsRead(submissionId int64, userId int64, staff bool) error
+	UnreadFeedback(userId int64) ([]entity.UnreadFeedbackDto, error)
 }
 
 type TaskSubmissionServiceImpl struct {
-	repo repo.TaskSubmissionRepository
+	repo         repo.TaskSubmissionRepository
+	tasks        repo.TaskRepository
+	gamification gamificationSvc.GamificationService
+	uploads      uploadRepo.UploadRepository
+	jobs         queue.Enqueuer
+	mail         mailer.Sender
 }
 
-func NewTaskSubmissionService(repo repo.TaskSubmissionRepository) TaskSubmissionService {
-	return &TaskSubmissionServiceImpl{repo: repo}
+func NewTaskSubmissionService(repo repo.TaskSubmissionRepository, tasks repo.TaskRepository, gamification gamificationSvc.GamificationService, uploads uploadRepo.UploadRepository, jobs queue.Enqueuer, mail mailer.Sender) TaskSubmissionService {
+	return &TaskSubmissionServiceImpl{repo: repo, tasks: tasks, gamification: gamification, uploads: uploads, jobs: jobs, mail: mail}
 }
 
+// SubmitTask only accepts work from students the task is assigned to and
+// inside its open/close window. Work after the due time is refused or
+// stored as late, depending on the task's late policy.
 func (s *TaskSubmissionServiceImpl) SubmitTask(taskId int64, userId int64, dto entity.SubmitTaskRequestDto) error {
-	err := s.repo.Create(taskId, userId, dto.Answer, "")
+	window, err := s.tasks.Window(int32(taskId))
+	if err != nil {
+		return err
+	}
+	assigned, err := s.tasks.IsAssigned(int32(taskId), userId)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return app.NewAppError(403, "task is not assigned to you")
+	}
+	late, err := window.Check(time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	// The file is read up front so the upload job can outlive this request.
+	// Its type is taken from its magic bytes, never from its name.
+	var attachment []byte
+	var attachmentType string
+	if dto.AttachedURL != nil {
+		attachment, err = storage.ReadFile(dto.AttachedURL)
+		if err != nil {
+			log.Error("[TaskSubmissionSvc] Failed to read attachment", "error", err)
+			return app.NewAppError(400, "failed to read attachment")
+		}
+		var ok bool
+		if attachmentType, ok = storage.Match(attachment, uploadEntity.TaskAttachmentTypes); !ok {
+			return app.NewAppError(400, fmt.Sprintf("attachment of type %q is not allowed", storage.Sniff(attachment)))
+		}
+	}
+
+	// A file uploaded directly to storage is claimed now, so it cannot be
+	// attached to a second submission.
+	var attachedURL string
+	if dto.UploadID != nil {
+		attachedURL, err = s.uploads.Link(*dto.UploadID, userId, uploadEntity.PurposeTaskAttachment)
+		if err != nil {
+			return err
+		}
+	}
+
+	id, attempt, err := s.repo.Create(taskId, userId, dto.Answer, attachedURL, late)
 	if err != nil {
+		if dto.UploadID != nil {
+			if err := s.uploads.Unlink(*dto.UploadID); err != nil {
+				log.Warn("[TaskSubmissionSvc] Failed to release upload", "error", err)
+			}
+		}
+		if _, ok := err.(*app.AppError); ok {
+			return err
+		}
 		log.Error("[TaskSubmissionSvc] Failed to create task submission", "error", err)
 		return app.NewAppError(500, "failed to create task submission")
 	}
 
+	// Only the first attempt earns XP, so resubmitting cannot be farmed.
+	if attempt == 1 {
+		if err := s.gamification.RecordTaskSubmission(int(id)); err != nil {
+			log.Warn("[TaskSubmissionSvc] Failed to record progress", "error", err)
+		}
+	}
+
 	if dto.AttachedURL != nil {
-		go func(file *multipart.FileHeader, taskId int64, userId int64) {
-			url, err := agent.FileUploadProxyRESTY(file, fmt.Sprintf("/prod/user/%d/task-submission/%d", taskId, userId))
-			if err != nil {
-				log.Error("[TaskSubmissionSvc] Failed to upload attachment in background", "error", err)
-				return
-			}
-			err = s.repo.UpdateAttachmentURL(taskId, userId, url)
-			if err != nil {
-				log.Error("[TaskSubmissionSvc] Failed to update attachment URL after upload", "error", err)
-			} else {
-				log.Info("[TaskSubmissionSvc] Successfully updated attachment URL", "url", url)
-			}
-		}(dto.AttachedURL, taskId, userId)
+		if err := s.queueAttachment(id, userId, dto.AttachedURL.Filename, attachmentType, attachment); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// queueAttachment enqueues the upload of a submission's file and records the
+// job on the submission, so its status shows on the submission detail.
+func (s *TaskSubmissionServiceImpl) queueAttachment(submissionId int64, userId int64, filename string, contentType string, content []byte) error {
+	jobId, err := s.jobs.Enqueue(context.Background(), JobSubmissionAttachment, submissionAttachmentJob{
+		SubmissionID: submissionId,
+		UserID:       userId,
+		Filename:     filename,
+		ContentType:  contentType,
+	}, content)
+	if err != nil {
+		return app.NewAppError(500, "submission saved but its attachment could not be queued for upload")
+	}
+	if err := s.repo.SetUploadJob(submissionId, jobId); err != nil {
+		log.Warn("[TaskSubmissionSvc] Failed to link upload job", "error", err)
+	}
+	return nil
+}
+
+// GiveScore scores a submission and emails the student their result. When
+// its task has a rubric the score is computed from the level picked for
+// each criterion.
 func (s *TaskSubmissionServiceImpl) GiveScore(submissionId int64, dto entity.ScoreSubmissionRequestDto) error {
+	taskId, err := s.repo.TaskID(submissionId)
+	if err != nil {
+		return err
+	}
+	criteria, err := s.tasks.Rubric(taskId)
+	if err != nil {
+		return err
+	}
+	if len(criteria) > 0 {
+		score, err := rubric.Score(criteria, dto.Rubric)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.ScoreWithRubric(submissionId, score, dto.Feedback, dto.Rubric); err != nil {
+			return err
+		}
+		s.notifyGraded(submissionId)
+		return nil
+	}
+	if len(dto.Rubric) > 0 {
+		return app.NewAppError(400, "task has no rubric : task.submission.rubric_missing")
+	}
+
 	if dto.Score < 0 || dto.Score > 100 {
 		return app.NewAppError(400, "score must be between 0 and 100 : task.submission.score_invalid")
 	}
-	return s.repo.ScoreSubmission(submissionId, dto)
+	if err := s.repo.ScoreSubmission(submissionId, dto); err != nil {
+		return err
+	}
+	s.notifyGraded(submissionId)
+	return nil
+}
+
+// notifyGraded queues the "task graded" email. The score is already saved,
+// so failures are only logged.
+func (s *TaskSubmissionServiceImpl) notifyGraded(submissionId int64) {
+	graded, err := s.repo.Graded(submissionId)
+	if err != nil {
+		log.Warn("[TaskSubmissionSvc] Failed to load graded submission", "error", err)
+		return
+	}
+	err = s.mail.Send(context.Background(), mailer.Email{
+		UserID:   graded.UserID,
+		Template: mailer.TemplateTaskGraded,
+		Data:     map[string]any{"Task": graded.TaskTitle, "Score": graded.Score, "Feedback": graded.Feedback},
+	})
+	if err != nil {
+		log.Warn("[TaskSubmissionSvc] Failed to queue graded email", "error", err)
+	}
 }
 
 func (s *TaskSubmissionServiceImpl) GetSubmissionsByUser(filter map[string]string, userId int64) (*response.PaginateResponse, error) {
@@ -69,6 +206,138 @@ func (s *TaskSubmissionServiceImpl) GetSubmissionsByTask(filter map[string]strin
 	return s.repo.LIstByTaskId(filter, taskId)
 }
 
-func (s *TaskSubmissionServiceImpl) GetSubmissionDetailById(submissionId int64) (*entity.TaskSubmissionDetailResponseDto, error) {
+func (s *TaskSubmissionServiceImpl) History(taskId int64, userId int64) (*entity.TaskAttemptHistoryDto, error) {
+	task, err := s.tasks.Index(int32(taskId))
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.History(taskId, userId)
+	if err != nil {
+		return nil, app.NewAppError(500, "failed to get attempt history")
+	}
+	return &entity.TaskAttemptHistoryDto{
+		TaskID:      task.ID,
+		UserID:      userId,
+		MaxAttempts: task.MaxAttempts,
+		Attempts:    attempts,
+	}, nil
+}
+
+func (s *TaskSubmissionServiceImpl) MarkFinal(submissionId int64, userId int64) error {
+	if err := s.repo.MarkFinal(submissionId, userId); err != nil {
+		if _, ok := err.(*app.AppError); ok {
+			return err
+		}
+		return app.NewAppError(500, "failed to mark final attempt")
+	}
+	return nil
+}
+
+func (s *TaskSubmissionServiceImpl) GetSubmissionDetailById(submissionId int64, userId int64, staff bool) (*entity.TaskSubmissionDetailResponseDto, error) {
+	if _, err := s.commentTarget(submissionId, userId, staff); err != nil {
+		return nil, err
+	}
 	return s.repo.SubmissionDetailById(submissionId)
 }
+
+func (s *TaskSubmissionServiceImpl) commentTarget(submissionId int64, userId int64, staff bool) (entity.CommentTarget, error) {
+	target, err := s.repo.CommentTarget(submissionId)
+	if err != nil {
+		if _, ok := err.(*app.AppError); ok {
+			return target, err
+		}
+		return target, app.NewAppError(500, "failed to get submission")
+	}
+	if !staff && target.OwnerID != userId {
+		return target, app.NewAppError(403, "not your submission")
+	}
+	return target, nil
+}
+
+// Comments returns the submission's feedback as threads: each thread starter
+// with its replies, oldest first.
+func (s *TaskSubmissionServiceImpl) Comments(submissionId int64, userId int64, staff bool) (*entity.SubmissionThreadsDto, error) {
+	target, err := s.commentTarget(submissionId, userId, staff)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.Comments(submissionId, userId)
+	if err != nil {
+		return nil, app.NewAppError(500, "failed to get comments")
+	}
+
+	result := &entity.SubmissionThreadsDto{SubmissionID: submissionId, Threads: []entity.SubmissionCommentDto{}}
+	roots := map[int64]int{}
+	for _, c := range comments {
+		if !c.IsRead {
+			result.Unread++
+		}
+		if c.ParentID == nil {
+			if c.Anchor != nil {
+				c.Quote = c.Anchor.Quote(target.Answer)
+			}
+			roots[c.ID] = len(result.Threads)
+			result.Threads = append(result.Threads, c)
+			continue
+		}
+		if i, ok := roots[*c.ParentID]; ok {
+			result.Threads[i].Replies = append(result.Threads[i].Replies, c)
+		}
+	}
+	return result, nil
+}
+
+func (s *TaskSubmissionServiceImpl) AddComment(submissionId int64, userId int64, staff bool, dto entity.CreateCommentRequestDto) (int64, error) {
+	target, err := s.commentTarget(submissionId, userId, staff)
+	if err != nil {
+		return 0, err
+	}
+
+	if dto.ParentID != nil {
+		if dto.Anchor != nil {
+			return 0, app.NewAppError(400, "replies cannot be anchored")
+		}
+		parentSubmission, isRoot, err := s.repo.CommentThread(*dto.ParentID)
+		if err != nil {
+			if _, ok := err.(*app.AppError); ok {
+				return 0, err
+			}
+			return 0, app.NewAppError(500, "failed to get comment")
+		}
+		if parentSubmission != submissionId {
+			return 0, app.NewAppError(404, "comment not found")
+		}
+		if !isRoot {
+			return 0, app.NewAppError(400, "reply to the thread, not to a reply")
+		}
+	}
+	if dto.Anchor != nil {
+		if err := dto.Anchor.Validate(target.Answer, target.HasAttachment); err != nil {
+			return 0, err
+		}
+	}
+
+	id, err := s.repo.AddComment(submissionId, userId, dto)
+	if err != nil {
+		return 0, app.NewAppError(500, "failed to add comment")
+	}
+	return id, nil
+}
+
+func (s *TaskSubmissionServiceImpl) MarkCommentsRead(submissionId int64, userId int64, staff bool) error {
+	if _, err := s.commentTarget(submissionId, userId, staff); err != nil {
+		return err
+	}
+	if err := s.repo.MarkCommentsRead(submissionId, userId); err != nil {
+		return app.NewAppError(500, "failed to mark comments read")
+	}
+	return nil
+}
+
+func (s *TaskSubmissionServiceImpl) UnreadFeedback(userId int64) ([]entity.UnreadFeedbackDto, error) {
+	unread, err := s.repo.UnreadFeedback(userId)
+	if err != nil {
+		return nil, app.NewAppError(500, "failed to get unread feedback")
+	}
+	return unread, nil
+}
@@ -1,8 +1,15 @@
 package svc
 
 import (
+	"fmt"
+	"strings"
+
+	classroomEntity "github.com/ghulammuzz/misterblast/internal/classroom/entity"
 	"github.com/ghulammuzz/misterblast/internal/task/entity"
 	"github.com/ghulammuzz/misterblast/internal/task/repo"
+	"github.com/ghulammuzz/misterblast/internal/task/rubric"
+	"github.com/ghulammuzz/misterblast/internal/task/schedule"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 )
 
@@ -12,6 +19,15 @@ type TaskService interface {
 	Index(taskId int32) (entity.TaskDetailResponseDto, error)
 	Delete(taskId int32) error
 	Update(taskId int32, task entity.UpdateTaskRequestDto) error
+
+	UpdateSchedule(taskId int32, dto entity.TaskScheduleRequestDto) error
+	Assign(caller classroomEntity.Caller, taskId int32, dto entity.AssignTaskRequestDto) error
+	Assignments(taskId int32) ([]entity.TaskAssignmentDto, error)
+	Unassign(taskId int32, assignmentId int32) error
+	ListForStudent(userId int32, status string, page, limit int) (*response.PaginateResponse, error)
+
+	Rubric(taskId int32) ([]rubric.Criterion, error)
+	SetRubric(taskId int32, dto entity.TaskRubricRequestDto) error
 }
 
 type TaskServiceImpl struct {
@@ -41,11 +57,26 @@ func (t *TaskServiceImpl) Index(taskId int32) (entity.TaskDetailResponseDto, err
 }
 
 func (t *TaskServiceImpl) Create(task entity.CreateTaskRequestDto) error {
+	if task.LatePolicy == "" {
+		task.LatePolicy = string(schedule.PolicyFlag)
+	}
+	window := schedule.Window{OpenAt: task.OpenAt, DueAt: task.DueAt, CloseAt: task.CloseAt, Policy: schedule.LatePolicy(task.LatePolicy)}
+	if err := window.Validate(); err != nil {
+		return err
+	}
+
 	taskEntity := entity.Task{
 		Title:       task.Title,
 		Description: task.Description,
 		Content:     task.Content,
 		AttachedURL: task.AttachedURL,
+		ClassID:     task.ClassID,
+		LessonID:    task.LessonID,
+		OpenAt:      task.OpenAt,
+		DueAt:       task.DueAt,
+		CloseAt:     task.CloseAt,
+		LatePolicy:  task.LatePolicy,
+		MaxAttempts: task.MaxAttempts,
 	}
 	return t.repo.Create(taskEntity)
 }
@@ -54,3 +85,91 @@ func (t *TaskServiceImpl) Delete(taskId int32) error {
 	return t.repo.Delete(taskId)
 
 }
+
+func (t *TaskServiceImpl) UpdateSchedule(taskId int32, dto entity.TaskScheduleRequestDto) error {
+	window := schedule.Window{OpenAt: dto.OpenAt, DueAt: dto.DueAt, CloseAt: dto.CloseAt, Policy: schedule.LatePolicy(dto.LatePolicy)}
+	if err := window.Validate(); err != nil {
+		return err
+	}
+	return t.repo.UpdateSchedule(taskId, window, dto.MaxAttempts)
+}
+
+func (t *TaskServiceImpl) Rubric(taskId int32) ([]rubric.Criterion, error) {
+	if _, err := t.repo.Window(taskId); err != nil {
+		return nil, err
+	}
+	return t.repo.Rubric(taskId)
+}
+
+// SetRubric replaces the task's rubric; it cannot change once submissions
+// have been scored with it.
+func (t *TaskServiceImpl) SetRubric(taskId int32, dto entity.TaskRubricRequestDto) error {
+	if err := rubric.Validate(dto.Criteria); err != nil {
+		return err
+	}
+	return t.repo.SetRubric(taskId, dto.Criteria)
+}
+
+// Assign targets the task at classrooms and single students. Teachers may
+// only assign to classrooms they teach; admins to any classroom.
+func (t *TaskServiceImpl) Assign(caller classroomEntity.Caller, taskId int32, dto entity.AssignTaskRequestDto) error {
+	if len(dto.ClassroomIDs) == 0 && len(dto.UserIDs) == 0 {
+		return app.NewAppError(400, "classroom_ids or user_ids is required")
+	}
+	if _, err := t.repo.Window(taskId); err != nil {
+		return err
+	}
+
+	teacherId := caller.UserID
+	if caller.Admin {
+		teacherId = 0
+	}
+	if len(dto.ClassroomIDs) > 0 {
+		untaught, err := t.repo.UntaughtClassrooms(teacherId, dto.ClassroomIDs)
+		if err != nil {
+			return err
+		}
+		if len(untaught) > 0 {
+			return app.NewAppError(403, fmt.Sprintf("cannot assign to classrooms %s", joinIDs(untaught)))
+		}
+	}
+	if len(dto.UserIDs) > 0 {
+		missing, err := t.repo.MissingUsers(dto.UserIDs)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return app.NewAppError(404, fmt.Sprintf("users %s not found", joinIDs(missing)))
+		}
+	}
+
+	return t.repo.Assign(taskId, caller.UserID, dto.ClassroomIDs, dto.UserIDs)
+}
+
+func (t *TaskServiceImpl) Assignments(taskId int32) ([]entity.TaskAssignmentDto, error) {
+	if _, err := t.repo.Window(taskId); err != nil {
+		return nil, err
+	}
+	return t.repo.Assignments(taskId)
+}
+
+func (t *TaskServiceImpl) Unassign(taskId int32, assignmentId int32) error {
+	return t.repo.Unassign(taskId, assignmentId)
+}
+
+func (t *TaskServiceImpl) ListForStudent(userId int32, status string, page, limit int) (*response.PaginateResponse, error) {
+	switch status {
+	case "", schedule.StatusPending, schedule.StatusOverdue, schedule.StatusSubmitted:
+	default:
+		return nil, app.NewAppError(400, fmt.Sprintf("status must be one of %s, %s, %s", schedule.StatusPending, schedule.StatusOverdue, schedule.StatusSubmitted))
+	}
+	return t.repo.ListForStudent(userId, status, page, limit)
+}
+
+func joinIDs(ids []int32) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprint(id)
+	}
+	return strings.Join(parts, ", ")
+}
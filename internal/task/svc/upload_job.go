@@ -0,0 +1,49 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ghulammuzz/misterblast/internal/task/repo"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+// JobSubmissionAttachment uploads the file of a task submission; the job's
+// blob is the file content.
+const JobSubmissionAttachment = "task.submission_attachment"
+
+type submissionAttachmentJob struct {
+	SubmissionID int64  `json:"submission_id"`
+	UserID       int64  `json:"user_id"`
+	Filename     string `json:"filename"`
+	ContentType  string `json:"content_type"`
+}
+
+// SubmissionAttachmentHandler stores the uploaded file and points the
+// submission at it.
+func SubmissionAttachmentHandler(submissions repo.TaskSubmissionRepository, files storage.Storage) queue.Handler {
+	return func(ctx context.Context, job queue.Job) error {
+		var p submissionAttachmentJob
+		if err := job.Bind(&p); err != nil {
+			return queue.Permanent(err)
+		}
+		key := fmt.Sprintf("/prod/user/%d/task-submission/%d", p.UserID, p.SubmissionID)
+		// Jobs queued before the type was checked up front have none.
+		contentType := p.ContentType
+		if contentType == "" {
+			contentType = storage.Sniff(job.Blob)
+		}
+		url, err := files.Put(ctx, key, bytes.NewReader(job.Blob), contentType)
+		if err != nil {
+			return err
+		}
+		if err := submissions.UpdateAttachmentURL(p.SubmissionID, url); err != nil {
+			return err
+		}
+		log.Info("[TaskSubmissionSvc] Successfully updated attachment URL", "url", url)
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+//go:build wireinject
+// +build wireinject
+
+package di
+
+import (
+	"database/sql"
+
+	uploadHandler "github.com/ghulammuzz/misterblast/internal/upload/handler"
+	uploadRepo "github.com/ghulammuzz/misterblast/internal/upload/repo"
+	uploadSvc "github.com/ghulammuzz/misterblast/internal/upload/svc"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedUploadServiceFake(sb *sql.DB, files storage.Storage, val *validator.Validate) *uploadHandler.UploadHandler {
+	wire.Build(
+		uploadHandler.NewUploadHandler,
+		uploadSvc.NewUploadService,
+		uploadRepo.NewUploadRepository,
+		imaging.NewPipeline,
+		wire.Bind(new(imaging.Putter), new(storage.Storage)),
+		wire.Bind(new(imaging.Saver), new(*imaging.Pipeline)),
+	)
+
+	return &uploadHandler.UploadHandler{}
+}
@@ -0,0 +1,65 @@
+package entity
+
+// Purposes say what an upload will be attached to. Answer images are not
+// linked to anything; their URL is set as an answer's img_url.
+const (
+	PurposeTaskAttachment = "task_attachment"
+	PurposeProfileImage   = "profile_image"
+	PurposeAnswerImage    = "answer_image"
+)
+
+// ImageTypes are the image formats the imaging pipeline reads.
+var ImageTypes = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+
+// TaskAttachmentTypes are the files a task submission may attach, the more
+// specific types first. Scriptable formats such as SVG are left out.
+var TaskAttachmentTypes = append(append([]string{}, ImageTypes...),
+	"application/pdf",
+	"application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"application/zip", "text/plain",
+)
+
+// An upload is pending until the client confirms it, confirmed once the
+// stored object has been checked, and linked once it is attached.
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusLinked    = "linked"
+)
+
+type Upload struct {
+	ID          int64
+	UserID      int64
+	Purpose     string
+	Key         string
+	ContentType string
+	Size        int64
+	Status      string
+	URL         string
+	ExpiresAt   int64
+}
+
+type CreateUploadRequestDto struct {
+	Purpose     string `json:"purpose" validate:"required,oneof=task_attachment profile_image answer_image"`
+	ContentType string `json:"content_type" validate:"required,max=100"`
+	Size        int64  `json:"size" validate:"required,gt=0"`
+}
+
+// UploadTicketDto tells the client how to upload: send Method to URL with
+// Headers and exactly the declared number of bytes before ExpiresAt.
+type UploadTicketDto struct {
+	ID        int64             `json:"upload_id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+type ConfirmedUploadDto struct {
+	ID     int64  `json:"upload_id"`
+	Status string `json:"status"`
+	URL    string `json:"url"`
+}
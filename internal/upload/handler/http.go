@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"github.com/ghulammuzz/misterblast/internal/upload/entity"
+	"github.com/ghulammuzz/misterblast/internal/upload/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	m "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type UploadHandler struct {
+	uploadService svc.UploadService
+	val           *validator.Validate
+}
+
+func NewUploadHandler(uploadService svc.UploadService, val *validator.Validate) *UploadHandler {
+	return &UploadHandler{uploadService, val}
+}
+
+// Router exposes direct-to-storage uploads: POST /uploads returns a
+// presigned URL, the client PUTs the file there, then confirms it.
+func (h *UploadHandler) Router(r fiber.Router) {
+	r.Post("/uploads", m.R100(), m.JWTProtected(), h.CreateHandler)
+	r.Post("/uploads/:id/confirm", m.R100(), m.JWTProtected(), h.ConfirmHandler)
+}
+
+func (h *UploadHandler) CreateHandler(c *fiber.Ctx) error {
+	userID, ok := userFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var upload entity.CreateUploadRequestDto
+	if err := c.BodyParser(&upload); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+	if err := h.val.Struct(upload); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", app.ValidationErrorResponse(err))
+	}
+	// Answer images end up in question banks, so only question editors may
+	// upload them.
+	if upload.Purpose == entity.PurposeAnswerImage {
+		claims, _ := c.Locals("claims").(jwt.MapClaims)
+		if !m.RoleFromClaims(claims).Can(m.PermManageQuestions) {
+			return response.SendError(c, fiber.StatusForbidden, "Forbidden", fiber.Map{"permission": m.PermManageQuestions})
+		}
+	}
+
+	ticket, err := h.uploadService.Create(c.UserContext(), userID, upload)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "upload created successfully", ticket)
+}
+
+func (h *UploadHandler) ConfirmHandler(c *fiber.Ctx) error {
+	userID, ok := userFromCtx(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid upload id", nil)
+	}
+
+	confirmed, err := h.uploadService.Confirm(c.UserContext(), int64(id), userID)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "upload confirmed successfully", confirmed)
+}
+
+func userFromCtx(c *fiber.Ctx) (int64, bool) {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int64(userID), true
+}
@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/ghulammuzz/misterblast/internal/upload/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type UploadRepository interface {
+	Create(upload entity.Upload) (int64, error)
+	Get(id int64) (entity.Upload, error)
+	// Confirm records the URL of a checked upload; it fails with 409 once
+	// the upload is no longer pending.
+	Confirm(id int64, url string) error
+	// Link claims a confirmed upload of userId made for purpose and returns
+	// its URL; an upload can only be linked once.
+	Link(id int64, userId int64, purpose string) (string, error)
+	// Unlink hands a linked upload back when attaching it failed.
+	Unlink(id int64) error
+	// LinkProfileImage links a confirmed profile image and makes it the
+	// user's picture.
+	LinkProfileImage(id int64, userId int64) (string, error)
+}
+
+type uploadRepository struct {
+	db *sql.DB
+}
+
+func NewUploadRepository(db *sql.DB) UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+func (r *uploadRepository) Create(upload entity.Upload) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`
+		INSERT INTO uploads (user_id, purpose, object_key, content_type, size, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, upload.UserID, upload.Purpose, upload.Key, upload.ContentType, upload.Size, upload.ExpiresAt).Scan(&id)
+	if err != nil {
+		log.Error("[Repo][CreateUpload] Error Insert: ", err)
+		return 0, app.NewAppError(500, "failed to create upload")
+	}
+	return id, nil
+}
+
+func (r *uploadRepository) Get(id int64) (entity.Upload, error) {
+	var upload entity.Upload
+	err := r.db.QueryRow(`
+		SELECT id, user_id, purpose, object_key, content_type, size, status, COALESCE(url, ''), expires_at
+		FROM uploads
+		WHERE id = $1
+	`, id).Scan(&upload.ID, &upload.UserID, &upload.Purpose, &upload.Key, &upload.ContentType,
+		&upload.Size, &upload.Status, &upload.URL, &upload.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Upload{}, app.NewAppError(404, "upload not found")
+	}
+	if err != nil {
+		log.Error("[Repo][GetUpload] Error Query: ", err)
+		return entity.Upload{}, app.NewAppError(500, "failed to get upload")
+	}
+	return upload, nil
+}
+
+func (r *uploadRepository) Confirm(id int64, url string) error {
+	res, err := r.db.Exec(`
+		UPDATE uploads
+		SET status = 'confirmed', url = $2, confirmed_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND status = 'pending'
+	`, id, url)
+	if err != nil {
+		log.Error("[Repo][ConfirmUpload] Error Update: ", err)
+		return app.NewAppError(500, "failed to confirm upload")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return app.NewAppError(409, "upload is already confirmed")
+	}
+	return nil
+}
+
+func (r *uploadRepository) Link(id int64, userId int64, purpose string) (string, error) {
+	return link(r.db, id, userId, purpose)
+}
+
+func (r *uploadRepository) Unlink(id int64) error {
+	_, err := r.db.Exec(`UPDATE uploads SET status = 'confirmed' WHERE id = $1 AND status = 'linked'`, id)
+	if err != nil {
+		log.Error("[Repo][UnlinkUpload] Error Update: ", err)
+		return app.NewAppError(500, "failed to release upload")
+	}
+	return nil
+}
+
+func (r *uploadRepository) LinkProfileImage(id int64, userId int64) (string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][LinkProfileImage] Error Begin: ", err)
+		return "", app.NewAppError(500, "failed to link upload")
+	}
+	defer tx.Rollback()
+
+	url, err := link(tx, id, userId, entity.PurposeProfileImage)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`UPDATE users SET img_url = $1 WHERE id = $2`, url, userId); err != nil {
+		log.Error("[Repo][LinkProfileImage] Error Update: ", err)
+		return "", app.NewAppError(500, "failed to update profile image")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][LinkProfileImage] Error Commit: ", err)
+		return "", app.NewAppError(500, "failed to link upload")
+	}
+	return url, nil
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func link(db queryRower, id int64, userId int64, purpose string) (string, error) {
+	var url string
+	err := db.QueryRow(`
+		UPDATE uploads
+		SET status = 'linked'
+		WHERE id = $1 AND user_id = $2 AND purpose = $3 AND status = 'confirmed'
+		RETURNING url
+	`, id, userId, purpose).Scan(&url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", app.NewAppError(400, "upload is not a confirmed "+purpose+" of yours or is already used")
+	}
+	if err != nil {
+		log.Error("[Repo][LinkUpload] Error Update: ", err)
+		return "", app.NewAppError(500, "failed to link upload")
+	}
+	return url, nil
+}
@@ -0,0 +1,81 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/upload/entity"
+	"github.com/ghulammuzz/misterblast/internal/upload/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUpload(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewUploadRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO uploads`).
+		WithArgs(int64(7), entity.PurposeTaskAttachment, "/prod/uploads/a", "application/pdf", int64(4), int64(100)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	id, err := repository.Create(entity.Upload{
+		UserID: 7, Purpose: entity.PurposeTaskAttachment, Key: "/prod/uploads/a",
+		ContentType: "application/pdf", Size: 4, ExpiresAt: 100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmUpload_NotPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewUploadRepository(db)
+
+	mock.ExpectExec(`UPDATE uploads`).WithArgs(int64(3), "/files/a").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.Confirm(3, "/files/a")
+	assert.Equal(t, 409, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLinkUpload_AlreadyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewUploadRepository(db)
+
+	mock.ExpectQuery(`UPDATE uploads`).
+		WithArgs(int64(3), int64(7), entity.PurposeTaskAttachment).
+		WillReturnRows(sqlmock.NewRows([]string{"url"}))
+
+	_, err = repository.Link(3, 7, entity.PurposeTaskAttachment)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLinkProfileImage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewUploadRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE uploads`).
+		WithArgs(int64(3), int64(7), entity.PurposeProfileImage).
+		WillReturnRows(sqlmock.NewRows([]string{"url"}).AddRow("/files/a"))
+	mock.ExpectExec(`UPDATE users SET img_url`).WithArgs("/files/a", int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	url, err := repository.LinkProfileImage(3, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "/files/a", url)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
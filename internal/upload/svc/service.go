@@ -0,0 +1,263 @@
+package svc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/upload/entity"
+	"github.com/ghulammuzz/misterblast/internal/upload/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+// TicketTTL is how long an upload URL can be used and its upload confirmed.
+const TicketTTL = 15 * time.Minute
+
+// policy bounds what may be uploaded for a purpose. Images go through the
+// imaging pipeline on confirm.
+type policy struct {
+	maxSize int64
+	types   []string
+	image   bool
+}
+
+var policies = map[string]policy{
+	entity.PurposeProfileImage: {
+		maxSize: 5 << 20,
+		types:   entity.ImageTypes,
+		image:   true,
+	},
+	entity.PurposeAnswerImage: {
+		maxSize: 5 << 20,
+		types:   entity.ImageTypes,
+		image:   true,
+	},
+	entity.PurposeTaskAttachment: {
+		maxSize: 50 << 20,
+		types:   entity.TaskAttachmentTypes,
+	},
+}
+
+func (p policy) allows(contentType string) bool {
+	return slices.Contains(p.types, contentType)
+}
+
+type UploadService interface {
+	// Create records an upload and returns the presigned URL to send it to.
+	Create(ctx context.Context, userId int64, dto entity.CreateUploadRequestDto) (*entity.UploadTicketDto, error)
+	// Confirm checks the stored object's size and magic bytes against what
+	// was declared. Profile and answer images then go through the imaging
+	// pipeline; profile images are linked to the uploader right away, and
+	// task attachments wait for a submission to claim them.
+	Confirm(ctx context.Context, uploadId int64, userId int64) (*entity.ConfirmedUploadDto, error)
+}
+
+type uploadService struct {
+	repo   repo.UploadRepository
+	files  storage.Storage
+	images imaging.Saver
+	now    func() time.Time
+}
+
+func NewUploadService(repo repo.UploadRepository, files storage.Storage, images imaging.Saver) UploadService {
+	return &uploadService{repo: repo, files: files, images: images, now: time.Now}
+}
+
+func (s *uploadService) Create(ctx context.Context, userId int64, dto entity.CreateUploadRequestDto) (*entity.UploadTicketDto, error) {
+	p, ok := policies[dto.Purpose]
+	if !ok {
+		return nil, app.NewAppError(400, "unknown upload purpose")
+	}
+	contentType := normalizeType(dto.ContentType)
+	if !p.allows(contentType) {
+		return nil, app.NewAppError(400, fmt.Sprintf("content type %q is not allowed for %s", dto.ContentType, dto.Purpose))
+	}
+	if dto.Size > p.maxSize {
+		return nil, app.NewAppError(400, fmt.Sprintf("file size exceeds %dMB limit", p.maxSize>>20))
+	}
+
+	key, err := newKey(userId, dto.Purpose)
+	if err != nil {
+		log.Error("[UploadSvc] Failed to generate key", "error", err)
+		return nil, app.NewAppError(500, "failed to create upload")
+	}
+	url, err := s.files.SignedPutURL(ctx, key, dto.ContentType, dto.Size, TicketTTL)
+	if errors.Is(err, storage.ErrUnsupported) {
+		return nil, app.NewAppError(501, "direct uploads are not supported by this storage backend")
+	}
+	if err != nil {
+		log.Error("[UploadSvc] Failed to sign upload URL", "error", err)
+		return nil, app.NewAppError(500, "failed to create upload")
+	}
+
+	expiresAt := s.now().Add(TicketTTL).Unix()
+	id, err := s.repo.Create(entity.Upload{
+		UserID:      userId,
+		Purpose:     dto.Purpose,
+		Key:         key,
+		ContentType: dto.ContentType,
+		Size:        dto.Size,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.UploadTicketDto{
+		ID:        id,
+		Method:    http.MethodPut,
+		URL:       url,
+		Headers:   map[string]string{"Content-Type": dto.ContentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *uploadService) Confirm(ctx context.Context, uploadId int64, userId int64) (*entity.ConfirmedUploadDto, error) {
+	upload, err := s.repo.Get(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userId {
+		return nil, app.NewAppError(404, "upload not found")
+	}
+
+	// A confirmed profile image that failed to link is simply linked again.
+	if upload.Status == entity.StatusPending {
+		if upload.URL, err = s.check(ctx, upload); err != nil {
+			return nil, err
+		}
+		if policies[upload.Purpose].image {
+			if upload.URL, err = s.processImage(ctx, upload); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.repo.Confirm(upload.ID, upload.URL); err != nil {
+			return nil, err
+		}
+		upload.Status = entity.StatusConfirmed
+	}
+
+	if upload.Purpose == entity.PurposeProfileImage && upload.Status == entity.StatusConfirmed {
+		if upload.URL, err = s.repo.LinkProfileImage(upload.ID, userId); err != nil {
+			return nil, err
+		}
+		upload.Status = entity.StatusLinked
+	}
+
+	return &entity.ConfirmedUploadDto{ID: upload.ID, Status: upload.Status, URL: upload.URL}, nil
+}
+
+// check compares the stored object with the declared size and type,
+// deleting it if they differ, and returns the URL it is served from.
+func (s *uploadService) check(ctx context.Context, upload entity.Upload) (string, error) {
+	if s.now().Unix() > upload.ExpiresAt {
+		return "", app.NewAppError(410, "upload has expired")
+	}
+
+	info, err := s.files.Stat(ctx, upload.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", app.NewAppError(409, "file has not been uploaded yet")
+	}
+	if err != nil {
+		log.Error("[UploadSvc] Failed to stat upload", "error", err)
+		return "", app.NewAppError(500, "failed to check upload")
+	}
+
+	var mismatch string
+	switch {
+	case info.Size != upload.Size:
+		mismatch = fmt.Sprintf("uploaded file is %d bytes, expected %d", info.Size, upload.Size)
+	case normalizeType(info.ContentType) != normalizeType(upload.ContentType):
+		mismatch = fmt.Sprintf("uploaded file is %q, expected %q", info.ContentType, upload.ContentType)
+	default:
+		// The declared type was only the client's word for it.
+		head, err := s.read(ctx, upload.Key, storage.SniffLen)
+		if err != nil {
+			return "", err
+		}
+		if !storage.Conforms(head, upload.ContentType) {
+			mismatch = fmt.Sprintf("uploaded file is %q, not %q", storage.Sniff(head), upload.ContentType)
+		}
+	}
+	if mismatch != "" {
+		s.discard(ctx, upload)
+		return "", app.NewAppError(400, mismatch)
+	}
+
+	url, err := s.files.URL(upload.Key)
+	if err != nil {
+		log.Error("[UploadSvc] Failed to build upload URL", "error", err)
+		return "", app.NewAppError(500, "failed to check upload")
+	}
+	return url, nil
+}
+
+// processImage strips the image's metadata and renders its variants,
+// replacing the uploaded object with the cleaned one.
+func (s *uploadService) processImage(ctx context.Context, upload entity.Upload) (string, error) {
+	content, err := s.read(ctx, upload.Key, upload.Size)
+	if err != nil {
+		return "", err
+	}
+	url, err := s.images.Save(ctx, upload.Key, content)
+	if imaging.IsInvalid(err) {
+		s.discard(ctx, upload)
+		return "", app.NewAppError(400, "uploaded file is not a usable image")
+	}
+	if err != nil {
+		log.Error("[UploadSvc] Failed to process image", "error", err)
+		return "", app.NewAppError(500, "failed to process image")
+	}
+	return url, nil
+}
+
+// read returns up to limit bytes from the start of the object.
+func (s *uploadService) read(ctx context.Context, key string, limit int64) ([]byte, error) {
+	body, err := s.files.Get(ctx, key)
+	if err != nil {
+		log.Error("[UploadSvc] Failed to read upload", "error", err)
+		return nil, app.NewAppError(500, "failed to check upload")
+	}
+	defer body.Close()
+	content, err := io.ReadAll(io.LimitReader(body, limit))
+	if err != nil {
+		log.Error("[UploadSvc] Failed to read upload", "error", err)
+		return nil, app.NewAppError(500, "failed to check upload")
+	}
+	return content, nil
+}
+
+// discard deletes an upload that failed its checks so it cannot be
+// confirmed later.
+func (s *uploadService) discard(ctx context.Context, upload entity.Upload) {
+	if err := s.files.Delete(ctx, upload.Key); err != nil {
+		log.Warn("[UploadSvc] Failed to delete rejected upload", "key", upload.Key, "error", err)
+	}
+}
+
+// normalizeType drops parameters such as charset and lower-cases the type.
+func normalizeType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func newKey(userId int64, purpose string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/prod/uploads/%s/%d/%s", purpose, userId, hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,288 @@
+package svc_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/upload/entity"
+	"github.com/ghulammuzz/misterblast/internal/upload/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUploadRepo struct {
+	mock.Mock
+}
+
+func (m *MockUploadRepo) Create(upload entity.Upload) (int64, error) {
+	args := m.Called(upload)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUploadRepo) Get(id int64) (entity.Upload, error) {
+	args := m.Called(id)
+	return args.Get(0).(entity.Upload), args.Error(1)
+}
+
+func (m *MockUploadRepo) Confirm(id int64, url string) error {
+	return m.Called(id, url).Error(0)
+}
+
+func (m *MockUploadRepo) Link(id int64, userId int64, purpose string) (string, error) {
+	args := m.Called(id, userId, purpose)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadRepo) Unlink(id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockUploadRepo) LinkProfileImage(id int64, userId int64) (string, error) {
+	args := m.Called(id, userId)
+	return args.String(0), args.Error(1)
+}
+
+type MockSaver struct {
+	mock.Mock
+}
+
+func (m *MockSaver) Save(ctx context.Context, key string, content []byte) (string, error) {
+	args := m.Called(key, content)
+	return args.String(0), args.Error(1)
+}
+
+func newLocal(t *testing.T) *storage.Local {
+	local, err := storage.NewLocal(t.TempDir(), "/files", "secret")
+	require.NoError(t, err)
+	return local
+}
+
+func appCode(t *testing.T, err error) int {
+	appErr, ok := err.(*app.AppError)
+	require.True(t, ok, "expected an AppError, got %v", err)
+	return appErr.Code
+}
+
+func pngBytes(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))))
+	return buf.Bytes()
+}
+
+const pdf = "%PDF-1.7\n"
+
+func pendingUpload(purpose, contentType string, size int64) entity.Upload {
+	return entity.Upload{
+		ID:          3,
+		UserID:      7,
+		Purpose:     purpose,
+		Key:         "/prod/uploads/" + purpose + "/7/abc",
+		ContentType: contentType,
+		Size:        size,
+		Status:      entity.StatusPending,
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestCreateUpload(t *testing.T) {
+	repo := new(MockUploadRepo)
+	service := svc.NewUploadService(repo, newLocal(t), new(MockSaver))
+
+	repo.On("Create", mock.MatchedBy(func(u entity.Upload) bool {
+		return u.UserID == 7 && u.Purpose == entity.PurposeTaskAttachment && u.Size == 1<<20 &&
+			strings.HasPrefix(u.Key, "/prod/uploads/task_attachment/7/")
+	})).Return(int64(3), nil)
+
+	ticket, err := service.Create(context.Background(), 7, entity.CreateUploadRequestDto{
+		Purpose:     entity.PurposeTaskAttachment,
+		ContentType: "application/pdf",
+		Size:        1 << 20,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), ticket.ID)
+	assert.Equal(t, "PUT", ticket.Method)
+	assert.Contains(t, ticket.URL, "/files/prod/uploads/task_attachment/7/")
+	assert.Equal(t, "application/pdf", ticket.Headers["Content-Type"])
+	repo.AssertExpectations(t)
+}
+
+func TestCreateUpload_Policy(t *testing.T) {
+	service := svc.NewUploadService(new(MockUploadRepo), newLocal(t), new(MockSaver))
+
+	_, err := service.Create(context.Background(), 7, entity.CreateUploadRequestDto{
+		Purpose: entity.PurposeProfileImage, ContentType: "application/pdf", Size: 10,
+	})
+	assert.Equal(t, 400, appCode(t, err))
+
+	_, err = service.Create(context.Background(), 7, entity.CreateUploadRequestDto{
+		Purpose: entity.PurposeProfileImage, ContentType: "image/png", Size: 6 << 20,
+	})
+	assert.Equal(t, 400, appCode(t, err))
+
+	// SVG can carry scripts, so it is not an allowed attachment.
+	_, err = service.Create(context.Background(), 7, entity.CreateUploadRequestDto{
+		Purpose: entity.PurposeTaskAttachment, ContentType: "image/svg+xml", Size: 10,
+	})
+	assert.Equal(t, 400, appCode(t, err))
+}
+
+func TestCreateUpload_Unsupported(t *testing.T) {
+	service := svc.NewUploadService(new(MockUploadRepo), storage.NewProxy("http://files", "token"), new(MockSaver))
+
+	_, err := service.Create(context.Background(), 7, entity.CreateUploadRequestDto{
+		Purpose: entity.PurposeProfileImage, ContentType: "image/png", Size: 10,
+	})
+	assert.Equal(t, 501, appCode(t, err))
+}
+
+func TestConfirmUpload_TaskAttachment(t *testing.T) {
+	repo := new(MockUploadRepo)
+	local := newLocal(t)
+	service := svc.NewUploadService(repo, local, new(MockSaver))
+
+	upload := pendingUpload(entity.PurposeTaskAttachment, "application/pdf", int64(len(pdf)))
+	_, err := local.Put(context.Background(), upload.Key, strings.NewReader(pdf), "application/pdf")
+	require.NoError(t, err)
+
+	repo.On("Get", int64(3)).Return(upload, nil)
+	repo.On("Confirm", int64(3), "/files/prod/uploads/task_attachment/7/abc").Return(nil)
+
+	confirmed, err := service.Confirm(context.Background(), 3, 7)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.ConfirmedUploadDto{ID: 3, Status: entity.StatusConfirmed, URL: "/files/prod/uploads/task_attachment/7/abc"}, confirmed)
+	repo.AssertExpectations(t)
+}
+
+func TestConfirmUpload_ProfileImage(t *testing.T) {
+	repo := new(MockUploadRepo)
+	local := newLocal(t)
+	images := new(MockSaver)
+	service := svc.NewUploadService(repo, local, images)
+
+	content := pngBytes(t)
+	upload := pendingUpload(entity.PurposeProfileImage, "image/png", int64(len(content)))
+	_, err := local.Put(context.Background(), upload.Key, bytes.NewReader(content), "image/png")
+	require.NoError(t, err)
+
+	url := "/files/prod/uploads/profile_image/7/abc"
+	images.On("Save", upload.Key, content).Return(url, nil)
+	repo.On("Get", int64(3)).Return(upload, nil)
+	repo.On("Confirm", int64(3), url).Return(nil)
+	repo.On("LinkProfileImage", int64(3), int64(7)).Return(url, nil)
+
+	confirmed, err := service.Confirm(context.Background(), 3, 7)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusLinked, confirmed.Status)
+	repo.AssertExpectations(t)
+	images.AssertExpectations(t)
+}
+
+func TestConfirmUpload_AnswerImage(t *testing.T) {
+	repo := new(MockUploadRepo)
+	local := newLocal(t)
+	images := new(MockSaver)
+	service := svc.NewUploadService(repo, local, images)
+
+	content := pngBytes(t)
+	upload := pendingUpload(entity.PurposeAnswerImage, "image/png", int64(len(content)))
+	_, err := local.Put(context.Background(), upload.Key, bytes.NewReader(content), "image/png")
+	require.NoError(t, err)
+
+	// The processed image's URL is what an answer's img_url is set to.
+	url := "/files/prod/uploads/answer_image/7/abc"
+	images.On("Save", upload.Key, content).Return(url, nil)
+	repo.On("Get", int64(3)).Return(upload, nil)
+	repo.On("Confirm", int64(3), url).Return(nil)
+
+	confirmed, err := service.Confirm(context.Background(), 3, 7)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.ConfirmedUploadDto{ID: 3, Status: entity.StatusConfirmed, URL: url}, confirmed)
+	repo.AssertExpectations(t)
+	images.AssertExpectations(t)
+}
+
+func TestConfirmUpload_Mismatch(t *testing.T) {
+	repo := new(MockUploadRepo)
+	local := newLocal(t)
+	service := svc.NewUploadService(repo, local, new(MockSaver))
+
+	upload := pendingUpload(entity.PurposeTaskAttachment, "application/pdf", 1<<20)
+	_, err := local.Put(context.Background(), upload.Key, strings.NewReader(pdf), "application/pdf")
+	require.NoError(t, err)
+	repo.On("Get", int64(3)).Return(upload, nil)
+
+	_, err = service.Confirm(context.Background(), 3, 7)
+	assert.Equal(t, 400, appCode(t, err))
+
+	// The mismatched object is removed so it cannot be confirmed later.
+	_, err = local.Stat(context.Background(), upload.Key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	repo.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything)
+}
+
+func TestConfirmUpload_ContentMismatch(t *testing.T) {
+	repo := new(MockUploadRepo)
+	local := newLocal(t)
+	service := svc.NewUploadService(repo, local, new(MockSaver))
+
+	// Declared and stored as a PDF, but the bytes are an executable.
+	content := "MZ\x90\x00\x03\x00\x00\x00"
+	upload := pendingUpload(entity.PurposeTaskAttachment, "application/pdf", int64(len(content)))
+	_, err := local.Put(context.Background(), upload.Key, strings.NewReader(content), "application/pdf")
+	require.NoError(t, err)
+	repo.On("Get", int64(3)).Return(upload, nil)
+
+	_, err = service.Confirm(context.Background(), 3, 7)
+	assert.Equal(t, 400, appCode(t, err))
+
+	_, err = local.Stat(context.Background(), upload.Key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	repo.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything)
+}
+
+func TestConfirmUpload_InvalidImage(t *testing.T) {
+	repo := new(MockUploadRepo)
+	local := newLocal(t)
+	images := new(MockSaver)
+	service := svc.NewUploadService(repo, local, images)
+
+	content := pngBytes(t)
+	upload := pendingUpload(entity.PurposeProfileImage, "image/png", int64(len(content)))
+	_, err := local.Put(context.Background(), upload.Key, bytes.NewReader(content), "image/png")
+	require.NoError(t, err)
+	repo.On("Get", int64(3)).Return(upload, nil)
+	images.On("Save", upload.Key, content).Return("", imaging.ErrInvalidImage)
+
+	_, err = service.Confirm(context.Background(), 3, 7)
+	assert.Equal(t, 400, appCode(t, err))
+	repo.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything)
+}
+
+func TestConfirmUpload_Errors(t *testing.T) {
+	repo := new(MockUploadRepo)
+	service := svc.NewUploadService(repo, newLocal(t), new(MockSaver))
+
+	missing := pendingUpload(entity.PurposeTaskAttachment, "application/pdf", 4)
+	repo.On("Get", int64(3)).Return(missing, nil)
+	_, err := service.Confirm(context.Background(), 3, 7)
+	assert.Equal(t, 409, appCode(t, err))
+
+	_, err = service.Confirm(context.Background(), 3, 8)
+	assert.Equal(t, 404, appCode(t, err))
+
+	expired := missing
+	expired.ID = 4
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	repo.On("Get", int64(4)).Return(expired, nil)
+	_, err = service.Confirm(context.Background(), 4, 7)
+	assert.Equal(t, 410, appCode(t, err))
+}
@@ -3,22 +3,37 @@ package di
 import (
 	"database/sql"
 
+	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
+	emailSvc "github.com/ghulammuzz/misterblast/internal/email/svc"
+	gamificationRepo "github.com/ghulammuzz/misterblast/internal/gamification/repo"
 	quizRepo "github.com/ghulammuzz/misterblast/internal/quiz/repo"
 	taskRepo "github.com/ghulammuzz/misterblast/internal/task/repo"
 	userHandler "github.com/ghulammuzz/misterblast/internal/user/handler"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
 	userSvc "github.com/ghulammuzz/misterblast/internal/user/svc"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitializedUserServiceFake(sb *sql.DB, val *validator.Validate) *userHandler.UserHandler {
+func InitializedUserServiceFake(sb *sql.DB, redis *redis.Client, val *validator.Validate) *userHandler.UserHandler {
 	wire.Build(
 		userHandler.NewUserHandler,
 		userSvc.NewUserService,
 		userRepo.NewUserRepository,
+		userRepo.NewSessionRepository,
 		quizRepo.NewQuizRepository,
 		taskRepo.NewTaskRepository,
+		gamificationRepo.NewGamificationRepository,
+		emailSvc.NewEmailService,
+		emailRepo.NewEmailRepository,
+		emailRepo.NewOTPService,
+		queue.NewPostgresQueue,
+		wire.Bind(new(queue.Enqueuer), new(*queue.PostgresQueue)),
+		mailer.NewOutbox,
+		wire.Bind(new(mailer.Sender), new(*mailer.Outbox)),
 	)
 
 	return &userHandler.UserHandler{}
@@ -8,21 +8,34 @@ package di
 
 import (
 	"database/sql"
+	repo5 "github.com/ghulammuzz/misterblast/internal/email/repo"
+	svc2 "github.com/ghulammuzz/misterblast/internal/email/svc"
+	repo4 "github.com/ghulammuzz/misterblast/internal/gamification/repo"
 	repo2 "github.com/ghulammuzz/misterblast/internal/quiz/repo"
 	repo3 "github.com/ghulammuzz/misterblast/internal/task/repo"
 	"github.com/ghulammuzz/misterblast/internal/user/handler"
 	"github.com/ghulammuzz/misterblast/internal/user/repo"
 	"github.com/ghulammuzz/misterblast/internal/user/svc"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
 )
 
 // Injectors from wire.go:
 
-func InitializedUserService(sb *sql.DB, val *validator.Validate) *handler.UserHandler {
+func InitializedUserService(sb *sql.DB, redis2 *redis.Client, val *validator.Validate) *handler.UserHandler {
 	userRepository := repo.NewUserRepository(sb)
-	quizRepository := repo2.NewQuizRepository(sb)
+	sessionRepository := repo.NewSessionRepository(sb, redis2)
+	quizRepository := repo2.NewQuizRepository(sb, redis2)
 	taskRepository := repo3.NewTaskRepository(sb)
-	userService := svc.NewUserService(userRepository, quizRepository, taskRepository)
+	gamificationRepository := repo4.NewGamificationRepository(sb)
+	postgresQueue := queue.NewPostgresQueue(sb, redis2)
+	emailRepository := repo5.NewEmailRepository(sb)
+	otp := repo5.NewOTPService()
+	outbox := mailer.NewOutbox(sb, postgresQueue)
+	emailService := svc2.NewEmailService(emailRepository, userRepository, otp, outbox)
+	userService := svc.NewUserService(userRepository, sessionRepository, quizRepository, taskRepository, gamificationRepository, postgresQueue, emailService)
 	userHandler := handler.NewUserHandler(userService, val)
 	return userHandler
 }
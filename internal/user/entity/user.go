@@ -15,10 +15,3 @@ type UserProfileImg struct {
 	Img *multipart.FileHeader `json:"img" validate:"required"`
 	Key string                `json:"key" validate:"required"`
 }
-
-type ImgResponse struct {
-	Message string `json:"message"`
-	Data    struct {
-		URL string `json:"url"`
-	} `json:"data"`
-}
@@ -1,6 +1,11 @@
 package entity
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+
+	gamificationEntity "github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+)
 
 type UserLogin struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -12,12 +17,15 @@ type Register struct {
 	Email    string  `json:"email" validate:"required,email"`
 	Password string  `json:"password" validate:"required,min=6,max=20"`
 	ImgUrl   *string `json:"img_url,omitempty"`
+	// Locale is the language of the user's emails; empty means "id".
+	Locale string `json:"locale,omitempty"`
 }
 
 type EditUser struct {
 	Name   string  `json:"name" validate:"required,min=2,max=20"`
 	Email  string  `json:"email" validate:"required,email"`
 	ImgUrl *string `json:"img_url,omitempty"`
+	Locale string  `json:"locale,omitempty"`
 }
 
 type ListUser struct {
@@ -41,6 +49,7 @@ type UserJWT struct {
 	Password   string `json:"password"`
 	IsAdmin    bool   `json:"is_admin"`
 	IsVerified bool   `json:"is_verified"`
+	Role       string `json:"role"`
 }
 
 type LoginResponse struct {
@@ -48,15 +57,30 @@ type LoginResponse struct {
 	Email      string `json:"email"`
 	IsAdmin    bool   `json:"is_admin"`
 	IsVerified bool   `json:"is_verified"`
+	Role       string `json:"role"`
+	*TokenPair
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type UserAuth struct {
-	ID         int32  `json:"id"`
-	Name       string `json:"name"`
-	Email      string `json:"email"`
-	ImgUrl     string `json:"img_url"`
-	IsAdmin    bool   `json:"is_admin"`
-	IsVerified bool   `json:"is_verified"`
+	ID     int32  `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	ImgUrl string `json:"img_url"`
+	// ImgVariants holds the resized WebP URLs of ImgUrl, when it has any.
+	ImgVariants imaging.Variants             `json:"img_variants,omitempty"`
+	IsAdmin     bool                         `json:"is_admin"`
+	IsVerified  bool                         `json:"is_verified"`
+	Progress    *gamificationEntity.Progress `json:"progress,omitempty"`
 }
 
 // ADMIN
@@ -77,17 +101,21 @@ type ChangePassword struct {
 	Password string `json:"password" validate:"required,min=6,max=20"`
 }
 
+// RegisterDTO may carry a picture in the multipart form. Deployments whose
+// storage driver supports presigning can also upload it later through /uploads.
 type RegisterDTO struct {
 	Name     string                `form:"name" validate:"required,min=2,max=20"`
 	Email    string                `form:"email" validate:"required,email"`
 	Password string                `form:"password" validate:"required,min=6,max=20"`
-	Img      *multipart.FileHeader `form:"image" validate:"omitempty"`
+	Locale   string                `form:"locale" validate:"omitempty,oneof=id en"`
+	Img      *multipart.FileHeader `form:"image,omitempty"`
 }
 
 type EditDTO struct {
-	Name  string                `form:"name" validate:"required,min=2,max=20"`
-	Email string                `form:"email" validate:"required,email"`
-	Img   *multipart.FileHeader `form:"image,omitempty"`
+	Name   string                `form:"name" validate:"required,min=2,max=20"`
+	Email  string                `form:"email" validate:"required,email"`
+	Locale string                `form:"locale" validate:"omitempty,oneof=id en"`
+	Img    *multipart.FileHeader `form:"image,omitempty"`
 }
 
 type UserSummary struct {
@@ -100,3 +128,7 @@ type UserSummary struct {
 type EditPasswordDTO struct {
 	Password string `json:"password" validate:"required,min=6,max=64"`
 }
+
+type UpdateRoleDTO struct {
+	Role string `json:"role" validate:"required,oneof=admin teacher content_editor student"`
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/user/entity"
 	"github.com/ghulammuzz/misterblast/internal/user/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	pkgJWT "github.com/ghulammuzz/misterblast/pkg/jwt"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
 	m "github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
@@ -27,14 +28,19 @@ func (h *UserHandler) Router(r fiber.Router) {
 	r.Post("/register", m.R100(), h.RegisterHandler)
 	r.Post("/admin-check", m.R100(), h.RegisterAdminHandler)
 	r.Post("/login", m.R100(), h.LoginHandler)
-	r.Get("/users", m.R100(), h.ListUsersHandler)
-	r.Get("/users/:id", m.R100(), h.DetailUserHandler)
-	r.Delete("/users/:id", m.R100(), h.DeleteUserHandler)
-	r.Put("/users/:id", m.R100(), h.EditUserHandler)
+	r.Post("/logout", m.R100(), m.JWTProtected(), h.LogoutHandler)
+	r.Post("/token/refresh", m.R100(), h.RefreshTokenHandler)
+	r.Get("/users", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageUsers), h.ListUsersHandler)
+	r.Get("/users/:id", m.R100(), m.JWTProtected(), m.AuthorizeSelfOr(m.PermManageUsers, "id"), h.DetailUserHandler)
+	r.Delete("/users/:id", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageUsers), h.DeleteUserHandler)
+	r.Put("/users/:id", m.R100(), m.JWTProtected(), m.AuthorizeSelfOr(m.PermManageUsers, "id"), h.EditUserHandler)
 	r.Get("/me", m.JWTProtected(), m.R100(), h.MeUserHandler)
 	r.Put("/reset-password", m.R100(), h.ChangePasswordHandler)
 	r.Get("/summary", m.JWTProtected(), m.R100(), h.SummaryUserHandler)
-	r.Put("/users/:id/password", m.R100(), h.UpdatePasswordHandler)
+	r.Get("/summary/mastery", m.JWTProtected(), m.R100(), h.MasteryUserHandler)
+	r.Get("/users/:id/mastery", m.R100(), m.JWTProtected(), m.AuthorizeSelfOr(m.PermViewSubmissions, "id"), h.MasteryUserHandler)
+	r.Put("/users/:id/password", m.R100(), m.JWTProtected(), m.AuthorizeSelfOr(m.PermManageUsers, "id"), h.UpdatePasswordHandler)
+	r.Put("/users/:id/role", m.R100(), m.JWTProtected(), m.Authorize(m.PermManageUsers), h.UpdateRoleHandler)
 }
 
 func (h *UserHandler) RegisterHandler(c *fiber.Ctx) error {
@@ -51,6 +57,7 @@ func (h *UserHandler) RegisterHandler(c *fiber.Ctx) error {
 		Name:     name,
 		Email:    email,
 		Password: password,
+		Locale:   c.FormValue("locale"),
 	}
 
 	if form, err := c.MultipartForm(); err == nil {
@@ -120,7 +127,7 @@ func (h *UserHandler) LoginHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	userData, token, err := h.userService.Login(user)
+	userData, tokens, err := h.userService.Login(user)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -129,16 +136,84 @@ func (h *UserHandler) LoginHandler(c *fiber.Ctx) error {
 		return response.SendError(c, appErr.Code, appErr.Message, nil)
 	}
 
+	setTokenCookies(c, tokens)
+	userData.TokenPair = tokens
+
+	return response.SendSuccess(c, "Login successful", userData)
+}
+
+func (h *UserHandler) RefreshTokenHandler(c *fiber.Ctx) error {
+	var dto entity.RefreshTokenDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&dto); err != nil {
+			return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+		}
+	}
+	if dto.RefreshToken == "" {
+		dto.RefreshToken = c.Cookies("refresh_token")
+	}
+	if dto.RefreshToken == "" {
+		return response.SendError(c, fiber.StatusUnauthorized, "Refresh token not found", nil)
+	}
+
+	tokens, err := h.userService.RefreshToken(dto.RefreshToken)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	setTokenCookies(c, tokens)
+
+	return response.SendSuccess(c, "Token refreshed successfully", tokens)
+}
+
+func (h *UserHandler) LogoutHandler(c *fiber.Ctx) error {
+	userToken := c.Locals("user").(*jwt.Token)
+
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok || !userToken.Valid {
+		log.Error("Invalid token")
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	if sessionID == "" {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	if err := h.userService.Logout(sessionID); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	c.ClearCookie("token", "refresh_token")
+
+	return response.SendSuccess(c, "Logout successful", nil)
+}
+
+func setTokenCookies(c *fiber.Ctx, tokens *entity.TokenPair) {
 	c.Cookie(&fiber.Cookie{
 		Name:     "token",
-		Value:    token,
-		Expires:  time.Now().Add(7 * 24 * 60 * 60 * 1000),
+		Value:    tokens.AccessToken,
+		Expires:  time.Now().Add(pkgJWT.AccessTokenTTL),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		Expires:  time.Now().Add(pkgJWT.RefreshTokenTTL),
 		HTTPOnly: true,
 		Secure:   true,
 		SameSite: "Strict",
 	})
-
-	return response.SendSuccess(c, "Login successful", userData)
 }
 
 func (h *UserHandler) ListUsersHandler(c *fiber.Ctx) error {
@@ -188,8 +263,9 @@ func (h *UserHandler) EditUserHandler(c *fiber.Ctx) error {
 	}
 
 	user := entity.EditDTO{
-		Name:  c.FormValue("name"),
-		Email: c.FormValue("email"),
+		Name:   c.FormValue("name"),
+		Email:  c.FormValue("email"),
+		Locale: c.FormValue("locale"),
 	}
 
 	if form, err := c.MultipartForm(); err == nil {
@@ -314,6 +390,46 @@ func (h *UserHandler) SummaryUserHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "User summary retrieved successfully", summary)
 }
 
+// MasteryUserHandler serves the mastery report of the caller on
+// /summary/mastery, or of the user in the id param on /users/:id/mastery.
+func (h *UserHandler) MasteryUserHandler(c *fiber.Ctx) error {
+	filter := map[string]string{}
+	for _, key := range []string{"class_id", "lesson_id", "set_id"} {
+		if c.Query(key) != "" {
+			filter[key] = c.Query(key)
+		}
+	}
+
+	var userID int
+	if c.Params("id") != "" {
+		id, err := c.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return response.SendError(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+		}
+		userID = id
+	} else {
+		userToken := c.Locals("user").(*jwt.Token)
+
+		claims, ok := userToken.Claims.(jwt.MapClaims)
+		if !ok || !userToken.Valid {
+			log.Error("Invalid token")
+			return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+		}
+
+		userID = int(claims["user_id"].(float64))
+	}
+
+	report, err := h.userService.MasteryUser(int32(userID), filter, c.QueryInt("weeks", 0))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	return response.SendSuccess(c, "User mastery retrieved successfully", report)
+}
+
 func (h *UserHandler) UpdatePasswordHandler(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
@@ -341,3 +457,31 @@ func (h *UserHandler) UpdatePasswordHandler(c *fiber.Ctx) error {
 
 	return response.SendSuccess(c, "Password updated successfully", nil)
 }
+
+func (h *UserHandler) UpdateRoleHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	var dto entity.UpdateRoleDTO
+	if err := c.BodyParser(&dto); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid JSON body", nil)
+	}
+
+	if err := h.val.Struct(dto); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.userService.UpdateRole(int32(id), dto.Role); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Role updated successfully", nil)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
 	"github.com/ghulammuzz/misterblast/internal/user/entity"
 	"github.com/ghulammuzz/misterblast/internal/user/handler"
 	"github.com/ghulammuzz/misterblast/pkg/middleware"
@@ -36,6 +37,14 @@ func (m *MockUserService) SummaryUser(id int32, filter map[string]string) (*enti
 	return args.Get(0).(*entity.UserSummary), args.Error(1)
 }
 
+func (m *MockUserService) MasteryUser(id int32, filter map[string]string, weeks int) (*quizEntity.MasteryReport, error) {
+	args := m.Called(id, filter, weeks)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*quizEntity.MasteryReport), args.Error(1)
+}
+
 func (m *MockUserService) Register(user entity.RegisterDTO) error {
 	args := m.Called(user)
 	return args.Error(0)
@@ -46,12 +55,25 @@ func (m *MockUserService) RegisterAdmin(admin entity.RegisterAdmin) error {
 	return args.Error(0)
 }
 
-func (m *MockUserService) Login(user entity.UserLogin) (*entity.LoginResponse, string, error) {
+func (m *MockUserService) Login(user entity.UserLogin) (*entity.LoginResponse, *entity.TokenPair, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
-		return nil, "", args.Error(2)
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.LoginResponse), args.Get(1).(*entity.TokenPair), args.Error(2)
+}
+
+func (m *MockUserService) RefreshToken(refreshToken string) (*entity.TokenPair, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entity.LoginResponse), args.String(1), args.Error(2)
+	return args.Get(0).(*entity.TokenPair), args.Error(1)
+}
+
+func (m *MockUserService) Logout(sessionID string) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
 }
 
 func (m *MockUserService) AuthUser(userID int32) (entity.UserAuth, error) {
@@ -98,6 +120,11 @@ func (m *MockUserService) UpdatePassword(id int32, pw string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserService) UpdateRole(id int32, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
 func TestRegisterHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockUserService)
@@ -155,8 +182,8 @@ func TestLoginHandler(t *testing.T) {
 
 	user := entity.UserLogin{Email: "john@example.com", Password: "password"}
 	userJWT := &entity.LoginResponse{ID: 1, Email: "john@example.com", IsAdmin: false, IsVerified: true}
-	token := "valid_token"
-	mockService.On("Login", user).Return(userJWT, token, nil)
+	tokens := &entity.TokenPair{AccessToken: "valid_token", RefreshToken: "refresh_token", ExpiresIn: 900}
+	mockService.On("Login", user).Return(userJWT, tokens, nil)
 
 	body, _ := json.Marshal(user)
 	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
@@ -167,6 +194,58 @@ func TestLoginHandler(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestRefreshTokenHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/token/refresh", h.RefreshTokenHandler)
+
+	t.Run("Success - Body Token", func(t *testing.T) {
+		tokens := &entity.TokenPair{AccessToken: "new_access", RefreshToken: "new_refresh", ExpiresIn: 900}
+		mockService.On("RefreshToken", "old_refresh").Return(tokens, nil).Once()
+
+		body, _ := json.Marshal(entity.RefreshTokenDTO{RefreshToken: "old_refresh"})
+		req := httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Fail - Missing Token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/token/refresh", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLogoutHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/logout", middleware.JWTProtected(), h.LogoutHandler)
+
+	claims := jwt.MapClaims{
+		"user_id": 1,
+		"sid":     "session-1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+	mockService.On("Logout", "session-1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
 func TestDeleteUserHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockUserService)
@@ -19,7 +19,7 @@ type UserRepository interface {
 	Exists(id int32) (bool, error)
 	List(filter map[string]string, page, limit int) (*response.PaginateResponse, error)
 	Detail(id int32) (userEntity.DetailUser, error)
-	Edit(id int32, user userEntity.EditUser) error
+	Edit(id int32, user userEntity.EditUser) (bool, error)
 	Delete(id int32) error
 	Auth(id int32) (userEntity.UserAuth, error)
 	AdminActivation(adminID int32) error
@@ -30,6 +30,7 @@ type UserRepository interface {
 	GenerateToken() (string, error)
 	UpdateImageURL(id int64, url string) error
 	UpdatePassword(id int32, password string) error
+	UpdateRole(id int32, role string) error
 }
 
 type userRepository struct {
@@ -52,8 +53,8 @@ func (r *userRepository) Exists(id int32) (bool, error) {
 }
 
 func (r *userRepository) Add(user userEntity.Register, IsVerified bool) (int64, error) {
-	query := `INSERT INTO users (name, email, password, img_url, is_verified)
-			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	query := `INSERT INTO users (name, email, password, img_url, is_verified, locale)
+			  VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'id')) RETURNING id`
 
 	// check if user already exists
 	var exists bool
@@ -76,7 +77,7 @@ func (r *userRepository) Add(user userEntity.Register, IsVerified bool) (int64,
 	}
 
 	var id int64
-	err = r.DB.QueryRow(query, user.Name, user.Email, hashedPassword, nil, IsVerified).Scan(&id)
+	err = r.DB.QueryRow(query, user.Name, user.Email, hashedPassword, nil, IsVerified, user.Locale).Scan(&id)
 	if err != nil {
 		log.Error("[UserRepo][Add] Error inserting user: ", err)
 		return 0, err
@@ -87,8 +88,8 @@ func (r *userRepository) Add(user userEntity.Register, IsVerified bool) (int64,
 
 func (r *userRepository) Check(user userEntity.UserLogin) (*userEntity.UserJWT, error) {
 	userResult := userEntity.UserJWT{}
-	query := "SELECT id, email, password, is_admin, is_verified FROM users WHERE email=$1"
-	err := r.DB.QueryRow(query, user.Email).Scan(&userResult.ID, &userResult.Email, &userResult.Password, &userResult.IsAdmin, &userResult.IsVerified)
+	query := "SELECT id, email, password, is_admin, is_verified, role FROM users WHERE email=$1"
+	err := r.DB.QueryRow(query, user.Email).Scan(&userResult.ID, &userResult.Email, &userResult.Password, &userResult.IsAdmin, &userResult.IsVerified, &userResult.Role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, app.NewAppError(404, "user not found")
@@ -166,7 +167,9 @@ func (r *userRepository) Detail(id int32) (userEntity.DetailUser, error) {
 	return user, nil
 }
 
-func (r *userRepository) Edit(id int32, user userEntity.EditUser) error {
+// Edit reports whether the email address changed. A new address is no
+// longer verified and may be sent a verification email straight away.
+func (r *userRepository) Edit(id int32, user userEntity.EditUser) (bool, error) {
 	query := `UPDATE users SET `
 	args := []interface{}{}
 	argIdx := 1
@@ -177,7 +180,7 @@ func (r *userRepository) Edit(id int32, user userEntity.EditUser) error {
 		argIdx++
 	}
 	if user.Email != "" {
-		query += fmt.Sprintf("email=$%d,", argIdx)
+		query += fmt.Sprintf("email=$%d, is_verified = is_verified AND email = $%d, verification_sent_at = CASE WHEN email = $%d THEN verification_sent_at END,", argIdx, argIdx, argIdx)
 		args = append(args, user.Email)
 		argIdx++
 	}
@@ -186,13 +189,29 @@ func (r *userRepository) Edit(id int32, user userEntity.EditUser) error {
 		args = append(args, *user.ImgUrl)
 		argIdx++
 	}
+	if user.Locale != "" {
+		query += fmt.Sprintf("locale=$%d,", argIdx)
+		args = append(args, user.Locale)
+		argIdx++
+	}
 
 	query += fmt.Sprintf("updated_at=EXTRACT(EPOCH FROM NOW()) WHERE id=$%d", argIdx)
 	args = append(args, id)
 	query = strings.Replace(query, ", updated_at", " updated_at", 1)
 
-	_, err := r.DB.Exec(query, args...)
-	return err
+	if user.Email == "" {
+		_, err := r.DB.Exec(query, args...)
+		return false, err
+	}
+
+	// The CTE still sees the row as it was before the update.
+	query = fmt.Sprintf("WITH old AS (SELECT email FROM users WHERE id=$%d) %s RETURNING (SELECT email FROM old) <> email", argIdx, query)
+	var changed bool
+	err := r.DB.QueryRow(query, args...).Scan(&changed)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return changed, err
 }
 
 func (r *userRepository) Delete(id int32) error {
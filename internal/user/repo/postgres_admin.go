@@ -18,10 +18,26 @@ func (r *userRepository) AdminActivation(adminID int32) error {
 	return nil
 }
 
+// UpdateRole keeps is_admin in step with the role, since tokens with is_admin
+// set are treated as admin whatever their role.
+func (r *userRepository) UpdateRole(id int32, role string) error {
+	query := `UPDATE users SET role = $1, is_admin = $2, updated_at = EXTRACT(EPOCH FROM NOW()) WHERE id = $3`
+	res, err := r.DB.Exec(query, role, role == "admin", id)
+	if err != nil {
+		log.Error("[Repo][userRepo.UpdateRole] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update user role")
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return app.NewAppError(404, "user not found")
+	}
+	return nil
+}
+
 func (r *userRepository) Auth(id int32) (userEntity.UserAuth, error) {
-	query := `SELECT id, name, email, COALESCE(img_url, ''), is_admin, is_verified  FROM users WHERE id=$1`
+	query := `SELECT u.id, u.name, u.email, COALESCE(u.img_url, ''), u.is_admin, u.is_verified, i.variants
+		FROM users u LEFT JOIN images i ON i.url = u.img_url WHERE u.id=$1`
 	var user userEntity.UserAuth
-	err := r.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.ImgUrl, &user.IsAdmin, &user.IsVerified)
+	err := r.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.ImgUrl, &user.IsAdmin, &user.IsVerified, &user.ImgVariants)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return user, app.NewAppError(404, "user not found")
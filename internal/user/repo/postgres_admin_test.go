@@ -4,9 +4,8 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/stretchr/testify/assert"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
-
+	"github.com/stretchr/testify/assert"
 )
 
 func TestUserRepository_AdminActivation(t *testing.T) {
@@ -24,6 +23,29 @@ func TestUserRepository_AdminActivation(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUserRepository_UpdateRole_DemotesAdmin(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewUserRepository(mockDB)
+
+	mock.ExpectExec("UPDATE users SET role = \\$1, is_admin = \\$2, updated_at = EXTRACT\\(EPOCH FROM NOW\\(\\)\\) WHERE id = \\$3").
+		WithArgs("teacher", false, int32(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateRole(1, "teacher"))
+
+	mock.ExpectExec("UPDATE users SET role = \\$1, is_admin = \\$2").
+		WithArgs("admin", true, int32(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateRole(2, "admin"))
+
+	mock.ExpectExec("UPDATE users SET role = \\$1, is_admin = \\$2").
+		WithArgs("student", false, int32(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Error(t, repo.UpdateRole(3, "student"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_Auth(t *testing.T) {
 	mockDB, mock := setupMockDB(t)
 	defer mockDB.Close()
@@ -31,14 +53,15 @@ func TestUserRepository_Auth(t *testing.T) {
 	repo := userRepo.NewUserRepository(mockDB)
 	id := int32(1)
 
-	mock.ExpectQuery("SELECT id, name, email, COALESCE\\(img_url, ''\\), is_admin, is_verified FROM users WHERE id=\\$1").
+	mock.ExpectQuery("SELECT u.id, u.name, u.email, COALESCE\\(u.img_url, ''\\), u.is_admin, u.is_verified, i.variants FROM users u LEFT JOIN images i ON i.url = u.img_url WHERE u.id=\\$1").
 		WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "img_url", "is_admin", "is_verified"}).
-			AddRow(1, "John Doe", "john@example.com", "", false, true))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "img_url", "is_admin", "is_verified", "variants"}).
+			AddRow(1, "John Doe", "john@example.com", "/files/a.png", false, true, []byte(`{"thumb":"/files/a.png_thumb.webp"}`)))
 
 	user, err := repo.Auth(id)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), user.ID)
 	assert.Equal(t, "John Doe", user.Name)
 	assert.Equal(t, "john@example.com", user.Email)
+	assert.Equal(t, "/files/a.png_thumb.webp", user.ImgVariants["thumb"])
 }
@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	cache "github.com/ghulammuzz/misterblast/config/redis"
+	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionRepository keeps one row per login in user_sessions. Only the sha256
+// of the current refresh token is stored; it is replaced on every refresh.
+type SessionRepository interface {
+	Create(sessionID string, userID int32, refreshHash string, expiresAt time.Time) error
+	Rotate(oldHash, newHash string) (string, *userEntity.UserJWT, error)
+	Revoke(sessionID string) error
+	RevokeAll(userID int32) error
+	IsRevoked(sessionID string) (bool, error)
+}
+
+type sessionRepository struct {
+	db    *sql.DB
+	redis *redis.Client
+}
+
+func NewSessionRepository(db *sql.DB, redis *redis.Client) SessionRepository {
+	return &sessionRepository{db, redis}
+}
+
+const (
+	sessionActive  = "active"
+	sessionRevoked = "revoked"
+)
+
+func sessionKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+func (r *sessionRepository) Create(sessionID string, userID int32, refreshHash string, expiresAt time.Time) error {
+	query := `INSERT INTO user_sessions (id, user_id, refresh_hash, expires_at) VALUES ($1, $2, $3, $4)`
+	if _, err := r.db.Exec(query, sessionID, userID, refreshHash, expiresAt); err != nil {
+		log.Error("[Repo][CreateSession] Error Exec: ", err)
+		return app.NewAppError(500, "failed to create session")
+	}
+	return nil
+}
+
+// Rotate swaps the refresh token of a live session and returns the session id
+// with the claims needed for a new access token. An unknown, expired or
+// revoked refresh token yields 401.
+func (r *sessionRepository) Rotate(oldHash, newHash string) (string, *userEntity.UserJWT, error) {
+	var sessionID string
+	var user userEntity.UserJWT
+
+	query := `
+		UPDATE user_sessions s
+		SET refresh_hash = $2, last_used_at = NOW()
+		FROM users u
+		WHERE s.refresh_hash = $1
+		  AND s.revoked_at IS NULL
+		  AND s.expires_at > NOW()
+		  AND u.id = s.user_id
+		RETURNING s.id, u.id, u.email, u.is_admin, u.is_verified, u.role
+	`
+	err := r.db.QueryRow(query, oldHash, newHash).Scan(&sessionID, &user.ID, &user.Email, &user.IsAdmin, &user.IsVerified, &user.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, app.NewAppError(401, "invalid refresh token")
+		}
+		log.Error("[Repo][RotateSession] Error QueryRow: ", err)
+		return "", nil, app.NewAppError(500, "failed to refresh session")
+	}
+
+	return sessionID, &user, nil
+}
+
+func (r *sessionRepository) Revoke(sessionID string) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := r.db.Exec(query, sessionID); err != nil {
+		log.Error("[Repo][RevokeSession] Error Exec: ", err)
+		return app.NewAppError(500, "failed to revoke session")
+	}
+
+	r.markRevoked(sessionID)
+	return nil
+}
+
+func (r *sessionRepository) RevokeAll(userID int32) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL RETURNING id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		log.Error("[Repo][RevokeAllSessions] Error Query: ", err)
+		return app.NewAppError(500, "failed to revoke sessions")
+	}
+	defer rows.Close()
+
+	var sessionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Error("[Repo][RevokeAllSessions] Error Scan: ", err)
+			return app.NewAppError(500, "failed to revoke sessions")
+		}
+		sessionIDs = append(sessionIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][RevokeAllSessions] Error iterating rows: ", err)
+		return app.NewAppError(500, "failed to revoke sessions")
+	}
+
+	for _, id := range sessionIDs {
+		r.markRevoked(id)
+	}
+	return nil
+}
+
+// IsRevoked backs the check in JWTProtected, so the answer is cached in Redis
+// and Postgres is only hit on a cache miss. Unknown sessions count as revoked.
+func (r *sessionRepository) IsRevoked(sessionID string) (bool, error) {
+	ctx := context.Background()
+
+	if r.redis != nil {
+		cached, err := cache.Get(ctx, sessionKey(sessionID), r.redis)
+		if err == nil {
+			return cached == sessionRevoked, nil
+		}
+	}
+
+	var active bool
+	query := `SELECT revoked_at IS NULL AND expires_at > NOW() FROM user_sessions WHERE id = $1`
+	if err := r.db.QueryRow(query, sessionID).Scan(&active); err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		log.Error("[Repo][IsSessionRevoked] Error QueryRow: ", err)
+		return false, app.NewAppError(500, "failed to check session")
+	}
+
+	state := sessionActive
+	if !active {
+		state = sessionRevoked
+	}
+	if r.redis != nil {
+		_ = cache.Set(ctx, sessionKey(sessionID), state, r.redis, cache.ExpFast)
+	}
+
+	return !active, nil
+}
+
+func (r *sessionRepository) markRevoked(sessionID string) {
+	if r.redis == nil {
+		return
+	}
+	if err := cache.Set(context.Background(), sessionKey(sessionID), sessionRevoked, r.redis, cache.ExpStandard); err != nil {
+		log.Warn("[Repo][RevokeSession] Failed to cache revocation: ", err)
+	}
+}
@@ -0,0 +1,58 @@
+package repo_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRepository_Rotate(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewSessionRepository(mockDB, nil)
+
+	mock.ExpectQuery("UPDATE user_sessions s").
+		WithArgs("old-hash", "new-hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "email", "is_admin", "is_verified", "role"}).
+			AddRow("session-1", 1, "john@example.com", false, true, "student"))
+
+	sessionID, user, err := repo.Rotate("old-hash", "new-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "session-1", sessionID)
+	assert.Equal(t, "student", user.Role)
+
+	mock.ExpectQuery("UPDATE user_sessions s").
+		WithArgs("stale-hash", "new-hash").
+		WillReturnError(sql.ErrNoRows)
+
+	_, _, err = repo.Rotate("stale-hash", "new-hash")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessionRepository_IsRevoked(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewSessionRepository(mockDB, nil)
+
+	mock.ExpectQuery("SELECT revoked_at IS NULL AND expires_at > NOW\\(\\) FROM user_sessions WHERE id = \\$1").
+		WithArgs("session-1").
+		WillReturnRows(sqlmock.NewRows([]string{"active"}).AddRow(true))
+	mock.ExpectQuery("SELECT revoked_at IS NULL AND expires_at > NOW\\(\\) FROM user_sessions WHERE id = \\$1").
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	revoked, err := repo.IsRevoked("session-1")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = repo.IsRevoked("unknown")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -53,8 +53,8 @@ func TestUserRepository_Add(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	// Mock insert user baru
-	mock.ExpectQuery(`INSERT INTO users \(name, email, password, img_url, is_verified\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id`).
-		WithArgs(user.Name, user.Email, sqlmock.AnyArg(), nil, isVerified).
+	mock.ExpectQuery(`INSERT INTO users \(name, email, password, img_url, is_verified, locale\) VALUES \(\$1, \$2, \$3, \$4, \$5, COALESCE\(NULLIF\(\$6, ''\), 'id'\)\) RETURNING id`).
+		WithArgs(user.Name, user.Email, sqlmock.AnyArg(), nil, isVerified, user.Locale).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	id, err := repo.Add(user, isVerified)
@@ -75,10 +75,10 @@ func TestUserRepository_Check(t *testing.T) {
 	}
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	mock.ExpectQuery("SELECT id, email, password, is_admin, is_verified FROM users WHERE email=\\$1").
+	mock.ExpectQuery("SELECT id, email, password, is_admin, is_verified, role FROM users WHERE email=\\$1").
 		WithArgs(user.Email).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password", "is_admin", "is_verified"}).
-			AddRow(1, user.Email, hashedPassword, false, true))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password", "is_admin", "is_verified", "role"}).
+			AddRow(1, user.Email, hashedPassword, false, true, "student"))
 
 	result, err := repo.Check(user)
 	assert.NoError(t, err)
@@ -100,3 +100,26 @@ func TestUserRepository_Delete(t *testing.T) {
 	err := repo.Delete(id)
 	assert.NoError(t, err)
 }
+
+func TestUserRepository_Edit(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewUserRepository(mockDB)
+
+	mock.ExpectExec(`UPDATE users SET name=\$1,updated_at=EXTRACT\(EPOCH FROM NOW\(\)\) WHERE id=\$2`).
+		WithArgs("John", int32(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	changed, err := repo.Edit(1, userEntity.EditUser{Name: "John"})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// A new address drops the verification and its resend cooldown.
+	mock.ExpectQuery(`WITH old AS \(SELECT email FROM users WHERE id=\$2\) UPDATE users SET email=\$1, is_verified = is_verified AND email = \$1, verification_sent_at = CASE WHEN email = \$1 THEN verification_sent_at END,updated_at=.* WHERE id=\$2 RETURNING \(SELECT email FROM old\) <> email`).
+		WithArgs("john@new.example.com", int32(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"changed"}).AddRow(true))
+	changed, err = repo.Edit(1, userEntity.EditUser{Email: "john@new.example.com"})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,19 +1,28 @@
 package svc
 
 import (
+	"time"
+
+	emailSvc "github.com/ghulammuzz/misterblast/internal/email/svc"
+	tGamRepo "github.com/ghulammuzz/misterblast/internal/gamification/repo"
+	quizEntity "github.com/ghulammuzz/misterblast/internal/quiz/entity"
+	"github.com/ghulammuzz/misterblast/internal/quiz/mastery"
 	tQuizRepo "github.com/ghulammuzz/misterblast/internal/quiz/repo"
 	tTaskRepo "github.com/ghulammuzz/misterblast/internal/task/repo"
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
 	"github.com/ghulammuzz/misterblast/pkg/jwt"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 )
 
 type UserService interface {
 	Register(user userEntity.RegisterDTO) error
 	RegisterAdmin(user userEntity.RegisterAdmin) error
-	Login(user userEntity.UserLogin) (*userEntity.LoginResponse, string, error)
+	Login(user userEntity.UserLogin) (*userEntity.LoginResponse, *userEntity.TokenPair, error)
+	RefreshToken(refreshToken string) (*userEntity.TokenPair, error)
+	Logout(sessionID string) error
 	ListUser(filter map[string]string, page, limit int) (*response.PaginateResponse, error)
 	DetailUser(id int32) (userEntity.DetailUser, error)
 	AuthUser(id int32) (userEntity.UserAuth, error)
@@ -21,16 +30,22 @@ type UserService interface {
 	DeleteUser(id int32) error
 	ChangePassword(token string, newPassword string) error
 	SummaryUser(id int32, filter map[string]string) (*userEntity.UserSummary, error)
+	MasteryUser(id int32, filter map[string]string, weeks int) (*quizEntity.MasteryReport, error)
 	UpdatePassword(id int32, pw string) error
+	UpdateRole(id int32, role string) error
 }
 type userService struct {
-	userRepo  userRepo.UserRepository
-	tQuizRepo tQuizRepo.QuizRepository
-	tTaskRepo tTaskRepo.TaskRepository
+	userRepo    userRepo.UserRepository
+	sessionRepo userRepo.SessionRepository
+	tQuizRepo   tQuizRepo.QuizRepository
+	tTaskRepo   tTaskRepo.TaskRepository
+	tGamRepo    tGamRepo.GamificationRepository
+	jobs        queue.Enqueuer
+	emails      emailSvc.EmailService
 }
 
-func NewUserService(userRepo userRepo.UserRepository, tQuizRepo tQuizRepo.QuizRepository, tTaskRepo tTaskRepo.TaskRepository) UserService {
-	return &userService{userRepo: userRepo, tQuizRepo: tQuizRepo, tTaskRepo: tTaskRepo}
+func NewUserService(userRepo userRepo.UserRepository, sessionRepo userRepo.SessionRepository, tQuizRepo tQuizRepo.QuizRepository, tTaskRepo tTaskRepo.TaskRepository, tGamRepo tGamRepo.GamificationRepository, jobs queue.Enqueuer, emails emailSvc.EmailService) UserService {
+	return &userService{userRepo: userRepo, sessionRepo: sessionRepo, tQuizRepo: tQuizRepo, tTaskRepo: tTaskRepo, tGamRepo: tGamRepo, jobs: jobs, emails: emails}
 }
 
 func (s *userService) SummaryUser(id int32, filter map[string]string) (*userEntity.UserSummary, error) {
@@ -52,26 +67,87 @@ func (s *userService) SummaryUser(id int32, filter map[string]string) (*userEnti
 	}, nil
 }
 
-func (s *userService) Login(user userEntity.UserLogin) (*userEntity.LoginResponse, string, error) {
+// MasteryUser reports the user's accuracy per class, lesson, set and Bloom
+// level from their per question quiz results, with a weekly trend.
+func (s *userService) MasteryUser(id int32, filter map[string]string, weeks int) (*quizEntity.MasteryReport, error) {
+	results, err := s.tQuizRepo.MasteryResults(int(id), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := mastery.Build(int(id), results, time.Now(), weeks)
+	return &report, nil
+}
+
+func (s *userService) Login(user userEntity.UserLogin) (*userEntity.LoginResponse, *userEntity.TokenPair, error) {
 
 	var userResponse userEntity.LoginResponse
 
 	userResult, err := s.userRepo.Check(user)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	userResponse.ID = userResult.ID
 	userResponse.Email = userResult.Email
 	userResponse.IsAdmin = userResult.IsAdmin
 	userResponse.IsVerified = userResult.IsVerified
+	userResponse.Role = userResult.Role
+
+	sessionID, err := jwt.NewSessionID()
+	if err != nil {
+		log.Error("[UserSvc][Login] Failed to generate session id", "error", err)
+		return nil, nil, err
+	}
+
+	refreshToken, refreshHash, err := jwt.NewRefreshToken()
+	if err != nil {
+		log.Error("[UserSvc][Login] Failed to generate refresh token", "error", err)
+		return nil, nil, err
+	}
+
+	if err := s.sessionRepo.Create(sessionID, userResult.ID, refreshHash, time.Now().Add(jwt.RefreshTokenTTL)); err != nil {
+		return nil, nil, err
+	}
 
-	token, err := jwt.GenerateJWT(*userResult)
+	token, err := jwt.GenerateJWT(*userResult, sessionID)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	return &userResponse, token, nil
+	return &userResponse, &userEntity.TokenPair{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(jwt.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *userService) RefreshToken(refreshToken string) (*userEntity.TokenPair, error) {
+	newToken, newHash, err := jwt.NewRefreshToken()
+	if err != nil {
+		log.Error("[UserSvc][RefreshToken] Failed to generate refresh token", "error", err)
+		return nil, err
+	}
+
+	sessionID, user, err := s.sessionRepo.Rotate(jwt.HashRefreshToken(refreshToken), newHash)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.GenerateJWT(*user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userEntity.TokenPair{
+		AccessToken:  token,
+		RefreshToken: newToken,
+		ExpiresIn:    int64(jwt.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *userService) Logout(sessionID string) error {
+	return s.sessionRepo.Revoke(sessionID)
 }
 
 func (s *userService) ListUser(filter map[string]string, page, limit int) (*response.PaginateResponse, error) {
@@ -82,11 +158,25 @@ func (s *userService) DetailUser(id int32) (userEntity.DetailUser, error) {
 	return s.userRepo.Detail(id)
 }
 
+// AuthUser returns the signed-in user with their XP, streak and badges.
 func (s *userService) AuthUser(id int32) (userEntity.UserAuth, error) {
-	return s.userRepo.Auth(id)
+	user, err := s.userRepo.Auth(id)
+	if err != nil {
+		return user, err
+	}
+
+	progress, err := s.tGamRepo.Progress(int(id), time.Now())
+	if err != nil {
+		return user, err
+	}
+	user.Progress = &progress
+	return user, nil
 }
 
 func (s *userService) DeleteUser(id int32) error {
+	if err := s.sessionRepo.RevokeAll(id); err != nil {
+		return err
+	}
 	return s.userRepo.Delete(id)
 }
 
@@ -97,7 +187,11 @@ func (s *userService) ChangePassword(token string, newPassword string) error {
 		return err
 	}
 
-	return s.userRepo.EditPassword(deeplink.UserID, newPassword)
+	if err := s.userRepo.EditPassword(deeplink.UserID, newPassword); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAll(deeplink.UserID)
 }
 
 func (s *userService) UpdatePassword(id int32, pw string) error {
@@ -105,5 +199,14 @@ func (s *userService) UpdatePassword(id int32, pw string) error {
 		log.Error("[UserSvc][UpdatePassword] Failed to update password", "error", err)
 		return err
 	}
-	return nil
+	return s.sessionRepo.RevokeAll(id)
+}
+
+func (s *userService) UpdateRole(id int32, role string) error {
+	if err := s.userRepo.UpdateRole(id, role); err != nil {
+		log.Error("[UserSvc][UpdateRole] Failed to update role", "error", err)
+		return err
+	}
+	// Access tokens carry the role, so sign the user out everywhere.
+	return s.sessionRepo.RevokeAll(id)
 }
@@ -2,14 +2,14 @@ package svc
 
 import (
 	"errors"
-	"fmt"
-	"mime/multipart"
 	"os"
 	"time"
 
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
-	"github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
 	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 )
 
 func (s *userService) Register(user userEntity.RegisterDTO) error {
@@ -17,6 +17,19 @@ func (s *userService) Register(user userEntity.RegisterDTO) error {
 		return errors.New("password must be at least 6 characters")
 	}
 
+	var img []byte
+	if user.Img != nil {
+		content, err := storage.ReadFile(user.Img)
+		if err != nil {
+			log.Error("[UserSvc] Failed to read user image", "error", err)
+			return app.NewAppError(400, "failed to read image")
+		}
+		if !imaging.Supported(storage.Sniff(content)) {
+			return app.NewAppError(400, "image must be a JPEG, PNG, GIF or WebP file")
+		}
+		img = content
+	}
+
 	log.Info("[RegisterSvc] Start AddRepo")
 	startAddRepo := time.Now()
 
@@ -24,10 +37,10 @@ func (s *userService) Register(user userEntity.RegisterDTO) error {
 		Name:     user.Name,
 		Email:    user.Email,
 		Password: user.Password,
+		Locale:   user.Locale,
 	}
-	isVerified := true
-
-	id, err := s.userRepo.Add(regUser, isVerified)
+	// Students verify their address through the link emailed below.
+	id, err := s.userRepo.Add(regUser, false)
 	if err != nil {
 		log.Error("[UserSvc] Failed to register user", "error", err)
 		return err
@@ -35,25 +48,12 @@ func (s *userService) Register(user userEntity.RegisterDTO) error {
 	log.Info("[RegisterSvc] End AddRepo", "Total Duration", time.Since(startAddRepo))
 
 	if user.Img != nil {
-		go func(userImg *multipart.FileHeader, userID int64, svc *userService) {
-			log.Info("[RegisterSvc] Start UploadImg")
-			startUpload := time.Now()
-
-			url, err := repo.ImageUploadProxyRESTY(userImg, fmt.Sprintf("/prod/user/profile-img/%d", userID))
-			if err != nil {
-				log.Error("[UserSvc] Failed to upload user image", "error", err)
-				return
-			}
-			log.Info("[RegisterSvc] End UploadImg", "Total Duration", time.Since(startUpload))
-
-			log.Info("[RegisterSvc] Start UpdateImg")
-			startUpdate := time.Now()
-			if err := svc.userRepo.UpdateImageURL(userID, url); err != nil {
-				log.Error("[UserSvc] Failed to update user image URL", "error", err)
-				return
-			}
-			log.Info("[RegisterSvc] End UpdateImg", "Total Duration", time.Since(startUpdate))
-		}(user.Img, id, s)
+		s.queueProfileImage(id, user.Img.Filename, img)
+	}
+
+	// The account exists either way; the user can ask for the email again.
+	if err := s.emails.SendVerification(int32(id)); err != nil {
+		log.Warn("[UserSvc] Failed to queue verification email", "error", err)
 	}
 	return nil
 }
@@ -78,27 +78,41 @@ func (s *userService) RegisterAdmin(user userEntity.RegisterAdmin) error {
 }
 
 func (s *userService) EditUser(id int32, user userEntity.EditDTO) error {
+	// The image is checked by its magic bytes before anything is saved.
+	var img []byte
+	if user.Img != nil {
+		content, err := storage.ReadFile(user.Img)
+		if err != nil {
+			log.Error("[UserSvc] Failed to read user image", "error", err)
+			return app.NewAppError(400, "failed to read image")
+		}
+		if !imaging.Supported(storage.Sniff(content)) {
+			return app.NewAppError(400, "image must be a JPEG, PNG, GIF or WebP file")
+		}
+		img = content
+	}
+
 	edUser := userEntity.EditUser{
-		Name:  user.Name,
-		Email: user.Email,
+		Name:   user.Name,
+		Email:  user.Email,
+		Locale: user.Locale,
 	}
 
-	if err := s.userRepo.Edit(id, edUser); err != nil {
+	emailChanged, err := s.userRepo.Edit(id, edUser)
+	if err != nil {
 		log.Error("[UserSvc] Failed to update user", "error", err)
 		return err
 	}
 
+	// The new address has to be verified before it counts.
+	if emailChanged {
+		if err := s.emails.SendVerification(id); err != nil {
+			log.Warn("[UserSvc] Failed to queue verification email", "error", err)
+		}
+	}
+
 	if user.Img != nil {
-		go func(userImg *multipart.FileHeader, userID int64, svc *userService) {
-			url, err := repo.ImageUploadProxyRESTY(userImg, fmt.Sprintf("/prod/user/profile-img/%d", userID))
-			if err != nil {
-				log.Error("[UserSvc] Failed to upload user image", "error", err)
-				return
-			}
-			if err := svc.userRepo.UpdateImageURL(userID, url); err != nil {
-				log.Error("[UserSvc] Failed to update user image URL", "error", err)
-			}
-		}(user.Img, int64(id), s)
+		s.queueProfileImage(int64(id), user.Img.Filename, img)
 	}
 
 	return nil
@@ -1,17 +1,47 @@
 package svc_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	emailEntity "github.com/ghulammuzz/misterblast/internal/email/entity"
+	gamificationEntity "github.com/ghulammuzz/misterblast/internal/gamification/entity"
+	gamificationRepo "github.com/ghulammuzz/misterblast/internal/gamification/repo"
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
 	userSvc "github.com/ghulammuzz/misterblast/internal/user/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 )
 
+type MockEmailService struct {
+	mock.Mock
+}
+
+func (m *MockEmailService) SendOTP(email string) error {
+	return m.Called(email).Error(0)
+}
+
+func (m *MockEmailService) SendDeeplink(email string) (string, error) {
+	args := m.Called(email)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockEmailService) ValidateOTP(adminID int32, otp string) error {
+	return m.Called(adminID, otp).Error(0)
+}
+
+func (m *MockEmailService) SendVerification(userID int32) error {
+	return m.Called(userID).Error(0)
+}
+
+func (m *MockEmailService) VerifyEmail(token string) error {
+	return m.Called(token).Error(0)
+}
+
 type MockUserRepository struct {
 	mock.Mock
 }
@@ -57,9 +87,9 @@ func (m *MockUserRepository) Auth(id int32) (userEntity.UserAuth, error) {
 	return args.Get(0).(userEntity.UserAuth), args.Error(1)
 }
 
-func (m *MockUserRepository) Edit(id int32, user userEntity.EditUser) error {
+func (m *MockUserRepository) Edit(id int32, user userEntity.EditUser) (bool, error) {
 	args := m.Called(id, user)
-	return args.Error(0)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *MockUserRepository) EditPassword(id int32, newPassword string) error {
@@ -102,30 +132,73 @@ func (m *MockUserRepository) UpdatePassword(id int32, password string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateRole(id int32, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(sessionID string, userID int32, refreshHash string, expiresAt time.Time) error {
+	args := m.Called(sessionID, userID, refreshHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Rotate(oldHash, newHash string) (string, *userEntity.UserJWT, error) {
+	args := m.Called(oldHash, newHash)
+	if args.Get(1) == nil {
+		return "", nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*userEntity.UserJWT), args.Error(2)
+}
+
+func (m *MockSessionRepository) Revoke(sessionID string) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAll(userID int32) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) IsRevoked(sessionID string) (bool, error) {
+	args := m.Called(sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestUserService_Register(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	mockEmail := new(MockEmailService)
+	service := userSvc.NewUserService(mockRepo, nil, nil, nil, nil, nil, mockEmail)
 
 	dto := userEntity.RegisterDTO{
 		Name:     "John Doe",
 		Email:    "john@example.com",
 		Password: "password123",
+		Locale:   "en",
 	}
 
 	mockRepo.
 		On("Add", mock.MatchedBy(func(input userEntity.Register) bool {
-			return input.Name == dto.Name && input.Email == dto.Email && input.Password == dto.Password
-		}), true).
+			return input.Name == dto.Name && input.Email == dto.Email && input.Password == dto.Password && input.Locale == "en"
+		}), false).
 		Return(int64(1), nil)
+	// A failed verification email does not fail the registration.
+	mockEmail.On("SendVerification", int32(1)).Return(errors.New("queue down"))
 
 	err := service.Register(dto)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockEmail.AssertExpectations(t)
 }
 
 func TestUserService_Login(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	mockSession := new(MockSessionRepository)
+	service := userSvc.NewUserService(mockRepo, mockSession, nil, nil, nil, nil, nil)
 
 	user := userEntity.UserLogin{
 		Email:    "john@example.com",
@@ -140,29 +213,74 @@ func TestUserService_Login(t *testing.T) {
 	}
 
 	mockRepo.On("Check", user).Return(userJWT, nil)
+	mockSession.On("Create", mock.AnythingOfType("string"), int32(1), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
 
-	resp, token, err := service.Login(user)
+	resp, tokens, err := service.Login(user)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
 	assert.Equal(t, user.Email, resp.Email)
 	mockRepo.AssertExpectations(t)
+	mockSession.AssertExpectations(t)
+}
+
+func TestUserService_RefreshToken(t *testing.T) {
+	mockSession := new(MockSessionRepository)
+	service := userSvc.NewUserService(nil, mockSession, nil, nil, nil, nil, nil)
+
+	userJWT := &userEntity.UserJWT{ID: 1, Email: "john@example.com", Role: "student"}
+
+	t.Run("Success - Rotates Refresh Token", func(t *testing.T) {
+		mockSession.On("Rotate", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return("session-1", userJWT, nil).Once()
+
+		tokens, err := service.RefreshToken("old-refresh-token")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEqual(t, "old-refresh-token", tokens.RefreshToken)
+	})
+
+	t.Run("Fail - Unknown Refresh Token", func(t *testing.T) {
+		mockSession.On("Rotate", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return("", nil, app.NewAppError(401, "invalid refresh token")).Once()
+
+		tokens, err := service.RefreshToken("unknown")
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+	})
+
+	mockSession.AssertExpectations(t)
 }
 
 func TestUserService_DeleteUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	mockSession := new(MockSessionRepository)
+	service := userSvc.NewUserService(mockRepo, mockSession, nil, nil, nil, nil, nil)
 
 	id := int32(1)
+	mockSession.On("RevokeAll", id).Return(nil)
 	mockRepo.On("Delete", id).Return(nil)
 
 	err := service.DeleteUser(id)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockSession.AssertExpectations(t)
+}
+
+// MockGamificationRepo only implements what the user service calls; the
+// embedded interface panics on anything else.
+type MockGamificationRepo struct {
+	gamificationRepo.GamificationRepository
+	mock.Mock
+}
+
+func (m *MockGamificationRepo) Progress(userID int, now time.Time) (gamificationEntity.Progress, error) {
+	args := m.Called(userID)
+	return args.Get(0).(gamificationEntity.Progress), args.Error(1)
 }
 
 func TestUserService_AuthUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	mockGam := new(MockGamificationRepo)
+	service := userSvc.NewUserService(mockRepo, nil, nil, nil, mockGam, nil, nil)
 
 	id := int32(1)
 	userAuth := userEntity.UserAuth{
@@ -174,16 +292,21 @@ func TestUserService_AuthUser(t *testing.T) {
 		IsVerified: true,
 	}
 
+	progress := gamificationEntity.Progress{XP: 120, CurrentStreak: 3, LongestStreak: 7, Badges: []gamificationEntity.UserBadge{}}
+
 	mockRepo.On("Auth", id).Return(userAuth, nil)
+	mockGam.On("Progress", 1).Return(progress, nil)
 
 	resp, err := service.AuthUser(id)
 	assert.NoError(t, err)
-	assert.Equal(t, userAuth, resp)
+	assert.Equal(t, userAuth.Name, resp.Name)
+	assert.Equal(t, &progress, resp.Progress)
 	mockRepo.AssertExpectations(t)
+	mockGam.AssertExpectations(t)
 }
 func TestUserService_ListUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	service := userSvc.NewUserService(mockRepo, nil, nil, nil, nil, nil, nil)
 
 	filter := map[string]string{"role": "user"}
 	page, limit := 1, 10
@@ -219,7 +342,7 @@ func TestUserService_ListUser(t *testing.T) {
 
 func TestUserService_DetailUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	service := userSvc.NewUserService(mockRepo, nil, nil, nil, nil, nil, nil)
 
 	id := int32(1)
 	mockUser := userEntity.DetailUser{ID: id, Name: "John Doe", Email: "john@example.com"}
@@ -234,12 +357,12 @@ func TestUserService_DetailUser(t *testing.T) {
 
 func TestUserService_EditUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo, nil, nil)
+	service := userSvc.NewUserService(mockRepo, nil, nil, nil, nil, nil, nil)
 
 	id := int32(1)
 	userEdit := userEntity.EditUser{Name: "John Updated"}
 
-	mockRepo.On("Edit", id, userEdit).Return(nil)
+	mockRepo.On("Edit", id, userEdit).Return(false, nil)
 
 	editDTO := userEntity.EditDTO{
 		Name: userEdit.Name,
@@ -249,3 +372,21 @@ func TestUserService_EditUser(t *testing.T) {
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserService_EditUser_EmailChanged(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockEmail := new(MockEmailService)
+	service := userSvc.NewUserService(mockRepo, nil, nil, nil, nil, nil, mockEmail)
+
+	id := int32(1)
+	userEdit := userEntity.EditUser{Name: "John", Email: "john@new.example.com"}
+
+	// The repo reports the address changed, so the new one is verified again.
+	mockRepo.On("Edit", id, userEdit).Return(true, nil)
+	mockEmail.On("SendVerification", id).Return(nil)
+
+	err := service.EditUser(id, userEntity.EditDTO{Name: userEdit.Name, Email: userEdit.Email})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockEmail.AssertExpectations(t)
+}
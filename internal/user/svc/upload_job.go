@@ -0,0 +1,48 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+)
+
+// JobProfileImage uploads a user's profile image; the job's blob is the
+// image.
+const JobProfileImage = "user.profile_img"
+
+type profileImageJob struct {
+	UserID   int64  `json:"user_id"`
+	Filename string `json:"filename"`
+}
+
+// ProfileImageHandler processes and stores the uploaded image and sets it
+// on the user. An image that cannot be processed is never retried.
+func ProfileImageHandler(users repo.UserRepository, images imaging.Saver) queue.Handler {
+	return func(ctx context.Context, job queue.Job) error {
+		var p profileImageJob
+		if err := job.Bind(&p); err != nil {
+			return queue.Permanent(err)
+		}
+		key := fmt.Sprintf("/prod/user/profile-img/%d", p.UserID)
+		url, err := images.Save(ctx, key, job.Blob)
+		if imaging.IsInvalid(err) {
+			return queue.Permanent(err)
+		}
+		if err != nil {
+			return err
+		}
+		return users.UpdateImageURL(p.UserID, url)
+	}
+}
+
+// queueProfileImage enqueues the upload; failures are logged like the rest
+// of the profile update, which has already been saved.
+func (s *userService) queueProfileImage(userID int64, filename string, content []byte) {
+	if _, err := s.jobs.Enqueue(context.Background(), JobProfileImage, profileImageJob{UserID: userID, Filename: filename}, content); err != nil {
+		log.Error("[UserSvc] Failed to queue user image upload", "error", err)
+	}
+}
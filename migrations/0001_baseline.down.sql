@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS authors;
+DROP TABLE IF EXISTS content;
+DROP TABLE IF EXISTS task_submissions;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS quiz_submissions;
+DROP TABLE IF EXISTS answers;
+DROP TABLE IF EXISTS questions;
+DROP TABLE IF EXISTS sets;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS classes;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS user_otps;
+DROP TABLE IF EXISTS users;
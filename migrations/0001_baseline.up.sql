@@ -0,0 +1,127 @@
+-- Schema as it existed before migrations were tracked. Every statement is
+-- guarded so it can be applied to a database that was created by hand.
+
+CREATE TABLE IF NOT EXISTS users (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    email       VARCHAR(255) NOT NULL UNIQUE,
+    password    VARCHAR(255) NOT NULL,
+    img_url     TEXT,
+    is_admin    BOOLEAN NOT NULL DEFAULT FALSE,
+    is_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    updated_at  BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE TABLE IF NOT EXISTS user_otps (
+    admin_id   INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    otp_code   VARCHAR(10) NOT NULL,
+    expires_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token      VARCHAR(255) NOT NULL UNIQUE,
+    expires_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS classes (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS lessons (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    code VARCHAR(20) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS sets (
+    id        SERIAL PRIMARY KEY,
+    name      VARCHAR(100) NOT NULL,
+    lesson_id INT NOT NULL REFERENCES lessons (id) ON DELETE CASCADE,
+    class_id  INT NOT NULL REFERENCES classes (id) ON DELETE CASCADE,
+    is_quiz   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS questions (
+    id          SERIAL PRIMARY KEY,
+    number      INT NOT NULL,
+    type        VARCHAR(30) NOT NULL,
+    format      VARCHAR(10) NOT NULL,
+    content     TEXT NOT NULL,
+    is_quiz     BOOLEAN NOT NULL DEFAULT FALSE,
+    explanation TEXT NOT NULL DEFAULT '',
+    reasoning   TEXT NOT NULL DEFAULT '',
+    lang        VARCHAR(5) NOT NULL DEFAULT 'id',
+    set_id      INT NOT NULL REFERENCES sets (id) ON DELETE CASCADE,
+    deleted_at  BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_questions_set_id ON questions (set_id);
+
+CREATE TABLE IF NOT EXISTS answers (
+    id          SERIAL PRIMARY KEY,
+    question_id INT NOT NULL REFERENCES questions (id) ON DELETE CASCADE,
+    code        VARCHAR(10) NOT NULL,
+    content     TEXT NOT NULL DEFAULT '',
+    img_url     TEXT,
+    is_answer   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_answers_question_id ON answers (question_id);
+
+CREATE TABLE IF NOT EXISTS quiz_submissions (
+    id           SERIAL PRIMARY KEY,
+    user_id      INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    set_id       INT NOT NULL REFERENCES sets (id) ON DELETE CASCADE,
+    answer       TEXT,
+    correct      INT NOT NULL DEFAULT 0,
+    grade        INT NOT NULL DEFAULT 0,
+    attempt_no   INT NOT NULL DEFAULT 1,
+    submitted_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_submissions_user_id ON quiz_submissions (user_id);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id             SERIAL PRIMARY KEY,
+    title          VARCHAR(255) NOT NULL,
+    description    TEXT NOT NULL DEFAULT '',
+    content        TEXT NOT NULL DEFAULT '',
+    attachment_url TEXT,
+    created_at     BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    updated_at     BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    deleted_at     BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS task_submissions (
+    id             SERIAL PRIMARY KEY,
+    task_id        INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id        INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    answer         TEXT NOT NULL DEFAULT '',
+    attachment_url TEXT,
+    score          INT,
+    feedback       TEXT,
+    scored_at      BIGINT,
+    created_at     BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_submissions_user_id ON task_submissions (user_id);
+
+CREATE TABLE IF NOT EXISTS content (
+    id          SERIAL PRIMARY KEY,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    img_url     TEXT NOT NULL DEFAULT '',
+    site_url    TEXT NOT NULL DEFAULT '',
+    lang        VARCHAR(5) NOT NULL DEFAULT 'id'
+);
+
+CREATE TABLE IF NOT EXISTS authors (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    img_url     TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,33 @@
+-- Quiz answers are stored one row per question instead of the packed
+-- quiz_submissions.answer string.
+
+CREATE TABLE IF NOT EXISTS quiz_submission_answers (
+    id            SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL REFERENCES quiz_submissions (id) ON DELETE CASCADE,
+    question_id   INT NOT NULL REFERENCES questions (id) ON DELETE CASCADE,
+    number        INT NOT NULL,
+    answer        TEXT NOT NULL DEFAULT '',
+    score         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    is_correct    BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_submission_answers_submission_id ON quiz_submission_answers (submission_id);
+CREATE INDEX IF NOT EXISTS idx_quiz_submission_answers_question_id ON quiz_submission_answers (question_id);
+
+-- The packed string holds one option code per question of the set, in
+-- question number order. Unpack it so older submissions keep their answers.
+INSERT INTO quiz_submission_answers (submission_id, question_id, number, answer, score, is_correct)
+SELECT qs.id, q.id, q.number, SUBSTRING(qs.answer FROM q.position FOR 1),
+       CASE WHEN a.id IS NULL THEN 0 ELSE 1 END, a.id IS NOT NULL
+FROM quiz_submissions qs
+JOIN (
+    SELECT id, set_id, number, ROW_NUMBER() OVER (PARTITION BY set_id ORDER BY number, id) AS position
+    FROM questions
+) q ON q.set_id = qs.set_id
+LEFT JOIN answers a ON a.question_id = q.id AND a.is_answer
+    AND a.code = SUBSTRING(qs.answer FROM q.position FOR 1)
+WHERE qs.answer IS NOT NULL
+  AND q.position <= LENGTH(qs.answer)
+  AND NOT EXISTS (SELECT 1 FROM quiz_submission_answers sa WHERE sa.submission_id = qs.id);
+
+ALTER TABLE quiz_submissions ALTER COLUMN answer DROP NOT NULL;
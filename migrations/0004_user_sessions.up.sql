@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id           VARCHAR(32) PRIMARY KEY,
+    user_id      INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    refresh_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions (user_id);
@@ -0,0 +1,9 @@
+ALTER TABLE quiz_submission_answers DROP COLUMN IF EXISTS time_spent;
+
+ALTER TABLE quiz_submissions DROP COLUMN IF EXISTS time_taken;
+ALTER TABLE quiz_submissions DROP COLUMN IF EXISTS started_at;
+ALTER TABLE quiz_submissions DROP COLUMN IF EXISTS session_id;
+
+DROP TABLE IF EXISTS quiz_sessions;
+
+ALTER TABLE sets DROP COLUMN IF EXISTS duration_seconds;
@@ -0,0 +1,25 @@
+-- Timed quiz sessions. A session is started before answering and the
+-- submission is tied back to it so the server knows how long it took.
+
+ALTER TABLE sets ADD COLUMN IF NOT EXISTS duration_seconds INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS quiz_sessions (
+    id             SERIAL PRIMARY KEY,
+    user_id        INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    set_id         INT NOT NULL REFERENCES sets (id) ON DELETE CASCADE,
+    lang           VARCHAR(5) NOT NULL DEFAULT 'id',
+    status         VARCHAR(10) NOT NULL DEFAULT 'active',
+    question_order INT[] NOT NULL DEFAULT '{}',
+    started_at     BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    deadline_at    BIGINT,
+    finished_at    BIGINT,
+    CONSTRAINT quiz_sessions_status_check CHECK (status IN ('active', 'submitted', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_sessions_user_set ON quiz_sessions (user_id, set_id, status);
+
+ALTER TABLE quiz_submissions ADD COLUMN IF NOT EXISTS session_id INT REFERENCES quiz_sessions (id) ON DELETE SET NULL;
+ALTER TABLE quiz_submissions ADD COLUMN IF NOT EXISTS started_at BIGINT;
+ALTER TABLE quiz_submissions ADD COLUMN IF NOT EXISTS time_taken INT;
+
+ALTER TABLE quiz_submission_answers ADD COLUMN IF NOT EXISTS time_spent INT;
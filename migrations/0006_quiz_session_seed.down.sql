@@ -0,0 +1 @@
+ALTER TABLE quiz_sessions DROP COLUMN IF EXISTS seed;
@@ -0,0 +1,3 @@
+-- Seed for the per attempt question and option shuffle. Zero means the
+-- attempt was not shuffled.
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0;
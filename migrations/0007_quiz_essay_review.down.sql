@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_quiz_submission_answers_pending;
+
+ALTER TABLE quiz_submission_answers DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE quiz_submission_answers DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE quiz_submission_answers DROP COLUMN IF EXISTS feedback;
+ALTER TABLE quiz_submission_answers DROP COLUMN IF EXISTS pending;
+
+ALTER TABLE quiz_submissions DROP CONSTRAINT IF EXISTS quiz_submissions_status_check;
+ALTER TABLE quiz_submissions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,12 @@
+-- Essay answers are stored as pending and scored by a teacher. A submission
+-- stays in pending_review until every essay in it has been scored.
+
+ALTER TABLE quiz_submissions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'graded';
+ALTER TABLE quiz_submissions ADD CONSTRAINT quiz_submissions_status_check CHECK (status IN ('graded', 'pending_review'));
+
+ALTER TABLE quiz_submission_answers ADD COLUMN IF NOT EXISTS pending BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE quiz_submission_answers ADD COLUMN IF NOT EXISTS feedback TEXT;
+ALTER TABLE quiz_submission_answers ADD COLUMN IF NOT EXISTS reviewed_by INT REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE quiz_submission_answers ADD COLUMN IF NOT EXISTS reviewed_at BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_submission_answers_pending ON quiz_submission_answers (submission_id) WHERE pending;
@@ -0,0 +1,14 @@
+-- Periodic item analysis snapshots per question, computed from quiz
+-- submission answers. The newest snapshot is shown in the admin list.
+
+CREATE TABLE IF NOT EXISTS question_stat_snapshots (
+    id             SERIAL PRIMARY KEY,
+    question_id    INT NOT NULL REFERENCES questions (id) ON DELETE CASCADE,
+    responses      INT NOT NULL DEFAULT 0,
+    difficulty     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    discrimination DOUBLE PRECISION,
+    option_counts  JSONB,
+    computed_at    BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_stat_snapshots_question ON question_stat_snapshots (question_id, computed_at DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_quiz_submissions_set_user;
+
+ALTER TABLE users DROP COLUMN IF EXISTS leaderboard_opt_out;
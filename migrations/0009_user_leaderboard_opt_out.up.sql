@@ -0,0 +1,5 @@
+-- Users who opt out are left off every leaderboard.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS leaderboard_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_submissions_set_user ON quiz_submissions (set_id, user_id);
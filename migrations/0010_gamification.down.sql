@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_badges;
+DROP TABLE IF EXISTS badges;
+DROP TABLE IF EXISTS user_progress;
+DROP TABLE IF EXISTS xp_events;
@@ -0,0 +1,49 @@
+-- XP, daily streaks and achievement badges. Every award is an xp_events row
+-- keyed by its source, so the same submission never pays out twice.
+
+CREATE TABLE IF NOT EXISTS xp_events (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    source     VARCHAR(30) NOT NULL,
+    source_id  INT NOT NULL,
+    points     INT NOT NULL,
+    created_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    UNIQUE (source, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_xp_events_user_id ON xp_events (user_id);
+
+CREATE TABLE IF NOT EXISTS user_progress (
+    user_id        INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    xp             INT NOT NULL DEFAULT 0,
+    current_streak INT NOT NULL DEFAULT 0,
+    longest_streak INT NOT NULL DEFAULT 0,
+    last_active_on DATE,
+    updated_at     BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+-- A badge is earned once the user's value of metric reaches threshold.
+CREATE TABLE IF NOT EXISTS badges (
+    id          SERIAL PRIMARY KEY,
+    code        VARCHAR(50) NOT NULL UNIQUE,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    img_url     TEXT,
+    metric      VARCHAR(30) NOT NULL,
+    threshold   INT NOT NULL DEFAULT 1,
+    created_at  BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    updated_at  BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE TABLE IF NOT EXISTS user_badges (
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    badge_id   INT NOT NULL REFERENCES badges (id) ON DELETE CASCADE,
+    awarded_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    PRIMARY KEY (user_id, badge_id)
+);
+
+INSERT INTO badges (code, name, description, metric, threshold) VALUES
+    ('first_perfect_score', 'Perfect Score', 'Score 100 on a quiz for the first time.', 'perfect_scores', 1),
+    ('streak_7', '7-Day Streak', 'Stay active seven days in a row.', 'streak', 7),
+    ('lesson_complete', 'Lesson Complete', 'Finish every quiz set in a lesson.', 'lessons_completed', 1)
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,5 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS class_id;
+
+DROP TABLE IF EXISTS classroom_invites;
+DROP TABLE IF EXISTS classroom_members;
+DROP TABLE IF EXISTS classrooms;
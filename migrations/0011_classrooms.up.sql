@@ -0,0 +1,37 @@
+-- Classrooms are teacher-run groups of students on top of a grade-level class.
+-- Students enroll with the classroom's join code or through an email invite.
+
+CREATE TABLE IF NOT EXISTS classrooms (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    class_id   INT NOT NULL REFERENCES classes (id) ON DELETE CASCADE,
+    teacher_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    join_code  VARCHAR(8) NOT NULL UNIQUE,
+    created_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    updated_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_classrooms_teacher_id ON classrooms (teacher_id);
+
+CREATE TABLE IF NOT EXISTS classroom_members (
+    classroom_id INT NOT NULL REFERENCES classrooms (id) ON DELETE CASCADE,
+    user_id      INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    joined_at    BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    PRIMARY KEY (classroom_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_classroom_members_user_id ON classroom_members (user_id);
+
+CREATE TABLE IF NOT EXISTS classroom_invites (
+    id           SERIAL PRIMARY KEY,
+    classroom_id INT NOT NULL REFERENCES classrooms (id) ON DELETE CASCADE,
+    email        VARCHAR(255) NOT NULL,
+    token        VARCHAR(64) NOT NULL UNIQUE,
+    invited_by   INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at   BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    expires_at   BIGINT NOT NULL,
+    accepted_at  BIGINT
+);
+
+-- Tasks without a class stay visible to every student.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS class_id INT REFERENCES classes (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS task_assignments;
+
+ALTER TABLE task_submissions DROP COLUMN IF EXISTS is_late;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS late_policy;
+ALTER TABLE tasks DROP COLUMN IF EXISTS close_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS open_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS lesson_id;
@@ -0,0 +1,28 @@
+-- Task deadlines and targeting. open_at, due_at and close_at are epoch
+-- seconds; work submitted after due_at is late and late_policy decides
+-- whether it is flagged or refused. Nothing is accepted after close_at.
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS lesson_id INT REFERENCES lessons (id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS open_at BIGINT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at BIGINT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS close_at BIGINT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS late_policy VARCHAR(10) NOT NULL DEFAULT 'flag';
+
+ALTER TABLE task_submissions ADD COLUMN IF NOT EXISTS is_late BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A task is assigned either to a whole classroom or to a single student.
+-- Tasks without assignments stay visible to everyone, as before.
+CREATE TABLE IF NOT EXISTS task_assignments (
+    id           SERIAL PRIMARY KEY,
+    task_id      INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    classroom_id INT REFERENCES classrooms (id) ON DELETE CASCADE,
+    user_id      INT REFERENCES users (id) ON DELETE CASCADE,
+    assigned_by  INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at   BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    CHECK ((classroom_id IS NULL) <> (user_id IS NULL)),
+    UNIQUE (task_id, classroom_id),
+    UNIQUE (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_assignments_classroom_id ON task_assignments (classroom_id);
+CREATE INDEX IF NOT EXISTS idx_task_assignments_user_id ON task_assignments (user_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_task_submissions_attempt;
+
+ALTER TABLE task_submissions DROP COLUMN IF EXISTS is_final;
+ALTER TABLE task_submissions DROP COLUMN IF EXISTS attempt;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS max_attempts;
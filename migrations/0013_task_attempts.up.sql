@@ -0,0 +1,20 @@
+-- Every task submission is a numbered attempt. The final attempt is the one
+-- that counts; by default it is the latest. max_attempts NULL means
+-- unlimited.
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_attempts INT;
+
+ALTER TABLE task_submissions ADD COLUMN IF NOT EXISTS attempt INT NOT NULL DEFAULT 1;
+ALTER TABLE task_submissions ADD COLUMN IF NOT EXISTS is_final BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE task_submissions ts
+SET attempt = numbered.attempt, is_final = numbered.attempt = numbered.attempts
+FROM (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY task_id, user_id ORDER BY created_at, id) AS attempt,
+           COUNT(*) OVER (PARTITION BY task_id, user_id) AS attempts
+    FROM task_submissions
+) numbered
+WHERE numbered.id = ts.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_submissions_attempt ON task_submissions (task_id, user_id, attempt);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_submission_rubric_scores;
+DROP TABLE IF EXISTS task_rubric_levels;
+DROP TABLE IF EXISTS task_rubric_criteria;
@@ -0,0 +1,33 @@
+-- Rubrics for tasks. A rubric is a set of weighted criteria, each with
+-- performance levels worth some points. Scoring a submission picks one level
+-- per criterion; task_submissions.score keeps the computed total.
+
+CREATE TABLE IF NOT EXISTS task_rubric_criteria (
+    id          SERIAL PRIMARY KEY,
+    task_id     INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    weight      INT NOT NULL CHECK (weight > 0),
+    position    INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_rubric_criteria_task_id ON task_rubric_criteria (task_id);
+
+CREATE TABLE IF NOT EXISTS task_rubric_levels (
+    id           SERIAL PRIMARY KEY,
+    criterion_id INT NOT NULL REFERENCES task_rubric_criteria (id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    points       INT NOT NULL CHECK (points >= 0),
+    position     INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_rubric_levels_criterion_id ON task_rubric_levels (criterion_id);
+
+CREATE TABLE IF NOT EXISTS task_submission_rubric_scores (
+    submission_id INT NOT NULL REFERENCES task_submissions (id) ON DELETE CASCADE,
+    criterion_id  INT NOT NULL REFERENCES task_rubric_criteria (id) ON DELETE CASCADE,
+    level_id      INT NOT NULL REFERENCES task_rubric_levels (id) ON DELETE CASCADE,
+    comment       TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (submission_id, criterion_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_submission_comment_reads;
+DROP TABLE IF EXISTS task_submission_comments;
@@ -0,0 +1,28 @@
+-- Threaded feedback on task submissions. A thread starts with a root comment
+-- that may be anchored to a character range of the answer (anchor_start
+-- inclusive, anchor_end exclusive) or a page of the attachment; replies hang
+-- off the root. A read row marks a comment as read by one user.
+
+CREATE TABLE IF NOT EXISTS task_submission_comments (
+    id            SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL REFERENCES task_submissions (id) ON DELETE CASCADE,
+    parent_id     INT REFERENCES task_submission_comments (id) ON DELETE CASCADE,
+    author_id     INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body          TEXT NOT NULL,
+    anchor_start  INT,
+    anchor_end    INT,
+    anchor_page   INT,
+    created_at    BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    CHECK ((anchor_start IS NULL) = (anchor_end IS NULL)),
+    CHECK (anchor_start IS NULL OR (anchor_start >= 0 AND anchor_end > anchor_start)),
+    CHECK (anchor_page IS NULL OR anchor_page > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_submission_comments_submission_id ON task_submission_comments (submission_id);
+
+CREATE TABLE IF NOT EXISTS task_submission_comment_reads (
+    comment_id INT NOT NULL REFERENCES task_submission_comments (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    read_at    BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    PRIMARY KEY (comment_id, user_id)
+);
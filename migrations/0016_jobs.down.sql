@@ -0,0 +1,3 @@
+ALTER TABLE task_submissions DROP COLUMN IF EXISTS upload_job_id;
+
+DROP TABLE IF EXISTS jobs;
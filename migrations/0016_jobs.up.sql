@@ -0,0 +1,23 @@
+-- Background jobs. Workers claim queued jobs whose run_at has come, or
+-- running jobs whose lease (locked_until) ran out. Failed jobs go back to
+-- queued with a later run_at until max_attempts, then to dead. blob holds
+-- binary input such as an uploaded file and is cleared once the job is done.
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id           BIGSERIAL PRIMARY KEY,
+    kind         VARCHAR(50) NOT NULL,
+    payload      JSONB NOT NULL DEFAULT '{}',
+    blob         BYTEA,
+    status       VARCHAR(10) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'dead')),
+    attempts     INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    run_at       BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    locked_until BIGINT,
+    last_error   TEXT,
+    created_at   BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+    updated_at   BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_runnable ON jobs (run_at, id) WHERE status IN ('queued', 'running');
+
+ALTER TABLE task_submissions ADD COLUMN IF NOT EXISTS upload_job_id BIGINT REFERENCES jobs (id) ON DELETE SET NULL;
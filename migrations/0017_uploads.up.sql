@@ -0,0 +1,20 @@
+-- Direct-to-storage uploads. A row is created when a client asks for a
+-- presigned URL (pending), checked against the stored object once the client
+-- confirms (confirmed), and finally attached to a submission or profile
+-- (linked). Pending rows past expires_at can no longer be confirmed.
+
+CREATE TABLE IF NOT EXISTS uploads (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose      VARCHAR(20) NOT NULL CHECK (purpose IN ('task_attachment', 'profile_image')),
+    object_key   TEXT NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size         BIGINT NOT NULL CHECK (size > 0),
+    status       VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'linked')),
+    url          TEXT,
+    expires_at   BIGINT NOT NULL,
+    confirmed_at BIGINT,
+    created_at   BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_user ON uploads (user_id, status);
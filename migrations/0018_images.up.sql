@@ -0,0 +1,12 @@
+-- Images stored through the imaging pipeline, keyed by the URL saved in
+-- users.img_url, answers.img_url and content.img_url. variants maps a
+-- variant name such as "thumb" to the URL of its WebP rendition.
+
+CREATE TABLE IF NOT EXISTS images (
+    url          TEXT PRIMARY KEY,
+    content_type VARCHAR(50) NOT NULL,
+    width        INT NOT NULL,
+    height       INT NOT NULL,
+    variants     JSONB NOT NULL DEFAULT '{}',
+    created_at   BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
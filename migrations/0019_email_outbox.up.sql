@@ -0,0 +1,22 @@
+-- Outgoing email. Rows are rendered when queued and delivered by the job
+-- worker; attempts and last_error record failed deliveries, and a row is
+-- failed once the server rejects it or its retries run out.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(5) NOT NULL DEFAULT 'id';
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id         BIGSERIAL PRIMARY KEY,
+    recipient  VARCHAR(255) NOT NULL,
+    template   VARCHAR(50) NOT NULL,
+    locale     VARCHAR(5) NOT NULL,
+    subject    TEXT NOT NULL,
+    text_body  TEXT NOT NULL,
+    html_body  TEXT NOT NULL,
+    status     VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts   INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    sent_at    BIGINT,
+    created_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_status ON email_outbox (status, created_at);
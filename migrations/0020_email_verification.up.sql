@@ -0,0 +1,4 @@
+-- When the last verification email was sent to an unverified user, so
+-- resends can be throttled per account.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at BIGINT;
@@ -0,0 +1,5 @@
+DELETE FROM uploads WHERE purpose = 'answer_image';
+
+ALTER TABLE uploads DROP CONSTRAINT IF EXISTS uploads_purpose_check;
+ALTER TABLE uploads ADD CONSTRAINT uploads_purpose_check
+    CHECK (purpose IN ('task_attachment', 'profile_image'));
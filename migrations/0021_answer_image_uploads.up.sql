@@ -0,0 +1,6 @@
+-- Answer images are uploaded directly too, so they go through the imaging
+-- pipeline like profile images.
+
+ALTER TABLE uploads DROP CONSTRAINT IF EXISTS uploads_purpose_check;
+ALTER TABLE uploads ADD CONSTRAINT uploads_purpose_check
+    CHECK (purpose IN ('task_attachment', 'profile_image', 'answer_image'));
@@ -0,0 +1,90 @@
+package migrations_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/migrations"
+	"github.com/ghulammuzz/misterblast/pkg/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sqlLiteralRe = regexp.MustCompile("(?s)`([^`]*)`|\"((?i:SELECT|INSERT|UPDATE|DELETE)[^\"]*)\"")
+	tableRefRe   = regexp.MustCompile(`(?i)\b(FROM|JOIN|INTO|UPDATE)\s+(?:public\.)?([a-z_][a-z0-9_]*)(\s*\()?`)
+	insertRe     = regexp.MustCompile(`(?is)INSERT\s+INTO\s+(?:public\.)?(\w+)\s*\(([^)]*)\)`)
+	updateRe     = regexp.MustCompile(`(?is)UPDATE\s+(?:public\.)?(\w+)\s+(?:\w+\s+)?SET\s+(.*?)(?:\bWHERE\b|\bFROM\b|\bRETURNING\b|$)`)
+	assignRe     = regexp.MustCompile(`(?:^|,)\s*(\w+)\s*=`)
+	cteRe        = regexp.MustCompile(`(?i)(?:\bWITH|,)\s*([a-z_][a-z0-9_]*)\s+AS\s*\(`)
+)
+
+var sqlKeywords = map[string]bool{"set": true, "select": true}
+
+// TestRepoQueriesMatchSchema checks the tables and columns named in the repo
+// packages' SQL against the schema the migrations produce, so the sqlmock
+// based repo tests cannot drift away from the real database.
+func TestRepoQueriesMatchSchema(t *testing.T) {
+	all, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	schema := migrate.BuildSchema(all)
+
+	files, err := filepath.Glob("../internal/*/repo/*.go")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		// Queries may be assembled from several literals, so common table
+		// expressions are collected over the whole file.
+		ctes := map[string]bool{}
+		for _, m := range cteRe.FindAllStringSubmatch(string(src), -1) {
+			ctes[strings.ToLower(m[1])] = true
+		}
+
+		for _, lit := range sqlLiteralRe.FindAllStringSubmatch(string(src), -1) {
+			query := lit[1] + lit[2]
+			if !regexp.MustCompile(`(?i)\b(SELECT|INSERT|UPDATE|DELETE)\b`).MatchString(query) {
+				continue
+			}
+
+			for _, m := range tableRefRe.FindAllStringSubmatch(query, -1) {
+				table := strings.ToLower(m[2])
+				if sqlKeywords[table] || ctes[table] || (m[3] != "" && !strings.EqualFold(m[1], "INTO")) {
+					continue
+				}
+				assert.True(t, schema.HasTable(table), "%s: unknown table %q", file, table)
+			}
+
+			for _, m := range insertRe.FindAllStringSubmatch(query, -1) {
+				for _, col := range strings.Split(m[2], ",") {
+					col = strings.TrimSpace(col)
+					assert.True(t, schema.HasColumn(m[1], col), "%s: unknown column %s.%s", file, m[1], col)
+				}
+			}
+
+			for _, m := range updateRe.FindAllStringSubmatch(query, -1) {
+				for _, a := range assignRe.FindAllStringSubmatch(m[2], -1) {
+					assert.True(t, schema.HasColumn(m[1], a[1]), "%s: unknown column %s.%s", file, m[1], a[1])
+				}
+			}
+		}
+	}
+}
+
+func TestMigrationsLoad(t *testing.T) {
+	all, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+
+	for i, mig := range all {
+		assert.Equal(t, i+1, mig.Version, "migration versions must be contiguous")
+	}
+}
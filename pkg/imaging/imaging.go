@@ -0,0 +1,137 @@
+// Package imaging checks uploaded images by their content, strips their
+// metadata and renders the resized WebP variants served next to them.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// ErrInvalidImage wraps every rejection caused by the content itself, so
+// callers can answer 400 instead of 500.
+var ErrInvalidImage = errors.New("imaging: invalid image")
+
+// MaxPixels bounds decoded images so a small file cannot expand into a
+// huge bitmap.
+const MaxPixels = 40_000_000
+
+// Variant is a WebP rendition that fits in a MaxSide square. Images are
+// only ever scaled down.
+type Variant struct {
+	Name    string
+	MaxSide int
+}
+
+// DefaultVariants are rendered for every image. WebP encoding here is
+// lossless, so they are kept small.
+var DefaultVariants = []Variant{
+	{Name: "thumb", MaxSide: 256},
+	{Name: "medium", MaxSide: 800},
+}
+
+type Image struct {
+	// Content is the image without metadata, in its uploaded format.
+	Content     []byte
+	ContentType string
+	Width       int
+	Height      int
+	// Renditions holds the WebP encoding of each variant by name.
+	Renditions map[string][]byte
+}
+
+var decoders = map[string]func([]byte) (image.Image, error){
+	"image/jpeg": func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) },
+	"image/png":  func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) },
+	"image/gif":  func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) },
+	"image/webp": func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) },
+}
+
+var configs = map[string]func([]byte) (image.Config, error){
+	"image/jpeg": func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) },
+	"image/png":  func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) },
+	"image/gif":  func(b []byte) (image.Config, error) { return gif.DecodeConfig(bytes.NewReader(b)) },
+	"image/webp": func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) },
+}
+
+// Supported reports whether Process accepts contentType.
+func Supported(contentType string) bool {
+	_, ok := decoders[contentType]
+	return ok
+}
+
+// Process identifies content by its magic bytes, strips EXIF and other
+// metadata and renders DefaultVariants. A JPEG whose EXIF orientation says
+// it is rotated is re-encoded upright, since stripping would lose the
+// rotation. Animated GIFs keep their frames; their variants show the first.
+func Process(content []byte) (*Image, error) {
+	contentType := mimetype.Detect(content).String()
+	decode, ok := decoders[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrInvalidImage, contentType)
+	}
+	cfg, err := configs[contentType](content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is too large", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+	src, err := decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	stripped, err := stripMetadata(content, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	img := &Image{Content: stripped, ContentType: contentType, Renditions: map[string][]byte{}}
+
+	if contentType == "image/jpeg" {
+		if o := jpegOrientation(content); o > 1 {
+			src = orient(src, o)
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: 90}); err != nil {
+				return nil, err
+			}
+			img.Content = buf.Bytes()
+		}
+	}
+	img.Width, img.Height = src.Bounds().Dx(), src.Bounds().Dy()
+
+	for _, v := range DefaultVariants {
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, fit(src, v.MaxSide), nil); err != nil {
+			return nil, fmt.Errorf("imaging: encode %s: %w", v.Name, err)
+		}
+		img.Renditions[v.Name] = buf.Bytes()
+	}
+	return img, nil
+}
+
+// fit scales src down to fit in a maxSide square, keeping its aspect ratio.
+func fit(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+	if w >= h {
+		w, h = maxSide, max(1, h*maxSide/w)
+	} else {
+		w, h = max(1, w*maxSide/h), maxSide
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,157 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func gradient(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// exifJPEG encodes a w×h JPEG carrying an EXIF orientation tag.
+func exifJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, gradient(w, h), nil))
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(payload)+2))
+	app1 = append(app1, payload...)
+
+	content := buf.Bytes()
+	return append(append(append([]byte{}, content[:2]...), app1...), content[2:]...)
+}
+
+// textPNG encodes a PNG with a tEXt chunk right after IHDR.
+func textPNG(t *testing.T, text string) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, gradient(4, 4)))
+	content := buf.Bytes()
+
+	data := []byte("Comment\x00" + text)
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], "tEXt")
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	ihdrEnd := 8 + 12 + 13
+	return append(append(append([]byte{}, content[:ihdrEnd]...), chunk...), content[ihdrEnd:]...)
+}
+
+func TestProcess_JPEGOrientation(t *testing.T) {
+	content := exifJPEG(t, 40, 20, 6)
+
+	img, err := imaging.Process(content)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", img.ContentType)
+	assert.False(t, bytes.Contains(img.Content, []byte("Exif\x00\x00")))
+
+	// Rotated 90° clockwise, so the stored image is upright.
+	assert.Equal(t, 20, img.Width)
+	assert.Equal(t, 40, img.Height)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(img.Content))
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Width)
+	assert.Equal(t, 40, cfg.Height)
+}
+
+func TestProcess_JPEGStripsWithoutReencoding(t *testing.T) {
+	content := exifJPEG(t, 40, 20, 1)
+
+	img, err := imaging.Process(content)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(img.Content, []byte("Exif\x00\x00")))
+	// Only the EXIF segment goes; the compressed scan is copied as it is.
+	assert.Less(t, len(img.Content), len(content))
+	assert.True(t, bytes.HasSuffix(content, img.Content[len(img.Content)-256:]))
+	assert.Equal(t, 40, img.Width)
+}
+
+func TestProcess_PNGStripsText(t *testing.T) {
+	content := textPNG(t, "taken at home")
+
+	img, err := imaging.Process(content)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.ContentType)
+	assert.False(t, bytes.Contains(img.Content, []byte("taken at home")))
+	_, err = png.Decode(bytes.NewReader(img.Content))
+	assert.NoError(t, err)
+}
+
+func TestProcess_Variants(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, gradient(1000, 500)))
+
+	img, err := imaging.Process(buf.Bytes())
+	require.NoError(t, err)
+
+	sizes := map[string][2]int{"thumb": {256, 128}, "medium": {800, 400}}
+	for name, size := range sizes {
+		cfg, err := webp.DecodeConfig(bytes.NewReader(img.Renditions[name]))
+		require.NoError(t, err, name)
+		assert.Equal(t, size[0], cfg.Width, name)
+		assert.Equal(t, size[1], cfg.Height, name)
+	}
+}
+
+func TestProcess_SmallImagesAreNotEnlarged(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, gradient(30, 60)))
+
+	img, err := imaging.Process(buf.Bytes())
+	require.NoError(t, err)
+	cfg, err := webp.DecodeConfig(bytes.NewReader(img.Renditions["thumb"]))
+	require.NoError(t, err)
+	assert.Equal(t, 30, cfg.Width)
+	assert.Equal(t, 60, cfg.Height)
+}
+
+func TestProcess_Invalid(t *testing.T) {
+	_, err := imaging.Process([]byte("%PDF-1.7\n"))
+	assert.True(t, imaging.IsInvalid(err))
+
+	// Right magic bytes, broken body.
+	_, err = imaging.Process([]byte("\x89PNG\r\n\x1a\n\x00\x00"))
+	assert.True(t, imaging.IsInvalid(err))
+}
+
+func TestSupported(t *testing.T) {
+	assert.True(t, imaging.Supported("image/webp"))
+	assert.False(t, imaging.Supported("image/svg+xml"))
+}
+
+func TestVariantsScan(t *testing.T) {
+	var v imaging.Variants
+	require.NoError(t, v.Scan([]byte(`{"thumb":"/files/a_thumb.webp"}`)))
+	assert.Equal(t, imaging.Variants{"thumb": "/files/a_thumb.webp"}, v)
+
+	require.NoError(t, v.Scan(nil))
+	assert.Nil(t, v)
+}
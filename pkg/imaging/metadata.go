@@ -0,0 +1,210 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+)
+
+var errMalformed = errors.New("malformed image")
+
+// stripMetadata drops EXIF, XMP, IPTC, comments and text chunks without
+// re-encoding. Colour profiles are kept, and GIFs are returned as they are.
+func stripMetadata(content []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(content)
+	case "image/png":
+		return stripPNG(content)
+	case "image/webp":
+		return stripWebP(content)
+	default:
+		return content, nil
+	}
+}
+
+// stripJPEG copies the segments before the scan, except APP1 (EXIF, XMP),
+// APP13 (IPTC) and comments; the scan and everything after it are copied
+// as they are.
+func stripJPEG(content []byte) ([]byte, error) {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.Write(content[:2])
+	for i := 2; i+4 <= len(content); {
+		if content[i] != 0xFF {
+			return nil, errMalformed
+		}
+		marker := content[i+1]
+		if marker == 0xFF {
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			out.Write(content[i:])
+			return out.Bytes(), nil
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:]))
+		if end > len(content) {
+			return nil, errMalformed
+		}
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+	return nil, errMalformed
+}
+
+var pngMetadata = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(content []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(content, []byte(signature)) {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.WriteString(signature)
+	for i := len(signature); i < len(content); {
+		if i+8 > len(content) {
+			return nil, errMalformed
+		}
+		// length, type, data, CRC
+		end := i + 12 + int(binary.BigEndian.Uint32(content[i:]))
+		if end > len(content) || end < i {
+			return nil, errMalformed
+		}
+		if !pngMetadata[string(content[i+4:i+8])] {
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their flags in the
+// VP8X header.
+func stripWebP(content []byte) ([]byte, error) {
+	if len(content) < 12 || string(content[:4]) != "RIFF" || string(content[8:12]) != "WEBP" {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.Write(content[:12])
+	for i := 12; i < len(content); {
+		if i+8 > len(content) {
+			return nil, errMalformed
+		}
+		size := int(binary.LittleEndian.Uint32(content[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(content) || end < i {
+			return nil, errMalformed
+		}
+		switch string(content[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), content[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out.Write(chunk)
+		default:
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+	b := out.Bytes()
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b, nil
+}
+
+// jpegOrientation reads the EXIF orientation tag, 1 (upright) to 8. It
+// returns 1 when there is none.
+func jpegOrientation(content []byte) int {
+	for i := 2; i+4 <= len(content) && content[i] == 0xFF; {
+		marker := content[i+1]
+		if marker == 0xDA {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:]))
+		if end > len(content) {
+			break
+		}
+		if marker == 0xE1 && bytes.HasPrefix(content[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(content[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) || ifd < 0 {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// orient turns src upright according to an EXIF orientation.
+func orient(src image.Image, orientation int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, color.NRGBAModel.Convert(src.At(b.Min.X+sx, b.Min.Y+sy)))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Variants maps variant names to their URLs. Readers LEFT JOIN the images
+// table on the image URL and scan its variants column straight into it;
+// images stored before the pipeline existed have none.
+type Variants map[string]string
+
+func (v *Variants) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, v)
+	case string:
+		return json.Unmarshal([]byte(src), v)
+	default:
+		return fmt.Errorf("imaging: cannot scan %T into Variants", src)
+	}
+}
+
+func (v Variants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+// Saver processes and stores an image, returning the URL of the cleaned
+// original.
+type Saver interface {
+	Save(ctx context.Context, key string, content []byte) (string, error)
+}
+
+// Putter is the part of storage.Storage the pipeline writes through.
+type Putter interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+}
+
+// Pipeline is the Saver used by the app. Variants are stored next to the
+// original as <key>_<name>.webp and recorded in the images table.
+type Pipeline struct {
+	db    *sql.DB
+	files Putter
+}
+
+func NewPipeline(db *sql.DB, files Putter) *Pipeline {
+	return &Pipeline{db: db, files: files}
+}
+
+func (p *Pipeline) Save(ctx context.Context, key string, content []byte) (string, error) {
+	img, err := Process(content)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := p.files.Put(ctx, key, bytes.NewReader(img.Content), img.ContentType)
+	if err != nil {
+		return "", err
+	}
+	variants := Variants{}
+	for _, v := range DefaultVariants {
+		variantURL, err := p.files.Put(ctx, key+"_"+v.Name+".webp", bytes.NewReader(img.Renditions[v.Name]), "image/webp")
+		if err != nil {
+			return "", err
+		}
+		variants[v.Name] = variantURL
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO images (url, content_type, width, height, variants)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (url) DO UPDATE
+		SET content_type = EXCLUDED.content_type, width = EXCLUDED.width, height = EXCLUDED.height,
+		    variants = EXCLUDED.variants, created_at = EXTRACT(EPOCH FROM NOW())
+	`, url, img.ContentType, img.Width, img.Height, variants)
+	if err != nil {
+		return "", fmt.Errorf("imaging: record variants: %w", err)
+	}
+	return url, nil
+}
+
+// IsInvalid reports whether err was caused by the image itself.
+func IsInvalid(err error) bool {
+	return errors.Is(err, ErrInvalidImage)
+}
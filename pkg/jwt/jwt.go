@@ -1,6 +1,11 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"os"
 	"strings"
@@ -12,13 +17,26 @@ import (
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
-func GenerateJWT(userResult entity.UserJWT) (string, error) {
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	// EmailTokenTTL is how long an email verification link stays valid.
+	EmailTokenTTL = 24 * time.Hour
+)
+
+// GenerateJWT signs a short lived access token bound to the session sessionID.
+// The session id lets JWTProtected reject the token once the session is revoked.
+func GenerateJWT(userResult entity.UserJWT, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"apps":     "misterblast-core",
 		"email":    userResult.Email,
 		"user_id":  userResult.ID,
 		"is_admin": userResult.IsAdmin,
-		"exp":      time.Now().Add(time.Hour * 24 * 7).Unix(),
+		"role":     userResult.Role,
+		"sid":      sessionID,
+		"iat":      time.Now().Unix(),
+		"exp":      time.Now().Add(AccessTokenTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -46,3 +64,77 @@ func VerifyToken(tokenString string) (*jwt.Token, jwt.MapClaims, error) {
 
 	return token, claims, nil
 }
+
+// emailKey signs email verification tokens. It is derived from the JWT secret
+// but differs from it, so a verification link can never pass as an access
+// token.
+func emailKey() []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte("misterblast-verify-email"))
+	return mac.Sum(nil)
+}
+
+// GenerateEmailToken signs a link token proving that whoever holds it
+// received mail at email for the user userID. Changing the address
+// invalidates earlier tokens.
+func GenerateEmailToken(userID int32, email string) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"email":   email,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(EmailTokenTTL).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(emailKey())
+}
+
+// VerifyEmailToken checks a token from GenerateEmailToken and returns the user
+// and address it was issued for. Expired tokens fail with jwt.ErrTokenExpired.
+func VerifyEmailToken(tokenString string) (int32, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return emailKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, "", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, "", errors.New("invalid token")
+	}
+	userID, _ := claims["user_id"].(float64)
+	email, _ := claims["email"].(string)
+	if userID <= 0 || email == "" {
+		return 0, "", errors.New("invalid token")
+	}
+	return int32(userID), email, nil
+}
+
+// IsExpired reports whether err is a token that was valid but has expired.
+func IsExpired(err error) bool {
+	return errors.Is(err, jwt.ErrTokenExpired)
+}
+
+// NewSessionID returns a random identifier for a login session.
+func NewSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewRefreshToken returns an opaque refresh token together with the hash that
+// is stored server side. The plain token is only ever handed to the client.
+func NewRefreshToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
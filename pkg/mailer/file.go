@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// devSender is the From address of messages that never leave the machine.
+const devSender = "Misterblast <noreply@misterblast.local>"
+
+// File writes each message to a directory as an .eml file that any mail
+// client can open. It is meant for development.
+type File struct {
+	dir string
+}
+
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &File{dir: dir}, nil
+}
+
+func (f *File) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	body, err := compose(devSender, msg, now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(envelope(msg.To)))
+	return os.WriteFile(filepath.Join(f.dir, name), body, 0o644)
+}
+
+// Mailbox keeps messages in memory so tests can inspect what was sent.
+type Mailbox struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (m *Mailbox) Send(ctx context.Context, msg Message) error {
+	if _, err := compose(devSender, msg, time.Now()); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *Mailbox) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
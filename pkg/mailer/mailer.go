@@ -0,0 +1,90 @@
+// Package mailer renders localized emails from templates and delivers them.
+// Services hand an Email to the Outbox, which renders it, records it in the
+// email_outbox table and queues its delivery; the job worker then sends it
+// through the configured Mailer, retrying on failure.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Message is a rendered email ready for delivery.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers rendered messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+const (
+	TemplateOTP             = "otp"
+	TemplatePasswordReset   = "password_reset"
+	TemplateWelcome         = "welcome"
+	TemplateTaskGraded      = "task_graded"
+	TemplateClassroomInvite = "classroom_invite"
+	TemplateVerifyEmail     = "verify_email"
+)
+
+const (
+	LocaleID = "id"
+	LocaleEN = "en"
+
+	DefaultLocale = LocaleID
+)
+
+// Email is a templated email before rendering.
+type Email struct {
+	// To is the recipient's address. Set UserID instead to send to a user;
+	// their address, name and locale are then looked up.
+	To     string
+	UserID int64
+	// Locale picks the template language. When empty it is the recipient's
+	// own, or DefaultLocale for addresses without an account.
+	Locale   string
+	Template string
+	Data     map[string]any
+}
+
+// Sender is what services depend on to send email.
+type Sender interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// FromEnv builds the transport named by MAIL_DRIVER: "smtp" (the default)
+// or "file", which writes each message to MAIL_DIR for development.
+func FromEnv() (Mailer, error) {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", "smtp":
+		port, err := strconv.Atoi(envOr("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("mailer: invalid SMTP_PORT: %w", err)
+		}
+		return NewSMTP(SMTPConfig{
+			Host:     envOr("SMTP_HOST", "smtp.gmail.com"),
+			Port:     port,
+			Username: os.Getenv("EMAIL_HOST_USER"),
+			Password: os.Getenv("EMAIL_HOST_PASSWORD"),
+			From:     envOr("MAIL_FROM", os.Getenv("EMAIL_HOST_USER")),
+			TLS:      TLSMode(envOr("SMTP_TLS", string(TLSStartTLS))),
+		})
+	case "file":
+		return NewFile(envOr("MAIL_DIR", "mail"))
+	default:
+		return nil, fmt.Errorf("mailer: unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,228 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleData fills in every field any template uses.
+var sampleData = map[string]any{
+	"Name":      "Andi",
+	"Code":      "123456",
+	"Minutes":   2,
+	"Link":      "https://misterblast.example/x?code=abc",
+	"Task":      "Esai Sejarah",
+	"Score":     int64(88),
+	"Feedback":  "Bagus, tambahkan sumber.",
+	"Classroom": "5A Pagi",
+	"Days":      7,
+	"Hours":     24,
+}
+
+func TestTemplatesRenderEveryLocale(t *testing.T) {
+	templates, err := mailer.LoadTemplates()
+	require.NoError(t, err)
+
+	names := []string{mailer.TemplateOTP, mailer.TemplatePasswordReset, mailer.TemplateWelcome, mailer.TemplateTaskGraded, mailer.TemplateClassroomInvite, mailer.TemplateVerifyEmail}
+	for _, locale := range mailer.Locales {
+		for _, name := range names {
+			msg, err := templates.Render(name, locale, sampleData)
+			require.NoError(t, err, "%s/%s", locale, name)
+			assert.NotEmpty(t, msg.Subject, "%s/%s", locale, name)
+			assert.NotContains(t, msg.Subject, "\n")
+			assert.Contains(t, msg.Text, "Andi")
+			assert.Contains(t, msg.HTML, `<html lang="`+locale+`">`)
+			assert.Contains(t, msg.HTML, "Andi")
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	templates, err := mailer.LoadTemplates()
+	require.NoError(t, err)
+
+	msg, err := templates.Render(mailer.TemplateOTP, mailer.LocaleEN, map[string]any{"Code": "123456", "Minutes": 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Your Misterblast verification code", msg.Subject)
+	assert.True(t, strings.HasPrefix(msg.Text, "Hi,\n"))
+	assert.Contains(t, msg.Text, "Your verification code is 123456.")
+
+	// Unknown locales fall back to Indonesian.
+	msg, err = templates.Render(mailer.TemplatePasswordReset, "fr", map[string]any{"Link": "https://x/y", "Minutes": 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Atur ulang kata sandi Misterblast", msg.Subject)
+
+	// HTML escapes what users type; the text part keeps it as it is.
+	msg, err = templates.Render(mailer.TemplateTaskGraded, mailer.LocaleID, map[string]any{"Task": "<b>Esai</b>", "Score": 70, "Feedback": ""})
+	require.NoError(t, err)
+	assert.Contains(t, msg.HTML, "&lt;b&gt;Esai&lt;/b&gt;")
+	assert.Contains(t, msg.Text, `"<b>Esai</b>"`)
+	assert.NotContains(t, msg.Text, "Catatan dari guru")
+
+	_, err = templates.Render(mailer.TemplateOTP, mailer.LocaleID, map[string]any{"Code": "1"})
+	assert.Error(t, err, "missing fields are an error")
+
+	_, err = templates.Render("nope", mailer.LocaleID, nil)
+	assert.Error(t, err)
+}
+
+// readMessage parses a composed message into its headers and parts.
+func readMessage(t *testing.T, raw io.Reader) (*mail.Message, map[string]string) {
+	msg, err := mail.ReadMessage(raw)
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	return msg, parts
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := mailer.NewFile(dir)
+	require.NoError(t, err)
+
+	err = sink.Send(context.Background(), mailer.Message{
+		To:      "andi@example.com",
+		Subject: "Kode verifikasi Misterblast\r\nBcc: everyone@example.com",
+		Text:    "Halo Andi, kodenya 123456.\n",
+		HTML:    "<p>Halo Andi</p>",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	msg, parts := readMessage(t, f)
+	assert.Equal(t, "<andi@example.com>", msg.Header.Get("To"))
+	assert.Empty(t, msg.Header.Get("Bcc"), "a newline in the subject must not add headers")
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Kode verifikasi Misterblast\r\nBcc: everyone@example.com", subject)
+	assert.Equal(t, "Halo Andi, kodenya 123456.\r\n", parts["text/plain"])
+	assert.Equal(t, "<p>Halo Andi</p>", parts["text/html"])
+}
+
+func TestMailbox(t *testing.T) {
+	var box mailer.Mailbox
+	require.NoError(t, box.Send(context.Background(), mailer.Message{To: "andi@example.com", Subject: "Hi", Text: "Hi"}))
+
+	err := box.Send(context.Background(), mailer.Message{To: "not an address", Subject: "Hi", Text: "Hi"})
+	assert.True(t, mailer.IsRejected(err))
+	assert.Len(t, box.Messages(), 1)
+}
+
+// fakeSMTP accepts one plain-text session and answers RCPT with rcptReply.
+// It returns the server address and a channel with the DATA it received.
+func fakeSMTP(t *testing.T, rcptReply string) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO":
+				reply("250 fake")
+			case "MAIL":
+				reply("250 ok")
+			case "RCPT":
+				reply(rcptReply)
+			case "DATA":
+				reply("354 go ahead")
+				var body strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					body.WriteString(line)
+				}
+				data <- body.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), data
+}
+
+func newSMTP(t *testing.T, addr string) *mailer.SMTP {
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	m, err := mailer.NewSMTP(mailer.SMTPConfig{Host: host, Port: portNum, From: "Misterblast <noreply@misterblast.id>", TLS: mailer.TLSNone})
+	require.NoError(t, err)
+	return m
+}
+
+func TestSMTP(t *testing.T) {
+	addr, data := fakeSMTP(t, "250 ok")
+	err := newSMTP(t, addr).Send(context.Background(), mailer.Message{To: "andi@example.com", Subject: "Halo", Text: "Halo Andi\n"})
+	require.NoError(t, err)
+
+	msg, parts := readMessage(t, strings.NewReader(<-data))
+	assert.Equal(t, `"Misterblast" <noreply@misterblast.id>`, msg.Header.Get("From"))
+	assert.Equal(t, "Halo Andi\r\n", parts["text/plain"])
+}
+
+func TestSMTP_Rejected(t *testing.T) {
+	addr, _ := fakeSMTP(t, "550 no such user")
+	err := newSMTP(t, addr).Send(context.Background(), mailer.Message{To: "ghost@example.com", Subject: "Halo", Text: "Halo"})
+	assert.True(t, mailer.IsRejected(err))
+}
+
+func TestNewSMTP_UnknownTLS(t *testing.T) {
+	_, err := mailer.NewSMTP(mailer.SMTPConfig{Host: "localhost", Port: 25, TLS: "ssl"})
+	assert.Error(t, err)
+}
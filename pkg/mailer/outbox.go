@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+)
+
+// JobSend delivers one email_outbox row.
+const JobSend = "mail.send"
+
+type sendJob struct {
+	OutboxID int64 `json:"outbox_id"`
+}
+
+// Outbox is the Sender used by the app. Emails are rendered when sent, so
+// the row shows exactly what went out, and delivered by the job worker.
+type Outbox struct {
+	db        *sql.DB
+	jobs      queue.Enqueuer
+	templates func() (*Templates, error)
+}
+
+func NewOutbox(db *sql.DB, jobs queue.Enqueuer) *Outbox {
+	return &Outbox{db: db, jobs: jobs, templates: DefaultTemplates}
+}
+
+func (o *Outbox) Send(ctx context.Context, email Email) error {
+	templates, err := o.templates()
+	if err != nil {
+		return err
+	}
+	if err := o.resolve(ctx, &email); err != nil {
+		return err
+	}
+	msg, err := templates.Render(email.Template, email.Locale, email.Data)
+	if err != nil {
+		return err
+	}
+
+	var id int64
+	err = o.db.QueryRowContext(ctx, `
+		INSERT INTO email_outbox (recipient, template, locale, subject, text_body, html_body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, email.To, email.Template, email.Locale, msg.Subject, msg.Text, msg.HTML).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("mailer: store email: %w", err)
+	}
+	if _, err := o.jobs.Enqueue(ctx, JobSend, sendJob{OutboxID: id}, nil); err != nil {
+		return fmt.Errorf("mailer: queue email %d: %w", id, err)
+	}
+	return nil
+}
+
+// resolve fills in the address, name and locale from the recipient's user
+// row. Addresses without an account keep what the caller gave.
+func (o *Outbox) resolve(ctx context.Context, email *Email) error {
+	var row *sql.Row
+	switch {
+	case email.UserID != 0:
+		row = o.db.QueryRowContext(ctx, `SELECT email, name, locale FROM users WHERE id = $1`, email.UserID)
+	case email.To != "":
+		row = o.db.QueryRowContext(ctx, `SELECT email, name, locale FROM users WHERE email = $1`, email.To)
+	default:
+		return errors.New("mailer: email has no recipient")
+	}
+
+	var address, name, locale string
+	err := row.Scan(&address, &name, &locale)
+	switch {
+	case err == sql.ErrNoRows && email.UserID != 0:
+		return fmt.Errorf("mailer: user %d not found", email.UserID)
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return err
+	default:
+		email.To = address
+		if email.Locale == "" {
+			email.Locale = locale
+		}
+		if _, ok := email.Data["Name"]; !ok {
+			if email.Data == nil {
+				email.Data = map[string]any{}
+			}
+			email.Data["Name"] = name
+		}
+	}
+	if email.Locale == "" {
+		email.Locale = DefaultLocale
+	}
+	return nil
+}
+
+// DeliveryHandler sends queued emails through m. Each attempt is recorded
+// on the outbox row; the queue retries failures with backoff, and a message
+// the server rejects, or that runs out of attempts, is marked failed.
+func DeliveryHandler(db *sql.DB, m Mailer) queue.Handler {
+	return func(ctx context.Context, job queue.Job) error {
+		var payload sendJob
+		if err := job.Bind(&payload); err != nil {
+			return queue.Permanent(err)
+		}
+
+		var msg Message
+		var status string
+		err := db.QueryRowContext(ctx, `
+			SELECT recipient, subject, text_body, html_body, status
+			FROM email_outbox WHERE id = $1
+		`, payload.OutboxID).Scan(&msg.To, &msg.Subject, &msg.Text, &msg.HTML, &status)
+		if err == sql.ErrNoRows {
+			return queue.Permanent(fmt.Errorf("mailer: email %d not found", payload.OutboxID))
+		}
+		if err != nil {
+			return err
+		}
+		if status == "sent" {
+			return nil
+		}
+
+		if sendErr := m.Send(ctx, msg); sendErr != nil {
+			final := IsRejected(sendErr) || job.Attempts >= job.MaxAttempts
+			_, err := db.ExecContext(ctx, `
+				UPDATE email_outbox
+				SET attempts = attempts + 1, last_error = $2,
+				    status = CASE WHEN $3 THEN 'failed' ELSE status END
+				WHERE id = $1
+			`, payload.OutboxID, sendErr.Error(), final)
+			if err != nil {
+				log.Warn("[Mailer] failed to record delivery attempt: ", err)
+			}
+			if IsRejected(sendErr) {
+				return queue.Permanent(sendErr)
+			}
+			return sendErr
+		}
+
+		_, err = db.ExecContext(ctx, `
+			UPDATE email_outbox
+			SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = EXTRACT(EPOCH FROM NOW())
+			WHERE id = $1
+		`, payload.OutboxID)
+		return err
+	}
+}
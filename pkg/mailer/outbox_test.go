@@ -0,0 +1,140 @@
+package mailer_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/textproto"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type enqueued struct {
+	kind    string
+	payload any
+}
+
+type fakeQueue struct {
+	jobs []enqueued
+}
+
+func (q *fakeQueue) Enqueue(ctx context.Context, kind string, payload any, blob []byte) (int64, error) {
+	q.jobs = append(q.jobs, enqueued{kind, payload})
+	return int64(len(q.jobs)), nil
+}
+
+type mailerFunc func(ctx context.Context, msg mailer.Message) error
+
+func (f mailerFunc) Send(ctx context.Context, msg mailer.Message) error { return f(ctx, msg) }
+
+func TestOutboxSend_User(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	jobs := &fakeQueue{}
+	outbox := mailer.NewOutbox(db, jobs)
+
+	mock.ExpectQuery(`SELECT email, name, locale FROM users WHERE id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"email", "name", "locale"}).AddRow("andi@example.com", "Andi", "en"))
+	mock.ExpectQuery(`INSERT INTO email_outbox`).
+		WithArgs("andi@example.com", mailer.TemplateWelcome, "en", "Welcome to Misterblast", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+
+	err = outbox.Send(context.Background(), mailer.Email{UserID: 5, Template: mailer.TemplateWelcome})
+	require.NoError(t, err)
+	require.Len(t, jobs.jobs, 1)
+	assert.Equal(t, mailer.JobSend, jobs.jobs[0].kind)
+	payload, _ := json.Marshal(jobs.jobs[0].payload)
+	assert.JSONEq(t, `{"outbox_id":9}`, string(payload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxSend_Address(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	outbox := mailer.NewOutbox(db, &fakeQueue{})
+
+	// Invitees may not have an account yet; they get the default locale.
+	mock.ExpectQuery(`SELECT email, name, locale FROM users WHERE email = \$1`).
+		WithArgs("budi@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email", "name", "locale"}))
+	mock.ExpectQuery(`INSERT INTO email_outbox`).
+		WithArgs("budi@example.com", mailer.TemplateClassroomInvite, mailer.LocaleID, "Undangan kelas 5A Pagi", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+
+	err = outbox.Send(context.Background(), mailer.Email{
+		To:       "budi@example.com",
+		Template: mailer.TemplateClassroomInvite,
+		Data:     map[string]any{"Classroom": "5A Pagi", "Link": "https://x/invite", "Days": 7},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func outboxRow(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery(`SELECT recipient, subject, text_body, html_body, status\s+FROM email_outbox WHERE id = \$1`).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"recipient", "subject", "text_body", "html_body", "status"}).
+			AddRow("andi@example.com", "Halo", "Halo Andi", "<p>Halo Andi</p>", status))
+}
+
+func sendJob(attempts int) queue.Job {
+	return queue.Job{ID: 1, Kind: mailer.JobSend, Payload: json.RawMessage(`{"outbox_id":9}`), Attempts: attempts, MaxAttempts: 5}
+}
+
+func TestDeliveryHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	box := &mailer.Mailbox{}
+	handler := mailer.DeliveryHandler(db, box)
+
+	outboxRow(mock, "pending")
+	mock.ExpectExec(`UPDATE email_outbox\s+SET status = 'sent'`).WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, handler(context.Background(), sendJob(1)))
+	assert.Equal(t, []mailer.Message{{To: "andi@example.com", Subject: "Halo", Text: "Halo Andi", HTML: "<p>Halo Andi</p>"}}, box.Messages())
+
+	// A retried job whose email already went out is not sent twice.
+	outboxRow(mock, "sent")
+	require.NoError(t, handler(context.Background(), sendJob(2)))
+	assert.Len(t, box.Messages(), 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliveryHandler_Failures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	down := mailer.DeliveryHandler(db, mailerFunc(func(context.Context, mailer.Message) error {
+		return errors.New("connection refused")
+	}))
+	outboxRow(mock, "pending")
+	mock.ExpectExec(`UPDATE email_outbox\s+SET attempts = attempts \+ 1`).
+		WithArgs(int64(9), "connection refused", false).WillReturnResult(sqlmock.NewResult(0, 1))
+	err = down(context.Background(), sendJob(1))
+	assert.Error(t, err)
+	assert.False(t, queue.IsPermanent(err), "transient failures are retried")
+
+	// The last attempt marks the email failed.
+	outboxRow(mock, "pending")
+	mock.ExpectExec(`UPDATE email_outbox\s+SET attempts = attempts \+ 1`).
+		WithArgs(int64(9), "connection refused", true).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.Error(t, down(context.Background(), sendJob(5)))
+
+	rejected := mailer.DeliveryHandler(db, mailerFunc(func(context.Context, mailer.Message) error {
+		return &textproto.Error{Code: 550, Msg: "no such user"}
+	}))
+	outboxRow(mock, "pending")
+	mock.ExpectExec(`UPDATE email_outbox\s+SET attempts = attempts \+ 1`).
+		WithArgs(int64(9), `550 "no such user"`, true).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.True(t, queue.IsPermanent(rejected(context.Background(), sendJob(1))))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Locales are the languages every template is written in.
+var Locales = []string{LocaleID, LocaleEN}
+
+var templateNames = []string{TemplateOTP, TemplatePasswordReset, TemplateWelcome, TemplateTaskGraded, TemplateClassroomInvite, TemplateVerifyEmail}
+
+// Templates holds each message in each locale. A message is a pair of
+// files, templates/<locale>/<name>.txt and .html. The text file defines
+// "subject" and holds the plain text body; the HTML file defines "content",
+// which is wrapped in layout.html with the locale's footer.
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// DefaultTemplates are the embedded templates, parsed on first use.
+var DefaultTemplates = sync.OnceValues(LoadTemplates)
+
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{text: map[string]*texttemplate.Template{}, html: map[string]*htmltemplate.Template{}}
+	for _, locale := range Locales {
+		layout, err := htmltemplate.New("layout.html").Option("missingkey=error").
+			ParseFS(templateFS, "templates/layout.html", "templates/"+locale+"/footer.html")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range templateNames {
+			key := locale + "/" + name
+			text, err := texttemplate.New(name+".txt").Option("missingkey=error").ParseFS(templateFS, "templates/"+key+".txt")
+			if err != nil {
+				return nil, err
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("mailer: template %s.txt has no subject", key)
+			}
+			html, err := htmltemplate.Must(layout.Clone()).ParseFS(templateFS, "templates/"+key+".html")
+			if err != nil {
+				return nil, err
+			}
+			t.text[key], t.html[key] = text, html
+		}
+	}
+	return t, nil
+}
+
+// Render fills in a template. Unknown locales fall back to DefaultLocale,
+// and data missing a field the template uses is an error.
+func (t *Templates) Render(name, locale string, data map[string]any) (Message, error) {
+	if !slices.Contains(Locales, locale) {
+		locale = DefaultLocale
+	}
+	key := locale + "/" + name
+	text, ok := t.text[key]
+	if !ok {
+		return Message{}, fmt.Errorf("mailer: unknown template %q", name)
+	}
+
+	// Every template may greet by name and tag its language.
+	vars := map[string]any{"Name": "", "Locale": locale}
+	for k, v := range data {
+		vars[k] = v
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", vars); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&body, vars); err != nil {
+		return Message{}, err
+	}
+	if err := t.html[key].Execute(&html, vars); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
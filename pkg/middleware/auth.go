@@ -4,8 +4,67 @@ import (
 	"github.com/ghulammuzz/misterblast/pkg/jwt"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/gofiber/fiber/v2"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
 )
 
+// SessionChecker reports whether the login session behind an access token has
+// been revoked, e.g. by logout, a password change or the user being deleted.
+type SessionChecker interface {
+	IsRevoked(sessionID string) (bool, error)
+}
+
+var sessionChecker SessionChecker
+
+// SetSessionChecker enables the revocation check in JWTProtected. Without a
+// checker tokens are only verified by signature and expiry.
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// VerificationChecker reports whether a user has verified their email address.
+type VerificationChecker interface {
+	IsVerified(userID int64) (bool, error)
+}
+
+var verificationChecker VerificationChecker
+
+// SetVerificationChecker turns on RequireVerified. Without a checker it lets
+// every request through.
+func SetVerificationChecker(checker VerificationChecker) {
+	verificationChecker = checker
+}
+
+// RequireVerified must run after JWTProtected. It rejects students who have
+// not verified their email address with 403. Staff accounts are created by
+// admins and are not checked.
+func RequireVerified() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if verificationChecker == nil {
+			return c.Next()
+		}
+
+		claims, ok := c.Locals("claims").(jwtv5.MapClaims)
+		if !ok {
+			return response.SendError(c, fiber.StatusUnauthorized, "Unauthorized", "token not found")
+		}
+		if RoleFromClaims(claims) != RoleStudent {
+			return c.Next()
+		}
+
+		userID, _ := claims["user_id"].(float64)
+		verified, err := verificationChecker.IsVerified(int64(userID))
+		if err != nil {
+			Error("[Middleware][RequireVerified] Error checking verification: ", err)
+			return response.SendError(c, 500, "Internal Server Error", nil)
+		}
+		if !verified {
+			return response.SendError(c, fiber.StatusForbidden, "Forbidden", "email not verified")
+		}
+
+		return c.Next()
+	}
+}
+
 func JWTProtected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		tokenString := c.Get("Authorization")
@@ -18,6 +77,58 @@ func JWTProtected() fiber.Handler {
 			return response.SendError(c, 401, "Unauthorized", err.Error())
 		}
 
+		if sessionChecker != nil {
+			sessionID, _ := claims["sid"].(string)
+			if sessionID == "" {
+				return response.SendError(c, 401, "Unauthorized", "session not found")
+			}
+
+			revoked, err := sessionChecker.IsRevoked(sessionID)
+			if err != nil {
+				Error("[Middleware][JWTProtected] Error checking session: ", err)
+				return response.SendError(c, 500, "Internal Server Error", nil)
+			}
+			if revoked {
+				return response.SendError(c, 401, "Unauthorized", "session revoked")
+			}
+		}
+
+		c.Locals("user", token)
+		c.Locals("claims", claims)
+
+		return c.Next()
+	}
+}
+
+// OptionalJWT identifies the caller on public routes. Requests without a
+// usable token are let through anonymously instead of being rejected.
+func OptionalJWT() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString := c.Get("Authorization")
+		if tokenString == "" {
+			return c.Next()
+		}
+
+		token, claims, err := jwt.VerifyToken(tokenString)
+		if err != nil {
+			return c.Next()
+		}
+
+		if sessionChecker != nil {
+			sessionID, _ := claims["sid"].(string)
+			if sessionID == "" {
+				return c.Next()
+			}
+			revoked, err := sessionChecker.IsRevoked(sessionID)
+			if err != nil {
+				Error("[Middleware][OptionalJWT] Error checking session: ", err)
+				return c.Next()
+			}
+			if revoked {
+				return c.Next()
+			}
+		}
+
 		c.Locals("user", token)
 		c.Locals("claims", claims)
 
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Role string
+
+const (
+	RoleAdmin         Role = "admin"
+	RoleTeacher       Role = "teacher"
+	RoleContentEditor Role = "content_editor"
+	RoleStudent       Role = "student"
+)
+
+type Permission string
+
+const (
+	PermManageUsers      Permission = "users:manage"
+	PermManageCurriculum Permission = "curriculum:manage"
+	PermManageQuestions  Permission = "questions:manage"
+	PermManageContent    Permission = "content:manage"
+	PermManageTasks      Permission = "tasks:manage"
+	PermViewSubmissions  Permission = "submissions:view"
+	PermScoreSubmissions Permission = "submissions:score"
+	PermManageBadges     Permission = "badges:manage"
+	PermManageClassrooms Permission = "classrooms:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermManageUsers, PermManageCurriculum, PermManageQuestions, PermManageContent,
+		PermManageTasks, PermViewSubmissions, PermScoreSubmissions, PermManageBadges,
+		PermManageClassrooms,
+	},
+	RoleTeacher: {
+		PermManageQuestions, PermManageTasks, PermViewSubmissions, PermScoreSubmissions,
+		PermManageClassrooms,
+	},
+	RoleContentEditor: {
+		PermManageCurriculum, PermManageQuestions, PermManageContent,
+	},
+	RoleStudent: {},
+}
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Can(perm Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleFromClaims reads the role of a token. Tokens minted before roles existed
+// only carry is_admin, so that flag still maps to admin.
+func RoleFromClaims(claims jwt.MapClaims) Role {
+	if isAdmin, ok := claims["is_admin"].(bool); ok && isAdmin {
+		return RoleAdmin
+	}
+	if role, ok := claims["role"].(string); ok && Role(role).Valid() {
+		return Role(role)
+	}
+	return RoleStudent
+}
+
+// StudentID returns the caller's user id when the request carries a student's
+// token. Listings use it to scope the catalog to the student's classrooms.
+func StudentID(c *fiber.Ctx) (int, bool) {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok || RoleFromClaims(claims) != RoleStudent {
+		return 0, false
+	}
+	userID, ok := claims["user_id"].(float64)
+	return int(userID), ok
+}
+
+// Authorize must run after JWTProtected. It rejects the request with 403 when
+// the token's role lacks perm.
+func Authorize(perm Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(jwt.MapClaims)
+		if !ok {
+			return response.SendError(c, fiber.StatusUnauthorized, "Unauthorized", "token not found")
+		}
+
+		role := RoleFromClaims(claims)
+		if !role.Can(perm) {
+			return response.SendError(c, fiber.StatusForbidden, "Forbidden", fiber.Map{
+				"role":       role,
+				"permission": perm,
+			})
+		}
+
+		c.Locals("role", role)
+		return c.Next()
+	}
+}
+
+// AuthorizeSelfOr lets the request through when the :param route parameter is
+// the caller's own user id, otherwise the role must hold perm.
+func AuthorizeSelfOr(perm Permission, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(jwt.MapClaims)
+		if !ok {
+			return response.SendError(c, fiber.StatusUnauthorized, "Unauthorized", "token not found")
+		}
+
+		if userID, ok := claims["user_id"].(float64); ok {
+			if id, err := c.ParamsInt(param); err == nil && id == int(userID) {
+				return c.Next()
+			}
+		}
+
+		return Authorize(perm)(c)
+	}
+}
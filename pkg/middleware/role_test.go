@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+func newApp(claims jwt.MapClaims, route string, h fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Get(route, func(c *fiber.Ctx) error {
+		if claims != nil {
+			c.Locals("claims", claims)
+		}
+		return c.Next()
+	}, h, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func status(t *testing.T, app *fiber.App, path string) int {
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		perm   middleware.Permission
+		want   int
+	}{
+		{"admin manages users", jwt.MapClaims{"role": "admin"}, middleware.PermManageUsers, fiber.StatusOK},
+		{"teacher scores submissions", jwt.MapClaims{"role": "teacher"}, middleware.PermScoreSubmissions, fiber.StatusOK},
+		{"teacher cannot manage users", jwt.MapClaims{"role": "teacher"}, middleware.PermManageUsers, fiber.StatusForbidden},
+		{"editor manages content", jwt.MapClaims{"role": "content_editor"}, middleware.PermManageContent, fiber.StatusOK},
+		{"editor cannot view submissions", jwt.MapClaims{"role": "content_editor"}, middleware.PermViewSubmissions, fiber.StatusForbidden},
+		{"student cannot manage questions", jwt.MapClaims{"role": "student"}, middleware.PermManageQuestions, fiber.StatusForbidden},
+		{"unknown role is a student", jwt.MapClaims{"role": "root"}, middleware.PermManageUsers, fiber.StatusForbidden},
+		{"legacy admin flag", jwt.MapClaims{"is_admin": true}, middleware.PermManageUsers, fiber.StatusOK},
+		{"legacy admin flag wins over role", jwt.MapClaims{"is_admin": true, "role": "student"}, middleware.PermManageBadges, fiber.StatusOK},
+		{"legacy non-admin", jwt.MapClaims{"is_admin": false}, middleware.PermManageUsers, fiber.StatusForbidden},
+		{"no claims", nil, middleware.PermManageUsers, fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.claims, "/", middleware.Authorize(tt.perm))
+			assert.Equal(t, tt.want, status(t, app, "/"))
+		})
+	}
+}
+
+func TestAuthorizeSelfOr(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		path   string
+		want   int
+	}{
+		{"student reads self", jwt.MapClaims{"user_id": float64(7), "role": "student"}, "/users/7", fiber.StatusOK},
+		{"student reads other", jwt.MapClaims{"user_id": float64(7), "role": "student"}, "/users/8", fiber.StatusForbidden},
+		{"teacher reads other", jwt.MapClaims{"user_id": float64(7), "role": "teacher"}, "/users/8", fiber.StatusForbidden},
+		{"admin reads other", jwt.MapClaims{"user_id": float64(7), "role": "admin"}, "/users/8", fiber.StatusOK},
+		{"legacy admin reads other", jwt.MapClaims{"user_id": float64(7), "is_admin": true}, "/users/8", fiber.StatusOK},
+		{"non-numeric id", jwt.MapClaims{"user_id": float64(7), "role": "student"}, "/users/me", fiber.StatusForbidden},
+		{"no user id", jwt.MapClaims{"role": "student"}, "/users/7", fiber.StatusForbidden},
+		{"no claims", nil, "/users/7", fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.claims, "/users/:id", middleware.AuthorizeSelfOr(middleware.PermManageUsers, "id"))
+			assert.Equal(t, tt.want, status(t, app, tt.path))
+		})
+	}
+}
+
+func TestStudentID(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		wantID int
+		wantOK bool
+	}{
+		{"student", jwt.MapClaims{"user_id": float64(7), "role": "student"}, 7, true},
+		{"teacher", jwt.MapClaims{"user_id": float64(7), "role": "teacher"}, 0, false},
+		{"legacy admin", jwt.MapClaims{"user_id": float64(7), "is_admin": true}, 0, false},
+		{"no claims", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID int
+			var gotOK bool
+			app := newApp(tt.claims, "/", func(c *fiber.Ctx) error {
+				gotID, gotOK = middleware.StudentID(c)
+				return c.Next()
+			})
+			status(t, app, "/")
+			assert.Equal(t, tt.wantID, gotID)
+			assert.Equal(t, tt.wantOK, gotOK)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/pkg/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFS = fstest.MapFS{
+	"0002_add_role.up.sql":     {Data: []byte("ALTER TABLE users ADD COLUMN role VARCHAR(20);")},
+	"0002_add_role.down.sql":   {Data: []byte("ALTER TABLE users DROP COLUMN role;")},
+	"0001_users.up.sql":        {Data: []byte("CREATE TABLE users (\n    id SERIAL PRIMARY KEY,\n    name TEXT NOT NULL\n);")},
+	"0001_users.down.sql":      {Data: []byte("DROP TABLE users;")},
+	"README.md":                {Data: []byte("ignored")},
+	"0003_missing_down.up.sql": {Data: []byte("SELECT 1;")},
+}
+
+func TestLoad(t *testing.T) {
+	_, err := migrate.Load(testFS)
+	assert.Error(t, err, "a migration without a down file must be rejected")
+
+	fsys := fstest.MapFS{}
+	for name, f := range testFS {
+		if name != "0003_missing_down.up.sql" {
+			fsys[name] = f
+		}
+	}
+
+	all, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "users", all[0].Name)
+	assert.Equal(t, "add_role", all[1].Name)
+
+	schema := migrate.BuildSchema(all)
+	assert.True(t, schema.HasColumn("users", "name"))
+	assert.True(t, schema.HasColumn("users", "role"))
+	assert.False(t, schema.HasColumn("users", "email"))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"0001_users.up.sql":   testFS["0001_users.up.sql"],
+		"0001_users.down.sql": testFS["0001_users.down.sql"],
+	}
+	m, err := migrate.New(db, fsys)
+	require.NoError(t, err)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM schema_migrations WHERE version = \\$1\\)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec("CREATE TABLE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(1, "users").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	n, err := m.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_UpSkipsApplied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"0001_users.up.sql":   testFS["0001_users.up.sql"],
+		"0001_users.down.sql": testFS["0001_users.down.sql"],
+	}
+	m, err := migrate.New(db, fsys)
+	require.NoError(t, err)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	n, err := m.Up()
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
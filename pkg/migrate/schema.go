@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Schema maps each table to its columns after every up migration has run.
+type Schema map[string]map[string]bool
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)\s*\((.*?)\n\);`)
+	dropTableRe   = regexp.MustCompile(`(?i)DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(\w+)`)
+	addColumnRe   = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+	dropColumnRe  = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(\w+)\s+DROP\s+COLUMN\s+(?:IF\s+EXISTS\s+)?(\w+)`)
+)
+
+var constraintWords = map[string]bool{
+	"primary": true, "unique": true, "constraint": true, "foreign": true, "check": true,
+}
+
+// BuildSchema replays the DDL of the up migrations. It understands the subset
+// of statements this repo writes: CREATE/DROP TABLE and ADD/DROP COLUMN.
+func BuildSchema(migrations []Migration) Schema {
+	schema := Schema{}
+	for _, mig := range migrations {
+		for _, stmt := range strings.Split(mig.Up, ";") {
+			applyDDL(schema, strings.TrimSpace(stmt)+";")
+		}
+	}
+	return schema
+}
+
+func applyDDL(schema Schema, ddl string) {
+	for _, m := range createTableRe.FindAllStringSubmatch(ddl, -1) {
+		table := strings.ToLower(m[1])
+		if _, ok := schema[table]; !ok {
+			schema[table] = map[string]bool{}
+		}
+		for _, line := range strings.Split(m[2], "\n") {
+			fields := strings.Fields(strings.TrimSpace(line))
+			if len(fields) < 2 || constraintWords[strings.ToLower(fields[0])] {
+				continue
+			}
+			schema[table][strings.ToLower(fields[0])] = true
+		}
+	}
+	for _, m := range addColumnRe.FindAllStringSubmatch(ddl, -1) {
+		if cols, ok := schema[strings.ToLower(m[1])]; ok {
+			cols[strings.ToLower(m[2])] = true
+		}
+	}
+	for _, m := range dropColumnRe.FindAllStringSubmatch(ddl, -1) {
+		if cols, ok := schema[strings.ToLower(m[1])]; ok {
+			delete(cols, strings.ToLower(m[2]))
+		}
+	}
+	for _, m := range dropTableRe.FindAllStringSubmatch(ddl, -1) {
+		delete(schema, strings.ToLower(m[1]))
+	}
+}
+
+func (s Schema) HasTable(table string) bool {
+	_, ok := s[strings.ToLower(table)]
+	return ok
+}
+
+func (s Schema) HasColumn(table, column string) bool {
+	return s[strings.ToLower(table)][strings.ToLower(column)]
+}
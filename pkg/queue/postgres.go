@@ -0,0 +1,149 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+// wakeKey is the Redis list Enqueue pushes to so a waiting worker claims the
+// job right away instead of on its next poll.
+const wakeKey = "jobs:wake"
+
+type PostgresQueue struct {
+	db  *sql.DB
+	rdb *redis.Client
+}
+
+// NewPostgresQueue stores jobs in db. rdb may be nil, in which case workers
+// only poll.
+func NewPostgresQueue(db *sql.DB, rdb *redis.Client) *PostgresQueue {
+	return &PostgresQueue{db: db, rdb: rdb}
+}
+
+func (q *PostgresQueue) Enqueue(ctx context.Context, kind string, payload any, blob []byte) (int64, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = q.db.QueryRowContext(ctx, `
+		INSERT INTO jobs (kind, payload, blob, max_attempts)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, kind, body, blob, DefaultMaxAttempts).Scan(&id)
+	if err != nil {
+		log.Error("[Queue] failed to enqueue job, cause : %s", err.Error())
+		return 0, err
+	}
+
+	if q.rdb != nil {
+		pipe := q.rdb.Pipeline()
+		pipe.LPush(ctx, wakeKey, id)
+		pipe.LTrim(ctx, wakeKey, 0, 99)
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Warn("[Queue] failed to wake workers: ", err)
+		}
+	}
+	return id, nil
+}
+
+func (q *PostgresQueue) Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1,
+		    locked_until = EXTRACT(EPOCH FROM NOW()) + $3, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE kind = ANY($1)
+			  AND ((status = 'queued' AND run_at <= EXTRACT(EPOCH FROM NOW()))
+			    OR (status = 'running' AND locked_until < EXTRACT(EPOCH FROM NOW())))
+			ORDER BY run_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, kind, payload, blob, attempts, max_attempts
+	`, pq.Array(kinds), limit, int64(lease.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(&j.ID, &j.Kind, &j.Payload, &j.Blob, &j.Attempts, &j.MaxAttempts); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Complete drops the job's blob; it is not needed once the work is done.
+func (q *PostgresQueue) Complete(ctx context.Context, job Job) error {
+	res, err := q.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'done', blob = NULL, last_error = NULL, locked_until = NULL, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`, job.ID, job.Attempts)
+	return leased(res, err)
+}
+
+func (q *PostgresQueue) Retry(ctx context.Context, job Job, cause error, at time.Time) error {
+	res, err := q.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'queued', run_at = $3, last_error = $4, locked_until = NULL, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`, job.ID, job.Attempts, at.Unix(), cause.Error())
+	return leased(res, err)
+}
+
+// Bury keeps the blob so a dead job can be inspected and requeued by hand.
+func (q *PostgresQueue) Bury(ctx context.Context, job Job, cause error) error {
+	res, err := q.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'dead', last_error = $3, locked_until = NULL, updated_at = EXTRACT(EPOCH FROM NOW())
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`, job.ID, job.Attempts, cause.Error())
+	return leased(res, err)
+}
+
+// leased turns an update that matched no row into ErrLeaseLost: the job is no
+// longer running under the attempt this worker claimed.
+func leased(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (q *PostgresQueue) Wait(ctx context.Context, timeout time.Duration) {
+	if q.rdb == nil {
+		select {
+		case <-ctx.Done():
+		case <-time.After(timeout):
+		}
+		return
+	}
+	if err := q.rdb.BLPop(ctx, timeout, wakeKey).Err(); err != nil && err != redis.Nil && ctx.Err() == nil {
+		// Redis is down; fall back to polling.
+		select {
+		case <-ctx.Done():
+		case <-time.After(timeout):
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package queue runs background jobs stored in Postgres. Workers claim jobs
+// under a lease, retry failures with exponential backoff and move a job to
+// dead once its attempts run out. Redis, when available, only wakes idle
+// workers early; Postgres stays the source of truth.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusDead    Status = "dead"
+)
+
+const (
+	DefaultMaxAttempts = 5
+
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+type Job struct {
+	ID          int64
+	Kind        string
+	Payload     json.RawMessage
+	Blob        []byte
+	Attempts    int
+	MaxAttempts int
+}
+
+// Bind decodes the job payload into v.
+func (j Job) Bind(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+type Handler func(ctx context.Context, job Job) error
+
+// Enqueuer is what services depend on to schedule work. blob carries binary
+// input such as an uploaded file and may be nil.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, kind string, payload any, blob []byte) (int64, error)
+}
+
+type Store interface {
+	Enqueuer
+	// Claim leases up to limit runnable jobs of the given kinds. Jobs whose
+	// lease ran out, because their worker died, are runnable again.
+	Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]Job, error)
+	// Complete, Retry and Bury record the result of the run Claim returned
+	// job for. They return ErrLeaseLost when the job was claimed again since.
+	Complete(ctx context.Context, job Job) error
+	Retry(ctx context.Context, job Job, cause error, at time.Time) error
+	Bury(ctx context.Context, job Job, cause error) error
+	// Wait blocks until new work may be available or timeout passes.
+	Wait(ctx context.Context, timeout time.Duration)
+}
+
+// ErrLeaseLost means the job's lease ran out and another worker claimed it,
+// so the result of this run is dropped.
+var ErrLeaseLost = errors.New("queue: job lease lost")
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error retrying cannot fix; the job is buried at once.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Backoff is the delay before retrying a job that failed its attempt-th run:
+// 10s, 20s, 40s, ... capped at one hour.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := baseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+const (
+	defaultPoll  = 5 * time.Second
+	defaultLease = 5 * time.Minute
+
+	// recordTimeout bounds saving a job's result. It is separate from the
+	// lease so a handler that used all of it can still be recorded.
+	recordTimeout = 10 * time.Second
+)
+
+// Worker runs at most concurrency jobs at a time. A job gets the lease as
+// its deadline; a job still running after that may be claimed again.
+type Worker struct {
+	store       Store
+	handlers    map[string]Handler
+	concurrency int
+	Poll        time.Duration
+	Lease       time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorker(store Store, concurrency int) *Worker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		store:       store,
+		handlers:    map[string]Handler{},
+		concurrency: concurrency,
+		Poll:        defaultPoll,
+		Lease:       defaultLease,
+	}
+}
+
+// Handle registers the handler for a job kind. Call it before Start.
+func (w *Worker) Handle(kind string, h Handler) {
+	w.handlers[kind] = h
+}
+
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	kinds := make([]string, 0, len(w.handlers))
+	for kind := range w.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.loop(ctx, kinds)
+	}()
+}
+
+// Stop stops claiming jobs and waits for the running ones to finish.
+func (w *Worker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context, kinds []string) {
+	slots := make(chan struct{}, w.concurrency)
+	freed := make(chan struct{}, w.concurrency)
+
+	for ctx.Err() == nil {
+		free := w.concurrency - len(slots)
+		if free == 0 {
+			select {
+			case <-ctx.Done():
+			case <-freed:
+			}
+			continue
+		}
+
+		jobs, err := w.store.Claim(ctx, kinds, free, w.Lease)
+		if err != nil && ctx.Err() == nil {
+			log.Error("[Queue] failed to claim jobs: ", err)
+		}
+		for _, job := range jobs {
+			slots <- struct{}{}
+			w.wg.Add(1)
+			go func(job Job) {
+				defer func() {
+					<-slots
+					select {
+					case freed <- struct{}{}:
+					default:
+					}
+					w.wg.Done()
+				}()
+				w.run(job)
+			}(job)
+		}
+		if err == nil && len(jobs) == free {
+			// There may be more work waiting.
+			continue
+		}
+		w.store.Wait(ctx, w.Poll)
+	}
+}
+
+func (w *Worker) run(job Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.Lease)
+	err := w.call(ctx, job)
+	cancel()
+
+	ctx, cancel = context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	switch {
+	case err == nil:
+		err = w.store.Complete(ctx, job)
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		log.Error("[Queue] job is dead", "id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+		err = w.store.Bury(ctx, job, err)
+	default:
+		log.Warn("[Queue] job failed, retrying", "id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+		err = w.store.Retry(ctx, job, err, time.Now().Add(Backoff(job.Attempts)))
+	}
+	if errors.Is(err, ErrLeaseLost) {
+		log.Warn("[Queue] job lease lost, result dropped", "id", job.ID, "kind", job.Kind, "attempts", job.Attempts)
+		return
+	}
+	if err != nil {
+		log.Error("[Queue] failed to record job result", "id", job.ID, "error", err)
+	}
+}
+
+func (w *Worker) call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+	return handler(ctx, job)
+}
@@ -0,0 +1,270 @@
+package queue_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/pkg/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+// memStore runs every job immediately; retries ignore their backoff.
+type memStore struct {
+	mu    sync.Mutex
+	jobs  map[int64]*memJob
+	next  int64
+	wake  chan struct{}
+	doneC chan struct{}
+}
+
+type memJob struct {
+	job    queue.Job
+	status queue.Status
+	err    string
+}
+
+func newMemStore() *memStore {
+	return &memStore{jobs: map[int64]*memJob{}, wake: make(chan struct{}, 100), doneC: make(chan struct{}, 100)}
+}
+
+func (s *memStore) Enqueue(ctx context.Context, kind string, payload any, blob []byte) (int64, error) {
+	body, _ := json.Marshal(payload)
+	s.mu.Lock()
+	s.next++
+	id := s.next
+	s.jobs[id] = &memJob{job: queue.Job{ID: id, Kind: kind, Payload: body, Blob: blob, MaxAttempts: 3}, status: queue.StatusQueued}
+	s.mu.Unlock()
+	s.wake <- struct{}{}
+	return id, nil
+}
+
+func (s *memStore) Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]queue.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []queue.Job
+	for id := int64(1); id <= s.next && len(jobs) < limit; id++ {
+		j := s.jobs[id]
+		if j.status != queue.StatusQueued {
+			continue
+		}
+		j.status = queue.StatusRunning
+		j.job.Attempts++
+		jobs = append(jobs, j.job)
+	}
+	return jobs, nil
+}
+
+func (s *memStore) set(job queue.Job, status queue.Status, cause error) error {
+	s.mu.Lock()
+	j := s.jobs[job.ID]
+	if j.status != queue.StatusRunning || j.job.Attempts != job.Attempts {
+		s.mu.Unlock()
+		return queue.ErrLeaseLost
+	}
+	j.status = status
+	if cause != nil {
+		j.err = cause.Error()
+	}
+	s.mu.Unlock()
+	if status == queue.StatusQueued {
+		s.wake <- struct{}{}
+	} else {
+		s.doneC <- struct{}{}
+	}
+	return nil
+}
+
+func (s *memStore) Complete(ctx context.Context, job queue.Job) error {
+	return s.set(job, queue.StatusDone, nil)
+}
+
+func (s *memStore) Retry(ctx context.Context, job queue.Job, cause error, at time.Time) error {
+	return s.set(job, queue.StatusQueued, cause)
+}
+
+func (s *memStore) Bury(ctx context.Context, job queue.Job, cause error) error {
+	return s.set(job, queue.StatusDead, cause)
+}
+
+// reclaim simulates another worker claiming a job whose lease ran out.
+func (s *memStore) reclaim(id int64) {
+	s.mu.Lock()
+	s.jobs[id].job.Attempts++
+	s.mu.Unlock()
+}
+
+func (s *memStore) Wait(ctx context.Context, timeout time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-s.wake:
+	case <-time.After(timeout):
+	}
+}
+
+func (s *memStore) job(id int64) memJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.jobs[id]
+}
+
+func (s *memStore) waitFinished(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.doneC:
+		case <-time.After(2 * time.Second):
+			t.Fatal("jobs did not finish")
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, queue.Backoff(1))
+	assert.Equal(t, 20*time.Second, queue.Backoff(2))
+	assert.Equal(t, 80*time.Second, queue.Backoff(4))
+	assert.Equal(t, time.Hour, queue.Backoff(20))
+}
+
+func TestWorkerRetriesUntilSuccess(t *testing.T) {
+	store := newMemStore()
+	w := queue.NewWorker(store, 2)
+	w.Poll = 10 * time.Millisecond
+
+	var calls int32
+	w.Handle("upload", func(ctx context.Context, job queue.Job) error {
+		var p struct{ Name string }
+		assert.NoError(t, job.Bind(&p))
+		assert.Equal(t, "a.pdf", p.Name)
+		assert.Equal(t, []byte("data"), job.Blob)
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("storage unavailable")
+		}
+		return nil
+	})
+	w.Start()
+	defer w.Stop()
+
+	id, _ := store.Enqueue(context.Background(), "upload", map[string]string{"Name": "a.pdf"}, []byte("data"))
+	store.waitFinished(t, 1)
+
+	j := store.job(id)
+	assert.Equal(t, queue.StatusDone, j.status)
+	assert.Equal(t, 3, j.job.Attempts)
+}
+
+// strictStore refuses to record results under a context that is done, as a
+// database call would.
+type strictStore struct {
+	*memStore
+}
+
+func (s strictStore) Complete(ctx context.Context, job queue.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.memStore.Complete(ctx, job)
+}
+
+func TestWorkerRecordsResultAfterLease(t *testing.T) {
+	store := strictStore{newMemStore()}
+	w := queue.NewWorker(store, 1)
+	w.Poll = 10 * time.Millisecond
+	w.Lease = 30 * time.Millisecond
+
+	// The handler finishes its work right as the lease runs out.
+	w.Handle("mail", func(ctx context.Context, job queue.Job) error {
+		<-ctx.Done()
+		return nil
+	})
+	w.Start()
+	defer w.Stop()
+
+	id, _ := store.Enqueue(context.Background(), "mail", nil, nil)
+	store.waitFinished(t, 1)
+	assert.Equal(t, queue.StatusDone, store.job(id).status)
+}
+
+func TestWorkerDropsResultOfLostLease(t *testing.T) {
+	store := newMemStore()
+	w := queue.NewWorker(store, 1)
+	w.Poll = 10 * time.Millisecond
+
+	ran := make(chan struct{})
+	w.Handle("mail", func(ctx context.Context, job queue.Job) error {
+		// Another worker took the job over while this run was still busy.
+		store.reclaim(job.ID)
+		defer close(ran)
+		return errors.New("smtp down")
+	})
+	w.Start()
+
+	id, _ := store.Enqueue(context.Background(), "mail", nil, nil)
+	<-ran
+	w.Stop()
+
+	j := store.job(id)
+	assert.Equal(t, queue.StatusRunning, j.status)
+	assert.Equal(t, 2, j.job.Attempts)
+	assert.Empty(t, j.err)
+}
+
+func TestWorkerBuriesJobs(t *testing.T) {
+	store := newMemStore()
+	w := queue.NewWorker(store, 1)
+	w.Poll = 10 * time.Millisecond
+	w.Handle("flaky", func(ctx context.Context, job queue.Job) error { return errors.New("boom") })
+	w.Handle("broken", func(ctx context.Context, job queue.Job) error { return queue.Permanent(errors.New("bad payload")) })
+	w.Handle("panics", func(ctx context.Context, job queue.Job) error { panic("oops") })
+	w.Start()
+	defer w.Stop()
+
+	flaky, _ := store.Enqueue(context.Background(), "flaky", nil, nil)
+	broken, _ := store.Enqueue(context.Background(), "broken", nil, nil)
+	panics, _ := store.Enqueue(context.Background(), "panics", nil, nil)
+	store.waitFinished(t, 3)
+
+	j := store.job(flaky)
+	assert.Equal(t, queue.StatusDead, j.status)
+	assert.Equal(t, 3, j.job.Attempts)
+	assert.Equal(t, "boom", j.err)
+
+	j = store.job(broken)
+	assert.Equal(t, queue.StatusDead, j.status)
+	assert.Equal(t, 1, j.job.Attempts)
+
+	j = store.job(panics)
+	assert.Equal(t, queue.StatusDead, j.status)
+	assert.Equal(t, "panic: oops", j.err)
+}
+
+func TestWorkerConcurrencyLimit(t *testing.T) {
+	store := newMemStore()
+	w := queue.NewWorker(store, 2)
+	w.Poll = 10 * time.Millisecond
+
+	var running, peak int32
+	w.Handle("slow", func(ctx context.Context, job queue.Job) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	for i := 0; i < 6; i++ {
+		store.Enqueue(context.Background(), "slow", nil, nil)
+	}
+	w.Start()
+	defer w.Stop()
+
+	store.waitFinished(t, 6)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// metaDir holds each object's content type, mirroring the object tree.
+const metaDir = ".meta"
+
+// Local keeps objects under a directory and serves them from baseURL
+// through Handler. It is meant for development and tests.
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+func NewLocal(root, baseURL, secret string) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{root: root, baseURL: strings.TrimSuffix(baseURL, "/"), secret: []byte(secret), now: time.Now}, nil
+}
+
+func (l *Local) path(key string) (string, string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	if key == metaDir || strings.HasPrefix(key, metaDir+"/") {
+		return "", "", ErrInvalidKey
+	}
+	return key, filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) metaPath(key string) string {
+	return filepath.Join(l.root, metaDir, filepath.FromSlash(key))
+}
+
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	key, file, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so readers never see half an object.
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return "", err
+	}
+
+	meta := l.metaPath(key)
+	if contentType == "" {
+		os.Remove(meta)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(meta), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(meta, []byte(contentType), 0o644); err != nil {
+			return "", err
+		}
+	}
+	return l.baseURL + "/" + key, nil
+}
+
+func (l *Local) URL(key string) (string, error) {
+	key, _, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	return l.baseURL + "/" + key, nil
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	_, file, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	key, file, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	os.Remove(l.metaPath(key))
+	return err
+}
+
+// Stat falls back to sniffing the content for objects stored without a
+// content type.
+func (l *Local) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	key, file, err := l.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	fi, err := os.Stat(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Size: fi.Size(), ContentType: l.contentType(key, file)}, nil
+}
+
+func (l *Local) contentType(key, file string) string {
+	if meta, err := os.ReadFile(l.metaPath(key)); err == nil {
+		return string(meta)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	head := make([]byte, SniffLen)
+	n, _ := io.ReadFull(f, head)
+	return Sniff(head[:n])
+}
+
+// SignedURL needs STORAGE_SIGNING_KEY; Handler rejects the URL once it
+// expires or if it was tampered with.
+func (l *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	key, _, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if len(l.secret) == 0 {
+		return "", ErrUnsupported
+	}
+	expires := strconv.FormatInt(l.now().Add(expiry).Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {l.sign(key, expires)}}
+	return l.baseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// SignedPutURL works like SignedURL. Handler accepts the PUT only with the
+// signed Content-Type and a body of exactly size bytes; the body also
+// counts against the app's body limit.
+func (l *Local) SignedPutURL(ctx context.Context, key string, contentType string, size int64, expiry time.Duration) (string, error) {
+	key, _, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if len(l.secret) == 0 {
+		return "", ErrUnsupported
+	}
+	expires := strconv.FormatInt(l.now().Add(expiry).Unix(), 10)
+	sizeStr := strconv.FormatInt(size, 10)
+	q := url.Values{
+		"expires":   {expires},
+		"size":      {sizeStr},
+		"signature": {l.sign(http.MethodPut, key, expires, sizeStr, contentType)},
+	}
+	return l.baseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+func (l *Local) sign(parts ...string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MountPath is the path of baseURL, where Handler should be mounted.
+func (l *Local) MountPath() string {
+	if u, err := url.Parse(l.baseURL); err == nil {
+		return u.Path
+	}
+	return l.baseURL
+}
+
+// Handler serves objects for a route mounted at MountPath with a trailing
+// wildcard, e.g. app.Get("/files/*", local.Handler()), and stores uploads
+// PUT to a SignedPutURL on the same route. Requests carrying a signature
+// must be unexpired and match it.
+func (l *Local) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, file, err := l.path(c.Params("*"))
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+		if c.Method() == fiber.MethodPut {
+			return l.receive(c, key)
+		}
+		if sig := c.Query("signature"); sig != "" {
+			expires := c.Query("expires")
+			at, err := strconv.ParseInt(expires, 10, 64)
+			if err != nil || l.now().Unix() > at || len(l.secret) == 0 ||
+				!hmac.Equal([]byte(sig), []byte(l.sign(key, expires))) {
+				return fiber.ErrForbidden
+			}
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fiber.ErrNotFound
+		}
+		if err := c.SendFile(file); err != nil {
+			return err
+		}
+		if contentType := l.contentType(key, file); contentType != "" {
+			c.Set(fiber.HeaderContentType, contentType)
+		}
+		return nil
+	}
+}
+
+// receive stores the body of a PUT to a SignedPutURL.
+func (l *Local) receive(c *fiber.Ctx, key string) error {
+	expires, size := c.Query("expires"), c.Query("size")
+	contentType := c.Get(fiber.HeaderContentType)
+	at, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l.now().Unix() > at || len(l.secret) == 0 ||
+		!hmac.Equal([]byte(c.Query("signature")), []byte(l.sign(http.MethodPut, key, expires, size, contentType))) {
+		return fiber.ErrForbidden
+	}
+	body := c.Body()
+	if strconv.Itoa(len(body)) != size {
+		return fiber.NewError(fiber.StatusBadRequest, "body size does not match the signed size")
+	}
+	if _, err := l.Put(c.UserContext(), key, bytes.NewReader(body), contentType); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
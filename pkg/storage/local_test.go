@@ -0,0 +1,92 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	local, err := storage.NewLocal(t.TempDir(), "/files/", "secret")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	url, err := local.Put(ctx, "/prod/content/img/a.png", strings.NewReader("png"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, "/files/prod/content/img/a.png", url)
+
+	body, err := local.Get(ctx, "prod/content/img/a.png")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "png", string(content))
+
+	_, err = local.Put(ctx, "../escape", strings.NewReader("x"), "")
+	assert.ErrorIs(t, err, storage.ErrInvalidKey)
+
+	app := fiber.New()
+	app.Get("/files/*", local.Handler())
+
+	signed, err := local.SignedURL(ctx, "prod/content/img/a.png", time.Minute)
+	require.NoError(t, err)
+	resp, err := app.Test(httptest.NewRequest("GET", signed, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", strings.Replace(signed, "signature=", "signature=0", 1), nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	require.NoError(t, local.Delete(ctx, "prod/content/img/a.png"))
+	_, err = local.Get(ctx, "prod/content/img/a.png")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestLocalSignedPut(t *testing.T) {
+	local, err := storage.NewLocal(t.TempDir(), "/files", "secret")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	app := fiber.New()
+	app.Add(fiber.MethodGet, "/files/*", local.Handler())
+	app.Add(fiber.MethodPut, "/files/*", local.Handler())
+
+	signed, err := local.SignedPutURL(ctx, "/uploads/a.pdf", "application/pdf", 4, time.Minute)
+	require.NoError(t, err)
+
+	put := func(target, contentType, body string) int {
+		req := httptest.NewRequest("PUT", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, fiber.StatusForbidden, put(signed, "image/png", "%PDF"))
+	assert.Equal(t, fiber.StatusBadRequest, put(signed, "application/pdf", "%PDF-1.7"))
+	assert.Equal(t, fiber.StatusOK, put(signed, "application/pdf", "%PDF"))
+
+	info, err := local.Stat(ctx, "uploads/a.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, storage.ObjectInfo{Size: 4, ContentType: "application/pdf"}, info)
+
+	url, err := local.URL("uploads/a.pdf")
+	require.NoError(t, err)
+	resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+
+	_, err = local.Put(ctx, ".meta/uploads/a.pdf", strings.NewReader("x"), "")
+	assert.ErrorIs(t, err, storage.ErrInvalidKey)
+
+	require.NoError(t, local.Delete(ctx, "uploads/a.pdf"))
+	_, err = local.Stat(ctx, "uploads/a.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
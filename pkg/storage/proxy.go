@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"time"
+
+	log "github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/go-resty/resty/v2"
+)
+
+// Proxy uploads through the file service. The service only accepts
+// uploads and serves them publicly, so only Put is supported.
+type Proxy struct {
+	url    string
+	token  string
+	client *resty.Client
+}
+
+func NewProxy(url, token string) *Proxy {
+	return &Proxy{url: url, token: token, client: resty.New().SetTimeout(30 * time.Second)}
+}
+
+type proxyResponse struct {
+	Data struct {
+		URL string `json:"url"`
+	} `json:"data"`
+}
+
+func (p *Proxy) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	if p.token == "" {
+		return "", errors.New("FILE_SERVICE_TOKEN environment variable not set")
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("key", key); err != nil {
+		return "", err
+	}
+	fw, err := writer.CreateFormFile("file", path.Base(key))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(fw, body); err != nil {
+		return "", err
+	}
+	writer.Close()
+
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", writer.FormDataContentType()).
+		SetHeader("MISTERBLAST_API_KEY", p.token).
+		SetBody(buf.Bytes()).
+		Post(p.url)
+	if err != nil {
+		log.Error("[Storage][Proxy] request failed", "error", err)
+		return "", err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		log.Error("[Storage][Proxy] upload failed", "status_code", resp.StatusCode(), "body", string(resp.Body()))
+		return "", fmt.Errorf("upload failed with status code %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var result proxyResponse
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return "", err
+	}
+	if result.Data.URL == "" {
+		return "", errors.New("no URL returned from server")
+	}
+	return result.Data.URL, nil
+}
+
+func (p *Proxy) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, ErrUnsupported
+}
+
+func (p *Proxy) Delete(ctx context.Context, key string) error {
+	return ErrUnsupported
+}
+
+func (p *Proxy) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (p *Proxy) SignedPutURL(ctx context.Context, key string, contentType string, size int64, expiry time.Duration) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (p *Proxy) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	return ObjectInfo{}, ErrUnsupported
+}
+
+// URL is unsupported because the file service picks the URL on upload.
+func (p *Proxy) URL(key string) (string, error) {
+	return "", ErrUnsupported
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"mime"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// SniffLen is how many leading bytes Sniff needs to recognise every type
+// it knows.
+const SniffLen = 3072
+
+// Sniff detects the content type from content's magic bytes, ignoring
+// whatever name or type the client sent with it. Parameters such as the
+// charset of text are dropped.
+func Sniff(content []byte) string {
+	detected := mimetype.Detect(content).String()
+	if mediaType, _, err := mime.ParseMediaType(detected); err == nil {
+		return mediaType
+	}
+	return detected
+}
+
+// Match returns the first of types that content conforms to. Storing content
+// under that type rather than its sniffed one keeps, say, an SVG allowed as
+// text/plain from being served as an image.
+func Match(content []byte, types []string) (string, bool) {
+	for _, t := range types {
+		if Conforms(content, t) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// Conforms reports whether content is of contentType or of a more specific
+// type, so a DOCX file conforms to both its own type and application/zip.
+func Conforms(content []byte, contentType string) bool {
+	for m := mimetype.Detect(content); m != nil; m = m.Parent() {
+		if m.Is(contentType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniff(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	assert.Equal(t, "image/png", storage.Sniff(png))
+	assert.Equal(t, "application/pdf", storage.Sniff([]byte("%PDF-1.7\n")))
+	assert.Equal(t, "text/plain", storage.Sniff([]byte("just some notes")))
+
+	assert.True(t, storage.Conforms(png, "image/png"))
+	assert.False(t, storage.Conforms(png, "image/jpeg"))
+	// A PDF renamed to .png is still a PDF.
+	assert.False(t, storage.Conforms([]byte("%PDF-1.7\n"), "image/png"))
+	assert.True(t, storage.Conforms([]byte("just some notes"), "text/plain; charset=utf-8"))
+}
+
+func TestMatch(t *testing.T) {
+	types := []string{"image/png", "application/pdf", "text/plain"}
+
+	contentType, ok := storage.Match([]byte("%PDF-1.7\n"), types)
+	assert.True(t, ok)
+	assert.Equal(t, "application/pdf", contentType)
+
+	// SVG is XML, so it only passes as plain text.
+	contentType, ok = storage.Match([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), types)
+	assert.True(t, ok)
+	assert.Equal(t, "text/plain", contentType)
+
+	_, ok = storage.Match([]byte("\x00\x01\x02\x03 not a known format"), types[:2])
+	assert.False(t, ok)
+}
@@ -0,0 +1,97 @@
+// Package storage stores uploaded files. The backend is chosen with
+// STORAGE_DRIVER: "proxy" (the default) posts to the file service at
+// STORAGE_URL, "local" writes to disk for development and tests, and "s3"
+// talks to any S3-compatible store such as MinIO.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("storage: object not found")
+	ErrUnsupported = errors.New("storage: operation not supported by this backend")
+	ErrInvalidKey  = errors.New("storage: invalid key")
+)
+
+// Storage keys are slash-separated paths such as
+// "prod/user/profile-img/42"; a leading slash is ignored.
+type Storage interface {
+	// Put stores the object and returns the URL it is served from.
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that grants read access until expiry passes.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// SignedPutURL returns a URL a client can PUT the object to directly
+	// until expiry passes. The request must carry the given Content-Type.
+	SignedPutURL(ctx context.Context, key string, contentType string, size int64, expiry time.Duration) (string, error)
+	// Stat reports what was actually stored, so directly uploaded objects
+	// can be checked before they are used.
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// URL is where the object is served from, the same URL Put returns.
+	URL(key string) (string, error)
+}
+
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// FromEnv builds the backend named by STORAGE_DRIVER.
+func FromEnv() (Storage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "proxy":
+		return NewProxy(os.Getenv("STORAGE_URL"), os.Getenv("FILE_SERVICE_TOKEN")), nil
+	case "local":
+		return NewLocal(envOr("STORAGE_LOCAL_DIR", "storage"), envOr("STORAGE_PUBLIC_URL", "/files"), os.Getenv("STORAGE_SIGNING_KEY"))
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    envOr("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") != "false",
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// ReadFile reads an uploaded file into memory so it can outlive the request,
+// e.g. as the blob of a background job.
+func ReadFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return io.ReadAll(src)
+}
+
+// cleanKey strips the leading slash and rejects keys that would escape the
+// store, such as "../secrets".
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}